@@ -0,0 +1,9 @@
+package enum
+
+// CurrencyPolicy decides what a checkout does with products priced in another currency than the one it is paid in.
+type CurrencyPolicy string
+
+const (
+	CurrencyPolicyReject  CurrencyPolicy = "REJECT"
+	CurrencyPolicyConvert CurrencyPolicy = "CONVERT"
+)
@@ -0,0 +1,9 @@
+package enum
+
+// IdempotencyStatus tells whether the first request of an Idempotency-Key is still running or has a stored response.
+type IdempotencyStatus string
+
+const (
+	IdempotencyStatusProcessing IdempotencyStatus = "PROCESSING"
+	IdempotencyStatusCompleted  IdempotencyStatus = "COMPLETED"
+)
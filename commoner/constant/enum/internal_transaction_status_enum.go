@@ -12,6 +12,8 @@ var (
 	TrxInternalStatusSettled               TrxInternalStatus = "SETTLED"
 	TrxInternalStatusCancelledBySystem     TrxInternalStatus = "CANCELED_BY_SYSTEM"
 	TrxInternalStatusCancelledByUser       TrxInternalStatus = "CANCELED_BY_USER"
+	TrxInternalStatusRefunding             TrxInternalStatus = "REFUNDING"
+	TrxInternalStatusPartiallyRefunded     TrxInternalStatus = "PARTIALLY_REFUNDED"
 	TrxInternalStatusRefunded              TrxInternalStatus = "REFUNDED"
 	TrxInternalStatusFailed                TrxInternalStatus = "FAILED"
 )
@@ -0,0 +1,17 @@
+package enum
+
+// InventoryMovementType tells how a movement of the inventory ledger changed the stock of a product.
+type InventoryMovementType string
+
+const (
+	// InventoryMovementReserve holds available stock for a transaction
+	InventoryMovementReserve InventoryMovementType = "RESERVE"
+	// InventoryMovementRelease gives held stock back when the transaction is canceled or expired
+	InventoryMovementRelease InventoryMovementType = "RELEASE"
+	// InventoryMovementCommit takes held stock off the shelf as sold when the transaction is settled
+	InventoryMovementCommit InventoryMovementType = "COMMIT"
+	// InventoryMovementRestock puts sold stock back on the shelf when it is refunded
+	InventoryMovementRestock InventoryMovementType = "RESTOCK"
+	// InventoryMovementAdjust is a change of the on hand stock by the owner
+	InventoryMovementAdjust InventoryMovementType = "ADJUST"
+)
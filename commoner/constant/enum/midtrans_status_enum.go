@@ -1,13 +1,17 @@
 package enum
 
+// MidtransPaymentStatus is the raw transaction_status reported by Midtrans, only the Midtrans payment provider
+// reads it and maps it to a PaymentStatus.
 type MidtransPaymentStatus string
 
 const (
-	PaymentStatusCapture    MidtransPaymentStatus = "capture"
-	PaymentStatusSettlement MidtransPaymentStatus = "settlement"
-	PaymentStatusPending    MidtransPaymentStatus = "pending"
-	PaymentStatusDeny       MidtransPaymentStatus = "deny"
-	PaymentStatusCancel     MidtransPaymentStatus = "cancel"
-	PaymentStatusExpire     MidtransPaymentStatus = "expire"
-	PaymentStatusFailure    MidtransPaymentStatus = "failure"
+	MidtransPaymentStatusCapture       MidtransPaymentStatus = "capture"
+	MidtransPaymentStatusSettlement    MidtransPaymentStatus = "settlement"
+	MidtransPaymentStatusPending       MidtransPaymentStatus = "pending"
+	MidtransPaymentStatusDeny          MidtransPaymentStatus = "deny"
+	MidtransPaymentStatusCancel        MidtransPaymentStatus = "cancel"
+	MidtransPaymentStatusExpire        MidtransPaymentStatus = "expire"
+	MidtransPaymentStatusFailure       MidtransPaymentStatus = "failure"
+	MidtransPaymentStatusRefund        MidtransPaymentStatus = "refund"
+	MidtransPaymentStatusPartialRefund MidtransPaymentStatus = "partial_refund"
 )
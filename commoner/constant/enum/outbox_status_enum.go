@@ -0,0 +1,9 @@
+package enum
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "PENDING"
+	OutboxStatusSent    OutboxStatus = "SENT"
+	OutboxStatusFailed  OutboxStatus = "FAILED"
+)
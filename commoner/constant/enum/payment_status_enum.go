@@ -0,0 +1,20 @@
+package enum
+
+// PaymentStatus is the provider-neutral outcome of a charge, every payment provider maps its own statuses to it.
+type PaymentStatus string
+
+const (
+	PaymentStatusPending  PaymentStatus = "PENDING"
+	PaymentStatusPaid     PaymentStatus = "PAID"
+	PaymentStatusFailed   PaymentStatus = "FAILED"
+	PaymentStatusExpired  PaymentStatus = "EXPIRED"
+	PaymentStatusCanceled PaymentStatus = "CANCELED"
+	PaymentStatusRefunded PaymentStatus = "REFUNDED"
+)
+
+type PaymentProvider string
+
+const (
+	PaymentProviderMidtrans PaymentProvider = "MIDTRANS"
+	PaymentProviderXendit   PaymentProvider = "XENDIT"
+)
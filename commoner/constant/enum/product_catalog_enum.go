@@ -0,0 +1,13 @@
+package enum
+
+// ProductCatalogSort is the order the public catalog lists products in.
+type ProductCatalogSort string
+
+const (
+	// ProductCatalogSortRelevance lists the best matches of the search query first, it needs a query
+	ProductCatalogSortRelevance ProductCatalogSort = "relevance"
+	ProductCatalogSortNewest    ProductCatalogSort = "newest"
+	ProductCatalogSortPriceAsc  ProductCatalogSort = "price_asc"
+	ProductCatalogSortPriceDesc ProductCatalogSort = "price_desc"
+	ProductCatalogSortName      ProductCatalogSort = "name"
+)
@@ -8,4 +8,5 @@ const (
 	ProductTransactionStatusComitted ProductTransactionStatusEnum = "COMMITED"
 	ProductTransactionStatusExpired  ProductTransactionStatusEnum = "EXPIRED"
 	ProductTransactionStatusSettled  ProductTransactionStatusEnum = "SETTLED"
+	ProductTransactionStatusRefunded ProductTransactionStatusEnum = "REFUNDED"
 )
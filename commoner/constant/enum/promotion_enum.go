@@ -0,0 +1,29 @@
+package enum
+
+// PromotionType is how a promotion takes money off the lines it applies to.
+type PromotionType string
+
+const (
+	PromotionTypePercentage PromotionType = "PERCENTAGE"
+	PromotionTypeFixed      PromotionType = "FIXED"
+	PromotionTypeBuyXGetY   PromotionType = "BUY_X_GET_Y"
+)
+
+// PromotionScope tells which lines of an order a promotion applies to, a single product or every product of an owner.
+type PromotionScope string
+
+const (
+	PromotionScopeAll     PromotionScope = "ALL"
+	PromotionScopeProduct PromotionScope = "PRODUCT"
+	PromotionScopeOwner   PromotionScope = "OWNER"
+)
+
+// PromotionUsageStatus follows a promo code through the checkout, a RESERVED usage counts against the usage limits
+// until the transaction settles (REDEEMED) or is canceled or expires (RELEASED).
+type PromotionUsageStatus string
+
+const (
+	PromotionUsageStatusReserved PromotionUsageStatus = "RESERVED"
+	PromotionUsageStatusRedeemed PromotionUsageStatus = "REDEEMED"
+	PromotionUsageStatusReleased PromotionUsageStatus = "RELEASED"
+)
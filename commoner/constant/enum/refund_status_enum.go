@@ -0,0 +1,18 @@
+package enum
+
+type RefundStatus string
+
+const (
+	RefundStatusRequested RefundStatus = "REQUESTED"
+	RefundStatusSucceeded RefundStatus = "SUCCEEDED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+)
+
+// RefundInitiator tells who asked for a refund, SYSTEM refunds are started for late settlements.
+type RefundInitiator string
+
+const (
+	RefundInitiatorUser   RefundInitiator = "USER"
+	RefundInitiatorOwner  RefundInitiator = "OWNER"
+	RefundInitiatorSystem RefundInitiator = "SYSTEM"
+)
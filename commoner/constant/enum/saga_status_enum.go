@@ -0,0 +1,26 @@
+package enum
+
+type SagaStatus string
+
+const (
+	SagaStatusRunning      SagaStatus = "RUNNING"
+	SagaStatusWaiting      SagaStatus = "WAITING"
+	SagaStatusCompleted    SagaStatus = "COMPLETED"
+	SagaStatusCompensating SagaStatus = "COMPENSATING"
+	SagaStatusCompensated  SagaStatus = "COMPENSATED"
+)
+
+// SagaStepStatusFailed marks a step whose outcome is unknown (retries exhausted or interrupted), so it is still
+// compensated, while SagaStepStatusRejected marks a step that was refused and left nothing behind to undo.
+type SagaStepStatus string
+
+const (
+	SagaStepStatusPending     SagaStepStatus = "PENDING"
+	SagaStepStatusRunning     SagaStepStatus = "RUNNING"
+	SagaStepStatusWaiting     SagaStepStatus = "WAITING"
+	SagaStepStatusRetrying    SagaStepStatus = "RETRYING"
+	SagaStepStatusCompleted   SagaStepStatus = "COMPLETED"
+	SagaStepStatusFailed      SagaStepStatus = "FAILED"
+	SagaStepStatusRejected    SagaStepStatus = "REJECTED"
+	SagaStepStatusCompensated SagaStepStatus = "COMPENSATED"
+)
@@ -7,4 +7,5 @@ const (
 	TransactionEventCancelled = "CANCELLED"
 	TransactionEventSettled   = "SETTLED"
 	TransactionEventExpired   = "EXPIRED"
+	TransactionEventRefunded  = "REFUNDED"
 )
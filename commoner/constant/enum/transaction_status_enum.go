@@ -3,11 +3,12 @@ package enum
 type TransactionStatus string
 
 var (
-	TransactionStatusPending   TransactionStatus = "PENDING"
-	TransactionStatusExpired   TransactionStatus = "EXPIRED"
-	TransactionStatusSuccess   TransactionStatus = "SUCCESS"
-	TransactionStatusCancelled TransactionStatus = "CANCELED"
-	TransactionStatusRefunding TransactionStatus = "REFUNDING"
-	TransactionStatusRefunded  TransactionStatus = "REFUNDED"
-	TransactionStatusFailed    TransactionStatus = "FAILED"
+	TransactionStatusPending           TransactionStatus = "PENDING"
+	TransactionStatusExpired           TransactionStatus = "EXPIRED"
+	TransactionStatusSuccess           TransactionStatus = "SUCCESS"
+	TransactionStatusCancelled         TransactionStatus = "CANCELED"
+	TransactionStatusRefunding         TransactionStatus = "REFUNDING"
+	TransactionStatusPartiallyRefunded TransactionStatus = "PARTIALLY_REFUNDED"
+	TransactionStatusRefunded          TransactionStatus = "REFUNDED"
+	TransactionStatusFailed            TransactionStatus = "FAILED"
 )
@@ -4,4 +4,5 @@ const (
 	InternalNoRowsAffected = "no rows affected"
 	MultipleRowsAffected   = "multiple rows affected, expected only one row"
 	InternalGracefulError  = "Something wrong happened. Please try again"
+	DeadLetterNotFound     = "Dead letter not found for the given sequence"
 )
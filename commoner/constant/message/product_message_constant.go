@@ -7,9 +7,29 @@ const (
 	RequestedProductMoreThanAvailable = "Requested product quantity is more than available stock"
 	PriceChanged                      = "Product price has been changed, please check again"
 	ProductNotFound                   = "Product not found for the given id/uuid"
+	ProductVariantRequired            = "Product has variants, please choose one of them"
+	ProductVariantNotFound            = "Product variant not found for the given id/uuid"
+	DuplicateProductLine              = "The same product variant is requested more than once"
+	CatalogCursorInvalid              = "Cursor is invalid or was made for another sort"
+	CatalogRelevanceNeedsQuery        = "Sorting by relevance needs a search query"
+	CatalogPriceRangeInvalid          = "Minimum price must not be more than maximum price"
 
 	//owner side
 	ProductNotFoundOrAlreadyDeleted = "Product not found or already deleted"
 	ProductIsExistsByNameOrSlug     = "Product with the same name or slug already exists"
 	ProductTranscationNotFound      = "Product transaction not found for the given id/uuid"
+	CurrencyNotSupported            = "Currency is not supported"
+	RefundItemsRequired             = "Refund event must carry a refund id and the refunded items"
+	StockBelowReserved              = "On hand stock cannot be lower than the stock reserved by pending transactions"
+	ProductStockManagedByVariants   = "Stock of a product with variants is managed through its variants"
+	ProductStockMustBeEmpty         = "Product stock must be empty before its stock is split into variants"
+	ProductOptionsInvalid           = "Product options must have unique names and unique values"
+	ProductOptionsInUse             = "Product options cannot drop an option or value still used by a variant"
+	VariantOptionsMismatch          = "Variant must choose exactly one value of every product option"
+	VariantCurrencyMismatch         = "Variant price must be in the currency of the product"
+	VariantIsExistsBySKUOrOptions   = "Variant with the same sku or options already exists"
+	CategoryNotFound                = "Category not found for the given id/uuid"
+	CategoryIsExistsByName          = "Category with the same name already exists under this parent"
+	CategoryParentInvalid           = "Category cannot be moved under itself or one of its subcategories"
+	CategoryInUse                   = "Category still has subcategories or products"
 )
@@ -2,6 +2,52 @@ package message
 
 const (
 	//buyer side
-	TransactionNotFound       = "Transaction not found for the given id/uuid"
-	TransactionIsNotExpirable = "transaction is not pending, token ready, or expire"
+	TransactionNotFound        = "Transaction not found for the given id/uuid"
+	TransactionIsNotExpirable  = "transaction is not pending, token ready, or expire"
+	SagaNotFound               = "Saga not found for the given transaction id/uuid"
+	TransactionIsNotCancelable = "Transaction is no longer waiting for payment and cannot be canceled"
+
+	//refund
+	TransactionIsNotRefundable = "Transaction is not paid or has already been fully refunded"
+	RefundInProgress           = "Another refund for this transaction is still in progress"
+	RefundItemNotFound         = "Transaction detail not found in the given transaction"
+	RefundQuantityExceeded     = "Refund quantity exceeds the quantity that can still be refunded"
+	RefundItemDuplicated       = "A transaction detail can only be listed once per refund"
+	OwnerRefundItemsRequired   = "Owner refunds must list the transaction details to refund"
+	RefundAmountIsZero         = "Nothing was paid for the items to refund"
+
+	//idempotency
+	IdempotencyKeyTooLong    = "Idempotency-Key must not be longer than 255 characters"
+	IdempotencyKeyInProgress = "A request with the same Idempotency-Key is still being processed"
+	IdempotencyKeyReused     = "Idempotency-Key was already used for a different request"
+
+	//cart
+	CartNotFound             = "Cart not found for the given id"
+	CartIsEmpty              = "Cart is empty"
+	CartItemNotFound         = "Product is not in the cart"
+	CartProductNotFound      = "Product not found or no longer available"
+	CartQuantityExceedsStock = "Requested quantity exceeds the available stock"
+
+	//currency
+	CurrencyMismatch        = "Products priced in different currencies cannot be checked out together"
+	ExchangeRateUnavailable = "No exchange rate is available for the requested currency"
+
+	//promotion
+	PromotionNotFound           = "Promo code not found"
+	PromotionNotActive          = "Promo code is not active"
+	PromotionNotApplicable      = "Promo code does not apply to any product in the order"
+	PromotionCurrencyMismatch   = "Promo code does not apply to orders in this currency"
+	PromotionUsageLimitReached  = "Promo code has reached its usage limit"
+	PromotionCodeAlreadyExists  = "Promo code already exists"
+	PromotionPercentageRequired = "Percentage promotions need a percentage above 0 and up to 100"
+	PromotionAmountRequired     = "Fixed promotions need an amount"
+	PromotionBuyGetRequired     = "Buy X get Y promotions need buy_quantity and get_quantity"
+	PromotionScopeIDRequired    = "Product and owner promotions need a scope_id"
+	PromotionPeriodInvalid      = "ends_at must be after starts_at"
+	DiscountedTotalNotPayable   = "Discounted total must be greater than zero"
+
+	//payment provider
+	PaymentProviderNotSupported = "Payment provider is not supported"
+	PaymentProviderMismatch     = "Payment notification does not belong to the transaction's payment provider"
+	InvalidPaymentNotification  = "Payment notification could not be parsed"
 )
@@ -0,0 +1,84 @@
+package dlq
+
+import (
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/web"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeadLetterController serves the admin endpoints of the dead letter queue of a service.
+type DeadLetterController interface {
+	Search(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	Replay(ctx *fiber.Ctx) error
+}
+
+type deadLetterController struct {
+	deadLetterUseCase DeadLetterUseCase
+	logs              logs.Log
+}
+
+func NewDeadLetterController(deadLetterUseCase DeadLetterUseCase, logs logs.Log) DeadLetterController {
+	return &deadLetterController{deadLetterUseCase: deadLetterUseCase, logs: logs}
+}
+
+func (c *deadLetterController) Search(ctx *fiber.Ctx) error {
+	request := new(SearchDeadLettersRequest)
+	request.Limit = ctx.QueryInt("limit", 20)
+	request.FromSequence = uint64(max(ctx.QueryInt("from_sequence", 0), 0))
+
+	entries, err := c.deadLetterUseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Search dead letters error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[[]*Entry]{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+func (c *deadLetterController) Get(ctx *fiber.Ctx) error {
+	sequence, err := strconv.ParseUint(ctx.Params("sequence"), 10, 64)
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid dead letter sequence format")
+	}
+
+	entry, err := c.deadLetterUseCase.Get(ctx.UserContext(), &GetDeadLetterRequest{Sequence: sequence})
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get dead letter error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*Entry]{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+func (c *deadLetterController) Replay(ctx *fiber.Ctx) error {
+	sequence, err := strconv.ParseUint(ctx.Params("sequence"), 10, 64)
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid dead letter sequence format")
+	}
+
+	entry, err := c.deadLetterUseCase.Replay(ctx.UserContext(), &ReplayDeadLetterRequest{Sequence: sequence})
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Replay dead letter error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*Entry]{
+		Success: true,
+		Data:    entry,
+	})
+}
+
+// RegisterRoutes serves the controller under router, which is expected to be guarded by admin authentication.
+func RegisterRoutes(router fiber.Router, controller DeadLetterController) {
+	router.Get("/dlq", controller.Search)
+	router.Get("/dlq/:sequence", controller.Get)
+	router.Post("/dlq/:sequence/replay", controller.Replay)
+}
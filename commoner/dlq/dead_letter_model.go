@@ -0,0 +1,14 @@
+package dlq
+
+type SearchDeadLettersRequest struct {
+	FromSequence uint64
+	Limit        int `validate:"required,min=1,max=100"`
+}
+
+type GetDeadLetterRequest struct {
+	Sequence uint64 `validate:"required,min=1"`
+}
+
+type ReplayDeadLetterRequest struct {
+	Sequence uint64 `validate:"required,min=1"`
+}
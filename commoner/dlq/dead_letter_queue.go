@@ -0,0 +1,267 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Headers added to every dead-lettered message, the headers of the original message are kept next to them.
+const (
+	HeaderOriginalStream   = "Dlq-Original-Stream"
+	HeaderOriginalSubject  = "Dlq-Original-Subject"
+	HeaderOriginalSequence = "Dlq-Original-Sequence"
+	HeaderOriginalMsgID    = "Dlq-Original-Msg-Id"
+	HeaderConsumer         = "Dlq-Consumer"
+	HeaderDeliveryCount    = "Dlq-Delivery-Count"
+	HeaderFailureReason    = "Dlq-Failure-Reason"
+	HeaderFailedAt         = "Dlq-Failed-At"
+	HeaderReplayedFrom     = "Dlq-Replayed-From"
+)
+
+// SubjectPrefix is prepended to the original subject, a message failed on "transaction.expired" is stored
+// under "dlq.transaction.expired".
+const SubjectPrefix = "dlq."
+
+// publishRetryDelays are the waits between the attempts of Move to publish a message, the last delivery of a message
+// keeps being retried every finalDeliveryRetryDelay after them.
+var (
+	publishRetryDelays      = []time.Duration{1 * time.Second, 2 * time.Second, 5 * time.Second}
+	finalDeliveryRetryDelay = 10 * time.Second
+)
+
+const redeliveryDelay = 10 * time.Second
+
+var ErrEntryNotFound = errors.New("dead letter entry not found")
+
+type Entry struct {
+	Sequence         uint64              `json:"sequence"`
+	OriginalStream   string              `json:"original_stream"`
+	OriginalSubject  string              `json:"original_subject"`
+	OriginalSequence uint64              `json:"original_sequence"`
+	Consumer         string              `json:"consumer"`
+	DeliveryCount    uint64              `json:"delivery_count"`
+	FailureReason    string              `json:"failure_reason"`
+	FailedAt         string              `json:"failed_at"`
+	Headers          map[string][]string `json:"headers"`
+	Payload          string              `json:"payload"`
+}
+
+type DeadLetterQueue interface {
+	Stream() string
+	Publish(msg *nats.Msg, reason string) error
+	Move(ctx context.Context, msg *nats.Msg, reason string, maxDeliver int) error
+	List(ctx context.Context, fromSequence uint64, limit int) ([]*Entry, error)
+	Get(ctx context.Context, sequence uint64) (*Entry, error)
+	Replay(ctx context.Context, sequence uint64) (*Entry, error)
+}
+
+// delivery acknowledges a delivered JetStream message, it is implemented by *nats.Msg.
+type delivery interface {
+	Metadata() (*nats.MsgMetadata, error)
+	InProgress(opts ...nats.AckOpt) error
+	NakWithDelay(delay time.Duration, opts ...nats.AckOpt) error
+	Term(opts ...nats.AckOpt) error
+}
+
+type deadLetterQueue struct {
+	js     nats.JetStreamContext
+	stream string
+}
+
+func NewDeadLetterQueue(js nats.JetStreamContext, stream string) DeadLetterQueue {
+	return &deadLetterQueue{js: js, stream: stream}
+}
+
+func (q *deadLetterQueue) Stream() string {
+	return q.stream
+}
+
+// Publish copies a terminally failed JetStream message into the DLQ stream together with the failure reason.
+// The DLQ message id is derived from the original stream sequence, so publishing the same delivery twice stores it once.
+func (q *deadLetterQueue) Publish(msg *nats.Msg, reason string) error {
+	deadLetter := nats.NewMsg(SubjectPrefix + msg.Subject)
+	deadLetter.Data = msg.Data
+
+	for key, values := range msg.Header {
+		for _, value := range values {
+			deadLetter.Header.Add(key, value)
+		}
+	}
+
+	if msgID := msg.Header.Get(nats.MsgIdHdr); msgID != "" {
+		deadLetter.Header.Set(HeaderOriginalMsgID, msgID)
+	}
+	deadLetter.Header.Del(nats.MsgIdHdr)
+
+	if meta, err := msg.Metadata(); err == nil {
+		deadLetter.Header.Set(HeaderOriginalStream, meta.Stream)
+		deadLetter.Header.Set(HeaderOriginalSequence, strconv.FormatUint(meta.Sequence.Stream, 10))
+		deadLetter.Header.Set(HeaderConsumer, meta.Consumer)
+		deadLetter.Header.Set(HeaderDeliveryCount, strconv.FormatUint(meta.NumDelivered, 10))
+		deadLetter.Header.Set(nats.MsgIdHdr, fmt.Sprintf("%s:%d", meta.Stream, meta.Sequence.Stream))
+	}
+
+	deadLetter.Header.Set(HeaderOriginalSubject, msg.Subject)
+	deadLetter.Header.Set(HeaderFailureReason, reason)
+	deadLetter.Header.Set(HeaderFailedAt, time.Now().UTC().Format(time.RFC3339))
+
+	if _, err := q.js.PublishMsg(deadLetter); err != nil {
+		return fmt.Errorf("failed to publish to dead letter stream %q: %w", q.stream, err)
+	}
+
+	return nil
+}
+
+// Move publishes a terminally failed message to the DLQ and terminates it on its stream. A failed publish is retried
+// while the message is kept in progress, so its ack wait does not run out. When the DLQ stays unreachable a message
+// with deliveries left is NAKed to be redelivered. The last delivery is never NAKed, JetStream would drop it from the
+// original stream, it is retried until the publish succeeds or ctx is done.
+func (q *deadLetterQueue) Move(ctx context.Context, msg *nats.Msg, reason string, maxDeliver int) error {
+	return q.move(ctx, msg, msg, reason, maxDeliver)
+}
+
+func (q *deadLetterQueue) move(ctx context.Context, msg *nats.Msg, ack delivery, reason string, maxDeliver int) error {
+	finalDelivery := false
+	if meta, err := ack.Metadata(); err == nil {
+		finalDelivery = meta.NumDelivered >= uint64(maxDeliver)
+	}
+
+	err := q.Publish(msg, reason)
+	for attempt := 0; err != nil; attempt++ {
+		delay := finalDeliveryRetryDelay
+		if attempt < len(publishRetryDelays) {
+			delay = publishRetryDelays[attempt]
+		} else if !finalDelivery {
+			if nakErr := ack.NakWithDelay(redeliveryDelay); nakErr != nil {
+				return errors.Join(err, fmt.Errorf("failed to NAK message: %w", nakErr))
+			}
+			return err
+		}
+
+		// a failed heartbeat only shortens the ack wait, the publish is retried all the same
+		_ = ack.InProgress()
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+
+		err = q.Publish(msg, reason)
+	}
+
+	if err := ack.Term(); err != nil {
+		return fmt.Errorf("failed to TERM message: %w", err)
+	}
+
+	return nil
+}
+
+// List returns up to limit entries starting at fromSequence, replayed entries are deleted from the stream and skipped.
+func (q *deadLetterQueue) List(ctx context.Context, fromSequence uint64, limit int) ([]*Entry, error) {
+	info, err := q.js.StreamInfo(q.stream, nats.Context(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter stream %q info: %w", q.stream, err)
+	}
+
+	entries := make([]*Entry, 0, limit)
+	if info.State.Msgs == 0 {
+		return entries, nil
+	}
+
+	if fromSequence < info.State.FirstSeq {
+		fromSequence = info.State.FirstSeq
+	}
+
+	for sequence := fromSequence; sequence <= info.State.LastSeq && len(entries) < limit; sequence++ {
+		entry, err := q.Get(ctx, sequence)
+		if errors.Is(err, ErrEntryNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (q *deadLetterQueue) Get(ctx context.Context, sequence uint64) (*Entry, error) {
+	rawMsg, err := q.js.GetMsg(q.stream, sequence, nats.Context(ctx))
+	if err != nil {
+		if errors.Is(err, nats.ErrMsgNotFound) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, fmt.Errorf("failed to get dead letter %d from stream %q: %w", sequence, q.stream, err)
+	}
+
+	return rawMsgToEntry(rawMsg), nil
+}
+
+// Replay re-publishes the entry to its original subject and removes it from the DLQ. The Dlq-* headers are dropped
+// and the message gets a fresh Nats-Msg-Id so the original stream does not discard it as a duplicate.
+func (q *deadLetterQueue) Replay(ctx context.Context, sequence uint64) (*Entry, error) {
+	entry, err := q.Get(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.OriginalSubject == "" {
+		return nil, fmt.Errorf("dead letter %d from stream %q has no original subject", sequence, q.stream)
+	}
+
+	msg := nats.NewMsg(entry.OriginalSubject)
+	msg.Data = []byte(entry.Payload)
+	for key, values := range entry.Headers {
+		if strings.HasPrefix(key, "Dlq-") || key == nats.MsgIdHdr {
+			continue
+		}
+		for _, value := range values {
+			msg.Header.Add(key, value)
+		}
+	}
+
+	replayedFrom := fmt.Sprintf("%s:%d", q.stream, sequence)
+	msg.Header.Set(HeaderReplayedFrom, replayedFrom)
+	msg.Header.Set(nats.MsgIdHdr, replayedFrom)
+
+	if _, err := q.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to replay dead letter %d to subject %q: %w", sequence, entry.OriginalSubject, err)
+	}
+
+	if err := q.js.DeleteMsg(q.stream, sequence, nats.Context(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to delete replayed dead letter %d from stream %q: %w", sequence, q.stream, err)
+	}
+
+	return entry, nil
+}
+
+func rawMsgToEntry(rawMsg *nats.RawStreamMsg) *Entry {
+	originalSequence, _ := strconv.ParseUint(rawMsg.Header.Get(HeaderOriginalSequence), 10, 64)
+	deliveryCount, _ := strconv.ParseUint(rawMsg.Header.Get(HeaderDeliveryCount), 10, 64)
+
+	originalSubject := rawMsg.Header.Get(HeaderOriginalSubject)
+	if originalSubject == "" {
+		originalSubject = strings.TrimPrefix(rawMsg.Subject, SubjectPrefix)
+	}
+
+	return &Entry{
+		Sequence:         rawMsg.Sequence,
+		OriginalStream:   rawMsg.Header.Get(HeaderOriginalStream),
+		OriginalSubject:  originalSubject,
+		OriginalSequence: originalSequence,
+		Consumer:         rawMsg.Header.Get(HeaderConsumer),
+		DeliveryCount:    deliveryCount,
+		FailureReason:    rawMsg.Header.Get(HeaderFailureReason),
+		FailedAt:         rawMsg.Header.Get(HeaderFailedAt),
+		Headers:          rawMsg.Header,
+		Payload:          string(rawMsg.Data),
+	}
+}
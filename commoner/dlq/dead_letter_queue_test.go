@@ -0,0 +1,198 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const maxDeliver = 5
+
+var errUnavailable = errors.New("nats: no responders available for request")
+
+type fakeJetStream struct {
+	nats.JetStreamContext
+	failures  int
+	attempts  int
+	published []*nats.Msg
+}
+
+func (js *fakeJetStream) PublishMsg(m *nats.Msg, _ ...nats.PubOpt) (*nats.PubAck, error) {
+	js.attempts++
+	if js.failures < 0 || js.attempts <= js.failures {
+		return nil, errUnavailable
+	}
+	js.published = append(js.published, m)
+	return &nats.PubAck{Stream: "DLQ", Sequence: uint64(len(js.published))}, nil
+}
+
+type fakeDelivery struct {
+	numDelivered uint64
+	termErr      error
+	inProgress   int
+	naks         []time.Duration
+	terms        int
+}
+
+func (d *fakeDelivery) Metadata() (*nats.MsgMetadata, error) {
+	return &nats.MsgMetadata{Stream: "TRANSACTIONS", Consumer: "product-svc", NumDelivered: d.numDelivered}, nil
+}
+
+func (d *fakeDelivery) InProgress(_ ...nats.AckOpt) error {
+	d.inProgress++
+	return nil
+}
+
+func (d *fakeDelivery) NakWithDelay(delay time.Duration, _ ...nats.AckOpt) error {
+	d.naks = append(d.naks, delay)
+	return nil
+}
+
+func (d *fakeDelivery) Term(_ ...nats.AckOpt) error {
+	d.terms++
+	return d.termErr
+}
+
+func shortenRetryDelays(t *testing.T) {
+	publish, final := publishRetryDelays, finalDeliveryRetryDelay
+	publishRetryDelays = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	finalDeliveryRetryDelay = time.Millisecond
+	t.Cleanup(func() {
+		publishRetryDelays, finalDeliveryRetryDelay = publish, final
+	})
+}
+
+func TestDeadLetterQueue_Move(t *testing.T) {
+	shortenRetryDelays(t)
+
+	tests := []struct {
+		name           string
+		numDelivered   uint64
+		failures       int
+		termErr        error
+		wantErr        bool
+		wantAttempts   int
+		wantPublished  int
+		wantInProgress int
+		wantNaks       []time.Duration
+		wantTerms      int
+	}{
+		{
+			name:          "published delivery is terminated",
+			numDelivered:  1,
+			wantAttempts:  1,
+			wantPublished: 1,
+			wantTerms:     1,
+		},
+		{
+			name:           "failed publish is retried before terminating",
+			numDelivered:   1,
+			failures:       2,
+			wantAttempts:   3,
+			wantPublished:  1,
+			wantInProgress: 2,
+			wantTerms:      1,
+		},
+		{
+			name:           "delivery with deliveries left is NAKed when the queue stays unreachable",
+			numDelivered:   maxDeliver - 1,
+			failures:       -1,
+			wantErr:        true,
+			wantAttempts:   4,
+			wantInProgress: 3,
+			wantNaks:       []time.Duration{redeliveryDelay},
+		},
+		{
+			name:          "final delivery is published then terminated",
+			numDelivered:  maxDeliver,
+			wantAttempts:  1,
+			wantPublished: 1,
+			wantTerms:     1,
+		},
+		{
+			name:           "final delivery is never NAKed, it is retried until the publish succeeds",
+			numDelivered:   maxDeliver,
+			failures:       6,
+			wantAttempts:   7,
+			wantPublished:  1,
+			wantInProgress: 6,
+			wantTerms:      1,
+		},
+		{
+			name:          "failed TERM is returned",
+			numDelivered:  maxDeliver,
+			termErr:       nats.ErrMsgAlreadyAckd,
+			wantErr:       true,
+			wantAttempts:  1,
+			wantPublished: 1,
+			wantTerms:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js := &fakeJetStream{failures: tt.failures}
+			ack := &fakeDelivery{numDelivered: tt.numDelivered, termErr: tt.termErr}
+			q := &deadLetterQueue{js: js, stream: "DLQ"}
+
+			err := q.move(context.Background(), nats.NewMsg("transaction.created"), ack, "handler failed", maxDeliver)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, js.attempts)
+			assert.Len(t, js.published, tt.wantPublished)
+			assert.Equal(t, tt.wantInProgress, ack.inProgress)
+			assert.Equal(t, tt.wantNaks, ack.naks)
+			assert.Equal(t, tt.wantTerms, ack.terms)
+		})
+	}
+}
+
+func TestDeadLetterQueue_Move_FinalDeliveryStopsWhenContextIsDone(t *testing.T) {
+	shortenRetryDelays(t)
+
+	js := &fakeJetStream{failures: -1}
+	ack := &fakeDelivery{numDelivered: maxDeliver}
+	q := &deadLetterQueue{js: js, stream: "DLQ"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := q.move(ctx, nats.NewMsg("transaction.created"), ack, "handler failed", maxDeliver)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Greater(t, js.attempts, len(publishRetryDelays)+1)
+	assert.Empty(t, js.published)
+	assert.Empty(t, ack.naks)
+	assert.Zero(t, ack.terms)
+}
+
+func TestDeadLetterQueue_Publish(t *testing.T) {
+	js := &fakeJetStream{}
+	q := &deadLetterQueue{js: js, stream: "DLQ"}
+
+	msg := nats.NewMsg("transaction.created")
+	msg.Data = []byte(`{"id":"1"}`)
+	msg.Header.Set(nats.MsgIdHdr, "transaction-1")
+
+	require.NoError(t, q.Publish(msg, "handler failed"))
+	require.Len(t, js.published, 1)
+
+	deadLetter := js.published[0]
+	assert.Equal(t, SubjectPrefix+"transaction.created", deadLetter.Subject)
+	assert.Equal(t, msg.Data, deadLetter.Data)
+	assert.Equal(t, "transaction-1", deadLetter.Header.Get(HeaderOriginalMsgID))
+	assert.Equal(t, "transaction.created", deadLetter.Header.Get(HeaderOriginalSubject))
+	assert.Equal(t, "handler failed", deadLetter.Header.Get(HeaderFailureReason))
+	assert.Empty(t, deadLetter.Header.Get(nats.MsgIdHdr))
+}
@@ -0,0 +1,80 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+
+	"go.uber.org/zap"
+)
+
+// DeadLetterUseCase lets admins inspect and replay the dead letters of a service.
+type DeadLetterUseCase interface {
+	Search(ctx context.Context, request *SearchDeadLettersRequest) ([]*Entry, error)
+	Get(ctx context.Context, request *GetDeadLetterRequest) (*Entry, error)
+	Replay(ctx context.Context, request *ReplayDeadLetterRequest) (*Entry, error)
+}
+
+type deadLetterUseCase struct {
+	deadLetterQueue DeadLetterQueue
+	validator       helper.CustomValidator
+	log             logs.Log
+}
+
+func NewDeadLetterUseCase(deadLetterQueue DeadLetterQueue, validator helper.CustomValidator, log logs.Log) DeadLetterUseCase {
+	return &deadLetterUseCase{
+		deadLetterQueue: deadLetterQueue,
+		validator:       validator,
+		log:             log,
+	}
+}
+
+func (uc *deadLetterUseCase) Search(ctx context.Context, request *SearchDeadLettersRequest) ([]*Entry, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	entries, err := uc.deadLetterQueue.List(ctx, request.FromSequence, request.Limit)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to list dead letters", err)
+	}
+
+	return entries, nil
+}
+
+func (uc *deadLetterUseCase) Get(ctx context.Context, request *GetDeadLetterRequest) (*Entry, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	entry, err := uc.deadLetterQueue.Get(ctx, request.Sequence)
+	if err != nil {
+		if errors.Is(err, ErrEntryNotFound) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.DeadLetterNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to get dead letter", err)
+	}
+
+	return entry, nil
+}
+
+func (uc *deadLetterUseCase) Replay(ctx context.Context, request *ReplayDeadLetterRequest) (*Entry, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	entry, err := uc.deadLetterQueue.Replay(ctx, request.Sequence)
+	if err != nil {
+		if errors.Is(err, ErrEntryNotFound) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.DeadLetterNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to replay dead letter", err)
+	}
+
+	uc.log.Info("dead letter replayed", zap.String("stream", uc.deadLetterQueue.Stream()),
+		zap.Uint64("sequence", entry.Sequence), zap.String("subject", entry.OriginalSubject))
+	return entry, nil
+}
@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"crypto/subtle"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewAdminAuth guards operational endpoints with the shared ADMIN_API_KEY, every request is rejected when it is not set.
+func NewAdminAuth(logs logs.Log) fiber.Handler {
+	adminAPIKey := utils.GetEnv("ADMIN_API_KEY")
+	if adminAPIKey == "" {
+		logs.Warn("ADMIN_API_KEY is not set, admin endpoints are disabled")
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get("X-Admin-Key", "")
+		if adminAPIKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(adminAPIKey)) != 1 {
+			return fiber.NewError(fiber.ErrUnauthorized.Code, "Unauthorized access")
+		}
+
+		return ctx.Next()
+	}
+}
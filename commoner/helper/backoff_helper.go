@@ -0,0 +1,12 @@
+package helper
+
+import "time"
+
+// ExponentialBackoff returns base doubled once per attempt, base, 2*base, 4*base, ... capped at maxDelay.
+func ExponentialBackoff(attempts int, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
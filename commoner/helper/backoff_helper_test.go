@@ -0,0 +1,34 @@
+package helper_test
+
+import (
+	"go-saga-pattern/commoner/helper"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		base     time.Duration
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{name: "no attempt yet", attempts: 0, base: time.Second, maxDelay: 5 * time.Minute, want: time.Second},
+		{name: "first retry", attempts: 1, base: time.Second, maxDelay: 5 * time.Minute, want: 2 * time.Second},
+		{name: "doubles every attempt", attempts: 3, base: time.Second, maxDelay: 5 * time.Minute, want: 8 * time.Second},
+		{name: "last attempt below the cap", attempts: 8, base: time.Second, maxDelay: 5 * time.Minute, want: 256 * time.Second},
+		{name: "capped", attempts: 9, base: time.Second, maxDelay: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "does not overflow", attempts: 200, base: time.Second, maxDelay: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "other base", attempts: 5, base: 2 * time.Second, maxDelay: 2 * time.Minute, want: 64 * time.Second},
+		{name: "base above the cap", attempts: 0, base: time.Minute, maxDelay: 30 * time.Second, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, helper.ExponentialBackoff(tt.attempts, tt.base, tt.maxDelay))
+		})
+	}
+}
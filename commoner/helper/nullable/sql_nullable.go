@@ -3,6 +3,8 @@ package nullable
 import (
 	"database/sql"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // ToSQLFloat64 mengonversi pointer float64 ke sql.NullFloat64
@@ -49,6 +51,16 @@ func ToSQLString(input *string) sql.NullString {
 	}
 }
 
+func ToNullUUID(input *uuid.UUID) uuid.NullUUID {
+	if input == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{
+		UUID:  *input,
+		Valid: true,
+	}
+}
+
 func ToSQLTime(input time.Time) sql.NullTime {
 	if input.IsZero() {
 		return sql.NullTime{}
@@ -93,3 +105,10 @@ func SQLtoTime(ns sql.NullTime) string {
 	}
 	return ""
 }
+
+func UUIDtoString(id uuid.NullUUID) string {
+	if id.Valid {
+		return id.UUID.String()
+	}
+	return ""
+}
@@ -40,3 +40,30 @@ func GeneratePageURLs(baseURL string, metadata *web.PageMetadata) {
 		metadata.PreviousPageURL = parsedURL.String()
 	}
 }
+
+// CalculateCursorPagination describes a keyset paginated page, an empty next cursor means it is the last page.
+func CalculateCursorPagination(size int, nextCursor string) *web.PageMetadata {
+	return &web.PageMetadata{
+		Size:       size,
+		HasNext:    nextCursor != "",
+		NextCursor: nextCursor,
+	}
+}
+
+// GenerateCursorPageURL sets the next page URL of a keyset paginated page, pageURL keeps the query of the current
+// page so the filters carry over.
+func GenerateCursorPageURL(pageURL string, metadata *web.PageMetadata) {
+	if !metadata.HasNext {
+		return
+	}
+
+	parsedURL, err := url.Parse(pageURL)
+	if err != nil {
+		return
+	}
+
+	q := parsedURL.Query()
+	q.Set("cursor", metadata.NextCursor)
+	parsedURL.RawQuery = q.Encode()
+	metadata.NextPageURL = parsedURL.String()
+}
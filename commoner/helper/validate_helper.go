@@ -2,6 +2,8 @@ package helper
 
 import (
 	"fmt"
+	"go-saga-pattern/commoner/money"
+	"reflect"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -23,6 +25,11 @@ type customValidator struct {
 func NewCustomValidator() CustomValidator {
 	validate := validator.New()
 	validate.RegisterValidation("timeformat", timeFormatValidation)
+	validate.RegisterValidation("currency", currencyValidation)
+	// Money fields are validated on their amount, so gt=0 on a price checks the minor units
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(money.Money).Amount
+	}, money.Money{})
 	return &customValidator{Validator: validate}
 }
 
@@ -33,12 +40,18 @@ func timeFormatValidation(fl validator.FieldLevel) bool {
 	return err == nil
 }
 
+func currencyValidation(fl validator.FieldLevel) bool {
+	return money.Supported(fl.Field().String())
+}
+
 func getErrorMessage(err validator.FieldError) string {
 	switch err.Tag() {
 	case "required":
 		return fmt.Sprintf("%s is required", err.Field())
 	case "timeformat":
 		return fmt.Sprintf("'%s' must be a valid time format (example: %s)", err.Field(), time.RFC3339Nano)
+	case "currency":
+		return fmt.Sprintf("%s must be a supported currency code", err.Field())
 	case "email":
 		return fmt.Sprintf("%s must be a valid email address", err.Field())
 	case "min":
@@ -0,0 +1,386 @@
+// Package money keeps amounts as a whole number of the minor unit of their currency, so prices, sums and
+// proratings stay exact. Amounts are stored as NUMERIC(19,2) and travel as the Money proto message.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts that do not carry a currency, such as the price columns.
+const DefaultCurrency = "IDR"
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrOverflow         = errors.New("money: amount out of range")
+	ErrNotWholeUnits    = errors.New("money: amount is not a whole number of major units")
+)
+
+// minorUnitDigits is the ISO 4217 exponent of the supported currencies, the columns keep two decimals so no
+// currency may use more.
+var minorUnitDigits = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"JPY": 0,
+}
+
+// payableDigits is the number of decimals payment gateways charge a currency in when it is less than the exponent of
+// the currency, rupiah are charged in whole rupiah without sen.
+var payableDigits = map[string]int{
+	"IDR": 0,
+}
+
+// Money is an amount in the minor unit of its currency, Money{Amount: 10050, Currency: "IDR"} is Rp100.50.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: normalizeCurrency(currency)}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Supported tells whether the currency is one amounts may be kept in.
+func Supported(currency string) bool {
+	_, ok := minorUnitDigits[normalizeCurrency(currency)]
+	return ok
+}
+
+// Exponent returns the number of decimals of the currency, unknown currencies use the two decimals of the columns.
+func Exponent(currency string) int {
+	if digits, ok := minorUnitDigits[normalizeCurrency(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// PayableExponent returns the number of decimals payment gateways charge the currency in.
+func PayableExponent(currency string) int {
+	if digits, ok := payableDigits[normalizeCurrency(currency)]; ok {
+		return digits
+	}
+	return Exponent(currency)
+}
+
+// Parse reads a decimal amount such as "100.50" or "-3". Decimals beyond the exponent of the currency must be zero,
+// use Round to parse a value that needs rounding.
+func Parse(value, currency string) (Money, error) {
+	currency = normalizeCurrency(currency)
+	negative, whole, fraction, err := splitDecimal(value)
+	if err != nil {
+		return Money{}, err
+	}
+
+	exponent := Exponent(currency)
+	if len(fraction) > exponent {
+		if strings.Trim(fraction[exponent:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidAmount, value, exponent)
+		}
+		fraction = fraction[:exponent]
+	}
+
+	amount, err := minorUnits(whole, fraction, exponent, negative)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", err, value)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Round reads a decimal amount and rounds it half away from zero to the minor unit of the currency.
+func Round(value, currency string) (Money, error) {
+	currency = normalizeCurrency(currency)
+	negative, whole, fraction, err := splitDecimal(value)
+	if err != nil {
+		return Money{}, err
+	}
+
+	exponent := Exponent(currency)
+	roundUp := false
+	if len(fraction) > exponent {
+		roundUp = fraction[exponent] >= '5'
+		fraction = fraction[:exponent]
+	}
+
+	amount, err := minorUnits(whole, fraction, exponent, false)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", err, value)
+	}
+
+	if roundUp {
+		if amount == math.MaxInt64 {
+			return Money{}, fmt.Errorf("%w: %q", ErrOverflow, value)
+		}
+		amount++
+	}
+
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// FromFloat rounds a float, as sent by payment providers, half away from zero to the minor unit. The float is read
+// by its shortest decimal form, so 1.005 is rounded as the 1.005 it prints as.
+func FromFloat(value float64, currency string) (Money, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidAmount, value)
+	}
+	return Round(strconv.FormatFloat(value, 'f', -1, 64), currency)
+}
+
+// Sum adds up the values, an empty list is zero in the given currency.
+func Sum(currency string, values ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, value := range values {
+		var err error
+		total, err = total.Add(value)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	amount := m.Amount + other.Amount
+	if (other.Amount > 0 && amount < m.Amount) || (other.Amount < 0 && amount > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: amount, Currency: m.currency()}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Mul multiplies the amount by a quantity.
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: product.Int64(), Currency: m.currency()}, nil
+}
+
+// Prorate returns part/whole of the amount rounded half away from zero, such as the price of 2 of the 3 items of an
+// order line. Prorating every part of a whole separately may not add up to the amount, give the last part what is
+// left instead.
+func (m Money) Prorate(part, whole int64) (Money, error) {
+	if whole <= 0 {
+		return Money{}, fmt.Errorf("%w: prorate over %d", ErrInvalidAmount, whole)
+	}
+
+	quotient := divRound(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(part)), big.NewInt(whole))
+	if !quotient.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: quotient.Int64(), Currency: m.currency()}, nil
+}
+
+// ProratePayable is Prorate rounded half away from zero to the unit payment gateways charge the currency in, such as
+// a tax or a fee that is charged as it is computed.
+func (m Money) ProratePayable(part, whole int64) (Money, error) {
+	if whole <= 0 {
+		return Money{}, fmt.Errorf("%w: prorate over %d", ErrInvalidAmount, whole)
+	}
+
+	scale := big.NewInt(pow10(Exponent(m.currency()) - PayableExponent(m.currency())))
+	quotient := divRound(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(part)), new(big.Int).Mul(big.NewInt(whole), scale))
+	quotient.Mul(quotient, scale)
+	if !quotient.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: quotient.Int64(), Currency: m.currency()}, nil
+}
+
+// RoundPayable rounds the amount half away from zero to the unit payment gateways charge its currency in.
+func (m Money) RoundPayable() (Money, error) {
+	return m.ProratePayable(1, 1)
+}
+
+// Convert converts the amount into currency at rate, the price of one major unit of the currency of m in major
+// units of currency, and rounds half away from zero to the minor unit of currency.
+func (m Money) Convert(currency string, rate *big.Rat) (Money, error) {
+	currency = normalizeCurrency(currency)
+	if rate == nil || rate.Sign() <= 0 {
+		return Money{}, fmt.Errorf("%w: exchange rate %v", ErrInvalidAmount, rate)
+	}
+
+	// minor units of currency = amount * rate * 10^exponent(currency) / 10^exponent(m)
+	numerator := new(big.Int).Mul(big.NewInt(m.Amount), rate.Num())
+	numerator.Mul(numerator, big.NewInt(pow10(Exponent(currency))))
+	denominator := new(big.Int).Mul(rate.Denom(), big.NewInt(pow10(Exponent(m.currency()))))
+
+	quotient := divRound(numerator, denominator)
+	if !quotient.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: quotient.Int64(), Currency: currency}, nil
+}
+
+// WithCurrency returns the amount in the given currency without converting it, for amounts scanned from a column
+// that is kept apart from their currency column.
+func (m Money) WithCurrency(currency string) Money {
+	return New(m.Amount, currency)
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// MajorUnits returns the amount in whole major units for payment gateways that take no decimals, an amount with a
+// fraction is refused instead of being cut off.
+func (m Money) MajorUnits() (int64, error) {
+	scale := pow10(Exponent(m.currency()))
+	if m.Amount%scale != 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNotWholeUnits, m)
+	}
+	return m.Amount / scale, nil
+}
+
+// Decimal formats the amount with the decimals of its currency, such as "100.50".
+func (m Money) Decimal() string {
+	exponent := Exponent(m.currency())
+	amount := new(big.Int).Abs(big.NewInt(m.Amount)).String()
+	if exponent > 0 {
+		if len(amount) <= exponent {
+			amount = strings.Repeat("0", exponent-len(amount)+1) + amount
+		}
+		amount = amount[:len(amount)-exponent] + "." + amount[len(amount)-exponent:]
+	}
+
+	if m.Amount < 0 {
+		return "-" + amount
+	}
+	return amount
+}
+
+// CurrencyCode returns the currency of the amount, the zero value being in the default currency.
+func (m Money) CurrencyCode() string {
+	return m.currency()
+}
+
+func (m Money) String() string {
+	return m.currency() + " " + m.Decimal()
+}
+
+// currency treats the zero value as the default currency.
+func (m Money) currency() string {
+	return normalizeCurrency(m.Currency)
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.currency() != other.currency() {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency(), other.currency())
+	}
+	return nil
+}
+
+func normalizeCurrency(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+// splitDecimal splits a plain decimal into its sign, whole digits and fraction digits.
+func splitDecimal(value string) (negative bool, whole, fraction string, err error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	whole, fraction, _ = strings.Cut(value, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return false, "", "", fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	return negative, whole, fraction, nil
+}
+
+// minorUnits joins the whole digits and at most exponent fraction digits into an amount of minor units.
+func minorUnits(whole, fraction string, exponent int, negative bool) (int64, error) {
+	digits := strings.TrimLeft(whole+fraction+strings.Repeat("0", exponent-len(fraction)), "0")
+	if digits == "" {
+		return 0, nil
+	}
+
+	if negative {
+		digits = "-" + digits
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, ErrOverflow
+	}
+	return amount, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// divRound divides numerator by a positive denominator and rounds half away from zero.
+func divRound(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if twice := new(big.Int).Abs(remainder); twice.Lsh(twice, 1).Cmp(denominator) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(numerator.Sign())))
+	}
+	return quotient
+}
+
+func pow10(exponent int) int64 {
+	scale := int64(1)
+	for i := 0; i < exponent; i++ {
+		scale *= 10
+	}
+	return scale
+}
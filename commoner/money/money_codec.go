@@ -0,0 +1,91 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"go-saga-pattern/proto/moneypb"
+	"strconv"
+)
+
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON writes {"amount":"100.50","currency":"IDR"}, the amount is a string so clients never read it as a float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(`{"amount":"` + m.Decimal() + `","currency":"` + m.currency() + `"}`), nil
+}
+
+// UnmarshalJSON reads the object written by MarshalJSON, the amount may also be a JSON number. A bare number or
+// string is an amount in the default currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] != '{' {
+		var amount json.Number
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidAmount, data)
+		}
+		return m.parse(amount.String(), DefaultCurrency)
+	}
+
+	var value moneyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, data)
+	}
+	return m.parse(value.Amount.String(), value.Currency)
+}
+
+// Scan reads a NUMERIC column, the currency is kept when it was set before the scan and is the default otherwise.
+func (m *Money) Scan(src any) error {
+	currency := m.Currency
+	switch value := src.(type) {
+	case nil:
+		*m = Zero(currency)
+		return nil
+	case string:
+		return m.parse(value, currency)
+	case []byte:
+		return m.parse(string(value), currency)
+	case int64:
+		return m.parse(strconv.FormatInt(value, 10), currency)
+	case float64:
+		parsed, err := FromFloat(value, currency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, src)
+	}
+}
+
+// Value writes the amount as a decimal for a NUMERIC column.
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+func (m *Money) parse(value, currency string) error {
+	parsed, err := Parse(value, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func ToProto(m Money) *moneypb.Money {
+	return &moneypb.Money{Amount: m.Amount, Currency: m.currency()}
+}
+
+// FromProto converts the proto message, a missing message is zero in the default currency.
+func FromProto(m *moneypb.Money) Money {
+	return New(m.GetAmount(), m.GetCurrency())
+}
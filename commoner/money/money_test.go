@@ -0,0 +1,396 @@
+package money_test
+
+import (
+	"encoding/json"
+	"go-saga-pattern/commoner/money"
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var quickConfig = &quick.Config{MaxCount: 5000}
+
+// boundedAmount keeps generated amounts far enough from the int64 limits that sums of a few of them cannot overflow.
+func boundedAmount(amount int64) int64 {
+	return amount % (math.MaxInt64 / 1024)
+}
+
+// exactRat is the decimal string as an exact rational number of major units.
+func exactRat(t *testing.T, value string) *big.Rat {
+	r, ok := new(big.Rat).SetString(value)
+	require.True(t, ok, value)
+	return r
+}
+
+func TestRound_IsWithinHalfAMinorUnit(t *testing.T) {
+	property := func(whole uint32, fraction uint32, negative bool) bool {
+		value := strconv.FormatUint(uint64(whole), 10) + "." + strconv.FormatUint(uint64(fraction), 10)
+		if negative {
+			value = "-" + value
+		}
+
+		rounded, err := money.Round(value, "IDR")
+		if err != nil {
+			return false
+		}
+
+		// |rounded - exact| <= 0.005, the half minor unit of IDR
+		diff := new(big.Rat).Sub(exactRat(t, rounded.Decimal()), exactRat(t, value))
+		return diff.Abs(diff).Cmp(big.NewRat(1, 200)) <= 0
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+func TestRound_IsSymmetricAroundZero(t *testing.T) {
+	property := func(whole uint32, fraction uint16) bool {
+		value := strconv.FormatUint(uint64(whole), 10) + "." + strconv.FormatUint(uint64(fraction), 10)
+		positive, err := money.Round(value, "IDR")
+		if err != nil {
+			return false
+		}
+
+		negative, err := money.Round("-"+value, "IDR")
+		return err == nil && negative.Amount == -positive.Amount
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+func TestRound_HalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		expected int64
+	}{
+		{"1.005", "IDR", 101},
+		{"1.004", "IDR", 100},
+		{"-1.005", "IDR", -101},
+		{"0.5", "JPY", 1},
+		{"-0.5", "JPY", -1},
+		{"0.49", "JPY", 0},
+		{"10000", "IDR", 1000000},
+		{".5", "IDR", 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.currency, func(t *testing.T) {
+			rounded, err := money.Round(tt.value, tt.currency)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rounded.Amount)
+		})
+	}
+}
+
+func TestFromFloat_RoundsTheShortestDecimal(t *testing.T) {
+	// 1.005 is 1.00499999999999989... as a float64, it must still round as the 1.005 it prints as
+	rounded, err := money.FromFloat(1.005, "IDR")
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), rounded.Amount)
+
+	_, err = money.FromFloat(math.NaN(), "IDR")
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+}
+
+func TestDecimal_RoundTripsThroughParse(t *testing.T) {
+	property := func(amount int64, jpy bool) bool {
+		currency := "IDR"
+		if jpy {
+			currency = "JPY"
+		}
+
+		value := money.New(amount, currency)
+		parsed, err := money.Parse(value.Decimal(), currency)
+		return err == nil && parsed == value
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+func TestParse_RejectsLostPrecision(t *testing.T) {
+	_, err := money.Parse("1.001", "IDR")
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+
+	parsed, err := money.Parse("1.0100", "IDR")
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), parsed.Amount)
+
+	_, err = money.Parse("92233720368547758.08", "IDR")
+	assert.ErrorIs(t, err, money.ErrOverflow)
+
+	for _, value := range []string{"", ".", "1,00", "1e5", "--1", "abc"} {
+		_, err := money.Parse(value, "IDR")
+		assert.ErrorIs(t, err, money.ErrInvalidAmount, value)
+	}
+}
+
+func TestSum_IsExactAndOrderIndependent(t *testing.T) {
+	property := func(amounts []int64, seed int64) bool {
+		values := make([]money.Money, 0, len(amounts))
+		expected := new(big.Int)
+		for _, amount := range amounts {
+			amount = boundedAmount(amount)
+			values = append(values, money.New(amount, "IDR"))
+			expected.Add(expected, big.NewInt(amount))
+		}
+
+		total, err := money.Sum("IDR", values...)
+		if !expected.IsInt64() {
+			return err != nil
+		}
+		if err != nil || total.Amount != expected.Int64() {
+			return false
+		}
+
+		shuffled := append([]money.Money(nil), values...)
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		reordered, err := money.Sum("IDR", shuffled...)
+		return err == nil && reordered == total
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+func TestAdd_DetectsOverflow(t *testing.T) {
+	_, err := money.New(math.MaxInt64, "IDR").Add(money.New(1, "IDR"))
+	assert.ErrorIs(t, err, money.ErrOverflow)
+
+	_, err = money.New(math.MinInt64, "IDR").Sub(money.New(1, "IDR"))
+	assert.ErrorIs(t, err, money.ErrOverflow)
+
+	_, err = money.New(math.MaxInt64/2+1, "IDR").Mul(2)
+	assert.ErrorIs(t, err, money.ErrOverflow)
+}
+
+func TestAdd_RejectsCurrencyMismatch(t *testing.T) {
+	_, err := money.New(100, "IDR").Add(money.New(100, "USD"))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	_, err = money.Sum("IDR", money.New(100, "USD"))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	// the zero value is in the default currency
+	total, err := money.Money{}.Add(money.New(100, money.DefaultCurrency))
+	require.NoError(t, err)
+	assert.Equal(t, money.New(100, money.DefaultCurrency), total)
+}
+
+func TestProrate_IsWithinHalfAMinorUnit(t *testing.T) {
+	property := func(amount int64, part, whole uint16) bool {
+		amount = boundedAmount(amount)
+		if whole == 0 {
+			whole = 1
+		}
+		part %= whole + 1
+
+		prorated, err := money.New(amount, "IDR").Prorate(int64(part), int64(whole))
+		if err != nil {
+			return false
+		}
+
+		exact := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(part))), big.NewInt(int64(whole)))
+		diff := new(big.Rat).Sub(new(big.Rat).SetInt64(prorated.Amount), exact)
+		return diff.Abs(diff).Cmp(big.NewRat(1, 2)) <= 0
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+// TestProrate_RemainderStaysClose gives every part but the last its prorated share and the last part what is left,
+// as refunds do. The parts add up to the whole and the last part is off by at most half a minor unit per part.
+func TestProrate_RemainderStaysClose(t *testing.T) {
+	property := func(amount int64, parts []uint8) bool {
+		amount = boundedAmount(amount)
+		var whole int64
+		for _, part := range parts {
+			whole += int64(part)
+		}
+		if whole == 0 {
+			return true
+		}
+
+		value := money.New(amount, "IDR")
+		remaining := value
+		for _, part := range parts[:len(parts)-1] {
+			share, err := value.Prorate(int64(part), whole)
+			if err != nil {
+				return false
+			}
+			if remaining, err = remaining.Sub(share); err != nil {
+				return false
+			}
+		}
+
+		last := int64(parts[len(parts)-1])
+		exact := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(amount), big.NewInt(last)), big.NewInt(whole))
+		diff := new(big.Rat).Sub(new(big.Rat).SetInt64(remaining.Amount), exact)
+		return diff.Abs(diff).Cmp(big.NewRat(int64(len(parts)), 2)) <= 0
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+func TestProrate_WholeIsTheAmount(t *testing.T) {
+	property := func(amount int64, whole uint16) bool {
+		value := money.New(amount, "IDR")
+		prorated, err := value.Prorate(int64(whole)+1, int64(whole)+1)
+		return err == nil && prorated == value
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+func TestProrate_RejectsEmptyWhole(t *testing.T) {
+	_, err := money.New(100, "IDR").Prorate(1, 0)
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+}
+
+func TestMajorUnits_RefusesFractions(t *testing.T) {
+	units, err := money.New(1250000, "IDR").MajorUnits()
+	require.NoError(t, err)
+	assert.Equal(t, int64(12500), units)
+
+	_, err = money.New(1250050, "IDR").MajorUnits()
+	assert.ErrorIs(t, err, money.ErrNotWholeUnits)
+
+	units, err = money.New(1250, "JPY").MajorUnits()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1250), units)
+}
+
+func TestProratePayable_RoundsToThePayableUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount money.Money
+		part   int64
+		whole  int64
+		want   money.Money
+	}{
+		{"11% of Rp1001.00 is Rp110.11, charged as Rp110", money.New(100100, "IDR"), 1100, 10000, money.New(11000, "IDR")},
+		{"11% of Rp1005.00 is Rp110.55, charged as Rp111", money.New(100500, "IDR"), 1100, 10000, money.New(11100, "IDR")},
+		{"half a rupiah rounds away from zero", money.New(-50, "IDR"), 1, 1, money.New(-100, "IDR")},
+		{"cents stay payable in USD", money.New(100100, "USD"), 1100, 10000, money.New(11011, "USD")},
+		{"yen have no minor unit", money.New(1001, "JPY"), 1100, 10000, money.New(110, "JPY")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.amount.ProratePayable(tt.part, tt.whole)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			_, err = got.MajorUnits()
+			if money.PayableExponent(tt.amount.Currency) == 0 {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	_, err := money.New(100, "IDR").ProratePayable(1, 0)
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+}
+
+func TestJSON_RoundTrips(t *testing.T) {
+	property := func(amount int64) bool {
+		value := money.New(amount, "IDR")
+		data, err := json.Marshal(value)
+		if err != nil {
+			return false
+		}
+
+		var decoded money.Money
+		return json.Unmarshal(data, &decoded) == nil && decoded == value
+	}
+	assert.NoError(t, quick.Check(property, quickConfig))
+
+	data, err := json.Marshal(money.New(10050, "IDR"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"100.50","currency":"IDR"}`, string(data))
+
+	var decoded money.Money
+	require.NoError(t, json.Unmarshal([]byte(`100.5`), &decoded))
+	assert.Equal(t, money.New(10050, money.DefaultCurrency), decoded)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":12,"currency":"usd"}`), &decoded))
+	assert.Equal(t, money.New(1200, "USD"), decoded)
+}
+
+func TestScan_RoundTripsThroughValue(t *testing.T) {
+	property := func(amount int64) bool {
+		value := money.New(amount, money.DefaultCurrency)
+		column, err := value.Value()
+		if err != nil {
+			return false
+		}
+
+		var scanned money.Money
+		return scanned.Scan(column) == nil && scanned == value
+	}
+	assert.NoError(t, quick.Check(property, quickConfig))
+
+	scanned := money.Money{Currency: "JPY"}
+	require.NoError(t, scanned.Scan([]byte("1500.00")))
+	assert.Equal(t, money.New(1500, "JPY"), scanned)
+}
+
+func TestProto_RoundTrips(t *testing.T) {
+	value := money.New(-10050, "USD")
+	assert.Equal(t, value, money.FromProto(money.ToProto(value)))
+	assert.Equal(t, money.Zero(money.DefaultCurrency), money.FromProto(nil))
+}
+
+func TestConvert_RoundsToTheTargetMinorUnit(t *testing.T) {
+	tests := []struct {
+		value    money.Money
+		currency string
+		rate     string
+		expected money.Money
+	}{
+		{money.New(1050, "USD"), "IDR", "16250", money.New(17062500, "IDR")},
+		{money.New(1000000, "IDR"), "USD", "0.0000615", money.New(62, "USD")},
+		{money.New(1999, "USD"), "JPY", "150.5", money.New(3008, "JPY")},
+		{money.New(3008, "JPY"), "USD", "0.00665", money.New(2000, "USD")},
+		{money.New(-1050, "USD"), "IDR", "16250.005", money.New(-17062505, "IDR")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value.String()+" "+tt.currency, func(t *testing.T) {
+			rate, ok := new(big.Rat).SetString(tt.rate)
+			require.True(t, ok)
+
+			converted, err := tt.value.Convert(tt.currency, rate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, converted)
+		})
+	}
+}
+
+func TestConvert_IsWithinHalfAMinorUnit(t *testing.T) {
+	property := func(amount int32, rateNum, rateDenom uint16) bool {
+		rate := big.NewRat(int64(rateNum)+1, int64(rateDenom)+1)
+		converted, err := money.New(int64(amount), "USD").Convert("IDR", rate)
+		if err != nil {
+			return false
+		}
+
+		exact := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(amount)), rate)
+		diff := new(big.Rat).Sub(new(big.Rat).SetInt64(converted.Amount), exact)
+		return diff.Abs(diff).Cmp(big.NewRat(1, 2)) <= 0
+	}
+
+	assert.NoError(t, quick.Check(property, quickConfig))
+}
+
+func TestConvert_RejectsInvalidRates(t *testing.T) {
+	_, err := money.New(100, "USD").Convert("IDR", big.NewRat(0, 1))
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+
+	_, err = money.New(100, "USD").Convert("IDR", nil)
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+}
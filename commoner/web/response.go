@@ -16,4 +16,6 @@ type PageMetadata struct {
 	HasPrevious     bool
 	NextPageURL     string
 	PreviousPageURL string
+	// NextCursor is set on keyset paginated lists, it is passed back as the cursor to read the next page
+	NextCursor string `json:",omitempty"`
 }
@@ -6,12 +6,14 @@ import (
 
 	"go-saga-pattern/commoner/discovery"
 	"go-saga-pattern/commoner/discovery/consul"
+	"go-saga-pattern/commoner/dlq"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/product-svc/internal/adapter"
 	"go-saga-pattern/product-svc/internal/config"
 	consumer "go-saga-pattern/product-svc/internal/delivery/consumer/transaction"
 	grpcHandler "go-saga-pattern/product-svc/internal/delivery/grpc/handler"
+	"go-saga-pattern/product-svc/internal/delivery/scheduler"
 	"go-saga-pattern/product-svc/internal/delivery/web/controller"
 	"go-saga-pattern/product-svc/internal/delivery/web/middleware"
 	"go-saga-pattern/product-svc/internal/delivery/web/route"
@@ -47,6 +49,8 @@ func webServer(ctx context.Context) error {
 	jetStreamConfig := config.NewJetStream(logger)
 	config.DeleteTransactionStream(jetStreamConfig, logger)
 	config.InitTransactionStream(jetStreamConfig, logger)
+	config.InitTransactionDLQStream(jetStreamConfig, logger)
+	deadLetterQueue := dlq.NewDeadLetterQueue(jetStreamConfig, "TRANSACTION_DLQ")
 
 	customValidator := helper.NewCustomValidator()
 
@@ -86,12 +90,22 @@ func webServer(ctx context.Context) error {
 	go consul.StartHealthCheckLoop(ctx, registry, GRPCserviceID, serverConfig.ProductSvcName+"-grpc", logger)
 
 	productRepo := repository.NewProductRepository()
+	productVariantRepo := repository.NewProductVariantRepository()
+	categoryRepo := repository.NewCategoryRepository()
 	productTransactionRepo := repository.NewProductTransactionRepository()
+	inventoryRepo := repository.NewInventoryRepository()
+	processedEventRepo := repository.NewProcessedEventRepository()
 
-	productUC := usecase.NewProductUseCase(productRepo, databaseStore, customValidator, logger)
-	productTransactionUC := usecase.NewProductTransactionUseCase(productRepo, productTransactionRepo, databaseStore, customValidator, logger)
+	productUC := usecase.NewProductUseCase(productRepo, productVariantRepo, categoryRepo, inventoryRepo, databaseStore, customValidator, logger)
+	productTransactionUC := usecase.NewProductTransactionUseCase(productRepo, productVariantRepo, inventoryRepo, productTransactionRepo,
+		processedEventRepo, databaseStore, customValidator, config.ReservationTTL(), logger)
+
+	categoryUC := usecase.NewCategoryUseCase(categoryRepo, databaseStore, customValidator, logger)
+	deadLetterUC := dlq.NewDeadLetterUseCase(deadLetterQueue, customValidator, logger)
 
 	productController := controller.NewProductController(productUC, logger)
+	categoryController := controller.NewCategoryController(categoryUC, logger)
+	deadLetterController := dlq.NewDeadLetterController(deadLetterUC, logger)
 
 	go func() {
 		grpcServer = grpc.NewServer()
@@ -116,13 +130,24 @@ func webServer(ctx context.Context) error {
 	userRoute := route.NewProductRoute(app, productController, userMiddleware)
 	userRoute.RegisterRoutes()
 
+	categoryRoute := route.NewCategoryRoute(app, categoryController, userMiddleware)
+	categoryRoute.RegisterRoutes()
+
+	adminMiddleware := helper.NewAdminAuth(logger)
+	adminRoute := route.NewAdminRoute(app, deadLetterController, adminMiddleware)
+	adminRoute.RegisterRoutes()
+
 	serverErrors := make(chan error, 1)
 
 	go func() {
 		serverErrors <- app.Listen(fmt.Sprintf("%s:%s", serverConfig.ProductHTTPAddr, serverConfig.ProductHTTPPort))
 	}()
 
-	transactionConsumer := consumer.NewTransactionConsumer(productTransactionUC, jetStreamConfig, logger)
+	goCronConfig := config.NewGocron(logger)
+	schedulerRunner := scheduler.NewSchedulerRunner(goCronConfig, productTransactionUC, logger)
+	go schedulerRunner.Start()
+
+	transactionConsumer := consumer.NewTransactionConsumer(productTransactionUC, jetStreamConfig, deadLetterQueue, logger)
 	if err := transactionConsumer.ConsumeAllEvents(ctx); err != nil {
 		logger.Error("Failed to consume transaction events", zap.Error(err))
 	}
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Inbox untuk TransactionConsumer, satu baris per event transaksi yang sudah diproses
+CREATE TABLE IF NOT EXISTS processed_events (
+	transaction_id UUID NOT NULL,
+	event VARCHAR(100) NOT NULL,
+	message_id VARCHAR(255),
+	processed_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(transaction_id, event)
+);
+
+-- Nats-Msg-Id dari publisher, boleh kosong untuk pesan lama tanpa header
+CREATE UNIQUE INDEX unique_processed_events_message_id
+ON processed_events(message_id)
+WHERE message_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS unique_processed_events_message_id;
+DROP TABLE IF EXISTS processed_events;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE product_transactions
+	ADD COLUMN IF NOT EXISTS refunded_quantity INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN product_transactions.status IS 'RESERVED, CANCELED, COMMITED, EXPIRED, SETTLED, REFUNDED';
+COMMENT ON COLUMN product_transactions.refunded_quantity IS 'Jumlah item yang stoknya sudah dikembalikan karena refund, status menjadi REFUNDED jika sama dengan quantity';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE product_transactions
+	DROP COLUMN IF EXISTS refunded_at,
+	DROP COLUMN IF EXISTS refunded_quantity;
+
+COMMENT ON COLUMN product_transactions.status IS 'RESERVED, CANCELED, COMMITED, EXPIRED, SETTLED';
+-- +goose StatementEnd
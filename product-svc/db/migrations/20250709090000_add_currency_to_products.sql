@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+	ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE product_transactions
+	ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+COMMENT ON COLUMN products.currency IS 'Kode mata uang ISO 4217 dari price';
+COMMENT ON COLUMN product_transactions.currency IS 'Kode mata uang ISO 4217 dari total_price, sama dengan mata uang produk saat reservasi';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE product_transactions
+	DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE products
+	DROP COLUMN IF EXISTS currency;
+-- +goose StatementEnd
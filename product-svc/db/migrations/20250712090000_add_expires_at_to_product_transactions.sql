@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE product_transactions
+	ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN product_transactions.expires_at IS 'Batas waktu reservasi RESERVED, setelah lewat stok dikembalikan oleh sweeper jika transaksi belum committed';
+
+CREATE INDEX idx_product_transactions_expires_at ON product_transactions (expires_at) WHERE status = 'RESERVED';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_product_transactions_expires_at;
+
+ALTER TABLE product_transactions
+	DROP COLUMN IF EXISTS expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,77 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+	ADD COLUMN IF NOT EXISTS on_hand INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS reserved INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS sold INTEGER NOT NULL DEFAULT 0;
+
+-- stok yang masih ditahan reservasi ada di reserved, quantity lama hanya berisi stok tersedia
+WITH held AS (
+	SELECT product_id, SUM(quantity - refunded_quantity) AS quantity
+	FROM product_transactions
+	WHERE status IN ('RESERVED', 'COMMITED')
+	GROUP BY product_id
+), settled AS (
+	SELECT product_id, SUM(quantity - refunded_quantity) AS quantity
+	FROM product_transactions
+	WHERE status IN ('SETTLED', 'REFUNDED')
+	GROUP BY product_id
+)
+UPDATE products p
+SET
+	reserved = COALESCE((SELECT quantity FROM held WHERE held.product_id = p.id), 0),
+	on_hand = p.quantity + COALESCE((SELECT quantity FROM held WHERE held.product_id = p.id), 0),
+	sold = COALESCE((SELECT quantity FROM settled WHERE settled.product_id = p.id), 0);
+
+ALTER TABLE products
+	DROP COLUMN IF EXISTS quantity,
+	ADD COLUMN quantity INTEGER GENERATED ALWAYS AS (on_hand - reserved) STORED,
+	ADD CONSTRAINT check_products_stock CHECK(on_hand >= 0 AND reserved >= 0 AND sold >= 0 AND reserved <= on_hand);
+
+COMMENT ON COLUMN products.on_hand IS 'Stok fisik yang belum terjual, termasuk yang sedang direservasi';
+COMMENT ON COLUMN products.reserved IS 'Stok yang ditahan transaksi RESERVED atau COMMITED';
+COMMENT ON COLUMN products.sold IS 'Stok yang sudah terjual (SETTLED) dan belum di-refund';
+COMMENT ON COLUMN products.quantity IS 'Stok tersedia untuk dibeli, on_hand - reserved';
+
+CREATE TABLE IF NOT EXISTS inventory_movements (
+	id UUID NOT NULL default uuid_generate_v4(),
+	product_id UUID NOT NULL REFERENCES products(id),
+	transaction_id UUID,
+	-- RESERVE, RELEASE, COMMIT, RESTOCK, ADJUST
+	type VARCHAR(20) NOT NULL,
+	quantity INTEGER NOT NULL,
+	on_hand INTEGER NOT NULL,
+	reserved INTEGER NOT NULL,
+	sold INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(id)
+);COMMENT ON COLUMN inventory_movements.transaction_id IS 'Transaksi penyebab pergerakan stok, kosong untuk ADJUST oleh pemilik produk';
+COMMENT ON COLUMN inventory_movements.quantity IS 'Jumlah item yang berpindah, hanya ADJUST yang bisa negatif';
+COMMENT ON COLUMN inventory_movements.on_hand IS 'Saldo on_hand, reserved dan sold produk setelah pergerakan ini';
+
+CREATE INDEX idx_inventory_movements_product_id ON inventory_movements (product_id, created_at DESC);
+CREATE INDEX idx_inventory_movements_transaction_id ON inventory_movements (transaction_id);
+
+-- saldo awal setiap produk dicatat sebagai ADJUST
+INSERT INTO inventory_movements (product_id, type, quantity, on_hand, reserved, sold)
+SELECT id, 'ADJUST', on_hand, on_hand, reserved, sold FROM products;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_inventory_movements_transaction_id;
+DROP INDEX IF EXISTS idx_inventory_movements_product_id;
+DROP TABLE IF EXISTS inventory_movements;
+
+ALTER TABLE products
+	DROP CONSTRAINT IF EXISTS check_products_stock,
+	DROP COLUMN IF EXISTS quantity,
+	ADD COLUMN quantity INTEGER NOT NULL DEFAULT 0;
+
+UPDATE products SET quantity = on_hand - reserved;
+
+ALTER TABLE products
+	DROP COLUMN IF EXISTS sold,
+	DROP COLUMN IF EXISTS reserved,
+	DROP COLUMN IF EXISTS on_hand;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+	ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '[]';
+
+COMMENT ON COLUMN products.options IS 'Opsi varian produk, contoh [{"name": "size", "values": ["S", "M"]}]';
+
+CREATE TABLE IF NOT EXISTS product_variants (
+	id UUID NOT NULL default uuid_generate_v4(),
+	product_id UUID NOT NULL REFERENCES products(id),
+	sku VARCHAR(100) NOT NULL,
+	options JSONB NOT NULL DEFAULT '{}',
+	price NUMERIC(19,2) NOT NULL CHECK(price > 0),
+	currency CHAR(3) NOT NULL DEFAULT 'IDR',
+	on_hand INTEGER NOT NULL DEFAULT 0,
+	reserved INTEGER NOT NULL DEFAULT 0,
+	sold INTEGER NOT NULL DEFAULT 0,
+	quantity INTEGER GENERATED ALWAYS AS (on_hand - reserved) STORED,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	deleted_at TIMESTAMPTZ,
+	PRIMARY KEY(id),
+	CONSTRAINT check_product_variants_stock CHECK(on_hand >= 0 AND reserved >= 0 AND sold >= 0 AND reserved <= on_hand)
+);COMMENT ON COLUMN product_variants.options IS 'Nilai setiap opsi produk untuk varian ini, contoh {"size": "M"}';
+COMMENT ON COLUMN product_variants.on_hand IS 'Stok varian, stok produk adalah jumlah stok semua variannya';
+
+CREATE UNIQUE INDEX unique_product_variant_sku_not_deleted ON product_variants (sku) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX unique_product_variant_options_not_deleted ON product_variants (product_id, options) WHERE deleted_at IS NULL;
+
+-- satu transaksi bisa memesan beberapa varian dari produk yang sama
+ALTER TABLE product_transactions
+	ADD COLUMN IF NOT EXISTS variant_id UUID,
+	DROP CONSTRAINT IF EXISTS product_transactions_pkey;
+
+CREATE UNIQUE INDEX unique_product_transactions_line ON product_transactions (transaction_id, product_id, variant_id) NULLS NOT DISTINCT;
+
+ALTER TABLE inventory_movements
+	ADD COLUMN IF NOT EXISTS variant_id UUID;
+
+COMMENT ON COLUMN inventory_movements.variant_id IS 'Varian yang stoknya berpindah, saldo tetap saldo produk';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE inventory_movements
+	DROP COLUMN IF EXISTS variant_id;
+
+DROP INDEX IF EXISTS unique_product_transactions_line;
+
+ALTER TABLE product_transactions
+	DROP COLUMN IF EXISTS variant_id,
+	ADD PRIMARY KEY (transaction_id, product_id);
+
+DROP INDEX IF EXISTS unique_product_variant_options_not_deleted;
+DROP INDEX IF EXISTS unique_product_variant_sku_not_deleted;
+DROP TABLE IF EXISTS product_variants;
+
+ALTER TABLE products
+	DROP COLUMN IF EXISTS options;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- konfigurasi 'simple' tidak melakukan stemming sehingga nama produk dalam bahasa apa pun tetap bisa dicari
+ALTER TABLE products
+	ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+		setweight(to_tsvector('simple', COALESCE(description, '')), 'B')
+	) STORED;
+
+COMMENT ON COLUMN products.search_vector IS 'Dokumen pencarian katalog, nama produk lebih berbobot dari deskripsinya';
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector) WHERE deleted_at IS NULL;
+
+-- index untuk keyset pagination setiap urutan katalog
+CREATE INDEX idx_products_catalog_newest ON products (created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_products_catalog_price ON products (price, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_products_catalog_name ON products (name, id) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_products_catalog_name;
+DROP INDEX IF EXISTS idx_products_catalog_price;
+DROP INDEX IF EXISTS idx_products_catalog_newest;
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+ALTER TABLE products
+	DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
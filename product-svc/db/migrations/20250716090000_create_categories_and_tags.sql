@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS categories (
+	id UUID NOT NULL default uuid_generate_v4(),
+	user_id UUID NOT NULL,
+	parent_id UUID REFERENCES categories(id),
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(100) NOT NULL,
+	path TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	deleted_at TIMESTAMPTZ,
+	PRIMARY KEY(id)
+);COMMENT ON COLUMN categories.user_id IS 'Pemilik yang membuat kategori, hanya dia yang bisa mengubah atau menghapusnya';
+COMMENT ON COLUMN categories.path IS 'Slug kategori dan semua induknya dari akar, contoh books/fiction';
+
+-- path unik sehingga nama kategori hanya unik di bawah induk yang sama
+CREATE UNIQUE INDEX unique_category_path_not_deleted ON categories (path) WHERE deleted_at IS NULL;
+CREATE INDEX idx_categories_parent_id ON categories (parent_id) WHERE deleted_at IS NULL;
+
+ALTER TABLE products
+	ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories(id),
+	ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN products.tags IS 'Tag bebas produk dalam huruf kecil, contoh {organic,handmade}';
+
+CREATE INDEX idx_products_category_id ON products (category_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_products_tags ON products USING GIN (tags) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_products_tags;
+DROP INDEX IF EXISTS idx_products_category_id;
+
+ALTER TABLE products
+	DROP COLUMN IF EXISTS tags,
+	DROP COLUMN IF EXISTS category_id;
+
+DROP INDEX IF EXISTS idx_categories_parent_id;
+DROP INDEX IF EXISTS unique_category_path_not_deleted;
+DROP TABLE IF EXISTS categories;
+-- +goose StatementEnd
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/commoner/utils"
+	"time"
 
 	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
@@ -40,7 +41,22 @@ func DeleteTransactionStream(js nats.JetStreamContext, log logs.Log) {
 func InitTransactionStream(js nats.JetStreamContext, log logs.Log) {
 	_, err := js.AddStream(&nats.StreamConfig{
 		Name:     "TRANSACTION_STREAM",
-		Subjects: []string{"transaction.settled", "transaction.committed", "transaction.canceled", "transaction.expired"},
+		Subjects: []string{"transaction.settled", "transaction.committed", "transaction.canceled", "transaction.expired", "transaction.refunded"},
+		Storage:  nats.FileStorage,
+		// publishes carrying the same Nats-Msg-Id within this window are dropped by the server
+		Duplicates: 10 * time.Minute,
+	})
+
+	if err != nil && err != nats.ErrStreamNameAlreadyInUse {
+		log.Fatal("failed to create stream", zap.Error(err))
+	}
+}
+
+// InitTransactionDLQStream is never deleted on startup so dead letters survive restarts until they are replayed.
+func InitTransactionDLQStream(js nats.JetStreamContext, log logs.Log) {
+	_, err := js.AddStream(&nats.StreamConfig{
+		Name:     "TRANSACTION_DLQ",
+		Subjects: []string{"dlq.transaction.>"},
 		Storage:  nats.FileStorage,
 	})
 
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"go-saga-pattern/commoner/utils"
+	"strconv"
+	"time"
+)
+
+// ReservationTTL reads PRODUCT_RESERVATION_TTL_IN_SECONDS, how long a reservation holds the stock before the sweeper
+// releases it. It has to outlast the checkout saga up to transaction.committed, so it defaults to 15 minutes.
+func ReservationTTL() time.Duration {
+	ttl, err := strconv.Atoi(utils.GetEnv("PRODUCT_RESERVATION_TTL_IN_SECONDS"))
+	if err != nil || ttl <= 0 {
+		ttl = 900
+	}
+	return time.Duration(ttl) * time.Second
+}
@@ -1,6 +1,7 @@
 package consumer
 
 import (
+	"go-saga-pattern/commoner/dlq"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/product-svc/internal/usecase"
 
@@ -11,31 +12,38 @@ import (
 type TransactionConsumer struct {
 	transactionUseCase usecase.ProductTransactionUseCase
 	js                 nats.JetStreamContext
+	deadLetterQueue    dlq.DeadLetterQueue
 	logs               logs.Log
 	subjects           []string
 	durableNames       map[string]string
+	maxDeliver         int
 }
 
 func NewTransactionConsumer(
 	transactionUseCase usecase.ProductTransactionUseCase,
 	js nats.JetStreamContext,
+	deadLetterQueue dlq.DeadLetterQueue,
 	logs logs.Log,
 ) *TransactionConsumer {
 	return &TransactionConsumer{
 		transactionUseCase: transactionUseCase,
 		js:                 js,
+		deadLetterQueue:    deadLetterQueue,
 		logs:               logs,
+		maxDeliver:         5,
 		subjects: []string{
 			"transaction.committed",
 			"transaction.settled",
 			"transaction.canceled",
 			"transaction.expired",
+			"transaction.refunded",
 		},
 		durableNames: map[string]string{
 			"transaction.committed": "transaction_committed_consumer",
 			"transaction.settled":   "transaction_settled_consumer",
 			"transaction.canceled":  "transaction_canceled_consumer",
 			"transaction.expired":   "transaction_expired_consumer",
+			"transaction.refunded":  "transaction_refunded_consumer",
 		},
 	}
 }
@@ -19,7 +19,7 @@ func (s *TransactionConsumer) setupConsumer(subject string) error {
 	consumerConfig := &nats.ConsumerConfig{
 		Durable:       s.durableNames[subject],
 		AckPolicy:     nats.AckExplicitPolicy,
-		MaxDeliver:    5,
+		MaxDeliver:    s.maxDeliver,
 		BackOff:       []time.Duration{1 * time.Second, 5 * time.Second, 10 * time.Second},
 		DeliverPolicy: nats.DeliverAllPolicy,
 		AckWait:       30 * time.Second,
@@ -34,42 +34,87 @@ func (s *TransactionConsumer) handleMessage(ctx context.Context, msg *nats.Msg)
 	event := new(event.TransactionEvent)
 	if err := sonic.ConfigFastest.Unmarshal(msg.Data, event); err != nil {
 		s.logs.Error("failed to unmarshal message", zap.Error(err))
-		_ = msg.Nak()
+		s.deadLetter(ctx, msg, fmt.Sprintf("failed to unmarshal message: %v", err), "")
 		return
 	}
 
-	var err error
+	transactionID, err := uuid.Parse(event.TransactionID)
+	if err != nil {
+		s.logs.Error("invalid transaction id", zap.String("TransactionID", event.TransactionID), zap.Error(err))
+		s.deadLetter(ctx, msg, fmt.Sprintf("invalid transaction id: %v", err), event.TransactionID)
+		return
+	}
+
+	// set by the transaction-svc outbox relay, duplicates are also caught by (transaction_id, event) when it is missing
+	messageID := msg.Header.Get(nats.MsgIdHdr)
+
 	switch msg.Subject {
 	case "transaction.committed":
 		request := &model.CommitProductTransactionsRequest{
-			TransactionID: uuid.MustParse(event.TransactionID),
+			TransactionID: transactionID,
+			MessageID:     messageID,
 		}
 		err = s.transactionUseCase.CommitProductTransactionsRequest(ctx, request)
 
 	case "transaction.settled":
 		request := &model.SettleProductTransactionRequest{
-			TransactionID: uuid.MustParse(event.TransactionID),
+			TransactionID: transactionID,
+			MessageID:     messageID,
 		}
 		err = s.transactionUseCase.SettleProducts(ctx, request)
 
 	case "transaction.canceled":
 		request := &model.CancelProductTransactionsRequest{
-			TransactionID: uuid.MustParse(event.TransactionID),
+			TransactionID: transactionID,
+			MessageID:     messageID,
 		}
 		err = s.transactionUseCase.CancelProductTransactions(ctx, request)
 
 	case "transaction.expired":
 		request := &model.ExpireProductTransactionsRequest{
-			TransactionID: uuid.MustParse(event.TransactionID),
+			TransactionID: transactionID,
+			MessageID:     messageID,
 		}
 		err = s.transactionUseCase.ExpireProductTransactions(ctx, request)
 
+	case "transaction.refunded":
+		request := &model.RefundProductTransactionsRequest{
+			TransactionID: transactionID,
+			RefundID:      event.RefundID,
+			MessageID:     messageID,
+			Items:         make([]*model.RefundProductTransactionItem, 0, len(event.Items)),
+		}
+		for _, item := range event.Items {
+			productID, parseErr := uuid.Parse(item.ProductID)
+			if parseErr != nil {
+				err = helper.NewUseCaseError(errorcode.ErrInvalidArgument, fmt.Sprintf("invalid product id: %s", item.ProductID))
+				break
+			}
+			var variantID uuid.NullUUID
+			if item.VariantID != "" {
+				parsedVariantID, parseErr := uuid.Parse(item.VariantID)
+				if parseErr != nil {
+					err = helper.NewUseCaseError(errorcode.ErrInvalidArgument, fmt.Sprintf("invalid variant id: %s", item.VariantID))
+					break
+				}
+				variantID = uuid.NullUUID{UUID: parsedVariantID, Valid: true}
+			}
+			request.Items = append(request.Items, &model.RefundProductTransactionItem{
+				ProductID: productID,
+				VariantID: variantID,
+				Quantity:  item.Quantity,
+			})
+		}
+		if err == nil {
+			err = s.transactionUseCase.RefundProductTransactions(ctx, request)
+		}
+
 	default:
-		err = fmt.Errorf("unknown subject: %s", msg.Subject)
+		err = helper.NewUseCaseError(errorcode.ErrInvalidArgument, fmt.Sprintf("unknown subject: %s", msg.Subject))
 	}
 
 	if err != nil {
-		s.handleError(msg, err, event.TransactionID)
+		s.handleError(ctx, msg, err, event.TransactionID)
 		return
 	}
 
@@ -78,7 +123,7 @@ func (s *TransactionConsumer) handleMessage(ctx context.Context, msg *nats.Msg)
 	}
 }
 
-func (s *TransactionConsumer) handleError(msg *nats.Msg, err error, transactionID string) {
+func (s *TransactionConsumer) handleError(ctx context.Context, msg *nats.Msg, err error, transactionID string) {
 	s.logs.Error("failed to process transaction",
 		zap.Error(err),
 		zap.String("TransactionID", transactionID))
@@ -88,14 +133,31 @@ func (s *TransactionConsumer) handleError(msg *nats.Msg, err error, transactionI
 		appErr = &helper.AppError{Code: errorcode.ErrInternal}
 	}
 
-	switch appErr.Code {
-	case errorcode.ErrInvalidArgument:
-		s.logs.Warn("Invalid argument, acknowledging", zap.String("TransactionID", transactionID))
-		_ = msg.Ack()
-	default:
-		delay := 10 * time.Second
-		if err := msg.NakWithDelay(delay); err != nil {
-			s.logs.Error("failed to NAK message", zap.Error(err))
-		}
+	if appErr.Code == errorcode.ErrInvalidArgument {
+		s.logs.Warn("Invalid argument, moving message to dead letter queue", zap.String("TransactionID", transactionID))
+		s.deadLetter(ctx, msg, err.Error(), transactionID)
+		return
+	}
+
+	if meta, metaErr := msg.Metadata(); metaErr == nil && meta.NumDelivered >= uint64(s.maxDeliver) {
+		s.logs.Warn("Max deliveries reached, moving message to dead letter queue",
+			zap.String("TransactionID", transactionID), zap.Uint64("deliveries", meta.NumDelivered))
+		s.deadLetter(ctx, msg, fmt.Sprintf("max deliveries (%d) exhausted: %v", s.maxDeliver, err), transactionID)
+		return
+	}
+
+	delay := 10 * time.Second
+	if err := msg.NakWithDelay(delay); err != nil {
+		s.logs.Error("failed to NAK message", zap.Error(err))
+	}
+}
+
+// deadLetter moves a terminally failed message to TRANSACTION_DLQ and terminates it on the original stream.
+// When the DLQ publish keeps failing the message is redelivered, or kept in progress on its last delivery, instead of
+// being lost.
+func (s *TransactionConsumer) deadLetter(ctx context.Context, msg *nats.Msg, reason string, transactionID string) {
+	if err := s.deadLetterQueue.Move(ctx, msg, reason, s.maxDeliver); err != nil {
+		s.logs.Error("failed to move message to dead letter queue", zap.String("TransactionID", transactionID),
+			zap.String("subject", msg.Subject), zap.ByteString("payload", msg.Data), zap.Error(err))
 	}
 }
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/money"
 	"go-saga-pattern/product-svc/internal/model"
 	"go-saga-pattern/product-svc/internal/usecase"
 	"go-saga-pattern/proto/productpb"
@@ -48,10 +49,20 @@ func (h *ProductHandler) CheckProductAndReserve(ctx context.Context, pbReq *prod
 		if err != nil {
 			return nil, status.Error(codes.InvalidArgument, "Invalid product ID format")
 		}
+
+		var variantID uuid.NullUUID
+		if productPb.GetVariantId() != "" {
+			parsedVariantID, err := uuid.Parse(productPb.GetVariantId())
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "Invalid variant ID format")
+			}
+			variantID = uuid.NullUUID{UUID: parsedVariantID, Valid: true}
+		}
+
 		products = append(products, &model.CheckProductQuantity{
 			ProductID: productID,
+			VariantID: variantID,
 			Quantity:  int(productPb.GetQuantity()),
-			Price:     float64(productPb.GetPrice()),
 		})
 
 	}
@@ -68,13 +79,19 @@ func (h *ProductHandler) CheckProductAndReserve(ctx context.Context, pbReq *prod
 
 	productResponsePb := make([]*productpb.Product, 0, len(response.Products))
 	for _, product := range response.Products {
-		productResponsePb = append(productResponsePb, &productpb.Product{
+		productPb := &productpb.Product{
 			Id:          product.ID,
+			UserId:      product.UserID,
 			Name:        product.Name,
 			Description: product.Description,
-			Price:       float32(product.Price),
+			Price:       money.ToProto(product.Price),
 			Quantity:    int32(product.Quantity),
-		})
+			Category:    product.Category,
+		}
+		if product.Variant != nil {
+			productPb.Variant = productVariantToPb(product.Variant)
+		}
+		productResponsePb = append(productResponsePb, productPb)
 	}
 
 	pbResponse := &productpb.CheckProductQuantityResponse{
@@ -111,8 +128,57 @@ func (h *ProductHandler) OwnerGetProduct(ctx context.Context, pbReq *productpb.O
 			Id:          response.ID,
 			Name:        response.Name,
 			Description: response.Description,
-			Price:       float32(response.Price),
+			Price:       money.ToProto(response.Price),
 			Quantity:    int32(response.Quantity),
+			Category:    response.Category,
 		},
 	}, nil
 }
+
+func (h *ProductHandler) GetProducts(ctx context.Context, pbReq *productpb.GetProductsRequest) (*productpb.GetProductsResponse, error) {
+	productIDs := make([]uuid.UUID, 0, len(pbReq.GetProductIds()))
+	for _, productID := range pbReq.GetProductIds() {
+		parsedProductID, err := uuid.Parse(productID)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid product ID format")
+		}
+		productIDs = append(productIDs, parsedProductID)
+	}
+
+	response, err := h.productUC.GetManyByIDs(ctx, &model.GetProductsRequest{ProductIDs: productIDs})
+	if err != nil {
+		return nil, helper.ErrGRPC(err)
+	}
+
+	productsPb := make([]*productpb.Product, 0, len(response))
+	for _, product := range response {
+		productPb := &productpb.Product{
+			Id:          product.ID,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       money.ToProto(product.Price),
+			Quantity:    int32(product.Quantity),
+			Category:    product.Category,
+			Variants:    make([]*productpb.ProductVariant, 0, len(product.Variants)),
+		}
+		for _, variant := range product.Variants {
+			productPb.Variants = append(productPb.Variants, productVariantToPb(variant))
+		}
+		productsPb = append(productsPb, productPb)
+	}
+
+	return &productpb.GetProductsResponse{
+		Status:   int64(codes.OK),
+		Products: productsPb,
+	}, nil
+}
+
+func productVariantToPb(variant *model.ProductVariantResponse) *productpb.ProductVariant {
+	return &productpb.ProductVariant{
+		Id:       variant.ID,
+		Sku:      variant.SKU,
+		Options:  variant.Options,
+		Price:    money.ToProto(variant.Price),
+		Quantity: int32(variant.Quantity),
+	}
+}
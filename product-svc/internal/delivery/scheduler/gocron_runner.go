@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/utils"
+	"go-saga-pattern/product-svc/internal/usecase"
+	"strconv"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"go.uber.org/zap"
+)
+
+type SchedulerRunner interface {
+	Start()
+}
+
+type schedulerRunner struct {
+	scheduler              gocron.Scheduler
+	usecase                usecase.ProductTransactionUseCase
+	logs                   logs.Log
+	sweepSchedulerDuration time.Duration
+}
+
+func NewSchedulerRunner(
+	s gocron.Scheduler,
+	usecase usecase.ProductTransactionUseCase,
+	logs logs.Log,
+) SchedulerRunner {
+	sweepSchedulerInt, err := strconv.Atoi(utils.GetEnv("RESERVATION_SWEEP_SCHEDULER_IN_SECONDS"))
+	if err != nil || sweepSchedulerInt <= 0 {
+		sweepSchedulerInt = 60
+	}
+	return &schedulerRunner{
+		scheduler:              s,
+		usecase:                usecase,
+		logs:                   logs,
+		sweepSchedulerDuration: time.Duration(sweepSchedulerInt) * time.Second,
+	}
+}
+
+func (r *schedulerRunner) Start() {
+	_, err := r.scheduler.NewJob(
+		gocron.DurationJob(r.sweepSchedulerDuration),
+		gocron.NewTask(func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, 4*time.Minute)
+			defer cancel()
+
+			if err := r.usecase.ReleaseExpiredReservations(ctx); err != nil {
+				r.logs.Error("Failed to release expired reservations", zap.Error(err))
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		r.logs.Error("Failed to create job", zap.Error(err))
+		return
+	}
+	r.logs.Info("Scheduler job created to release expired reservations", zap.String("job", "ReleaseExpiredReservations"), zap.Duration("interval", r.sweepSchedulerDuration))
+	r.scheduler.Start()
+}
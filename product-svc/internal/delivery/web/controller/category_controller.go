@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/product-svc/internal/delivery/web/middleware"
+	"go-saga-pattern/product-svc/internal/model"
+	"go-saga-pattern/product-svc/internal/usecase"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CategoryController interface {
+	GetAll(ctx *fiber.Ctx) error
+	GetByID(ctx *fiber.Ctx) error
+	OwnerCreate(ctx *fiber.Ctx) error
+	OwnerDelete(ctx *fiber.Ctx) error
+	OwnerUpdate(ctx *fiber.Ctx) error
+}
+
+type categoryController struct {
+	categoryUseCase usecase.CategoryUseCase
+	logs            logs.Log
+}
+
+func NewCategoryController(categoryUseCase usecase.CategoryUseCase, logs logs.Log) CategoryController {
+	return &categoryController{categoryUseCase: categoryUseCase, logs: logs}
+}
+
+func (c *categoryController) GetAll(ctx *fiber.Ctx) error {
+	categories, err := c.categoryUseCase.GetAll(ctx.UserContext())
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get categories error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[[]*model.CategoryResponse]{
+		Success: true,
+		Data:    categories,
+	})
+}
+
+func (c *categoryController) GetByID(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Category ID format")
+	}
+
+	category, err := c.categoryUseCase.GetByID(ctx.UserContext(), parsedId)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get category by ID error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.CategoryResponse]{
+		Success: true,
+		Data:    category,
+	})
+}
+
+func (c *categoryController) OwnerCreate(ctx *fiber.Ctx) error {
+	request := new(model.CreateCategoryRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	category, err := c.categoryUseCase.OwnerCreate(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Create category error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(web.WebResponse[*model.CategoryResponse]{
+		Success: true,
+		Data:    category,
+	})
+}
+
+func (c *categoryController) OwnerUpdate(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Category ID format")
+	}
+
+	request := new(model.UpdateCategoryRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+	request.ID = parsedId
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	category, err := c.categoryUseCase.OwnerUpdate(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Update category error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.CategoryResponse]{
+		Success: true,
+		Data:    category,
+	})
+}
+
+func (c *categoryController) OwnerDelete(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Category ID format")
+	}
+
+	request := new(model.DeleteCategoryRequest)
+	request.ID = parsedId
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	if err := c.categoryUseCase.OwnerDelete(ctx.UserContext(), request); err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Delete category error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[any]{
+		Success: true,
+	})
+}
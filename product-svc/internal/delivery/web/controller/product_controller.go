@@ -1,13 +1,16 @@
 package controller
 
 import (
+	"go-saga-pattern/commoner/constant/enum"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
 	"go-saga-pattern/commoner/web"
 	"go-saga-pattern/product-svc/internal/delivery/web/middleware"
 	"go-saga-pattern/product-svc/internal/model"
 	"go-saga-pattern/product-svc/internal/usecase"
 	"net/http"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -17,8 +20,14 @@ import (
 type ProductController interface {
 	OwnerCreate(ctx *fiber.Ctx) error
 	OwnerDelete(ctx *fiber.Ctx) error
+	OwnerGet(ctx *fiber.Ctx) error
 	OwnerSearch(ctx *fiber.Ctx) error
+	OwnerSearchInventoryMovements(ctx *fiber.Ctx) error
 	OwnerUpdate(ctx *fiber.Ctx) error
+	OwnerUpdateOptions(ctx *fiber.Ctx) error
+	OwnerCreateVariant(ctx *fiber.Ctx) error
+	OwnerUpdateVariant(ctx *fiber.Ctx) error
+	OwnerDeleteVariant(ctx *fiber.Ctx) error
 	PublicSearch(ctx *fiber.Ctx) error
 	GetByID(ctx *fiber.Ctx) error
 	GetBySlug(ctx *fiber.Ctx) error
@@ -101,6 +110,55 @@ func (c *productController) OwnerSearch(ctx *fiber.Ctx) error {
 
 }
 
+func (c *productController) OwnerGet(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Product ID format")
+	}
+
+	request := new(model.OwnerGetProductRequest)
+	request.ProductID = parsedId
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	product, err := c.productUseCase.OwnerGet(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get product error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.ProductResponse]{
+		Success: true,
+		Data:    product,
+	})
+}
+
+func (c *productController) OwnerSearchInventoryMovements(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Product ID format")
+	}
+
+	request := new(model.OwnerSearchInventoryMovementsRequest)
+	request.ProductID = parsedId
+	request.Limit = ctx.QueryInt("limit", 10)
+	request.Page = ctx.QueryInt("page", 1)
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	movements, pageMetadata, err := c.productUseCase.OwnerSearchInventoryMovements(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Search inventory movements error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[[]*model.InventoryMovementResponse]{
+		Success:      true,
+		Data:         movements,
+		PageMetadata: pageMetadata,
+	})
+}
+
 // TODO implement user id
 func (c *productController) OwnerUpdate(ctx *fiber.Ctx) error {
 	productId := ctx.Params("id")
@@ -135,16 +193,172 @@ func (c *productController) OwnerUpdate(ctx *fiber.Ctx) error {
 	})
 }
 
+func (c *productController) OwnerUpdateOptions(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Product ID format")
+	}
+
+	request := new(model.UpdateProductOptionsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+	request.ID = parsedId
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	product, err := c.productUseCase.OwnerUpdateOptions(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Update product options error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.ProductResponse]{
+		Success: true,
+		Data:    product,
+	})
+}
+
+func (c *productController) OwnerCreateVariant(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Product ID format")
+	}
+
+	request := new(model.CreateProductVariantRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+	request.ProductID = parsedId
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	variant, err := c.productUseCase.OwnerCreateVariant(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Create product variant error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(web.WebResponse[*model.ProductVariantResponse]{
+		Success: true,
+		Data:    variant,
+	})
+}
+
+func (c *productController) OwnerUpdateVariant(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Product ID format")
+	}
+
+	parsedVariantId, err := uuid.Parse(ctx.Params("variant_id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Variant ID format")
+	}
+
+	request := new(model.UpdateProductVariantRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+	request.ID = parsedVariantId
+	request.ProductID = parsedId
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	variant, err := c.productUseCase.OwnerUpdateVariant(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Update product variant error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.ProductVariantResponse]{
+		Success: true,
+		Data:    variant,
+	})
+}
+
+func (c *productController) OwnerDeleteVariant(ctx *fiber.Ctx) error {
+	parsedId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Product ID format")
+	}
+
+	parsedVariantId, err := uuid.Parse(ctx.Params("variant_id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Variant ID format")
+	}
+
+	request := new(model.DeleteProductVariantRequest)
+	request.ID = parsedVariantId
+	request.ProductID = parsedId
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+
+	if err := c.productUseCase.OwnerDeleteVariant(ctx.UserContext(), request); err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Delete product variant error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[any]{
+		Success: true,
+	})
+}
+
 func (c *productController) PublicSearch(ctx *fiber.Ctx) error {
 	request := new(model.PublicSearchProductsRequest)
+	request.Query = ctx.Query("q")
+	request.Currency = strings.ToUpper(ctx.Query("currency"))
+	request.InStock = ctx.QueryBool("in_stock", false)
+	request.Sort = enum.ProductCatalogSort(ctx.Query("sort"))
+	request.Cursor = ctx.Query("cursor")
 	request.Limit = ctx.QueryInt("limit", 10)
-	request.Page = ctx.QueryInt("page", 1)
+
+	request.Tag = ctx.Query("tag")
+
+	if categoryID := ctx.Query("category_id"); categoryID != "" {
+		parsedCategoryID, err := uuid.Parse(categoryID)
+		if err != nil {
+			return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Category ID format")
+		}
+		request.CategoryID = &parsedCategoryID
+	}
+
+	if ownerID := ctx.Query("owner_id"); ownerID != "" {
+		parsedOwnerID, err := uuid.Parse(ownerID)
+		if err != nil {
+			return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid Owner ID format")
+		}
+		request.OwnerID = &parsedOwnerID
+	}
+
+	priceCurrency := request.Currency
+	if priceCurrency == "" {
+		priceCurrency = money.DefaultCurrency
+	}
+
+	if minPrice := ctx.Query("min_price"); minPrice != "" {
+		parsedMinPrice, err := money.Parse(minPrice, priceCurrency)
+		if err != nil {
+			return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid min_price format")
+		}
+		request.MinPrice = &parsedMinPrice
+	}
+
+	if maxPrice := ctx.Query("max_price"); maxPrice != "" {
+		parsedMaxPrice, err := money.Parse(maxPrice, priceCurrency)
+		if err != nil {
+			return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid max_price format")
+		}
+		request.MaxPrice = &parsedMaxPrice
+	}
 
 	products, pageMetadata, err := c.productUseCase.PublicSearch(ctx.UserContext(), request)
 	if err != nil {
 		return helper.ErrUseCaseResponseJSON(ctx, "Search products error : ", err, c.logs)
 	}
 
+	helper.GenerateCursorPageURL(ctx.BaseURL()+ctx.OriginalURL(), pageMetadata)
+
 	return ctx.Status(http.StatusOK).JSON(web.WebResponse[[]*model.ProductResponse]{
 		Success:      true,
 		Data:         products,
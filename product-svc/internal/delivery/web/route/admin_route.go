@@ -0,0 +1,26 @@
+package route
+
+import (
+	"go-saga-pattern/commoner/dlq"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminRoute struct {
+	app                  *fiber.App
+	deadLetterController dlq.DeadLetterController
+	adminMiddleware      fiber.Handler
+}
+
+func NewAdminRoute(app *fiber.App, deadLetterController dlq.DeadLetterController, adminMiddleware fiber.Handler) *AdminRoute {
+	return &AdminRoute{
+		app:                  app,
+		deadLetterController: deadLetterController,
+		adminMiddleware:      adminMiddleware,
+	}
+}
+
+func (r *AdminRoute) RegisterRoutes() {
+	adminRoutes := r.app.Group("/api/v1/admin", r.adminMiddleware)
+	dlq.RegisterRoutes(adminRoutes, r.deadLetterController)
+}
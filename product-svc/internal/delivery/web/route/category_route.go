@@ -0,0 +1,32 @@
+package route
+
+import (
+	"go-saga-pattern/product-svc/internal/delivery/web/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CategoryRoute struct {
+	app                *fiber.App
+	categoryController controller.CategoryController
+	userMiddleware     fiber.Handler
+}
+
+func NewCategoryRoute(app *fiber.App, categoryController controller.CategoryController, userMiddleware fiber.Handler) *CategoryRoute {
+	return &CategoryRoute{
+		app:                app,
+		categoryController: categoryController,
+		userMiddleware:     userMiddleware,
+	}
+}
+
+func (r *CategoryRoute) RegisterRoutes() {
+	catalogRoutes := r.app.Group("/api/v1/catalog/categories")
+	catalogRoutes.Get("/", r.categoryController.GetAll)
+	catalogRoutes.Get("/:id", r.categoryController.GetByID)
+
+	userRoutes := r.app.Group("/api/v1/categories", r.userMiddleware)
+	userRoutes.Post("/", r.categoryController.OwnerCreate)
+	userRoutes.Put("/:id", r.categoryController.OwnerUpdate)
+	userRoutes.Delete("/:id", r.categoryController.OwnerDelete)
+}
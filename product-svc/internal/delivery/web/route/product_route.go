@@ -21,9 +21,21 @@ func NewProductRoute(app *fiber.App, productController controller.ProductControl
 }
 
 func (r *ProductRoute) RegisterRoutes() {
+	// the catalog is browsed without logging in, so it sits outside the prefix guarded by the user middleware
+	catalogRoutes := r.app.Group("/api/v1/catalog/products")
+	catalogRoutes.Get("/", r.productController.PublicSearch)
+	catalogRoutes.Get("/slug/:slug", r.productController.GetBySlug)
+	catalogRoutes.Get("/:id", r.productController.GetByID)
+
 	userRoutes := r.app.Group("/api/v1/products", r.userMiddleware)
 	userRoutes.Post("/", r.productController.OwnerCreate)
 	userRoutes.Get("/", r.productController.OwnerSearch)
+	userRoutes.Get("/:id", r.productController.OwnerGet)
+	userRoutes.Get("/:id/inventory", r.productController.OwnerSearchInventoryMovements)
 	userRoutes.Put("/:id", r.productController.OwnerUpdate)
+	userRoutes.Put("/:id/options", r.productController.OwnerUpdateOptions)
+	userRoutes.Post("/:id/variants", r.productController.OwnerCreateVariant)
+	userRoutes.Put("/:id/variants/:variant_id", r.productController.OwnerUpdateVariant)
+	userRoutes.Delete("/:id/variants/:variant_id", r.productController.OwnerDeleteVariant)
 	userRoutes.Delete("/delete/:id", r.productController.OwnerDelete)
 }
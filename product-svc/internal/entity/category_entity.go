@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a node of the category tree, Path is the slug of the category and its ancestors from the root, such
+// as books/fiction.
+type Category struct {
+	ID        uuid.UUID     `db:"id"`
+	UserID    uuid.UUID     `db:"user_id"`
+	ParentID  uuid.NullUUID `db:"parent_id"`
+	Name      string        `db:"name"`
+	Slug      string        `db:"slug"`
+	Path      string        `db:"path"`
+	CreatedAt *time.Time    `db:"created_at"`
+	UpdatedAt *time.Time    `db:"updated_at"`
+	DeletedAt sql.NullTime  `db:"deleted_at"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InventoryMovement is one entry of the inventory ledger, OnHand, Reserved and Sold are the stock of the product after it.
+type InventoryMovement struct {
+	ID            uuid.UUID                  `db:"id"`
+	ProductID     uuid.UUID                  `db:"product_id"`
+	VariantID     uuid.NullUUID              `db:"variant_id"`
+	TransactionID uuid.NullUUID              `db:"transaction_id"`
+	Type          enum.InventoryMovementType `db:"type"`
+	Quantity      int                        `db:"quantity"`
+	OnHand        int                        `db:"on_hand"`
+	Reserved      int                        `db:"reserved"`
+	Sold          int                        `db:"sold"`
+	CreatedAt     *time.Time                 `db:"created_at"`
+}
+
+type InventoryMovementWithTotal struct {
+	InventoryMovement
+	TotalData int `db:"total_data"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProcessedEvent struct {
+	TransactionID uuid.UUID      `db:"transaction_id"`
+	Event         string         `db:"event"`
+	MessageID     sql.NullString `db:"message_id"`
+	ProcessedAt   *time.Time     `db:"processed_at"`
+}
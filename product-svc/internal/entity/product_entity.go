@@ -2,34 +2,91 @@ package entity
 
 import (
 	"database/sql"
+	"go-saga-pattern/commoner/money"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Product is a product of an owner, Category is the path of its category, it is read from the category and never
+// written.
 type Product struct {
 	ID          uuid.UUID      `db:"id"`
 	UserID      uuid.UUID      `db:"user_id"`
 	Name        string         `db:"name"`
 	Slug        string         `db:"slug"`
 	Description sql.NullString `db:"description"`
-	Price       float64        `db:"price"`
+	Price       money.Money    `db:"price"`
+	Currency    string         `db:"currency"`
 	Quantity    int            `db:"quantity"`
+	OnHand      int            `db:"on_hand"`
+	Reserved    int            `db:"reserved"`
+	Sold        int            `db:"sold"`
+	Options     ProductOptions `db:"options"`
+	CategoryID  uuid.NullUUID  `db:"category_id"`
+	Category    sql.NullString `db:"category"`
+	Tags        []string       `db:"tags"`
 	CreatedAt   *time.Time     `db:"created_at"`
 	UpdatedAt   *time.Time     `db:"updated_at"`
 	DeletedAt   sql.NullTime   `db:"deleted_at"`
 }
 
+// ApplyCurrency puts the currency column on the scanned price.
+func (p *Product) ApplyCurrency() {
+	p.Price = p.Price.WithCurrency(p.Currency)
+}
+
 type ProductWithTotal struct {
 	ID          uuid.UUID      `db:"id"`
 	UserID      uuid.UUID      `db:"user_id"`
 	Name        string         `db:"name"`
 	Slug        string         `db:"slug"`
 	Description sql.NullString `db:"description"`
-	Price       float64        `db:"price"`
+	Price       money.Money    `db:"price"`
+	Currency    string         `db:"currency"`
 	Quantity    int            `db:"quantity"`
+	OnHand      int            `db:"on_hand"`
+	Reserved    int            `db:"reserved"`
+	Sold        int            `db:"sold"`
+	Options     ProductOptions `db:"options"`
+	CategoryID  uuid.NullUUID  `db:"category_id"`
+	Category    sql.NullString `db:"category"`
+	Tags        []string       `db:"tags"`
 	CreatedAt   *time.Time     `db:"created_at"`
 	UpdatedAt   *time.Time     `db:"updated_at"`
 	DeletedAt   sql.NullTime   `db:"deleted_at"`
 	TotalData   int            `db:"total_data"`
 }
+
+// ApplyCurrency puts the currency column on the scanned price.
+func (p *ProductWithTotal) ApplyCurrency() {
+	p.Price = p.Price.WithCurrency(p.Currency)
+}
+
+// ProductWithRank is a product of the public catalog, Rank is how well it matches the search query.
+type ProductWithRank struct {
+	ID          uuid.UUID      `db:"id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	Name        string         `db:"name"`
+	Slug        string         `db:"slug"`
+	Description sql.NullString `db:"description"`
+	Price       money.Money    `db:"price"`
+	Currency    string         `db:"currency"`
+	Quantity    int            `db:"quantity"`
+	OnHand      int            `db:"on_hand"`
+	Reserved    int            `db:"reserved"`
+	Sold        int            `db:"sold"`
+	Options     ProductOptions `db:"options"`
+	CategoryID  uuid.NullUUID  `db:"category_id"`
+	Category    sql.NullString `db:"category"`
+	Tags        []string       `db:"tags"`
+	CreatedAt   *time.Time     `db:"created_at"`
+	UpdatedAt   *time.Time     `db:"updated_at"`
+	DeletedAt   sql.NullTime   `db:"deleted_at"`
+	Rank        float32        `db:"rank"`
+}
+
+// ApplyCurrency puts the currency column on the scanned price.
+func (p *ProductWithRank) ApplyCurrency() {
+	p.Price = p.Price.WithCurrency(p.Currency)
+}
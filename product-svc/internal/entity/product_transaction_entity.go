@@ -3,22 +3,33 @@ package entity
 import (
 	"database/sql"
 	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type ProductTransaction struct {
-	TransactionID uuid.UUID                         `db:"transaction_id"`
-	ProductID     uuid.UUID                         `db:"product_id"`
-	Status        enum.ProductTransactionStatusEnum `db:"status"`
-	Quantity      int                               `db:"quantity"`
-	TotalPrice    float64                           `db:"total_price"`
-	ReservedAt    *time.Time                        `db:"reserved_at"`
-	CanceledAt    sql.NullTime                      `db:"canceled_at"`
-	CommittedAt   sql.NullTime                      `db:"committed_at"`
-	ExpiredAt     sql.NullTime                      `db:"expired_at"`
-	SettledAt     sql.NullTime                      `db:"settled_at"`
-	CreatedAt     sql.NullTime                      `db:"created_at"`
-	UpdatedAt     sql.NullTime                      `db:"updated_at"`
+	TransactionID    uuid.UUID                         `db:"transaction_id"`
+	ProductID        uuid.UUID                         `db:"product_id"`
+	VariantID        uuid.NullUUID                     `db:"variant_id"`
+	Status           enum.ProductTransactionStatusEnum `db:"status"`
+	Quantity         int                               `db:"quantity"`
+	TotalPrice       money.Money                       `db:"total_price"`
+	Currency         string                            `db:"currency"`
+	ReservedAt       *time.Time                        `db:"reserved_at"`
+	ExpiresAt        sql.NullTime                      `db:"expires_at"`
+	CanceledAt       sql.NullTime                      `db:"canceled_at"`
+	CommittedAt      sql.NullTime                      `db:"committed_at"`
+	ExpiredAt        sql.NullTime                      `db:"expired_at"`
+	SettledAt        sql.NullTime                      `db:"settled_at"`
+	RefundedQuantity int                               `db:"refunded_quantity"`
+	RefundedAt       sql.NullTime                      `db:"refunded_at"`
+	CreatedAt        sql.NullTime                      `db:"created_at"`
+	UpdatedAt        sql.NullTime                      `db:"updated_at"`
+}
+
+// ApplyCurrency puts the currency column on the scanned total price.
+func (p *ProductTransaction) ApplyCurrency() {
+	p.TotalPrice = p.TotalPrice.WithCurrency(p.Currency)
 }
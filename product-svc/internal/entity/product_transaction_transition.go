@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"slices"
+)
+
+// productTransactionSources maps a status to the statuses a product transaction line can move to it from. REFUNDED is
+// final, a settled event that arrives after the refund must not revive the line.
+var productTransactionSources = map[enum.ProductTransactionStatusEnum][]enum.ProductTransactionStatusEnum{
+	enum.ProductTransactionStatusCanceled: {enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusComitted,
+		enum.ProductTransactionStatusSettled, enum.ProductTransactionStatusCanceled, enum.ProductTransactionStatusExpired},
+	enum.ProductTransactionStatusExpired: {enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusComitted,
+		enum.ProductTransactionStatusSettled, enum.ProductTransactionStatusCanceled, enum.ProductTransactionStatusExpired},
+	// only a reservation is committed, one the sweeper already released must not take the stock again
+	enum.ProductTransactionStatusComitted: {enum.ProductTransactionStatusReserved},
+	// canceled and expired lines already gave their stock back, a late settlement leaves them as they are
+	enum.ProductTransactionStatusSettled: {enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusComitted},
+}
+
+// ProductTransactionSources returns the statuses a line can move to status from, an UPDATE only touches lines in them.
+func ProductTransactionSources(status enum.ProductTransactionStatusEnum) ([]enum.ProductTransactionStatusEnum, error) {
+	sources, ok := productTransactionSources[status]
+	if !ok {
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	return sources, nil
+}
+
+// CanMoveTo reports whether the line can move from its current status to status.
+func (p *ProductTransaction) CanMoveTo(status enum.ProductTransactionStatusEnum) bool {
+	return slices.Contains(productTransactionSources[status], p.Status)
+}
@@ -0,0 +1,99 @@
+package entity_test
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/product-svc/internal/entity"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var allProductTransactionStatuses = []enum.ProductTransactionStatusEnum{
+	enum.ProductTransactionStatusReserved,
+	enum.ProductTransactionStatusComitted,
+	enum.ProductTransactionStatusSettled,
+	enum.ProductTransactionStatusCanceled,
+	enum.ProductTransactionStatusExpired,
+	enum.ProductTransactionStatusRefunded,
+}
+
+type productTransactionMove struct {
+	from enum.ProductTransactionStatusEnum
+	to   enum.ProductTransactionStatusEnum
+}
+
+// allowedProductTransactionMoves lists every legal pair, everything else leaves the line as it is.
+var allowedProductTransactionMoves = map[productTransactionMove]bool{
+	{enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusComitted}: true,
+	{enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusSettled}:  true,
+	{enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusCanceled}: true,
+	{enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusExpired}:  true,
+
+	{enum.ProductTransactionStatusComitted, enum.ProductTransactionStatusSettled}:  true,
+	{enum.ProductTransactionStatusComitted, enum.ProductTransactionStatusCanceled}: true,
+	{enum.ProductTransactionStatusComitted, enum.ProductTransactionStatusExpired}:  true,
+
+	{enum.ProductTransactionStatusSettled, enum.ProductTransactionStatusCanceled}: true,
+	{enum.ProductTransactionStatusSettled, enum.ProductTransactionStatusExpired}:  true,
+
+	{enum.ProductTransactionStatusCanceled, enum.ProductTransactionStatusCanceled}: true,
+	{enum.ProductTransactionStatusCanceled, enum.ProductTransactionStatusExpired}:  true,
+
+	{enum.ProductTransactionStatusExpired, enum.ProductTransactionStatusCanceled}: true,
+	{enum.ProductTransactionStatusExpired, enum.ProductTransactionStatusExpired}:  true,
+}
+
+func TestProductTransaction_CanMoveTo(t *testing.T) {
+	for _, from := range allProductTransactionStatuses {
+		for _, to := range allProductTransactionStatuses {
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				productTransaction := &entity.ProductTransaction{Status: from}
+				assert.Equal(t, allowedProductTransactionMoves[productTransactionMove{from, to}], productTransaction.CanMoveTo(to))
+			})
+		}
+	}
+}
+
+func TestProductTransaction_CanMoveTo_Guards(t *testing.T) {
+	tests := []struct {
+		name string
+		from enum.ProductTransactionStatusEnum
+		to   enum.ProductTransactionStatusEnum
+		want bool
+	}{
+		{name: "a reservation is committed", from: enum.ProductTransactionStatusReserved, to: enum.ProductTransactionStatusComitted, want: true},
+		{name: "a swept reservation is not committed", from: enum.ProductTransactionStatusExpired, to: enum.ProductTransactionStatusComitted},
+		{name: "a swept reservation is not settled", from: enum.ProductTransactionStatusExpired, to: enum.ProductTransactionStatusSettled},
+		{name: "a canceled reservation is not committed", from: enum.ProductTransactionStatusCanceled, to: enum.ProductTransactionStatusComitted},
+		{name: "a settled line is not committed again", from: enum.ProductTransactionStatusSettled, to: enum.ProductTransactionStatusComitted},
+		{name: "a refunded line is not settled again", from: enum.ProductTransactionStatusRefunded, to: enum.ProductTransactionStatusSettled},
+		{name: "a refunded line is not expired", from: enum.ProductTransactionStatusRefunded, to: enum.ProductTransactionStatusExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productTransaction := &entity.ProductTransaction{Status: tt.from}
+			assert.Equal(t, tt.want, productTransaction.CanMoveTo(tt.to))
+		})
+	}
+}
+
+func TestProductTransactionSources(t *testing.T) {
+	for _, status := range allProductTransactionStatuses {
+		t.Run(string(status), func(t *testing.T) {
+			sources, err := entity.ProductTransactionSources(status)
+			if status == enum.ProductTransactionStatusReserved || status == enum.ProductTransactionStatusRefunded {
+				// a line is reserved when it is inserted and refunded by AddRefundedQuantity, never by UpdateStatus
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			for _, from := range allProductTransactionStatuses {
+				assert.Equal(t, allowedProductTransactionMoves[productTransactionMove{from, status}], slices.Contains(sources, from),
+					"%s -> %s", from, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"database/sql"
+	"go-saga-pattern/commoner/money"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductOption is an option the variants of a product are chosen by, like a size or a color, with its values.
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductOptions []ProductOption
+
+// Match tells whether the variant options pick exactly one of the values of every option and nothing else.
+func (o ProductOptions) Match(variantOptions map[string]string) bool {
+	if len(variantOptions) != len(o) {
+		return false
+	}
+
+	for _, option := range o {
+		value, ok := variantOptions[option.Name]
+		if !ok || !slices.Contains(option.Values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProductVariant is a sellable variant of a product with its own SKU, price and stock. The stock of a product with
+// variants is the sum of the stock of its variants.
+type ProductVariant struct {
+	ID        uuid.UUID         `db:"id"`
+	ProductID uuid.UUID         `db:"product_id"`
+	SKU       string            `db:"sku"`
+	Options   map[string]string `db:"options"`
+	Price     money.Money       `db:"price"`
+	Currency  string            `db:"currency"`
+	Quantity  int               `db:"quantity"`
+	OnHand    int               `db:"on_hand"`
+	Reserved  int               `db:"reserved"`
+	Sold      int               `db:"sold"`
+	CreatedAt *time.Time        `db:"created_at"`
+	UpdatedAt *time.Time        `db:"updated_at"`
+	DeletedAt sql.NullTime      `db:"deleted_at"`
+}
+
+// ApplyCurrency puts the currency column on the scanned price.
+func (v *ProductVariant) ApplyCurrency() {
+	v.Price = v.Price.WithCurrency(v.Currency)
+}
@@ -0,0 +1,33 @@
+package model
+
+import "github.com/google/uuid"
+
+// CreateCategoryRequest adds a category under ParentID, or a root category when it is empty.
+type CreateCategoryRequest struct {
+	UserID   uuid.UUID  `json:"user_id" validate:"required,uuid"`
+	ParentID *uuid.UUID `json:"parent_id"`
+	Name     string     `json:"name" validate:"required,max=100"`
+}
+
+// UpdateCategoryRequest renames the category or moves it under another parent, its subcategories move along.
+type UpdateCategoryRequest struct {
+	ID       uuid.UUID  `json:"-" validate:"required,uuid"`
+	UserID   uuid.UUID  `json:"user_id" validate:"required,uuid"`
+	ParentID *uuid.UUID `json:"parent_id"`
+	Name     string     `json:"name" validate:"required,max=100"`
+}
+
+type DeleteCategoryRequest struct {
+	ID     uuid.UUID `validate:"required,uuid"`
+	UserID uuid.UUID `validate:"required,uuid"`
+}
+
+type CategoryResponse struct {
+	ID        string `json:"id"`
+	ParentID  string `json:"parent_id,omitempty"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	Path      string `json:"path"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,27 @@
+package converter
+
+import (
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/model"
+)
+
+func CategoryToResponse(category *entity.Category) *model.CategoryResponse {
+	return &model.CategoryResponse{
+		ID:        category.ID.String(),
+		ParentID:  nullable.UUIDtoString(category.ParentID),
+		Name:      category.Name,
+		Slug:      category.Slug,
+		Path:      category.Path,
+		CreatedAt: formatTime(category.CreatedAt),
+		UpdatedAt: formatTime(category.UpdatedAt),
+	}
+}
+
+func CategoriesToResponses(categories []*entity.Category) []*model.CategoryResponse {
+	responses := make([]*model.CategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		responses = append(responses, CategoryToResponse(category))
+	}
+	return responses
+}
@@ -1,9 +1,15 @@
 package converter
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
 	"go-saga-pattern/commoner/helper/nullable"
 	"go-saga-pattern/product-svc/internal/entity"
 	"go-saga-pattern/product-svc/internal/model"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -15,9 +21,77 @@ func ProductToResponse(product *entity.Product) *model.ProductResponse {
 		Description: nullable.SQLtoString(product.Description),
 		Price:       product.Price,
 		Quantity:    product.Quantity,
+		CategoryID:  nullable.UUIDtoString(product.CategoryID),
+		Category:    nullable.SQLtoString(product.Category),
+		Tags:        product.Tags,
 	}
 }
 
+// OwnerProductToResponse adds the stock accounting that is only shown to the owner of the product.
+func OwnerProductToResponse(product *entity.Product) *model.ProductResponse {
+	response := ProductToResponse(product)
+	response.Stock = &model.Stock{
+		OnHand:    product.OnHand,
+		Reserved:  product.Reserved,
+		Sold:      product.Sold,
+		Available: product.Quantity,
+	}
+	return response
+}
+
+// ProductWithVariantsToResponse adds the options of the product and the variants buyers can choose from.
+func ProductWithVariantsToResponse(product *entity.Product, variants []*entity.ProductVariant) *model.ProductResponse {
+	response := ProductToResponse(product)
+	response.Options = productOptionsToResponses(product.Options)
+	response.Variants = make([]*model.ProductVariantResponse, 0, len(variants))
+	for _, variant := range variants {
+		response.Variants = append(response.Variants, ProductVariantToResponse(variant))
+	}
+	return response
+}
+
+func OwnerProductWithVariantsToResponse(product *entity.Product, variants []*entity.ProductVariant) *model.ProductResponse {
+	response := OwnerProductToResponse(product)
+	response.Options = productOptionsToResponses(product.Options)
+	response.Variants = make([]*model.ProductVariantResponse, 0, len(variants))
+	for _, variant := range variants {
+		response.Variants = append(response.Variants, OwnerProductVariantToResponse(variant))
+	}
+	return response
+}
+
+func ProductVariantToResponse(variant *entity.ProductVariant) *model.ProductVariantResponse {
+	return &model.ProductVariantResponse{
+		ID:       variant.ID.String(),
+		SKU:      variant.SKU,
+		Options:  variant.Options,
+		Price:    variant.Price,
+		Quantity: variant.Quantity,
+	}
+}
+
+// OwnerProductVariantToResponse adds the stock accounting that is only shown to the owner of the product.
+func OwnerProductVariantToResponse(variant *entity.ProductVariant) *model.ProductVariantResponse {
+	response := ProductVariantToResponse(variant)
+	response.Stock = &model.Stock{
+		OnHand:    variant.OnHand,
+		Reserved:  variant.Reserved,
+		Sold:      variant.Sold,
+		Available: variant.Quantity,
+	}
+	response.CreatedAt = formatTime(variant.CreatedAt)
+	response.UpdatedAt = formatTime(variant.UpdatedAt)
+	return response
+}
+
+func productOptionsToResponses(options entity.ProductOptions) []*model.ProductOption {
+	responses := make([]*model.ProductOption, 0, len(options))
+	for _, option := range options {
+		responses = append(responses, &model.ProductOption{Name: option.Name, Values: option.Values})
+	}
+	return responses
+}
+
 func ProductsToResponses(products []*entity.Product) []*model.ProductResponse {
 	responses := make([]*model.ProductResponse, 0, len(products))
 	for _, product := range products {
@@ -26,32 +100,153 @@ func ProductsToResponses(products []*entity.Product) []*model.ProductResponse {
 	return responses
 }
 
-func ProductsWithTotalToResponses(productsWithTotal []*entity.ProductWithTotal) []*model.ProductResponse {
+func OwnerProductsWithTotalToResponses(productsWithTotal []*entity.ProductWithTotal) []*model.ProductResponse {
 	responses := make([]*model.ProductResponse, 0, len(productsWithTotal))
 	for _, productWithTotal := range productsWithTotal {
-		product := &entity.Product{
-			ID:          productWithTotal.ID,
-			UserID:      productWithTotal.UserID,
-			Name:        productWithTotal.Name,
-			Slug:        productWithTotal.Slug,
-			Description: productWithTotal.Description,
-			Price:       productWithTotal.Price,
-			Quantity:    productWithTotal.Quantity,
-		}
+		responses = append(responses, OwnerProductToResponse(productWithTotalToProduct(productWithTotal)))
+	}
+	return responses
+}
 
-		responses = append(responses, ProductToResponse(product))
+func ProductsWithRankToResponses(productsWithRank []*entity.ProductWithRank) []*model.ProductResponse {
+	responses := make([]*model.ProductResponse, 0, len(productsWithRank))
+	for _, productWithRank := range productsWithRank {
+		responses = append(responses, ProductToResponse(&entity.Product{
+			ID:          productWithRank.ID,
+			UserID:      productWithRank.UserID,
+			Name:        productWithRank.Name,
+			Slug:        productWithRank.Slug,
+			Description: productWithRank.Description,
+			Price:       productWithRank.Price,
+			Quantity:    productWithRank.Quantity,
+			OnHand:      productWithRank.OnHand,
+			Reserved:    productWithRank.Reserved,
+			Sold:        productWithRank.Sold,
+			Options:     productWithRank.Options,
+			CategoryID:  productWithRank.CategoryID,
+			Category:    productWithRank.Category,
+			Tags:        productWithRank.Tags,
+			CreatedAt:   productWithRank.CreatedAt,
+			UpdatedAt:   productWithRank.UpdatedAt,
+		}))
 	}
 	return responses
 }
 
-func ProductsToCheckQuantityResponse(transactionID uuid.UUID, products []*entity.Product) *model.CheckProductsQuantityRequestResponse {
+// EncodeProductCatalogCursor points the next catalog page after the product by the sort key of the sort.
+func EncodeProductCatalogCursor(sort enum.ProductCatalogSort, product *entity.ProductWithRank) string {
+	cursor := &model.ProductCatalogCursor{Sort: sort, ID: product.ID}
+	switch sort {
+	case enum.ProductCatalogSortRelevance:
+		cursor.Value = strconv.FormatFloat(float64(product.Rank), 'g', -1, 32)
+	case enum.ProductCatalogSortPriceAsc, enum.ProductCatalogSortPriceDesc:
+		cursor.Value = product.Price.Decimal()
+	case enum.ProductCatalogSortName:
+		cursor.Value = product.Name
+	default:
+		cursor.Value = product.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeProductCatalogCursor reads a cursor made by EncodeProductCatalogCursor, its sort key is checked so it can be
+// put in the keyset condition as is.
+func DecodeProductCatalogCursor(value string) (*model.ProductCatalogCursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := new(model.ProductCatalogCursor)
+	if err := json.Unmarshal(payload, cursor); err != nil {
+		return nil, err
+	}
+
+	switch cursor.Sort {
+	case enum.ProductCatalogSortRelevance:
+		_, err = strconv.ParseFloat(cursor.Value, 32)
+	case enum.ProductCatalogSortPriceAsc, enum.ProductCatalogSortPriceDesc:
+		_, err = strconv.ParseFloat(cursor.Value, 64)
+	case enum.ProductCatalogSortNewest:
+		_, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	case enum.ProductCatalogSortName:
+	default:
+		err = fmt.Errorf("unknown catalog sort %q", cursor.Sort)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func productWithTotalToProduct(productWithTotal *entity.ProductWithTotal) *entity.Product {
+	return &entity.Product{
+		ID:          productWithTotal.ID,
+		UserID:      productWithTotal.UserID,
+		Name:        productWithTotal.Name,
+		Slug:        productWithTotal.Slug,
+		Description: productWithTotal.Description,
+		Price:       productWithTotal.Price,
+		Quantity:    productWithTotal.Quantity,
+		OnHand:      productWithTotal.OnHand,
+		Reserved:    productWithTotal.Reserved,
+		Sold:        productWithTotal.Sold,
+		Options:     productWithTotal.Options,
+		CategoryID:  productWithTotal.CategoryID,
+		Category:    productWithTotal.Category,
+		Tags:        productWithTotal.Tags,
+	}
+}
+
+func InventoryMovementsWithTotalToResponses(movements []*entity.InventoryMovementWithTotal) []*model.InventoryMovementResponse {
+	responses := make([]*model.InventoryMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		response := &model.InventoryMovementResponse{
+			ID:        movement.ID.String(),
+			Type:      movement.Type,
+			Quantity:  movement.Quantity,
+			OnHand:    movement.OnHand,
+			Reserved:  movement.Reserved,
+			Sold:      movement.Sold,
+			VariantID: nullable.UUIDtoString(movement.VariantID),
+			CreatedAt: formatTime(movement.CreatedAt),
+		}
+		if movement.TransactionID.Valid {
+			response.TransactionID = movement.TransactionID.UUID.String()
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// ProductsToCheckQuantityResponse returns a product for every reserved line, variants[i] is the variant reserved by the
+// line of products[i] or nil when the product has no variants. The line is priced with the price of its variant.
+func ProductsToCheckQuantityResponse(transactionID uuid.UUID, products []*entity.Product,
+	variants []*entity.ProductVariant) *model.CheckProductsQuantityRequestResponse {
 	responses := make([]*model.ProductResponse, 0, len(products))
-	for _, product := range products {
-		responses = append(responses, &model.ProductResponse{
+	for i, product := range products {
+		response := &model.ProductResponse{
 			ID:       product.ID.String(),
+			UserID:   product.UserID.String(),
 			Quantity: product.Quantity,
-			Price:    product.Price},
-		)
+			Price:    product.Price,
+			Category: nullable.SQLtoString(product.Category),
+		}
+		if variants[i] != nil {
+			response.Variant = ProductVariantToResponse(variants[i])
+			response.Quantity = variants[i].Quantity
+			response.Price = variants[i].Price
+		}
+		responses = append(responses, response)
 	}
 	return &model.CheckProductsQuantityRequestResponse{TransactionID: transactionID, Products: responses}
 }
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC1123)
+}
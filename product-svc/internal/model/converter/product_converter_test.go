@@ -0,0 +1,73 @@
+package converter_test
+
+import (
+	"encoding/base64"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/model/converter"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductCatalogCursor_RoundTrips(t *testing.T) {
+	createdAt := time.Date(2025, 7, 15, 9, 30, 0, 123456789, time.UTC)
+	product := &entity.ProductWithRank{
+		ID:        uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		Name:      "Kopi Gayo 250g",
+		Price:     money.New(8950000, "IDR"),
+		CreatedAt: &createdAt,
+		Rank:      0.0759909,
+	}
+
+	tests := []struct {
+		sort  enum.ProductCatalogSort
+		value string
+	}{
+		{sort: enum.ProductCatalogSortRelevance, value: "0.0759909"},
+		{sort: enum.ProductCatalogSortNewest, value: "2025-07-15T09:30:00.123456789Z"},
+		{sort: enum.ProductCatalogSortPriceAsc, value: "89500.00"},
+		{sort: enum.ProductCatalogSortPriceDesc, value: "89500.00"},
+		{sort: enum.ProductCatalogSortName, value: "Kopi Gayo 250g"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			cursor, err := converter.DecodeProductCatalogCursor(converter.EncodeProductCatalogCursor(tt.sort, product))
+			require.NoError(t, err)
+			assert.Equal(t, tt.sort, cursor.Sort)
+			assert.Equal(t, tt.value, cursor.Value)
+			assert.Equal(t, product.ID, cursor.ID)
+		})
+	}
+}
+
+func TestDecodeProductCatalogCursor_RejectsATamperedCursor(t *testing.T) {
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "not json", cursor: encode("newest")},
+		{name: "unknown sort", cursor: encode(`{"sort":"popular","value":"1","id":"00000000-0000-0000-0000-000000000001"}`)},
+		{name: "relevance that is not a number", cursor: encode(`{"sort":"relevance","value":"high","id":"00000000-0000-0000-0000-000000000001"}`)},
+		{name: "price that is not a number", cursor: encode(`{"sort":"price_asc","value":"1; DROP TABLE products","id":"00000000-0000-0000-0000-000000000001"}`)},
+		{name: "newest that is not a time", cursor: encode(`{"sort":"newest","value":"yesterday","id":"00000000-0000-0000-0000-000000000001"}`)},
+		{name: "id that is not a uuid", cursor: encode(`{"sort":"name","value":"Kopi","id":"1"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.DecodeProductCatalogCursor(tt.cursor)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,13 +1,21 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+
+	"github.com/google/uuid"
+)
 
 type CreateProductRequest struct {
-	UserID      uuid.UUID `json:"user_id" validate:"required,uuid"`
-	Name        string    `json:"name" validate:"required"`
-	Description *string   `json:"description"`
-	Price       float64   `json:"price" validate:"required,gt=0"`
-	Quantity    int       `json:"quantity" validate:"required,gt=0"`
+	UserID      uuid.UUID   `json:"user_id" validate:"required,uuid"`
+	Name        string      `json:"name" validate:"required"`
+	Description *string     `json:"description"`
+	Price       money.Money `json:"price" validate:"required,gt=0"`
+	// Quantity is left empty for a product whose stock is split into variants
+	Quantity   int        `json:"quantity" validate:"gte=0"`
+	CategoryID *uuid.UUID `json:"category_id"`
+	Tags       []string   `json:"tags" validate:"max=20,dive,required,max=50"`
 }
 
 type GetProductRequest struct {
@@ -25,18 +33,88 @@ type OwnerGetProductRequest struct {
 	ProductID uuid.UUID `validate:"required,uuid"`
 }
 
+type GetProductsRequest struct {
+	ProductIDs []uuid.UUID `validate:"required,min=1,max=100"`
+}
+
+// PublicSearchProductsRequest browses the catalog, Query is matched against the name and description of the products
+// and a price bound only lists products priced in Currency.
 type PublicSearchProductsRequest struct {
-	Page  int `json:"page" validate:"required,min=1"`
-	Limit int `json:"limit" validate:"required,min=1,max=100"`
+	Query    string       `json:"q" validate:"max=200"`
+	OwnerID  *uuid.UUID   `json:"owner_id"`
+	Currency string       `json:"currency" validate:"omitempty,currency"`
+	MinPrice *money.Money `json:"min_price" validate:"omitempty,gt=0"`
+	MaxPrice *money.Money `json:"max_price" validate:"omitempty,gt=0"`
+	InStock  bool         `json:"in_stock"`
+	// CategoryID also lists the products of its subcategories
+	CategoryID *uuid.UUID              `json:"category_id"`
+	Tag        string                  `json:"tag" validate:"max=50"`
+	Sort       enum.ProductCatalogSort `json:"sort" validate:"omitempty,oneof=relevance newest price_asc price_desc name"`
+	Cursor     string                  `json:"cursor"`
+	Limit      int                     `json:"limit" validate:"required,min=1,max=100"`
+	// After is the decoded Cursor, the page starts after the product it points at
+	After *ProductCatalogCursor `json:"-"`
+}
+
+// ProductCatalogCursor points at the last product of a catalog page by its sort key, Value is the sort key of the
+// product as text and ID breaks ties between products with the same sort key.
+type ProductCatalogCursor struct {
+	Sort  enum.ProductCatalogSort `json:"sort"`
+	Value string                  `json:"value"`
+	ID    uuid.UUID               `json:"id"`
 }
 
+// UpdateProductRequest replaces the product, Quantity is the on hand stock and cannot go below the reserved stock.
 type UpdateProductRequest struct {
-	ID          uuid.UUID `json:"-" validate:"required,uuid"`
-	UserID      uuid.UUID `json:"user_id" validate:"required,uuid"`
-	Name        string    `json:"name" validate:"required"`
-	Description *string   `json:"description"`
-	Price       float64   `json:"price" validate:"gt=0"`
-	Quantity    int       `json:"quantity" validate:"gte=0"`
+	ID          uuid.UUID   `json:"-" validate:"required,uuid"`
+	UserID      uuid.UUID   `json:"user_id" validate:"required,uuid"`
+	Name        string      `json:"name" validate:"required"`
+	Description *string     `json:"description"`
+	Price       money.Money `json:"price" validate:"gt=0"`
+	Quantity    int         `json:"quantity" validate:"gte=0"`
+	// CategoryID and Tags replace the ones of the product, leaving them empty clears them
+	CategoryID *uuid.UUID `json:"category_id"`
+	Tags       []string   `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+// UpdateProductOptionsRequest replaces the options the variants of a product are chosen by, like a size or a color.
+type UpdateProductOptionsRequest struct {
+	ID      uuid.UUID        `json:"-" validate:"required,uuid"`
+	UserID  uuid.UUID        `json:"user_id" validate:"required,uuid"`
+	Options []*ProductOption `json:"options" validate:"dive,required"`
+}
+
+type ProductOption struct {
+	Name   string   `json:"name" validate:"required,max=50"`
+	Values []string `json:"values" validate:"required,min=1,dive,required,max=50"`
+}
+
+// CreateProductVariantRequest adds a variant that picks one value of every option of the product, Quantity is the on
+// hand stock of the variant.
+type CreateProductVariantRequest struct {
+	ProductID uuid.UUID         `json:"-" validate:"required,uuid"`
+	UserID    uuid.UUID         `json:"user_id" validate:"required,uuid"`
+	SKU       string            `json:"sku" validate:"required,max=100"`
+	Options   map[string]string `json:"options" validate:"required"`
+	Price     money.Money       `json:"price" validate:"required,gt=0"`
+	Quantity  int               `json:"quantity" validate:"gte=0"`
+}
+
+// UpdateProductVariantRequest replaces the variant, Quantity is the on hand stock and cannot go below the reserved stock.
+type UpdateProductVariantRequest struct {
+	ID        uuid.UUID         `json:"-" validate:"required,uuid"`
+	ProductID uuid.UUID         `json:"-" validate:"required,uuid"`
+	UserID    uuid.UUID         `json:"user_id" validate:"required,uuid"`
+	SKU       string            `json:"sku" validate:"required,max=100"`
+	Options   map[string]string `json:"options" validate:"required"`
+	Price     money.Money       `json:"price" validate:"required,gt=0"`
+	Quantity  int               `json:"quantity" validate:"gte=0"`
+}
+
+type DeleteProductVariantRequest struct {
+	ID        uuid.UUID `validate:"required,uuid"`
+	ProductID uuid.UUID `validate:"required,uuid"`
+	UserID    uuid.UUID `validate:"required,uuid"`
 }
 
 type DeleteProductRequest struct {
@@ -45,20 +123,69 @@ type DeleteProductRequest struct {
 }
 
 type ProductResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	CreatedAt   string  `json:"created_at,omitempty"`
-	UpdatedAt   string  `json:"updated_at,omitempty"`
-	DeletedAt   string  `json:"deleted_at,omitempty"`
+	ID          string      `json:"id"`
+	UserID      string      `json:"user_id,omitempty"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Quantity    int         `json:"quantity"`
+	CategoryID  string      `json:"category_id,omitempty"`
+	Category    string      `json:"category,omitempty"`
+	Tags        []string    `json:"tags"`
+	Stock       *Stock      `json:"stock,omitempty"`
+	CreatedAt   string      `json:"created_at,omitempty"`
+	UpdatedAt   string      `json:"updated_at,omitempty"`
+	DeletedAt   string      `json:"deleted_at,omitempty"`
+	// Options and Variants are only set on a single product
+	Options  []*ProductOption          `json:"options,omitempty"`
+	Variants []*ProductVariantResponse `json:"variants,omitempty"`
+	// Variant is the reserved variant, only set on reserved products
+	Variant *ProductVariantResponse `json:"variant,omitempty"`
 }
 
+type ProductVariantResponse struct {
+	ID        string            `json:"id"`
+	SKU       string            `json:"sku"`
+	Options   map[string]string `json:"options"`
+	Price     money.Money       `json:"price"`
+	Quantity  int               `json:"quantity"`
+	Stock     *Stock            `json:"stock,omitempty"`
+	CreatedAt string            `json:"created_at,omitempty"`
+	UpdatedAt string            `json:"updated_at,omitempty"`
+}
+
+// Stock is the stock accounting of a product shown to its owner, Available is what buyers can still reserve.
+type Stock struct {
+	OnHand    int `json:"on_hand"`
+	Reserved  int `json:"reserved"`
+	Sold      int `json:"sold"`
+	Available int `json:"available"`
+}
+
+type OwnerSearchInventoryMovementsRequest struct {
+	UserID    uuid.UUID `validate:"required,uuid"`
+	ProductID uuid.UUID `validate:"required,uuid"`
+	Page      int       `validate:"required,min=1"`
+	Limit     int       `validate:"required,min=1,max=100"`
+}
+
+type InventoryMovementResponse struct {
+	ID            string                     `json:"id"`
+	VariantID     string                     `json:"variant_id,omitempty"`
+	TransactionID string                     `json:"transaction_id,omitempty"`
+	Type          enum.InventoryMovementType `json:"type"`
+	Quantity      int                        `json:"quantity"`
+	OnHand        int                        `json:"on_hand"`
+	Reserved      int                        `json:"reserved"`
+	Sold          int                        `json:"sold"`
+	CreatedAt     string                     `json:"created_at"`
+}
+
+// CheckProductQuantity is a line to reserve, VariantID is required when the product has variants.
 type CheckProductQuantity struct {
 	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 	Quantity  int
-	Price     float64
 }
 
 type CheckProductsQuantityRequest struct {
@@ -68,18 +195,35 @@ type CheckProductsQuantityRequest struct {
 
 type CancelProductTransactionsRequest struct {
 	TransactionID uuid.UUID
+	MessageID     string
 }
 
 type ExpireProductTransactionsRequest struct {
 	TransactionID uuid.UUID
+	MessageID     string
 }
 
 type CommitProductTransactionsRequest struct {
 	TransactionID uuid.UUID
+	MessageID     string
 }
 
 type SettleProductTransactionRequest struct {
 	TransactionID uuid.UUID
+	MessageID     string
+}
+
+type RefundProductTransactionsRequest struct {
+	TransactionID uuid.UUID
+	RefundID      string
+	MessageID     string
+	Items         []*RefundProductTransactionItem
+}
+
+type RefundProductTransactionItem struct {
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Quantity  int
 }
 
 type CheckProductsQuantityRequestResponse struct {
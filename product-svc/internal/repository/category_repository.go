@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/repository/store"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+// CategoryRepository keeps the category tree, every category stores the path of slugs from the root down to itself.
+type CategoryRepository interface {
+	DeleteByIDAndUserID(ctx context.Context, db store.Querier, id uuid.UUID, userID uuid.UUID) error
+	ExistsByPath(ctx context.Context, db store.Querier, path string) (bool, error)
+	ExistsByPathExceptHerself(ctx context.Context, db store.Querier, path string, id uuid.UUID) (bool, error)
+	FindAll(ctx context.Context, db store.Querier) ([]*entity.Category, error)
+	FindByID(ctx context.Context, db store.Querier, id uuid.UUID, lockType enum.LockTypeEnum) (*entity.Category, error)
+	FindByIDAndUserID(ctx context.Context, db store.Querier, id uuid.UUID, userID uuid.UUID, lockType enum.LockTypeEnum) (*entity.Category, error)
+	Insert(ctx context.Context, db store.Querier, category *entity.Category) (*entity.Category, error)
+	IsInUse(ctx context.Context, db store.Querier, id uuid.UUID) (bool, error)
+	UpdateByID(ctx context.Context, db store.Querier, category *entity.Category) (*entity.Category, error)
+	UpdateDescendantPaths(ctx context.Context, db store.Querier, oldPath string, newPath string) error
+}
+
+type categoryRepository struct{}
+
+func NewCategoryRepository() CategoryRepository {
+	return &categoryRepository{}
+}
+
+func (r *categoryRepository) Insert(ctx context.Context, db store.Querier, category *entity.Category) (*entity.Category, error) {
+	query := `
+	INSERT INTO categories
+		(user_id, parent_id, name, slug, path)
+	VALUES
+		($1, $2, $3, $4, $5)
+	RETURNING
+		id, created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, category, query, category.UserID, category.ParentID, category.Name,
+		category.Slug, category.Path); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (r *categoryRepository) FindByID(ctx context.Context, db store.Querier, id uuid.UUID, lockType enum.LockTypeEnum) (*entity.Category, error) {
+	query := `
+	SELECT
+		id, user_id, parent_id, name, slug, path, created_at, updated_at, deleted_at
+	FROM
+		categories
+	WHERE
+		id = $1 AND deleted_at IS NULL
+	`
+	if lockType == enum.LockTypeUpdateEnum {
+		query += " FOR UPDATE"
+	} else if lockType == enum.LockTypeShareEnum {
+		query += " FOR SHARE"
+	}
+
+	category := new(entity.Category)
+	if err := pgxscan.Get(ctx, db, category, query, id); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (r *categoryRepository) FindByIDAndUserID(ctx context.Context, db store.Querier, id, userID uuid.UUID,
+	lockType enum.LockTypeEnum) (*entity.Category, error) {
+	query := `
+	SELECT
+		id, user_id, parent_id, name, slug, path, created_at, updated_at, deleted_at
+	FROM
+		categories
+	WHERE
+		id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`
+	if lockType == enum.LockTypeUpdateEnum {
+		query += " FOR UPDATE"
+	} else if lockType == enum.LockTypeShareEnum {
+		query += " FOR SHARE"
+	}
+
+	category := new(entity.Category)
+	if err := pgxscan.Get(ctx, db, category, query, id, userID); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// FindAll returns the whole tree ordered by path, so every category comes right after its parent.
+func (r *categoryRepository) FindAll(ctx context.Context, db store.Querier) ([]*entity.Category, error) {
+	var categories []*entity.Category
+	query := `
+	SELECT
+		id, user_id, parent_id, name, slug, path, created_at, updated_at, deleted_at
+	FROM
+		categories
+	WHERE
+		deleted_at IS NULL
+	ORDER BY
+		path
+	`
+	if err := pgxscan.Select(ctx, db, &categories, query); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) ExistsByPath(ctx context.Context, db store.Querier, path string) (bool, error) {
+	query := `
+	SELECT
+		COUNT(*)
+	FROM
+		categories
+	WHERE
+		path = $1 AND deleted_at IS NULL
+	`
+	var count int64
+	if err := db.QueryRow(ctx, query, path).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *categoryRepository) ExistsByPathExceptHerself(ctx context.Context, db store.Querier, path string, id uuid.UUID) (bool, error) {
+	query := `
+	SELECT
+		COUNT(*)
+	FROM
+		categories
+	WHERE
+		path = $1
+	AND
+		deleted_at IS NULL
+	AND
+		id != $2
+	`
+	var count int64
+	if err := db.QueryRow(ctx, query, path, id).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsInUse tells whether the category still has subcategories or products.
+func (r *categoryRepository) IsInUse(ctx context.Context, db store.Querier, id uuid.UUID) (bool, error) {
+	query := `
+	SELECT
+		EXISTS (SELECT 1 FROM categories WHERE parent_id = $1 AND deleted_at IS NULL)
+	OR
+		EXISTS (SELECT 1 FROM products WHERE category_id = $1 AND deleted_at IS NULL)
+	`
+	var inUse bool
+	if err := db.QueryRow(ctx, query, id).Scan(&inUse); err != nil {
+		return false, err
+	}
+	return inUse, nil
+}
+
+func (r *categoryRepository) UpdateByID(ctx context.Context, db store.Querier, category *entity.Category) (*entity.Category, error) {
+	query := `
+	UPDATE
+		categories
+	SET
+		parent_id = $1,
+		name = $2,
+		slug = $3,
+		path = $4,
+		updated_at = NOW()
+	WHERE
+		id = $5 AND user_id = $6 AND deleted_at IS NULL
+	RETURNING
+		created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, category, query, category.ParentID, category.Name, category.Slug, category.Path,
+		category.ID, category.UserID); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateDescendantPaths moves the subcategories of a category whose path changed from oldPath to newPath.
+func (r *categoryRepository) UpdateDescendantPaths(ctx context.Context, db store.Querier, oldPath, newPath string) error {
+	query := `
+	UPDATE
+		categories
+	SET
+		path = $2 || SUBSTRING(path FROM LENGTH($1) + 1),
+		updated_at = NOW()
+	WHERE
+		path LIKE $1 || '/%' AND deleted_at IS NULL
+	`
+	if _, err := db.Exec(ctx, query, oldPath, newPath); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *categoryRepository) DeleteByIDAndUserID(ctx context.Context, db store.Querier, id, userID uuid.UUID) error {
+	query := `
+	UPDATE
+		categories
+	SET
+		deleted_at = NOW()
+	WHERE
+		id = $1
+	AND
+		user_id = $2
+	AND
+		deleted_at IS NULL
+	`
+	row, err := db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return errors.New(message.InternalNoRowsAffected)
+	}
+
+	if row.RowsAffected() > 1 {
+		return errors.New(message.MultipleRowsAffected)
+	}
+
+	return nil
+}
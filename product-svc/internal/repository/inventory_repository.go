@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/model"
+	"go-saga-pattern/product-svc/internal/repository/store"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+// InventoryRepository moves the stock of a product between on hand, reserved and sold, every movement is recorded in
+// the inventory ledger in the same statement. A movement of a variant moves the stock of its product too, the product
+// holds the stock of all its variants. A movement the stock cannot cover affects no rows.
+type InventoryRepository interface {
+	Reserve(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID, transactionID uuid.UUID, quantity int) error
+	Release(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID, transactionID uuid.UUID, quantity int) error
+	Commit(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID, transactionID uuid.UUID, quantity int) error
+	Restock(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID, transactionID uuid.UUID, quantity int) error
+	// Adjust changes the on hand stock by quantity, which is negative when the owner takes stock off the shelf
+	Adjust(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID, quantity int) error
+	FindManyByProductID(ctx context.Context, db store.Querier, request *model.OwnerSearchInventoryMovementsRequest) ([]*entity.InventoryMovementWithTotal, *web.PageMetadata, error)
+}
+
+type inventoryRepository struct{}
+
+func NewInventoryRepository() InventoryRepository {
+	return &inventoryRepository{}
+}
+
+func (r *inventoryRepository) Reserve(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID,
+	transactionID uuid.UUID, quantity int) error {
+	return r.move(ctx, db, productID, variantID, uuid.NullUUID{UUID: transactionID, Valid: true}, enum.InventoryMovementReserve, quantity,
+		"reserved = reserved + $1", "on_hand - reserved >= $1")
+}
+
+func (r *inventoryRepository) Release(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID,
+	transactionID uuid.UUID, quantity int) error {
+	return r.move(ctx, db, productID, variantID, uuid.NullUUID{UUID: transactionID, Valid: true}, enum.InventoryMovementRelease, quantity,
+		"reserved = reserved - $1", "reserved >= $1")
+}
+
+func (r *inventoryRepository) Commit(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID,
+	transactionID uuid.UUID, quantity int) error {
+	return r.move(ctx, db, productID, variantID, uuid.NullUUID{UUID: transactionID, Valid: true}, enum.InventoryMovementCommit, quantity,
+		"on_hand = on_hand - $1, reserved = reserved - $1, sold = sold + $1", "reserved >= $1")
+}
+
+func (r *inventoryRepository) Restock(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID,
+	transactionID uuid.UUID, quantity int) error {
+	return r.move(ctx, db, productID, variantID, uuid.NullUUID{UUID: transactionID, Valid: true}, enum.InventoryMovementRestock, quantity,
+		"on_hand = on_hand + $1, sold = sold - $1", "sold >= $1")
+}
+
+func (r *inventoryRepository) Adjust(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID, quantity int) error {
+	return r.move(ctx, db, productID, variantID, uuid.NullUUID{}, enum.InventoryMovementAdjust, quantity,
+		"on_hand = on_hand + $1", "on_hand + $1 >= reserved")
+}
+
+// move applies set to the variant, when there is one, and to the product when guard holds on both and records the
+// movement with the stock of the product that results from it.
+func (r *inventoryRepository) move(ctx context.Context, db store.Querier, productID uuid.UUID, variantID uuid.NullUUID,
+	transactionID uuid.NullUUID, movementType enum.InventoryMovementType, quantity int, set string, guard string) error {
+	query := `
+	WITH moved_variant AS (
+		UPDATE
+			product_variants
+		SET
+			` + set + `,
+			updated_at = NOW()
+		WHERE
+			$5::uuid IS NOT NULL AND id = $5 AND product_id = $2 AND deleted_at IS NULL AND ` + guard + `
+		RETURNING
+			id
+	), moved AS (
+		UPDATE
+			products
+		SET
+			` + set + `,
+			updated_at = NOW()
+		WHERE
+			id = $2 AND ($5::uuid IS NULL OR EXISTS (SELECT 1 FROM moved_variant)) AND ` + guard + `
+		RETURNING
+			id, on_hand, reserved, sold
+	)
+	INSERT INTO inventory_movements
+		(product_id, variant_id, transaction_id, type, quantity, on_hand, reserved, sold)
+	SELECT
+		id, $5, $3, $4, $1, on_hand, reserved, sold
+	FROM
+		moved
+	`
+	row, err := db.Exec(ctx, query, quantity, productID, transactionID, movementType, variantID)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return errors.New(message.InternalNoRowsAffected)
+	}
+
+	if row.RowsAffected() > 1 {
+		return errors.New(message.MultipleRowsAffected)
+	}
+
+	return nil
+}
+
+func (r *inventoryRepository) FindManyByProductID(ctx context.Context, db store.Querier,
+	request *model.OwnerSearchInventoryMovementsRequest) ([]*entity.InventoryMovementWithTotal, *web.PageMetadata, error) {
+	query := `
+	SELECT
+		COUNT(*) OVER () AS total_data,
+		id, product_id, variant_id, transaction_id, type, quantity, on_hand, reserved, sold, created_at
+	FROM
+		inventory_movements
+	WHERE
+		product_id = $1
+	ORDER BY
+		created_at DESC, id
+	LIMIT $2 OFFSET $3
+	`
+
+	var movements []*entity.InventoryMovementWithTotal
+	if err := pgxscan.Select(ctx, db, &movements, query, request.ProductID, request.Limit, (request.Page-1)*request.Limit); err != nil {
+		return nil, nil, err
+	}
+
+	if len(movements) == 0 {
+		return nil, &web.PageMetadata{}, nil
+	}
+
+	pageMetadata := helper.CalculatePagination(int64(movements[0].TotalData), request.Page, request.Limit)
+	return movements, pageMetadata, nil
+}
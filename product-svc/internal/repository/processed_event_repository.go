@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/repository/store"
+)
+
+type ProcessedEventRepository interface {
+	Insert(ctx context.Context, tx store.Querier, processedEvent *entity.ProcessedEvent) (bool, error)
+}
+
+type processedEventRepository struct {
+}
+
+func NewProcessedEventRepository() ProcessedEventRepository {
+	return &processedEventRepository{}
+}
+
+// Insert records an event as processed and reports false when the same (transaction_id, event) pair
+// or message id was already recorded, so the caller can skip the redelivery.
+func (r *processedEventRepository) Insert(ctx context.Context, tx store.Querier, processedEvent *entity.ProcessedEvent) (bool, error) {
+	query := `
+	INSERT INTO processed_events
+		(transaction_id, event, message_id)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT DO NOTHING
+	`
+	row, err := tx.Exec(ctx, query, processedEvent.TransactionID, processedEvent.Event, processedEvent.MessageID)
+	if err != nil {
+		return false, err
+	}
+
+	return row.RowsAffected() > 0, nil
+}
@@ -27,11 +27,10 @@ type ProductRepository interface {
 	FindManyByIDs(ctx context.Context, db store.Querier, ids []uuid.UUID, lockType enum.LockTypeEnum) ([]*entity.Product, error)
 	Insert(ctx context.Context, db store.Querier, product *entity.Product) (*entity.Product, error)
 	OwnerFindAll(ctx context.Context, db store.Querier, request *model.OwnerSearchProductsRequest) ([]*entity.ProductWithTotal, *web.PageMetadata, error)
-	PublicFindAll(ctx context.Context, db store.Querier, page int, limit int) ([]*entity.ProductWithTotal, *web.PageMetadata, error)
+	PublicFindAll(ctx context.Context, db store.Querier, request *model.PublicSearchProductsRequest) ([]*entity.ProductWithRank, error)
 	UpdateByID(ctx context.Context, db store.Querier, product *entity.Product) (*entity.Product, error)
+	UpdateOptionsByID(ctx context.Context, db store.Querier, product *entity.Product) (*entity.Product, error)
 	// UpdateQuantityByID(ctx context.Context, db store.Querier, id uuid.UUID, quantity int) (*entity.Product, error)
-	ReduceQuantity(ctx context.Context, db store.Querier, id uuid.UUID, quantity int) error
-	RestoreQuantity(ctx context.Context, db store.Querier, id uuid.UUID, quantity int) error
 }
 
 type productRepository struct{}
@@ -43,14 +42,15 @@ func NewProductRepository() ProductRepository {
 func (r *productRepository) Insert(ctx context.Context, db store.Querier, product *entity.Product) (*entity.Product, error) {
 	query := `
 	INSERT INTO products
-		(user_id, name, slug, description, price, quantity)
+		(user_id, name, slug, description, price, currency, on_hand, category_id, tags)
 	VALUES
-		($1, $2, $3, $4, $5, $6)
+		($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	RETURNING
-		id, created_at, updated_at
+		id, quantity, on_hand, reserved, sold, options,
+		(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at
 	`
-	if err := pgxscan.Get(ctx, db, product, query, product.UserID,
-		product.Name, product.Slug, product.Description, product.Price, product.Quantity); err != nil {
+	if err := pgxscan.Get(ctx, db, product, query, product.UserID, product.Name, product.Slug, product.Description,
+		product.Price, product.Currency, product.OnHand, product.CategoryID, pq.Array(product.Tags)); err != nil {
 		return nil, err
 	}
 	return product, nil
@@ -59,7 +59,8 @@ func (r *productRepository) Insert(ctx context.Context, db store.Querier, produc
 func (r *productRepository) FindByIDAndUserID(ctx context.Context, db store.Querier, id, userID uuid.UUID) (*entity.Product, error) {
 	query := `
 	SELECT
-		id, user_id, name, slug, description, price, quantity, created_at, updated_at, deleted_at
+		id, user_id, name, slug, description, price, currency, quantity, on_hand, reserved, sold, options, category_id, tags,
+		(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at, deleted_at
 	FROM
 		products
 	WHERE
@@ -69,13 +70,15 @@ func (r *productRepository) FindByIDAndUserID(ctx context.Context, db store.Quer
 	if err := pgxscan.Get(ctx, db, product, query, id, userID); err != nil {
 		return nil, err
 	}
+	product.ApplyCurrency()
 	return product, nil
 }
 
 func (r *productRepository) FindByID(ctx context.Context, db store.Querier, id uuid.UUID) (*entity.Product, error) {
 	query := `
 	SELECT
-		id, name, slug, description, price, quantity, created_at, updated_at, deleted_at
+		id, name, slug, description, price, currency, quantity, on_hand, reserved, sold, options, category_id, tags,
+		(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at, deleted_at
 	FROM
 		products
 	WHERE
@@ -85,6 +88,7 @@ func (r *productRepository) FindByID(ctx context.Context, db store.Querier, id u
 	if err := pgxscan.Get(ctx, db, product, query, id); err != nil {
 		return nil, err
 	}
+	product.ApplyCurrency()
 	return product, nil
 }
 
@@ -92,7 +96,8 @@ func (r *productRepository) FindManyByIDs(ctx context.Context, db store.Querier,
 	var products []*entity.Product
 	query := `
 	SELECT 
-		id, name, slug, description, price, quantity, created_at, updated_at, deleted_at 
+		id, user_id, name, slug, description, price, currency, quantity, on_hand, reserved, sold, options, category_id, tags,
+		(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at, deleted_at
 	FROM 
 		products 
 	WHERE 
@@ -108,13 +113,18 @@ func (r *productRepository) FindManyByIDs(ctx context.Context, db store.Querier,
 		return nil, err
 	}
 
+	for _, product := range products {
+		product.ApplyCurrency()
+	}
+
 	return products, nil
 }
 
 func (r *productRepository) FindBySlug(ctx context.Context, db store.Querier, slug string) (*entity.Product, error) {
 	query := `
 	SELECT
-		id, name, slug, description, price, quantity, created_at, updated_at, deleted_at
+		id, name, slug, description, price, currency, quantity, on_hand, reserved, sold, options, category_id, tags,
+		(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at, deleted_at
 	FROM
 		products
 	WHERE
@@ -124,6 +134,7 @@ func (r *productRepository) FindBySlug(ctx context.Context, db store.Querier, sl
 	if err := pgxscan.Get(ctx, db, product, query, slug); err != nil {
 		return nil, err
 	}
+	product.ApplyCurrency()
 	return product, nil
 }
 
@@ -172,18 +183,40 @@ func (r *productRepository) UpdateByID(ctx context.Context, db store.Querier, pr
 		slug = COALESCE($2, slug),
 		description = COALESCE($3, description),
 		price = COALESCE($4, price),
-		quantity = COALESCE($5, quantity),
+		currency = COALESCE($5, currency),
+		category_id = $8,
+		tags = $9,
 		updated_at = NOW()
 	WHERE
 		id = $6 AND user_id = $7 AND deleted_at IS NULL
 	RETURNING
-		created_at, updated_at
+		quantity, on_hand, reserved, sold, options,
+		(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at
 	`
 
 	log.Default().Printf("Update Product Query: %s with Product: %+v", query, product)
 
 	if err := pgxscan.Get(ctx, db, product, query, product.Name, product.Slug, product.Description,
-		product.Price, product.Quantity, product.ID, product.UserID); err != nil {
+		product.Price, product.Currency, product.ID, product.UserID, product.CategoryID, pq.Array(product.Tags)); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// UpdateOptionsByID replaces the options the variants of the product are chosen by.
+func (r *productRepository) UpdateOptionsByID(ctx context.Context, db store.Querier, product *entity.Product) (*entity.Product, error) {
+	query := `
+	UPDATE
+		products
+	SET
+		options = $1,
+		updated_at = NOW()
+	WHERE
+		id = $2 AND user_id = $3 AND deleted_at IS NULL
+	RETURNING
+		quantity, on_hand, reserved, sold, options, created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, product, query, product.Options, product.ID, product.UserID); err != nil {
 		return nil, err
 	}
 	return product, nil
@@ -221,35 +254,83 @@ func (r *productRepository) DeleteByIDAndUserID(ctx context.Context, db store.Qu
 
 }
 
-func (r *productRepository) PublicFindAll(ctx context.Context, db store.Querier, page,
-	limit int) ([]*entity.ProductWithTotal, *web.PageMetadata, error) {
-	var totalItems int
+// catalogSorts is the keyset of every catalog sort, the condition puts a page after the product its cursor points at.
+var catalogSorts = map[enum.ProductCatalogSort]struct {
+	condition string
+	orderBy   string
+}{
+	enum.ProductCatalogSortRelevance: {"(rank, id) < ($10::text::real, $11::uuid)", "rank DESC, id DESC"},
+	enum.ProductCatalogSortNewest:    {"(created_at, id) < ($10::text::timestamptz, $11::uuid)", "created_at DESC, id DESC"},
+	enum.ProductCatalogSortPriceAsc:  {"(price, id) > ($10::text::numeric, $11::uuid)", "price ASC, id ASC"},
+	enum.ProductCatalogSortPriceDesc: {"(price, id) < ($10::text::numeric, $11::uuid)", "price DESC, id DESC"},
+	enum.ProductCatalogSortName:      {"(name, id) > ($10::text, $11::uuid)", "name ASC, id ASC"},
+}
+
+// PublicFindAll lists the catalog by keyset pagination, one product more than the limit is returned when there is a
+// next page.
+func (r *productRepository) PublicFindAll(ctx context.Context, db store.Querier,
+	request *model.PublicSearchProductsRequest) ([]*entity.ProductWithRank, error) {
+	sort, ok := catalogSorts[request.Sort]
+	if !ok {
+		sort = catalogSorts[enum.ProductCatalogSortNewest]
+	}
+
 	query := `
 	SELECT
-		COUNT(*) OVER () AS total_data,
-		id, name, slug, description, price, quantity, created_at, updated_at, deleted_at
-	FROM
-		products
-	WHERE
-		deleted_at IS NULL
-	ORDER BY
-		created_at DESC
-	LIMIT $1 OFFSET $2
+		id, user_id, name, slug, description, price, currency, quantity, on_hand, reserved, sold, options, category_id, tags,
+		category, created_at, updated_at, deleted_at, rank
+	FROM (
+		SELECT
+			id, user_id, name, slug, description, price, currency, quantity, on_hand, reserved, sold, options, category_id, tags,
+			(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at, deleted_at,
+			CASE WHEN $1::text = '' THEN 0 ELSE ts_rank(search_vector, websearch_to_tsquery('simple', $1::text)) END AS rank
+		FROM
+			products
+		WHERE
+			deleted_at IS NULL
+		AND
+			($1::text = '' OR search_vector @@ websearch_to_tsquery('simple', $1::text))
+		AND
+			($2::uuid IS NULL OR user_id = $2)
+		AND
+			($3::text = '' OR currency = $3)
+		AND
+			($4::numeric IS NULL OR price >= $4)
+		AND
+			($5::numeric IS NULL OR price <= $5)
+		AND
+			(NOT $6::boolean OR quantity > 0)
+		AND
+			($8::uuid IS NULL OR category_id IN (
+				SELECT
+					child.id
+				FROM
+					categories child, categories parent
+				WHERE
+					parent.id = $8 AND child.deleted_at IS NULL AND (child.id = parent.id OR child.path LIKE parent.path || '/%')
+			))
+		AND
+			($9::text = '' OR tags @> ARRAY[$9::text])
+	) catalog
 	`
-
-	var products []*entity.ProductWithTotal
-	if err := pgxscan.Select(ctx, db, &products, query, limit, (page-1)*limit); err != nil {
-		return nil, nil, err
+	args := []any{request.Query, request.OwnerID, request.Currency, request.MinPrice, request.MaxPrice, request.InStock, request.Limit + 1,
+		request.CategoryID, request.Tag}
+	if request.After != nil {
+		query += " WHERE " + sort.condition
+		args = append(args, request.After.Value, request.After.ID)
 	}
+	query += " ORDER BY " + sort.orderBy + " LIMIT $7"
 
-	if len(products) == 0 {
-		return nil, nil, nil
+	var products []*entity.ProductWithRank
+	if err := pgxscan.Select(ctx, db, &products, query, args...); err != nil {
+		return nil, err
 	}
 
-	totalItems = products[0].TotalData
+	for _, product := range products {
+		product.ApplyCurrency()
+	}
 
-	pageMetadata := helper.CalculatePagination(int64(totalItems), page, limit)
-	return products, pageMetadata, nil
+	return products, nil
 }
 
 func (r *productRepository) OwnerFindAll(ctx context.Context, db store.Querier, request *model.OwnerSearchProductsRequest) ([]*entity.ProductWithTotal, *web.PageMetadata, error) {
@@ -257,7 +338,8 @@ func (r *productRepository) OwnerFindAll(ctx context.Context, db store.Querier,
 	query := `
 	SELECT
 		COUNT(*) OVER () AS total_data,
-		id, name, slug, description, price, quantity, created_at, updated_at, deleted_at
+		id, name, slug, description, price, currency, quantity, on_hand, reserved, sold, options, category_id, tags,
+		(SELECT path FROM categories WHERE categories.id = products.category_id) AS category, created_at, updated_at, deleted_at
 	FROM
 		products
 	WHERE
@@ -276,44 +358,12 @@ func (r *productRepository) OwnerFindAll(ctx context.Context, db store.Querier,
 		return nil, nil, nil
 	}
 
+	for _, product := range products {
+		product.ApplyCurrency()
+	}
+
 	totalItems = products[0].TotalData
 
 	pageMetadata := helper.CalculatePagination(int64(totalItems), request.Page, request.Limit)
 	return products, pageMetadata, nil
 }
-
-func (r *productRepository) ReduceQuantity(ctx context.Context, db store.Querier, id uuid.UUID, quantity int) error {
-	query := "UPDATE products SET quantity = quantity - $1 WHERE id = $2"
-	row, err := db.Exec(ctx, query, quantity, id)
-	if err != nil {
-		return err
-	}
-
-	if row.RowsAffected() == 0 {
-		return errors.New(message.InternalNoRowsAffected)
-	}
-
-	if row.RowsAffected() > 1 {
-		return errors.New(message.MultipleRowsAffected)
-	}
-
-	return nil
-}
-
-func (r *productRepository) RestoreQuantity(ctx context.Context, db store.Querier, id uuid.UUID, quantity int) error {
-	query := "UPDATE products SET quantity = quantity + $1 WHERE id = $2"
-	row, err := db.Exec(ctx, query, quantity, id)
-	if err != nil {
-		return err
-	}
-
-	if row.RowsAffected() == 0 {
-		return errors.New(message.InternalNoRowsAffected)
-	}
-
-	if row.RowsAffected() > 1 {
-		return errors.New(message.MultipleRowsAffected)
-	}
-
-	return nil
-}
@@ -10,13 +10,16 @@ import (
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type ProductTransactionRepository interface {
 	FindManyByTrxID(ctx context.Context, db store.Querier, transactionID uuid.UUID, forUpdate bool) ([]*entity.ProductTransaction, error)
 	Insert(ctx context.Context, db store.Querier, productTransaction *entity.ProductTransaction) (*entity.ProductTransaction, error)
 	UpdateStatus(ctx context.Context, db store.Querier, transactionID uuid.UUID, status enum.ProductTransactionStatusEnum) error
+	AddRefundedQuantity(ctx context.Context, db store.Querier, transactionID, productID uuid.UUID, variantID uuid.NullUUID, quantity int) error
 	InsertMany(ctx context.Context, db store.Querier, productTransactions []*entity.ProductTransaction) ([]*entity.ProductTransaction, error)
+	FindManyExpiredReservationTrxIDs(ctx context.Context, db store.Querier, limit int) ([]uuid.UUID, error)
 }
 
 type productTransactionRepository struct {
@@ -48,6 +51,11 @@ func (r *productTransactionRepository) Insert(ctx context.Context, db store.Quer
 func (r *productTransactionRepository) UpdateStatus(ctx context.Context, db store.Querier, transactionID uuid.UUID,
 	status enum.ProductTransactionStatusEnum) error {
 
+	fromStatuses, err := entity.ProductTransactionSources(status)
+	if err != nil {
+		return err
+	}
+
 	// var returningStatusTime string
 	query := "UPDATE product_transactions SET status = $1, "
 	switch status {
@@ -63,18 +71,60 @@ func (r *productTransactionRepository) UpdateStatus(ctx context.Context, db stor
 	case enum.ProductTransactionStatusSettled:
 		query += "settled_at = now()"
 		// returningStatusTime = " RETURNING settled_at"
-	default:
-		return fmt.Errorf("invalid status: %s", status)
 	}
 
-	query += ", updated_at = now() WHERE transaction_id = $2"
+	query += ", updated_at = now() WHERE transaction_id = $2 AND status = ANY($3)"
 	// query += returningStatusTime
 
-	_, err := db.Exec(ctx, query, status, transactionID)
+	_, err = db.Exec(ctx, query, status, transactionID, pq.Array(fromStatuses))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindManyExpiredReservationTrxIDs returns the transactions whose reservations passed expires_at without being committed.
+func (r *productTransactionRepository) FindManyExpiredReservationTrxIDs(ctx context.Context, db store.Querier, limit int) ([]uuid.UUID, error) {
+	transactionIDs := make([]uuid.UUID, 0)
+	query := `
+	SELECT DISTINCT
+		transaction_id
+	FROM
+		product_transactions
+	WHERE
+		status = $1 AND expires_at <= now()
+	LIMIT $2
+	`
+	if err := pgxscan.Select(ctx, db, &transactionIDs, query, enum.ProductTransactionStatusReserved, limit); err != nil {
+		return nil, err
+	}
+
+	return transactionIDs, nil
+}
+
+// AddRefundedQuantity records refunded items of a line and marks it REFUNDED once every item was refunded.
+func (r *productTransactionRepository) AddRefundedQuantity(ctx context.Context, db store.Querier, transactionID, productID uuid.UUID,
+	variantID uuid.NullUUID, quantity int) error {
+	query := `
+	UPDATE product_transactions
+	SET
+		refunded_quantity = refunded_quantity + $1,
+		status = CASE WHEN refunded_quantity + $1 = quantity THEN $2 ELSE status END,
+		refunded_at = now(),
+		updated_at = now()
+	WHERE
+		transaction_id = $3 AND product_id = $4 AND variant_id IS NOT DISTINCT FROM $5 AND refunded_quantity + $1 <= quantity
+	`
+	row, err := db.Exec(ctx, query, quantity, enum.ProductTransactionStatusRefunded, transactionID, productID, variantID)
 	if err != nil {
 		return err
 	}
 
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for product transaction %s/%s/%s", transactionID, productID, variantID.UUID)
+	}
+
 	return nil
 }
 
@@ -91,6 +141,10 @@ func (r *productTransactionRepository) FindManyByTrxID(ctx context.Context, db s
 		return nil, err
 	}
 
+	for _, productTransaction := range productTransactions {
+		productTransaction.ApplyCurrency()
+	}
+
 	return productTransactions, nil
 }
 
@@ -98,7 +152,7 @@ func (r *productTransactionRepository) InsertMany(ctx context.Context, db store.
 	productTransactions []*entity.ProductTransaction) ([]*entity.ProductTransaction, error) {
 	query := `
     INSERT INTO product_transactions 
-    (transaction_id, product_id, status, quantity, total_price, reserved_at) 
+    (transaction_id, product_id, variant_id, status, quantity, total_price, currency, reserved_at, expires_at) 
     VALUES `
 
 	var args []interface{}
@@ -106,19 +160,23 @@ func (r *productTransactionRepository) InsertMany(ctx context.Context, db store.
 	argPos := 1
 
 	for _, pt := range productTransactions {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, now())",
-			argPos, argPos+1, argPos+2, argPos+3, argPos+4))
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, now(), $%d)",
+			argPos, argPos+1, argPos+2, argPos+3, argPos+4, argPos+5, argPos+6, argPos+7))
 
-		args = append(args, pt.TransactionID, pt.ProductID, pt.Status, pt.Quantity, pt.TotalPrice)
-		argPos += 5
+		args = append(args, pt.TransactionID, pt.ProductID, pt.VariantID, pt.Status, pt.Quantity, pt.TotalPrice, pt.Currency, pt.ExpiresAt)
+		argPos += 8
 	}
 
 	query += strings.Join(valueStrings, ",")
-	query += " RETURNING transaction_id, product_id, status, quantity, total_price, reserved_at"
+	query += " RETURNING transaction_id, product_id, variant_id, status, quantity, total_price, currency, reserved_at, expires_at"
 
 	if err := pgxscan.Select(ctx, db, &productTransactions, query, args...); err != nil {
 		return nil, err
 	}
 
+	for _, productTransaction := range productTransactions {
+		productTransaction.ApplyCurrency()
+	}
+
 	return productTransactions, nil
 }
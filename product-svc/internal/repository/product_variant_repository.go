@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/repository/store"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ProductVariantRepository keeps the variants of a product, their stock is only moved through InventoryRepository.
+type ProductVariantRepository interface {
+	DeleteByIDAndProductID(ctx context.Context, db store.Querier, id uuid.UUID, productID uuid.UUID) error
+	ExistsBySKU(ctx context.Context, db store.Querier, sku string) (bool, error)
+	ExistsBySKUExceptHerself(ctx context.Context, db store.Querier, sku string, id uuid.UUID) (bool, error)
+	FindByIDAndProductID(ctx context.Context, db store.Querier, id uuid.UUID, productID uuid.UUID, lockType enum.LockTypeEnum) (*entity.ProductVariant, error)
+	FindManyByIDs(ctx context.Context, db store.Querier, ids []uuid.UUID, lockType enum.LockTypeEnum) ([]*entity.ProductVariant, error)
+	FindManyByProductID(ctx context.Context, db store.Querier, productID uuid.UUID) ([]*entity.ProductVariant, error)
+	FindManyByProductIDs(ctx context.Context, db store.Querier, productIDs []uuid.UUID) ([]*entity.ProductVariant, error)
+	Insert(ctx context.Context, db store.Querier, variant *entity.ProductVariant) (*entity.ProductVariant, error)
+	UpdateByID(ctx context.Context, db store.Querier, variant *entity.ProductVariant) (*entity.ProductVariant, error)
+}
+
+type productVariantRepository struct{}
+
+func NewProductVariantRepository() ProductVariantRepository {
+	return &productVariantRepository{}
+}
+
+func (r *productVariantRepository) Insert(ctx context.Context, db store.Querier, variant *entity.ProductVariant) (*entity.ProductVariant, error) {
+	query := `
+	INSERT INTO product_variants
+		(product_id, sku, options, price, currency)
+	VALUES
+		($1, $2, $3, $4, $5)
+	RETURNING
+		id, quantity, on_hand, reserved, sold, created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, variant, query, variant.ProductID, variant.SKU, variant.Options,
+		variant.Price, variant.Currency); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (r *productVariantRepository) FindByIDAndProductID(ctx context.Context, db store.Querier, id, productID uuid.UUID,
+	lockType enum.LockTypeEnum) (*entity.ProductVariant, error) {
+	query := `
+	SELECT
+		id, product_id, sku, options, price, currency, quantity, on_hand, reserved, sold, created_at, updated_at, deleted_at
+	FROM
+		product_variants
+	WHERE
+		id = $1 AND product_id = $2 AND deleted_at IS NULL
+	`
+	if lockType == enum.LockTypeUpdateEnum {
+		query += " FOR UPDATE"
+	} else if lockType == enum.LockTypeShareEnum {
+		query += " FOR SHARE"
+	}
+
+	variant := new(entity.ProductVariant)
+	if err := pgxscan.Get(ctx, db, variant, query, id, productID); err != nil {
+		return nil, err
+	}
+	variant.ApplyCurrency()
+	return variant, nil
+}
+
+func (r *productVariantRepository) FindManyByIDs(ctx context.Context, db store.Querier, ids []uuid.UUID,
+	lockType enum.LockTypeEnum) ([]*entity.ProductVariant, error) {
+	var variants []*entity.ProductVariant
+	query := `
+	SELECT
+		id, product_id, sku, options, price, currency, quantity, on_hand, reserved, sold, created_at, updated_at, deleted_at
+	FROM
+		product_variants
+	WHERE
+		id = ANY($1) AND deleted_at IS NULL
+	`
+	if lockType == enum.LockTypeUpdateEnum {
+		query += " FOR UPDATE"
+	} else if lockType == enum.LockTypeShareEnum {
+		query += " FOR SHARE"
+	}
+
+	if err := pgxscan.Select(ctx, db, &variants, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		variant.ApplyCurrency()
+	}
+
+	return variants, nil
+}
+
+func (r *productVariantRepository) FindManyByProductID(ctx context.Context, db store.Querier, productID uuid.UUID) ([]*entity.ProductVariant, error) {
+	var variants []*entity.ProductVariant
+	query := `
+	SELECT
+		id, product_id, sku, options, price, currency, quantity, on_hand, reserved, sold, created_at, updated_at, deleted_at
+	FROM
+		product_variants
+	WHERE
+		product_id = $1 AND deleted_at IS NULL
+	ORDER BY
+		created_at, id
+	`
+	if err := pgxscan.Select(ctx, db, &variants, query, productID); err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		variant.ApplyCurrency()
+	}
+
+	return variants, nil
+}
+
+func (r *productVariantRepository) FindManyByProductIDs(ctx context.Context, db store.Querier, productIDs []uuid.UUID) ([]*entity.ProductVariant, error) {
+	var variants []*entity.ProductVariant
+	query := `
+	SELECT
+		id, product_id, sku, options, price, currency, quantity, on_hand, reserved, sold, created_at, updated_at, deleted_at
+	FROM
+		product_variants
+	WHERE
+		product_id = ANY($1) AND deleted_at IS NULL
+	ORDER BY
+		created_at, id
+	`
+	if err := pgxscan.Select(ctx, db, &variants, query, pq.Array(productIDs)); err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		variant.ApplyCurrency()
+	}
+
+	return variants, nil
+}
+
+func (r *productVariantRepository) ExistsBySKU(ctx context.Context, db store.Querier, sku string) (bool, error) {
+	query := `
+	SELECT
+		COUNT(*)
+	FROM
+		product_variants
+	WHERE
+		sku = $1 AND deleted_at IS NULL
+	`
+	var count int64
+	if err := db.QueryRow(ctx, query, sku).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *productVariantRepository) ExistsBySKUExceptHerself(ctx context.Context, db store.Querier, sku string, id uuid.UUID) (bool, error) {
+	query := `
+	SELECT
+		COUNT(*)
+	FROM
+		product_variants
+	WHERE
+		sku = $1
+	AND
+		deleted_at IS NULL
+	AND
+		id != $2
+	`
+	var count int64
+	if err := db.QueryRow(ctx, query, sku, id).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *productVariantRepository) UpdateByID(ctx context.Context, db store.Querier, variant *entity.ProductVariant) (*entity.ProductVariant, error) {
+	query := `
+	UPDATE
+		product_variants
+	SET
+		sku = $1,
+		options = $2,
+		price = $3,
+		currency = $4,
+		updated_at = NOW()
+	WHERE
+		id = $5 AND product_id = $6 AND deleted_at IS NULL
+	RETURNING
+		quantity, on_hand, reserved, sold, created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, variant, query, variant.SKU, variant.Options, variant.Price, variant.Currency,
+		variant.ID, variant.ProductID); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// DeleteByIDAndProductID soft deletes a variant that no longer holds any stock.
+func (r *productVariantRepository) DeleteByIDAndProductID(ctx context.Context, db store.Querier, id, productID uuid.UUID) error {
+	query := `
+	UPDATE
+		product_variants
+	SET
+		deleted_at = NOW()
+	WHERE
+		id = $1
+	AND
+		product_id = $2
+	AND
+		on_hand = 0
+	AND
+		deleted_at IS NULL
+	`
+	row, err := db.Exec(ctx, query, id, productID)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return errors.New(message.InternalNoRowsAffected)
+	}
+
+	if row.RowsAffected() > 1 {
+		return errors.New(message.MultipleRowsAffected)
+	}
+
+	return nil
+}
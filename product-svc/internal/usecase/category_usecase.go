@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/model"
+	"go-saga-pattern/product-svc/internal/model/converter"
+	"go-saga-pattern/product-svc/internal/repository"
+	"go-saga-pattern/product-svc/internal/repository/store"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gosimple/slug"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type CategoryUseCase interface {
+	GetAll(ctx context.Context) ([]*model.CategoryResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.CategoryResponse, error)
+	OwnerCreate(ctx context.Context, request *model.CreateCategoryRequest) (*model.CategoryResponse, error)
+	OwnerDelete(ctx context.Context, request *model.DeleteCategoryRequest) error
+	OwnerUpdate(ctx context.Context, request *model.UpdateCategoryRequest) (*model.CategoryResponse, error)
+}
+
+type categoryUseCase struct {
+	categoryRepository repository.CategoryRepository
+	databaseStore      store.DatabaseStore
+	validator          helper.CustomValidator
+	log                logs.Log
+}
+
+func NewCategoryUseCase(categoryRepository repository.CategoryRepository, databaseStore store.DatabaseStore,
+	validator helper.CustomValidator, log logs.Log) CategoryUseCase {
+	return &categoryUseCase{
+		categoryRepository: categoryRepository,
+		databaseStore:      databaseStore,
+		validator:          validator,
+		log:                log,
+	}
+}
+
+func (uc *categoryUseCase) GetAll(ctx context.Context) ([]*model.CategoryResponse, error) {
+	categories, err := uc.categoryRepository.FindAll(ctx, uc.databaseStore)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find categories", err)
+	}
+
+	return converter.CategoriesToResponses(categories), nil
+}
+
+func (uc *categoryUseCase) GetByID(ctx context.Context, id uuid.UUID) (*model.CategoryResponse, error) {
+	category, err := uc.categoryRepository.FindByID(ctx, uc.databaseStore, id, enum.LockTypeNoneEnum)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CategoryNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find category by id", err)
+	}
+
+	return converter.CategoryToResponse(category), nil
+}
+
+func (uc *categoryUseCase) OwnerCreate(ctx context.Context, request *model.CreateCategoryRequest) (*model.CategoryResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	category := &entity.Category{
+		UserID: request.UserID,
+		Name:   request.Name,
+		Slug:   slug.Make(request.Name),
+	}
+
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		parentPath, err := uc.findParentPath(ctx, tx, request.ParentID)
+		if err != nil {
+			return err
+		}
+
+		category.ParentID = nullable.ToNullUUID(request.ParentID)
+		category.Path = categoryPath(parentPath, category.Slug)
+
+		isExistsByPath, err := uc.categoryRepository.ExistsByPath(ctx, tx, category.Path)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to check category exists by path", err)
+		}
+
+		if isExistsByPath {
+			return helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.CategoryIsExistsByName)
+		}
+
+		if _, err := uc.categoryRepository.Insert(ctx, tx, category); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert category", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return converter.CategoryToResponse(category), nil
+}
+
+func (uc *categoryUseCase) OwnerUpdate(ctx context.Context, request *model.UpdateCategoryRequest) (*model.CategoryResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	var category *entity.Category
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		var err error
+		category, err = uc.categoryRepository.FindByIDAndUserID(ctx, tx, request.ID, request.UserID, enum.LockTypeUpdateEnum)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CategoryNotFound)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find category by id", err)
+		}
+
+		parentPath, err := uc.findParentPath(ctx, tx, request.ParentID)
+		if err != nil {
+			return err
+		}
+
+		// a category cannot become a descendant of itself
+		if parentPath == category.Path || strings.HasPrefix(parentPath, category.Path+"/") {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CategoryParentInvalid)
+		}
+
+		oldPath := category.Path
+		category.ParentID = nullable.ToNullUUID(request.ParentID)
+		category.Name = request.Name
+		category.Slug = slug.Make(request.Name)
+		category.Path = categoryPath(parentPath, category.Slug)
+
+		isExistsByPath, err := uc.categoryRepository.ExistsByPathExceptHerself(ctx, tx, category.Path, category.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to check category exists by path", err)
+		}
+
+		if isExistsByPath {
+			return helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.CategoryIsExistsByName)
+		}
+
+		if _, err := uc.categoryRepository.UpdateByID(ctx, tx, category); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update category", err)
+		}
+
+		if oldPath == category.Path {
+			return nil
+		}
+
+		if err := uc.categoryRepository.UpdateDescendantPaths(ctx, tx, oldPath, category.Path); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to move subcategories", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return converter.CategoryToResponse(category), nil
+}
+
+func (uc *categoryUseCase) OwnerDelete(ctx context.Context, request *model.DeleteCategoryRequest) error {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return validatonErrs
+	}
+
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		category, err := uc.categoryRepository.FindByIDAndUserID(ctx, tx, request.ID, request.UserID, enum.LockTypeUpdateEnum)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CategoryNotFound)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find category by id", err)
+		}
+
+		inUse, err := uc.categoryRepository.IsInUse(ctx, tx, category.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to check category is in use", err)
+		}
+
+		if inUse {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CategoryInUse)
+		}
+
+		if err := uc.categoryRepository.DeleteByIDAndUserID(ctx, tx, category.ID, category.UserID); err != nil {
+			if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CategoryNotFound)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to delete category", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	uc.log.Info("Category deleted successfully", zap.String("category_id", request.ID.String()))
+
+	return nil
+}
+
+// findParentPath locks the parent so it cannot be deleted while a category is put under it, a root category has
+// no parent path.
+func (uc *categoryUseCase) findParentPath(ctx context.Context, tx store.Querier, parentID *uuid.UUID) (string, error) {
+	if parentID == nil {
+		return "", nil
+	}
+
+	parent, err := uc.categoryRepository.FindByID(ctx, tx, *parentID, enum.LockTypeShareEnum)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return "", helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CategoryNotFound)
+		}
+		return "", helper.WrapInternalServerError(uc.log, "failed to find parent category by id", err)
+	}
+	return parent.Path, nil
+}
+
+func categoryPath(parentPath, categorySlug string) string {
+	if parentPath == "" {
+		return categorySlug
+	}
+	return parentPath + "/" + categorySlug
+}
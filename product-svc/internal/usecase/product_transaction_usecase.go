@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"go-saga-pattern/commoner/constant/enum"
@@ -15,7 +16,9 @@ import (
 	"go-saga-pattern/product-svc/internal/repository"
 	"go-saga-pattern/product-svc/internal/repository/store"
 	"log"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -27,33 +30,55 @@ type ProductTransactionUseCase interface {
 	CommitProductTransactionsRequest(ctx context.Context, request *model.CommitProductTransactionsRequest) error
 	ExpireProductTransactions(ctx context.Context, request *model.ExpireProductTransactionsRequest) error
 	SettleProducts(ctx context.Context, request *model.SettleProductTransactionRequest) error
-	updateAndRestoreProductTransactions(ctx context.Context, transactionID uuid.UUID, status enum.ProductTransactionStatusEnum) error
+	ReleaseExpiredReservations(ctx context.Context) error
+	RefundProductTransactions(ctx context.Context, request *model.RefundProductTransactionsRequest) error
+	updateAndRestoreProductTransactions(ctx context.Context, transactionID uuid.UUID, messageID string, status enum.ProductTransactionStatusEnum,
+		event string) error
+}
+
+// reservationSweepBatchSize caps the transactions released by one sweep, the next run picks up the rest.
+const reservationSweepBatchSize = 100
+
+// productLineKey identifies a line of a transaction, VariantID is empty for a product without variants.
+type productLineKey struct {
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 }
 
 type productTransactionUseCase struct {
-	productRepository      repository.ProductRepository
-	productTransactionRepo repository.ProductTransactionRepository
-	databaseStore          store.DatabaseStore
-	validator              helper.CustomValidator
-	log                    logs.Log
+	productRepository        repository.ProductRepository
+	productVariantRepository repository.ProductVariantRepository
+	inventoryRepository      repository.InventoryRepository
+	productTransactionRepo   repository.ProductTransactionRepository
+	processedEventRepo       repository.ProcessedEventRepository
+	databaseStore            store.DatabaseStore
+	validator                helper.CustomValidator
+	reservationTTL           time.Duration
+	log                      logs.Log
 }
 
-func NewProductTransactionUseCase(productRepository repository.ProductRepository,
-	productTransactionRepo repository.ProductTransactionRepository, databaseStore store.DatabaseStore,
-	validator helper.CustomValidator, log logs.Log,
+func NewProductTransactionUseCase(productRepository repository.ProductRepository, productVariantRepository repository.ProductVariantRepository,
+	inventoryRepository repository.InventoryRepository, productTransactionRepo repository.ProductTransactionRepository,
+	processedEventRepo repository.ProcessedEventRepository, databaseStore store.DatabaseStore, validator helper.CustomValidator,
+	reservationTTL time.Duration, log logs.Log,
 ) ProductTransactionUseCase {
 	return &productTransactionUseCase{
-		productRepository:      productRepository,
-		productTransactionRepo: productTransactionRepo,
-		databaseStore:          databaseStore,
-		validator:              validator,
-		log:                    log,
+		productRepository:        productRepository,
+		productVariantRepository: productVariantRepository,
+		inventoryRepository:      inventoryRepository,
+		productTransactionRepo:   productTransactionRepo,
+		processedEventRepo:       processedEventRepo,
+		databaseStore:            databaseStore,
+		validator:                validator,
+		reservationTTL:           reservationTTL,
+		log:                      log,
 	}
 }
 
 // TODO : Cannot deleted product when product transaction exists and status != canceled or expired
 func (uc *productTransactionUseCase) CancelProductTransactions(ctx context.Context, request *model.CancelProductTransactionsRequest) error {
-	if err := uc.updateAndRestoreProductTransactions(ctx, request.TransactionID, enum.ProductTransactionStatusCanceled); err != nil {
+	if err := uc.updateAndRestoreProductTransactions(ctx, request.TransactionID, request.MessageID,
+		enum.ProductTransactionStatusCanceled, enum.TransactionEventCancelled); err != nil {
 		return err
 	}
 
@@ -62,7 +87,8 @@ func (uc *productTransactionUseCase) CancelProductTransactions(ctx context.Conte
 
 // TODO : Cannot deleted product when product transaction exists and status != canceled or expired
 func (uc *productTransactionUseCase) ExpireProductTransactions(ctx context.Context, request *model.ExpireProductTransactionsRequest) error {
-	if err := uc.updateAndRestoreProductTransactions(ctx, request.TransactionID, enum.ProductTransactionStatusExpired); err != nil {
+	if err := uc.updateAndRestoreProductTransactions(ctx, request.TransactionID, request.MessageID,
+		enum.ProductTransactionStatusExpired, enum.TransactionEventExpired); err != nil {
 		return err
 	}
 
@@ -71,9 +97,14 @@ func (uc *productTransactionUseCase) ExpireProductTransactions(ctx context.Conte
 
 // TODO : Cannot deleted product when product transaction exists and status != canceled or expired
 func (uc *productTransactionUseCase) updateAndRestoreProductTransactions(ctx context.Context, transactionID uuid.UUID,
-	status enum.ProductTransactionStatusEnum) error {
+	messageID string, status enum.ProductTransactionStatusEnum, event string) error {
 	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
-		productTransactions, err := uc.productTransactionRepo.FindManyByTrxID(ctx, uc.databaseStore, transactionID, true)
+		processed, err := uc.markEventProcessed(ctx, tx, transactionID, messageID, event)
+		if err != nil || processed {
+			return err
+		}
+
+		productTransactions, err := uc.productTransactionRepo.FindManyByTrxID(ctx, tx, transactionID, true)
 		if err != nil {
 			return helper.WrapInternalServerError(uc.log, "failed to find products by user id", err)
 		}
@@ -82,39 +113,213 @@ func (uc *productTransactionUseCase) updateAndRestoreProductTransactions(ctx con
 			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductTranscationNotFound)
 		}
 
-		productIDs := make([]uuid.UUID, 0, len(productTransactions))
-		for _, productReq := range productTransactions {
-			productIDs = append(productIDs, productReq.ProductID)
+		// a cancel after an expire (or the other way around) must not give the stock back twice
+		for _, productTransaction := range productTransactions {
+			if productTransaction.Status == enum.ProductTransactionStatusCanceled ||
+				productTransaction.Status == enum.ProductTransactionStatusExpired ||
+				productTransaction.Status == enum.ProductTransactionStatusRefunded {
+				uc.log.Warn("product transactions already restored, skipping",
+					zap.String("transaction_id", transactionID.String()), zap.String("event", event))
+				return nil
+			}
+		}
+
+		return uc.restoreProductTransactions(ctx, tx, transactionID, productTransactions, status)
+	}); err != nil {
+		uc.log.Error("failed to check products and reserve", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// restoreProductTransactions gives the stock of the product transactions back and moves them to the given status.
+func (uc *productTransactionUseCase) restoreProductTransactions(ctx context.Context, tx store.Transaction, transactionID uuid.UUID,
+	productTransactions []*entity.ProductTransaction, status enum.ProductTransactionStatusEnum) error {
+	productIDs := productTransactionProductIDs(productTransactions)
+
+	products, err := uc.productRepository.FindManyByIDs(ctx, tx, productIDs, enum.LockTypeUpdateEnum)
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to find products by user id", err)
+	}
+
+	// CASE WHEN PRODUCT ALREADY DELETED (HAVE TO HANDLE PRODUCT CONSISTENCY WELL)
+	if len(productIDs) != len(products) {
+		return helper.NewUseCaseError(errorcode.ErrInvalidArgument, "product product")
+	}
+
+	// NO NEED TO VALIDATE QUANTITY OR PRICE
+	// a held line is released, a settled one was already sold and goes back on the shelf
+	for _, productTransaction := range productTransactions {
+		quantity := productTransaction.Quantity - productTransaction.RefundedQuantity
+		if quantity <= 0 {
+			continue
+		}
+
+		move := uc.inventoryRepository.Release
+		if productTransaction.Status == enum.ProductTransactionStatusSettled {
+			move = uc.inventoryRepository.Restock
 		}
 
-		products, err := uc.productRepository.FindManyByIDs(ctx, uc.databaseStore, productIDs, enum.LockTypeUpdateEnum)
+		if err := move(ctx, tx, productTransaction.ProductID, productTransaction.VariantID, transactionID, quantity); err != nil {
+			if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductNotFoundOrAlreadyDeleted)
+			}
+			return err
+		}
+	}
+
+	err = uc.productTransactionRepo.UpdateStatus(ctx, tx, transactionID, status)
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to update many product transactions", err)
+	}
+	return nil
+}
+
+// ReleaseExpiredReservations expires the reservations that were not committed before their expires_at and gives their
+// stock back, so stock is not held forever when transaction-svc never publishes an event for the transaction.
+// A transaction is released as a whole in its own database transaction, a later cancel or expire event skips it.
+func (uc *productTransactionUseCase) ReleaseExpiredReservations(ctx context.Context) error {
+	transactionIDs, err := uc.productTransactionRepo.FindManyExpiredReservationTrxIDs(ctx, uc.databaseStore, reservationSweepBatchSize)
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to find expired reservations", err)
+	}
+
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+
+	released := 0
+	for _, transactionID := range transactionIDs {
+		if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+			productTransactions, err := uc.productTransactionRepo.FindManyByTrxID(ctx, tx, transactionID, true)
+			if err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to find product transactions by transaction id", err)
+			}
+
+			// the transaction may have been committed or canceled since it was picked up
+			now := time.Now()
+			for _, productTransaction := range productTransactions {
+				if productTransaction.Status != enum.ProductTransactionStatusReserved ||
+					!productTransaction.ExpiresAt.Valid || productTransaction.ExpiresAt.Time.After(now) {
+					return nil
+				}
+			}
+
+			if err := uc.restoreProductTransactions(ctx, tx, transactionID, productTransactions,
+				enum.ProductTransactionStatusExpired); err != nil {
+				return err
+			}
+
+			released++
+			return nil
+		}); err != nil {
+			uc.log.Error("failed to release expired reservation", zap.String("transaction_id", transactionID.String()), zap.Error(err))
+			continue
+		}
+	}
+
+	uc.log.Info("released expired reservations", zap.Int("found", len(transactionIDs)), zap.Int("released", released))
+	return nil
+}
+
+// RefundProductTransactions restocks the refunded items of a transaction. Canceled and expired lines already gave
+// their stock back, a late settlement is refunded after it expired, so only lines still holding stock are restocked.
+func (uc *productTransactionUseCase) RefundProductTransactions(ctx context.Context, request *model.RefundProductTransactionsRequest) error {
+	if request.RefundID == "" || len(request.Items) == 0 {
+		return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RefundItemsRequired)
+	}
+
+	// a transaction can be refunded several times, every refund is its own event in the inbox
+	event := fmt.Sprintf("%s:%s", enum.TransactionEventRefunded, request.RefundID)
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		processed, err := uc.markEventProcessed(ctx, tx, request.TransactionID, request.MessageID, event)
+		if err != nil || processed {
+			return err
+		}
+
+		productTransactions, err := uc.productTransactionRepo.FindManyByTrxID(ctx, tx, request.TransactionID, true)
 		if err != nil {
-			return helper.WrapInternalServerError(uc.log, "failed to find products by user id", err)
+			return helper.WrapInternalServerError(uc.log, "failed to find product transactions by transaction id", err)
+		}
+
+		productTransactionMap := make(map[productLineKey]*entity.ProductTransaction, len(productTransactions))
+		for _, productTransaction := range productTransactions {
+			productTransactionMap[productLineKey{productTransaction.ProductID, productTransaction.VariantID}] = productTransaction
+		}
+
+		restockQuantities := make(map[productLineKey]int, len(request.Items))
+		lines := make([]productLineKey, 0, len(request.Items))
+		productIDs := make([]uuid.UUID, 0, len(request.Items))
+		for _, item := range request.Items {
+			line := productLineKey{item.ProductID, item.VariantID}
+			productTransaction, ok := productTransactionMap[line]
+			if !ok {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductTranscationNotFound)
+			}
+
+			if productTransaction.Status == enum.ProductTransactionStatusCanceled ||
+				productTransaction.Status == enum.ProductTransactionStatusExpired {
+				uc.log.Info("product transaction already restored, skipping refund restock",
+					zap.String("transaction_id", request.TransactionID.String()), zap.String("product_id", item.ProductID.String()),
+					zap.String("status", string(productTransaction.Status)))
+				continue
+			}
+
+			quantity := min(item.Quantity, productTransaction.Quantity-productTransaction.RefundedQuantity)
+			if quantity <= 0 {
+				uc.log.Warn("product transaction already fully refunded, skipping refund restock",
+					zap.String("transaction_id", request.TransactionID.String()), zap.String("product_id", item.ProductID.String()))
+				continue
+			}
+
+			if _, ok := restockQuantities[line]; !ok {
+				lines = append(lines, line)
+				if !slices.Contains(productIDs, item.ProductID) {
+					productIDs = append(productIDs, item.ProductID)
+				}
+			}
+			restockQuantities[line] = quantity
+		}
+
+		if len(lines) == 0 {
+			return nil
+		}
+
+		products, err := uc.productRepository.FindManyByIDs(ctx, tx, productIDs, enum.LockTypeUpdateEnum)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find products by ids", err)
 		}
 
 		// CASE WHEN PRODUCT ALREADY DELETED (HAVE TO HANDLE PRODUCT CONSISTENCY WELL)
 		if len(productIDs) != len(products) {
-			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, "product product")
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductNotFoundOrAlreadyDeleted)
 		}
 
-		// NO NEED TO VALIDATE QUANTITY OR PRICE
-		for _, productTransaction := range productTransactions {
-			if err := uc.productRepository.RestoreQuantity(ctx, uc.databaseStore, productTransaction.ProductID,
-				productTransaction.Quantity); err != nil {
+		for _, line := range lines {
+			// a line refunded before its settlement was processed still holds its stock as reserved
+			move := uc.inventoryRepository.Restock
+			if productTransactionMap[line].Status != enum.ProductTransactionStatusSettled &&
+				productTransactionMap[line].Status != enum.ProductTransactionStatusRefunded {
+				move = uc.inventoryRepository.Release
+			}
+
+			if err := move(ctx, tx, line.ProductID, line.VariantID, request.TransactionID, restockQuantities[line]); err != nil {
 				if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
 					return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductNotFoundOrAlreadyDeleted)
 				}
 				return err
 			}
-		}
 
-		err = uc.productTransactionRepo.UpdateStatus(ctx, uc.databaseStore, transactionID, status)
-		if err != nil {
-			return helper.WrapInternalServerError(uc.log, "failed to update many product transactions", err)
+			if err := uc.productTransactionRepo.AddRefundedQuantity(ctx, tx, request.TransactionID, line.ProductID, line.VariantID,
+				restockQuantities[line]); err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to update refunded quantity", err)
+			}
 		}
+
 		return nil
 	}); err != nil {
-		uc.log.Error("failed to check products and reserve", zap.Error(err))
+		uc.log.Error("failed to refund product transactions", zap.Error(err))
 		return err
 	}
 
@@ -134,8 +339,10 @@ func (uc *productTransactionUseCase) CheckProductsAndReserve(ctx context.Context
 	log.Printf("[CheckProductsAndReserve] Processing %d products", len(request.Products))
 
 	// Initialize data structures
-	productReqMap := make(map[uuid.UUID]*model.CheckProductQuantity)
+	productReqs := make([]*model.CheckProductQuantity, 0, len(request.Products))
+	lines := make(map[productLineKey]bool, len(request.Products))
 	var productIDs []uuid.UUID
+	var variantIDs []uuid.UUID
 	log.Println("[CheckProductsAndReserve] Initialized empty map and slice")
 
 	for i, productReq := range request.Products {
@@ -154,8 +361,20 @@ func (uc *productTransactionUseCase) CheckProductsAndReserve(ctx context.Context
 			return nil, fmt.Errorf("invalid product ID at position %d", i)
 		}
 
-		productReqMap[productReq.ProductID] = productReq
-		productIDs = append(productIDs, productReq.ProductID)
+		// a line is a product or one of its variants, the same line twice would be reserved twice
+		line := productLineKey{productReq.ProductID, productReq.VariantID}
+		if lines[line] {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.DuplicateProductLine)
+		}
+		lines[line] = true
+
+		productReqs = append(productReqs, productReq)
+		if !slices.Contains(productIDs, productReq.ProductID) {
+			productIDs = append(productIDs, productReq.ProductID)
+		}
+		if productReq.VariantID.Valid {
+			variantIDs = append(variantIDs, productReq.VariantID.UUID)
+		}
 
 		// Log successful processing
 		log.Printf("[CheckProductsAndReserve] Successfully processed product %s at position %d",
@@ -164,54 +383,95 @@ func (uc *productTransactionUseCase) CheckProductsAndReserve(ctx context.Context
 
 	// Log summary before continuing
 	log.Printf("[CheckProductsAndReserve] Processed %d valid products out of %d",
-		len(productReqMap), len(request.Products))
+		len(productReqs), len(request.Products))
 	log.Printf("[CheckProductsAndReserve] Product IDs collected: %v", productIDs)
 
-	var products []*entity.Product
+	// products and variants hold the product and the variant of every line, in the order of the request
+	products := make([]*entity.Product, 0, len(productReqs))
+	variants := make([]*entity.ProductVariant, 0, len(productReqs))
 
 	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
-		var err error
-		products, err = uc.productRepository.FindManyByIDs(ctx, tx, productIDs, enum.LockTypeUpdateEnum)
+		foundProducts, err := uc.productRepository.FindManyByIDs(ctx, tx, productIDs, enum.LockTypeUpdateEnum)
 		if err != nil {
 			return helper.WrapInternalServerError(uc.log, "failed to find products by user id", err)
 		}
 
-		if len(productIDs) != len(products) {
+		if len(productIDs) != len(foundProducts) {
 			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductNotFound)
 		}
 
-		productTransactions := make([]*entity.ProductTransaction, 0, len(products))
-		for _, product := range products {
-			productReq := productReqMap[product.ID]
-			if product.Quantity == 0 {
-				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductOutOfStock)
+		productMap := make(map[uuid.UUID]*entity.Product, len(foundProducts))
+		for _, product := range foundProducts {
+			productMap[product.ID] = product
+		}
+
+		variantMap := make(map[uuid.UUID]*entity.ProductVariant, len(variantIDs))
+		if len(variantIDs) > 0 {
+			foundVariants, err := uc.productVariantRepository.FindManyByIDs(ctx, tx, variantIDs, enum.LockTypeUpdateEnum)
+			if err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to find product variants by ids", err)
 			}
 
-			if productReq.Quantity > product.Quantity {
-				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RequestedProductMoreThanAvailable)
+			for _, variant := range foundVariants {
+				variantMap[variant.ID] = variant
 			}
+		}
 
-			if productReq.Price != product.Price {
-				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PriceChanged)
+		// the reservation holds the stock until transaction.committed, the sweeper releases it after expiresAt
+		expiresAt := time.Now().Add(uc.reservationTTL)
+		productTransactions := make([]*entity.ProductTransaction, 0, len(productReqs))
+		for _, productReq := range productReqs {
+			product := productMap[productReq.ProductID]
+
+			// a variant is stocked and priced on its own, the product only holds the stock of all its variants
+			var variant *entity.ProductVariant
+			available, price := product.Quantity, product.Price
+			if productReq.VariantID.Valid {
+				variant = variantMap[productReq.VariantID.UUID]
+				if variant == nil || variant.ProductID != product.ID {
+					return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductVariantNotFound)
+				}
+				available, price = variant.Quantity, variant.Price
+			} else if len(product.Options) > 0 {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductVariantRequired)
 			}
 
-			if err := uc.productRepository.ReduceQuantity(ctx, tx, productReq.ProductID, productReq.Quantity); err != nil {
+			if available == 0 {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductOutOfStock)
+			}
+
+			if productReq.Quantity > available {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RequestedProductMoreThanAvailable)
+			}
+
+			if err := uc.inventoryRepository.Reserve(ctx, tx, productReq.ProductID, productReq.VariantID, request.TransactionID,
+				productReq.Quantity); err != nil {
 				if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
-					return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductNotFoundOrAlreadyDeleted)
+					return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RequestedProductMoreThanAvailable)
 				}
-				return helper.WrapInternalServerError(uc.log, "failed to reduce product quantity", err)
+				return helper.WrapInternalServerError(uc.log, "failed to reserve product stock", err)
+			}
+
+			// The order is priced with the stored price, transaction-svc takes its totals from the response
+			totalPrice, err := price.Mul(int64(productReq.Quantity))
+			if err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to price product transaction", err)
 			}
 
-			//TODO finalize
 			productTransaction := &entity.ProductTransaction{
 				TransactionID: request.TransactionID,
 				ProductID:     product.ID,
-				Status:        enum.ProductTransactionStatusComitted,
+				VariantID:     productReq.VariantID,
+				Status:        enum.ProductTransactionStatusReserved,
 				Quantity:      productReq.Quantity,
-				TotalPrice:    product.Price,
+				TotalPrice:    totalPrice,
+				Currency:      totalPrice.Currency,
+				ExpiresAt:     sql.NullTime{Time: expiresAt, Valid: true},
 			}
 
 			productTransactions = append(productTransactions, productTransaction)
+			products = append(products, product)
+			variants = append(variants, variant)
 		}
 
 		productTransactions, err = uc.productTransactionRepo.InsertMany(ctx, tx, productTransactions)
@@ -224,12 +484,13 @@ func (uc *productTransactionUseCase) CheckProductsAndReserve(ctx context.Context
 		return nil, err
 	}
 
-	return converter.ProductsToCheckQuantityResponse(request.TransactionID, products), nil
+	return converter.ProductsToCheckQuantityResponse(request.TransactionID, products, variants), nil
 }
 
 // TODO : Cannot deleted product when product transaction exists and status != canceled or expired
 func (uc *productTransactionUseCase) CommitProductTransactionsRequest(ctx context.Context, request *model.CommitProductTransactionsRequest) error {
-	if err := uc.updateProductTransactionsStatus(ctx, request.TransactionID, enum.ProductTransactionStatusComitted); err != nil {
+	if err := uc.updateProductTransactionsStatus(ctx, request.TransactionID, request.MessageID,
+		enum.ProductTransactionStatusComitted, enum.TransactionEventCommited); err != nil {
 		return err
 	}
 
@@ -238,7 +499,8 @@ func (uc *productTransactionUseCase) CommitProductTransactionsRequest(ctx contex
 
 // TODO : Cannot deleted product when product transaction exists and status != canceled or expired
 func (uc *productTransactionUseCase) SettleProducts(ctx context.Context, request *model.SettleProductTransactionRequest) error {
-	if err := uc.updateProductTransactionsStatus(ctx, request.TransactionID, enum.ProductTransactionStatusSettled); err != nil {
+	if err := uc.updateProductTransactionsStatus(ctx, request.TransactionID, request.MessageID,
+		enum.ProductTransactionStatusSettled, enum.TransactionEventSettled); err != nil {
 		return err
 	}
 
@@ -247,9 +509,14 @@ func (uc *productTransactionUseCase) SettleProducts(ctx context.Context, request
 
 // TODO : Cannot deleted product when product transaction exists and status != canceled or expired
 func (uc *productTransactionUseCase) updateProductTransactionsStatus(ctx context.Context, transactionID uuid.UUID,
-	status enum.ProductTransactionStatusEnum) error {
+	messageID string, status enum.ProductTransactionStatusEnum, event string) error {
 	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
-		productTransactions, err := uc.productTransactionRepo.FindManyByTrxID(ctx, uc.databaseStore, transactionID, true)
+		processed, err := uc.markEventProcessed(ctx, tx, transactionID, messageID, event)
+		if err != nil || processed {
+			return err
+		}
+
+		productTransactions, err := uc.productTransactionRepo.FindManyByTrxID(ctx, tx, transactionID, true)
 		if err != nil {
 			return helper.WrapInternalServerError(uc.log, "failed to find products by user id", err)
 		}
@@ -258,12 +525,21 @@ func (uc *productTransactionUseCase) updateProductTransactionsStatus(ctx context
 			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductTranscationNotFound)
 		}
 
-		productIDs := make([]uuid.UUID, 0, len(productTransactions))
-		for _, productReq := range productTransactions {
-			productIDs = append(productIDs, productReq.ProductID)
+		for _, productTransaction := range productTransactions {
+			if status == enum.ProductTransactionStatusComitted && productTransaction.Status == enum.ProductTransactionStatusExpired {
+				uc.log.Warn("reservation already released by the sweeper, transaction committed without holding stock",
+					zap.String("transaction_id", transactionID.String()), zap.String("product_id", productTransaction.ProductID.String()))
+			}
+		}
+
+		productIDs := productTransactionProductIDs(productTransactions)
+
+		lockType := enum.LockTypeShareEnum
+		if status == enum.ProductTransactionStatusSettled {
+			lockType = enum.LockTypeUpdateEnum
 		}
 
-		products, err := uc.productRepository.FindManyByIDs(ctx, uc.databaseStore, productIDs, enum.LockTypeShareEnum)
+		products, err := uc.productRepository.FindManyByIDs(ctx, tx, productIDs, lockType)
 		if err != nil {
 			return helper.WrapInternalServerError(uc.log, "failed to find products by user id", err)
 		}
@@ -273,7 +549,27 @@ func (uc *productTransactionUseCase) updateProductTransactionsStatus(ctx context
 			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductNotFoundOrAlreadyDeleted)
 		}
 
-		err = uc.productTransactionRepo.UpdateStatus(ctx, uc.databaseStore, transactionID, status)
+		// the settlement sells the stock the lines still hold, canceled and expired lines already released theirs
+		if status == enum.ProductTransactionStatusSettled {
+			for _, productTransaction := range productTransactions {
+				if productTransaction.Status != enum.ProductTransactionStatusReserved &&
+					productTransaction.Status != enum.ProductTransactionStatusComitted {
+					continue
+				}
+
+				quantity := productTransaction.Quantity - productTransaction.RefundedQuantity
+				if quantity <= 0 {
+					continue
+				}
+
+				if err := uc.inventoryRepository.Commit(ctx, tx, productTransaction.ProductID, productTransaction.VariantID,
+					transactionID, quantity); err != nil {
+					return helper.WrapInternalServerError(uc.log, "failed to commit product stock", err)
+				}
+			}
+		}
+
+		err = uc.productTransactionRepo.UpdateStatus(ctx, tx, transactionID, status)
 		if err != nil {
 			uc.log.Error("failed to update many product transactions", zap.Error(err))
 			return helper.WrapInternalServerError(uc.log, "failed to insert many product transactions", err)
@@ -286,3 +582,36 @@ func (uc *productTransactionUseCase) updateProductTransactionsStatus(ctx context
 
 	return nil
 }
+
+// productTransactionProductIDs returns the products of the lines once, several variants of a product are separate lines.
+func productTransactionProductIDs(productTransactions []*entity.ProductTransaction) []uuid.UUID {
+	productIDs := make([]uuid.UUID, 0, len(productTransactions))
+	for _, productTransaction := range productTransactions {
+		if !slices.Contains(productIDs, productTransaction.ProductID) {
+			productIDs = append(productIDs, productTransaction.ProductID)
+		}
+	}
+	return productIDs
+}
+
+// markEventProcessed records the event in the processed_events inbox inside the caller's transaction, so the row only
+// persists together with the stock change. It reports true when the event was already processed and must be skipped.
+func (uc *productTransactionUseCase) markEventProcessed(ctx context.Context, tx store.Transaction, transactionID uuid.UUID,
+	messageID string, event string) (bool, error) {
+	inserted, err := uc.processedEventRepo.Insert(ctx, tx, &entity.ProcessedEvent{
+		TransactionID: transactionID,
+		Event:         event,
+		MessageID:     sql.NullString{String: messageID, Valid: messageID != ""},
+	})
+	if err != nil {
+		return false, helper.WrapInternalServerError(uc.log, "failed to insert processed event", err)
+	}
+
+	if !inserted {
+		uc.log.Info("duplicate transaction event, skipping", zap.String("transaction_id", transactionID.String()),
+			zap.String("event", event), zap.String("message_id", messageID))
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,559 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/product-svc/internal/entity"
+	"go-saga-pattern/product-svc/internal/model"
+	"go-saga-pattern/product-svc/internal/repository"
+	"go-saga-pattern/product-svc/internal/repository/store"
+	"go-saga-pattern/product-svc/internal/usecase"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var (
+	coffeeID = uuid.MustParse("00000000-0000-0000-0000-00000000c0ff")
+	teaID    = uuid.MustParse("00000000-0000-0000-0000-0000000007ea")
+)
+
+// initialOnHand is the on hand stock of every product before the lines of a test took their share of it.
+const initialOnHand = 10
+
+type productTransactionFixture struct {
+	useCase            usecase.ProductTransactionUseCase
+	productTransaction *fakeProductTransactionRepository
+	inventory          *fakeInventoryRepository
+}
+
+// newProductTransactionFixture stores the lines and the stock they hold, a reserved or committed line holds its
+// quantity as reserved and a settled one as sold.
+func newProductTransactionFixture(lines ...*entity.ProductTransaction) *productTransactionFixture {
+	productTransactionRepo := &fakeProductTransactionRepository{lines: lines}
+	inventoryRepo := &fakeInventoryRepository{stock: make(map[uuid.UUID]*stock)}
+	productRepo := &fakeProductRepository{products: make(map[uuid.UUID]*entity.Product)}
+
+	for _, line := range lines {
+		productRepo.products[line.ProductID] = &entity.Product{ID: line.ProductID, Price: money.New(1000000, "IDR")}
+
+		productStock := inventoryRepo.stockOf(line.ProductID)
+		held := line.Quantity - line.RefundedQuantity
+		switch line.Status {
+		case enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusComitted:
+			productStock.reserved += held
+		case enum.ProductTransactionStatusSettled:
+			productStock.onHand -= held
+			productStock.sold += held
+		}
+	}
+
+	return &productTransactionFixture{
+		useCase: usecase.NewProductTransactionUseCase(productRepo, nil, inventoryRepo, productTransactionRepo,
+			&fakeProcessedEventRepository{}, &fakeDatabaseStore{}, nil, 15*time.Minute, zap.NewNop()),
+		productTransaction: productTransactionRepo,
+		inventory:          inventoryRepo,
+	}
+}
+
+func newLine(transactionID, productID uuid.UUID, status enum.ProductTransactionStatusEnum, quantity int) *entity.ProductTransaction {
+	return &entity.ProductTransaction{
+		TransactionID: transactionID,
+		ProductID:     productID,
+		Status:        status,
+		Quantity:      quantity,
+	}
+}
+
+func TestProductTransactionUseCase_SkipsARedeliveredEvent(t *testing.T) {
+	ctx := context.Background()
+	transactionID := uuid.New()
+
+	tests := []struct {
+		name             string
+		status           enum.ProductTransactionStatusEnum
+		deliver          func(uc usecase.ProductTransactionUseCase, messageID string) error
+		redeliveredMsgID string
+		wantMoves        []string
+		wantStatus       enum.ProductTransactionStatusEnum
+	}{
+		{
+			name:   "transaction.canceled",
+			status: enum.ProductTransactionStatusReserved,
+			deliver: func(uc usecase.ProductTransactionUseCase, messageID string) error {
+				return uc.CancelProductTransactions(ctx, &model.CancelProductTransactionsRequest{TransactionID: transactionID, MessageID: messageID})
+			},
+			wantMoves:  []string{"RELEASE 2"},
+			wantStatus: enum.ProductTransactionStatusCanceled,
+		},
+		{
+			name:   "transaction.expired",
+			status: enum.ProductTransactionStatusReserved,
+			deliver: func(uc usecase.ProductTransactionUseCase, messageID string) error {
+				return uc.ExpireProductTransactions(ctx, &model.ExpireProductTransactionsRequest{TransactionID: transactionID, MessageID: messageID})
+			},
+			wantMoves:  []string{"RELEASE 2"},
+			wantStatus: enum.ProductTransactionStatusExpired,
+		},
+		{
+			name:   "transaction.committed",
+			status: enum.ProductTransactionStatusReserved,
+			deliver: func(uc usecase.ProductTransactionUseCase, messageID string) error {
+				return uc.CommitProductTransactionsRequest(ctx, &model.CommitProductTransactionsRequest{TransactionID: transactionID, MessageID: messageID})
+			},
+			wantStatus: enum.ProductTransactionStatusComitted,
+		},
+		{
+			name:   "transaction.settled",
+			status: enum.ProductTransactionStatusComitted,
+			deliver: func(uc usecase.ProductTransactionUseCase, messageID string) error {
+				return uc.SettleProducts(ctx, &model.SettleProductTransactionRequest{TransactionID: transactionID, MessageID: messageID})
+			},
+			wantMoves:  []string{"COMMIT 2"},
+			wantStatus: enum.ProductTransactionStatusSettled,
+		},
+		{
+			name:   "transaction.refunded",
+			status: enum.ProductTransactionStatusSettled,
+			deliver: func(uc usecase.ProductTransactionUseCase, messageID string) error {
+				return uc.RefundProductTransactions(ctx, &model.RefundProductTransactionsRequest{
+					TransactionID: transactionID,
+					RefundID:      "refund-1",
+					MessageID:     messageID,
+					Items:         []*model.RefundProductTransactionItem{{ProductID: coffeeID, Quantity: 1}},
+				})
+			},
+			wantMoves:  []string{"RESTOCK 1"},
+			wantStatus: enum.ProductTransactionStatusSettled,
+		},
+		{
+			name:   "the same event published again under another message id",
+			status: enum.ProductTransactionStatusReserved,
+			deliver: func(uc usecase.ProductTransactionUseCase, messageID string) error {
+				return uc.CancelProductTransactions(ctx, &model.CancelProductTransactionsRequest{TransactionID: transactionID, MessageID: messageID})
+			},
+			redeliveredMsgID: "msg-2",
+			wantMoves:        []string{"RELEASE 2"},
+			wantStatus:       enum.ProductTransactionStatusCanceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newProductTransactionFixture(newLine(transactionID, coffeeID, tt.status, 2))
+
+			require.NoError(t, tt.deliver(fixture.useCase, "msg-1"))
+			statusUpdates := fixture.productTransaction.statusUpdates
+
+			redeliveredMsgID := tt.redeliveredMsgID
+			if redeliveredMsgID == "" {
+				redeliveredMsgID = "msg-1"
+			}
+			assert.NoError(t, tt.deliver(fixture.useCase, redeliveredMsgID), "the redelivery is acknowledged")
+
+			assert.Equal(t, tt.wantMoves, fixture.inventory.moves, "the stock moved once")
+			assert.Equal(t, statusUpdates, fixture.productTransaction.statusUpdates, "the redelivery changed nothing")
+			assert.Equal(t, tt.wantStatus, fixture.productTransaction.lines[0].Status)
+		})
+	}
+}
+
+func TestProductTransactionUseCase_ReleaseExpiredReservations(t *testing.T) {
+	expired := sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	notExpired := sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+	withExpiry := func(line *entity.ProductTransaction, expiresAt sql.NullTime) *entity.ProductTransaction {
+		line.ExpiresAt = expiresAt
+		return line
+	}
+
+	transactionID := uuid.New()
+
+	tests := []struct {
+		name         string
+		lines        []*entity.ProductTransaction
+		wantMoves    []string
+		wantStatuses []enum.ProductTransactionStatusEnum
+		wantReserved int
+	}{
+		{
+			name:         "an expired reservation gives its stock back",
+			lines:        []*entity.ProductTransaction{withExpiry(newLine(transactionID, coffeeID, enum.ProductTransactionStatusReserved, 2), expired)},
+			wantMoves:    []string{"RELEASE 2"},
+			wantStatuses: []enum.ProductTransactionStatusEnum{enum.ProductTransactionStatusExpired},
+			wantReserved: 0,
+		},
+		{
+			name:         "a reservation that has not expired is kept",
+			lines:        []*entity.ProductTransaction{withExpiry(newLine(transactionID, coffeeID, enum.ProductTransactionStatusReserved, 2), notExpired)},
+			wantStatuses: []enum.ProductTransactionStatusEnum{enum.ProductTransactionStatusReserved},
+			wantReserved: 2,
+		},
+		{
+			name:         "a committed reservation is kept after it expired",
+			lines:        []*entity.ProductTransaction{withExpiry(newLine(transactionID, coffeeID, enum.ProductTransactionStatusComitted, 2), expired)},
+			wantStatuses: []enum.ProductTransactionStatusEnum{enum.ProductTransactionStatusComitted},
+			wantReserved: 2,
+		},
+		{
+			name: "a transaction committed since it was picked up is kept whole",
+			lines: []*entity.ProductTransaction{
+				withExpiry(newLine(transactionID, coffeeID, enum.ProductTransactionStatusReserved, 2), expired),
+				withExpiry(newLine(transactionID, teaID, enum.ProductTransactionStatusComitted, 1), expired),
+			},
+			wantStatuses: []enum.ProductTransactionStatusEnum{enum.ProductTransactionStatusReserved, enum.ProductTransactionStatusComitted},
+			wantReserved: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newProductTransactionFixture(tt.lines...)
+
+			require.NoError(t, fixture.useCase.ReleaseExpiredReservations(context.Background()))
+
+			assert.Equal(t, tt.wantMoves, fixture.inventory.moves)
+			assert.Equal(t, tt.wantStatuses, fixture.productTransaction.statuses())
+			assert.Equal(t, tt.wantReserved, fixture.inventory.stockOf(coffeeID).reserved)
+		})
+	}
+}
+
+func TestProductTransactionUseCase_SweptReservationIsNotRevived(t *testing.T) {
+	ctx := context.Background()
+	transactionID := uuid.New()
+	line := newLine(transactionID, coffeeID, enum.ProductTransactionStatusReserved, 2)
+	line.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	fixture := newProductTransactionFixture(line)
+
+	require.NoError(t, fixture.useCase.ReleaseExpiredReservations(ctx))
+
+	// the payment settles after the sweeper released the reservation
+	require.NoError(t, fixture.useCase.CommitProductTransactionsRequest(ctx, &model.CommitProductTransactionsRequest{
+		TransactionID: transactionID, MessageID: "msg-committed"}))
+	require.NoError(t, fixture.useCase.SettleProducts(ctx, &model.SettleProductTransactionRequest{
+		TransactionID: transactionID, MessageID: "msg-settled"}))
+
+	assert.Equal(t, []enum.ProductTransactionStatusEnum{enum.ProductTransactionStatusExpired}, fixture.productTransaction.statuses())
+	assert.Equal(t, []string{"RELEASE 2"}, fixture.inventory.moves, "the released stock is not taken again")
+	assert.Equal(t, stock{onHand: initialOnHand}, *fixture.inventory.stockOf(coffeeID))
+}
+
+func TestProductTransactionUseCase_MovesStockThroughTheLedger(t *testing.T) {
+	ctx := context.Background()
+	transactionID := uuid.New()
+	fixture := newProductTransactionFixture(newLine(transactionID, coffeeID, enum.ProductTransactionStatusReserved, 3))
+
+	steps := []struct {
+		name      string
+		apply     func() error
+		wantStock stock
+	}{
+		{
+			name:      "reserved",
+			apply:     func() error { return nil },
+			wantStock: stock{onHand: 10, reserved: 3},
+		},
+		{
+			name: "committed",
+			apply: func() error {
+				return fixture.useCase.CommitProductTransactionsRequest(ctx, &model.CommitProductTransactionsRequest{
+					TransactionID: transactionID, MessageID: "msg-committed"})
+			},
+			wantStock: stock{onHand: 10, reserved: 3},
+		},
+		{
+			name: "settled",
+			apply: func() error {
+				return fixture.useCase.SettleProducts(ctx, &model.SettleProductTransactionRequest{
+					TransactionID: transactionID, MessageID: "msg-settled"})
+			},
+			wantStock: stock{onHand: 7, sold: 3},
+		},
+		{
+			name: "partially refunded",
+			apply: func() error {
+				return fixture.useCase.RefundProductTransactions(ctx, &model.RefundProductTransactionsRequest{
+					TransactionID: transactionID, RefundID: "refund-1", MessageID: "msg-refund-1",
+					Items: []*model.RefundProductTransactionItem{{ProductID: coffeeID, Quantity: 1}}})
+			},
+			wantStock: stock{onHand: 8, sold: 2},
+		},
+		{
+			name: "refunded for more than is left",
+			apply: func() error {
+				return fixture.useCase.RefundProductTransactions(ctx, &model.RefundProductTransactionsRequest{
+					TransactionID: transactionID, RefundID: "refund-2", MessageID: "msg-refund-2",
+					Items: []*model.RefundProductTransactionItem{{ProductID: coffeeID, Quantity: 5}}})
+			},
+			wantStock: stock{onHand: 10},
+		},
+	}
+
+	for _, step := range steps {
+		require.NoError(t, step.apply(), step.name)
+		assert.Equal(t, step.wantStock, *fixture.inventory.stockOf(coffeeID), step.name)
+	}
+
+	assert.Equal(t, []string{"COMMIT 3", "RESTOCK 1", "RESTOCK 2"}, fixture.inventory.moves)
+	assert.Equal(t, []enum.ProductTransactionStatusEnum{enum.ProductTransactionStatusRefunded}, fixture.productTransaction.statuses())
+}
+
+func TestProductTransactionUseCase_RefundRestock(t *testing.T) {
+	transactionID := uuid.New()
+	refundBoth := []*model.RefundProductTransactionItem{{ProductID: coffeeID, Quantity: 1}, {ProductID: teaID, Quantity: 1}}
+
+	tests := []struct {
+		name             string
+		teaStatus        enum.ProductTransactionStatusEnum
+		wantMoves        []string
+		wantTeaStock     stock
+		wantTeaRefunded  int
+		wantCoffeeRefund int
+	}{
+		{
+			name:             "a settled line is put back on the shelf",
+			teaStatus:        enum.ProductTransactionStatusSettled,
+			wantMoves:        []string{"RESTOCK 1", "RESTOCK 1"},
+			wantTeaStock:     stock{onHand: 9, sold: 1},
+			wantTeaRefunded:  1,
+			wantCoffeeRefund: 1,
+		},
+		{
+			name:             "a canceled line already gave its stock back",
+			teaStatus:        enum.ProductTransactionStatusCanceled,
+			wantMoves:        []string{"RESTOCK 1"},
+			wantTeaStock:     stock{onHand: initialOnHand},
+			wantCoffeeRefund: 1,
+		},
+		{
+			name:             "an expired line already gave its stock back",
+			teaStatus:        enum.ProductTransactionStatusExpired,
+			wantMoves:        []string{"RESTOCK 1"},
+			wantTeaStock:     stock{onHand: initialOnHand},
+			wantCoffeeRefund: 1,
+		},
+		{
+			name:             "a line refunded before its settlement was processed releases its reservation",
+			teaStatus:        enum.ProductTransactionStatusComitted,
+			wantMoves:        []string{"RESTOCK 1", "RELEASE 1"},
+			wantTeaStock:     stock{onHand: initialOnHand, reserved: 1},
+			wantTeaRefunded:  1,
+			wantCoffeeRefund: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newProductTransactionFixture(
+				newLine(transactionID, coffeeID, enum.ProductTransactionStatusSettled, 2),
+				newLine(transactionID, teaID, tt.teaStatus, 2),
+			)
+
+			require.NoError(t, fixture.useCase.RefundProductTransactions(context.Background(), &model.RefundProductTransactionsRequest{
+				TransactionID: transactionID, RefundID: "refund-1", MessageID: "msg-refund-1", Items: refundBoth}))
+
+			assert.Equal(t, tt.wantMoves, fixture.inventory.moves)
+			assert.Equal(t, tt.wantTeaStock, *fixture.inventory.stockOf(teaID))
+			assert.Equal(t, tt.wantCoffeeRefund, fixture.productTransaction.lines[0].RefundedQuantity)
+			assert.Equal(t, tt.wantTeaRefunded, fixture.productTransaction.lines[1].RefundedQuantity)
+		})
+	}
+}
+
+type fakeProcessedEventRepository struct {
+	events []*entity.ProcessedEvent
+}
+
+// Insert conflicts on the (transaction_id, event) pair and on the message id, like the unique indexes of processed_events.
+func (r *fakeProcessedEventRepository) Insert(_ context.Context, _ store.Querier, processedEvent *entity.ProcessedEvent) (bool, error) {
+	for _, event := range r.events {
+		if event.TransactionID == processedEvent.TransactionID && event.Event == processedEvent.Event {
+			return false, nil
+		}
+		if event.MessageID.Valid && event.MessageID == processedEvent.MessageID {
+			return false, nil
+		}
+	}
+
+	r.events = append(r.events, processedEvent)
+	return true, nil
+}
+
+type fakeProductTransactionRepository struct {
+	repository.ProductTransactionRepository
+	lines         []*entity.ProductTransaction
+	statusUpdates int
+}
+
+func (r *fakeProductTransactionRepository) statuses() []enum.ProductTransactionStatusEnum {
+	statuses := make([]enum.ProductTransactionStatusEnum, 0, len(r.lines))
+	for _, line := range r.lines {
+		statuses = append(statuses, line.Status)
+	}
+	return statuses
+}
+
+func (r *fakeProductTransactionRepository) FindManyByTrxID(_ context.Context, _ store.Querier, transactionID uuid.UUID,
+	_ bool) ([]*entity.ProductTransaction, error) {
+	lines := make([]*entity.ProductTransaction, 0)
+	for _, line := range r.lines {
+		if line.TransactionID == transactionID {
+			found := *line
+			lines = append(lines, &found)
+		}
+	}
+	return lines, nil
+}
+
+// UpdateStatus only moves the lines the transition table allows, like the status = ANY guard of the query.
+func (r *fakeProductTransactionRepository) UpdateStatus(_ context.Context, _ store.Querier, transactionID uuid.UUID,
+	status enum.ProductTransactionStatusEnum) error {
+	if _, err := entity.ProductTransactionSources(status); err != nil {
+		return err
+	}
+
+	r.statusUpdates++
+	for _, line := range r.lines {
+		if line.TransactionID == transactionID && line.CanMoveTo(status) {
+			line.Status = status
+		}
+	}
+	return nil
+}
+
+func (r *fakeProductTransactionRepository) AddRefundedQuantity(_ context.Context, _ store.Querier, transactionID, productID uuid.UUID,
+	variantID uuid.NullUUID, quantity int) error {
+	for _, line := range r.lines {
+		if line.TransactionID != transactionID || line.ProductID != productID || line.VariantID != variantID {
+			continue
+		}
+
+		if line.RefundedQuantity+quantity > line.Quantity {
+			return errors.New(message.InternalNoRowsAffected)
+		}
+
+		line.RefundedQuantity += quantity
+		if line.RefundedQuantity == line.Quantity {
+			line.Status = enum.ProductTransactionStatusRefunded
+		}
+		return nil
+	}
+	return errors.New(message.InternalNoRowsAffected)
+}
+
+func (r *fakeProductTransactionRepository) FindManyExpiredReservationTrxIDs(_ context.Context, _ store.Querier, limit int) ([]uuid.UUID, error) {
+	transactionIDs := make([]uuid.UUID, 0)
+	for _, line := range r.lines {
+		if line.Status != enum.ProductTransactionStatusReserved || !line.ExpiresAt.Valid || line.ExpiresAt.Time.After(time.Now()) {
+			continue
+		}
+
+		found := false
+		for _, transactionID := range transactionIDs {
+			found = found || transactionID == line.TransactionID
+		}
+		if !found && len(transactionIDs) < limit {
+			transactionIDs = append(transactionIDs, line.TransactionID)
+		}
+	}
+	return transactionIDs, nil
+}
+
+type stock struct {
+	onHand   int
+	reserved int
+	sold     int
+}
+
+// fakeInventoryRepository keeps the stock of every product and refuses a movement the stock cannot cover, moves
+// records the movements that took place as "<TYPE> <quantity>".
+type fakeInventoryRepository struct {
+	repository.InventoryRepository
+	stock map[uuid.UUID]*stock
+	moves []string
+}
+
+func (r *fakeInventoryRepository) stockOf(productID uuid.UUID) *stock {
+	if _, ok := r.stock[productID]; !ok {
+		r.stock[productID] = &stock{onHand: initialOnHand}
+	}
+	return r.stock[productID]
+}
+
+func (r *fakeInventoryRepository) move(movementType enum.InventoryMovementType, productID uuid.UUID, quantity int, covered bool,
+	apply func(productStock *stock)) error {
+	if !covered {
+		return errors.New(message.InternalNoRowsAffected)
+	}
+
+	apply(r.stockOf(productID))
+	r.moves = append(r.moves, string(movementType)+" "+strconv.Itoa(quantity))
+	return nil
+}
+
+func (r *fakeInventoryRepository) Release(_ context.Context, _ store.Querier, productID uuid.UUID, _ uuid.NullUUID, _ uuid.UUID, quantity int) error {
+	return r.move(enum.InventoryMovementRelease, productID, quantity, r.stockOf(productID).reserved >= quantity,
+		func(productStock *stock) { productStock.reserved -= quantity })
+}
+
+func (r *fakeInventoryRepository) Commit(_ context.Context, _ store.Querier, productID uuid.UUID, _ uuid.NullUUID, _ uuid.UUID, quantity int) error {
+	return r.move(enum.InventoryMovementCommit, productID, quantity, r.stockOf(productID).reserved >= quantity,
+		func(productStock *stock) {
+			productStock.onHand -= quantity
+			productStock.reserved -= quantity
+			productStock.sold += quantity
+		})
+}
+
+func (r *fakeInventoryRepository) Restock(_ context.Context, _ store.Querier, productID uuid.UUID, _ uuid.NullUUID, _ uuid.UUID, quantity int) error {
+	return r.move(enum.InventoryMovementRestock, productID, quantity, r.stockOf(productID).sold >= quantity,
+		func(productStock *stock) {
+			productStock.onHand += quantity
+			productStock.sold -= quantity
+		})
+}
+
+type fakeProductRepository struct {
+	repository.ProductRepository
+	products map[uuid.UUID]*entity.Product
+}
+
+func (r *fakeProductRepository) FindManyByIDs(_ context.Context, _ store.Querier, ids []uuid.UUID, _ enum.LockTypeEnum) ([]*entity.Product, error) {
+	products := make([]*entity.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+// fakeDatabaseStore hands out transactions that commit nothing, the fake repositories apply every write at once.
+type fakeDatabaseStore struct {
+	store.DatabaseStore
+}
+
+func (s *fakeDatabaseStore) Begin(context.Context) (store.Transaction, error) {
+	return &fakeTransaction{}, nil
+}
+
+type fakeTransaction struct {
+	store.Transaction
+}
+
+func (t *fakeTransaction) Commit(context.Context) error {
+	return nil
+}
+
+func (t *fakeTransaction) Rollback(context.Context) error {
+	return nil
+}
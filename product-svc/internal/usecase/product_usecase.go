@@ -2,17 +2,20 @@ package usecase
 
 import (
 	"context"
+	"go-saga-pattern/commoner/constant/enum"
 	errorcode "go-saga-pattern/commoner/constant/errcode"
 	"go-saga-pattern/commoner/constant/message"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/helper/nullable"
 	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
 	"go-saga-pattern/commoner/web"
 	"go-saga-pattern/product-svc/internal/entity"
 	"go-saga-pattern/product-svc/internal/model"
 	"go-saga-pattern/product-svc/internal/model/converter"
 	"go-saga-pattern/product-svc/internal/repository"
 	"go-saga-pattern/product-svc/internal/repository/store"
+	"maps"
 	"strings"
 
 	"github.com/google/uuid"
@@ -24,30 +27,42 @@ import (
 type ProductUseCase interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*model.ProductResponse, error)
 	GetBySlug(ctx context.Context, slug string) (*model.ProductResponse, error)
+	GetManyByIDs(ctx context.Context, request *model.GetProductsRequest) ([]*model.ProductResponse, error)
 	OwnerCreate(ctx context.Context, request *model.CreateProductRequest) (*model.ProductResponse, error)
 	OwnerDelete(ctx context.Context, request *model.DeleteProductRequest) error
 	OwnerSearch(ctx context.Context, request *model.OwnerSearchProductsRequest) ([]*model.ProductResponse, *web.PageMetadata, error)
 	OwnerUpdate(ctx context.Context, request *model.UpdateProductRequest) (*model.ProductResponse, error)
 	OwnerGet(ctx context.Context, request *model.OwnerGetProductRequest) (*model.ProductResponse, error)
+	OwnerSearchInventoryMovements(ctx context.Context, request *model.OwnerSearchInventoryMovementsRequest) ([]*model.InventoryMovementResponse, *web.PageMetadata, error)
+	OwnerUpdateOptions(ctx context.Context, request *model.UpdateProductOptionsRequest) (*model.ProductResponse, error)
+	OwnerCreateVariant(ctx context.Context, request *model.CreateProductVariantRequest) (*model.ProductVariantResponse, error)
+	OwnerUpdateVariant(ctx context.Context, request *model.UpdateProductVariantRequest) (*model.ProductVariantResponse, error)
+	OwnerDeleteVariant(ctx context.Context, request *model.DeleteProductVariantRequest) error
 	PublicSearch(ctx context.Context, request *model.PublicSearchProductsRequest) ([]*model.ProductResponse, *web.PageMetadata, error)
 }
 
 type productUseCase struct {
-	productRepository repository.ProductRepository
-	databaseStore     store.DatabaseStore
-	validator         helper.CustomValidator
-	log               logs.Log
+	productRepository        repository.ProductRepository
+	productVariantRepository repository.ProductVariantRepository
+	categoryRepository       repository.CategoryRepository
+	inventoryRepository      repository.InventoryRepository
+	databaseStore            store.DatabaseStore
+	validator                helper.CustomValidator
+	log                      logs.Log
 }
 
-func NewProductUseCase(productRepository repository.ProductRepository,
-	databaseStore store.DatabaseStore, validator helper.CustomValidator,
+func NewProductUseCase(productRepository repository.ProductRepository, productVariantRepository repository.ProductVariantRepository,
+	categoryRepository repository.CategoryRepository, inventoryRepository repository.InventoryRepository, databaseStore store.DatabaseStore, validator helper.CustomValidator,
 	log logs.Log,
 ) ProductUseCase {
 	return &productUseCase{
-		productRepository: productRepository,
-		databaseStore:     databaseStore,
-		validator:         validator,
-		log:               log,
+		productRepository:        productRepository,
+		productVariantRepository: productVariantRepository,
+		categoryRepository:       categoryRepository,
+		inventoryRepository:      inventoryRepository,
+		databaseStore:            databaseStore,
+		validator:                validator,
+		log:                      log,
 	}
 }
 
@@ -56,6 +71,10 @@ func (uc *productUseCase) OwnerCreate(ctx context.Context, request *model.Create
 		return nil, validatonErrs
 	}
 
+	if !money.Supported(request.Price.Currency) {
+		return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CurrencyNotSupported)
+	}
+
 	slug := slug.Make(request.Name)
 
 	isExistsByNameOrSlug, err := uc.productRepository.ExistsByNameOrSlug(ctx, uc.databaseStore, request.Name, slug)
@@ -74,16 +93,39 @@ func (uc *productUseCase) OwnerCreate(ctx context.Context, request *model.Create
 		Description: nullable.ToSQLString(
 			request.Description,
 		),
-		Price:    request.Price,
-		Quantity: request.Quantity,
+		Price:      request.Price,
+		Currency:   request.Price.Currency,
+		Options:    entity.ProductOptions{},
+		CategoryID: nullable.ToNullUUID(request.CategoryID),
+		Tags:       normalizeTags(request.Tags),
 	}
 
-	createdProduct, err := uc.productRepository.Insert(ctx, uc.databaseStore, product)
-	if err != nil {
-		return nil, helper.WrapInternalServerError(uc.log, "failed to insert product", err)
+	// the initial stock is put on the shelf through the ledger, so the ledger adds up to the on hand stock
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		if err := uc.checkCategory(ctx, tx, product.CategoryID); err != nil {
+			return err
+		}
+
+		if _, err := uc.productRepository.Insert(ctx, tx, product); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert product", err)
+		}
+
+		// a product that is split into variants starts empty, its stock is put on hand per variant
+		if request.Quantity == 0 {
+			return nil
+		}
+
+		if err := uc.inventoryRepository.Adjust(ctx, tx, product.ID, uuid.NullUUID{}, request.Quantity); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to put product stock on hand", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return converter.ProductToResponse(createdProduct), nil
+	product.OnHand = request.Quantity
+	product.Quantity = request.Quantity
+	return converter.OwnerProductToResponse(product), nil
 }
 
 func (uc *productUseCase) GetByID(ctx context.Context, id uuid.UUID) (*model.ProductResponse, error) {
@@ -95,7 +137,12 @@ func (uc *productUseCase) GetByID(ctx context.Context, id uuid.UUID) (*model.Pro
 		return nil, helper.WrapInternalServerError(uc.log, "failed to find product by id", err)
 	}
 
-	return converter.ProductToResponse(product), nil
+	variants, err := uc.productVariantRepository.FindManyByProductID(ctx, uc.databaseStore, product.ID)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find product variants by product id", err)
+	}
+
+	return converter.ProductWithVariantsToResponse(product, variants), nil
 }
 
 func (uc *productUseCase) GetBySlug(ctx context.Context, slug string) (*model.ProductResponse, error) {
@@ -107,15 +154,57 @@ func (uc *productUseCase) GetBySlug(ctx context.Context, slug string) (*model.Pr
 		return nil, helper.WrapInternalServerError(uc.log, "failed to find product by id", err)
 	}
 
-	return converter.ProductToResponse(product), nil
+	variants, err := uc.productVariantRepository.FindManyByProductID(ctx, uc.databaseStore, product.ID)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find product variants by product id", err)
+	}
+
+	return converter.ProductWithVariantsToResponse(product, variants), nil
 }
 
 // ISSUE product doesnt populated
+// GetManyByIDs returns the products that still exist along with their variants, deleted or unknown ids are left out.
+func (uc *productUseCase) GetManyByIDs(ctx context.Context, request *model.GetProductsRequest) ([]*model.ProductResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	products, err := uc.productRepository.FindManyByIDs(ctx, uc.databaseStore, request.ProductIDs, enum.LockTypeNoneEnum)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find products by ids", err)
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+
+	variants, err := uc.productVariantRepository.FindManyByProductIDs(ctx, uc.databaseStore, productIDs)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find product variants by product ids", err)
+	}
+
+	productVariants := make(map[uuid.UUID][]*entity.ProductVariant, len(products))
+	for _, variant := range variants {
+		productVariants[variant.ProductID] = append(productVariants[variant.ProductID], variant)
+	}
+
+	responses := make([]*model.ProductResponse, 0, len(products))
+	for _, product := range products {
+		responses = append(responses, converter.ProductWithVariantsToResponse(product, productVariants[product.ID]))
+	}
+	return responses, nil
+}
+
 func (uc *productUseCase) OwnerUpdate(ctx context.Context, request *model.UpdateProductRequest) (*model.ProductResponse, error) {
 	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
 		return nil, validatonErrs
 	}
 
+	if !money.Supported(request.Price.Currency) {
+		return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CurrencyNotSupported)
+	}
+
 	product, err := uc.productRepository.FindByIDAndUserID(ctx, uc.databaseStore, request.ID, request.UserID)
 	if err != nil {
 		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
@@ -146,15 +235,46 @@ func (uc *productUseCase) OwnerUpdate(ctx context.Context, request *model.Update
 	product.UserID = request.UserID
 	product.Description = nullable.ToSQLString(request.Description)
 	product.Price = request.Price
-	product.Quantity = request.Quantity
+	product.Currency = request.Price.Currency
+	product.CategoryID = nullable.ToNullUUID(request.CategoryID)
+	product.Tags = normalizeTags(request.Tags)
 
-	product, err = uc.productRepository.UpdateByID(ctx, uc.databaseStore, product)
-	if err != nil {
-		uc.log.Error("failed to update product", zap.Error(err), zap.String("product_id", request.ID.String()))
-		return nil, helper.WrapInternalServerError(uc.log, "failed to update product", err)
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		if err := uc.checkCategory(ctx, tx, product.CategoryID); err != nil {
+			return err
+		}
+
+		// the update locks the product, so the on hand stock it returns is the one the adjustment applies to
+		product, err = uc.productRepository.UpdateByID(ctx, tx, product)
+		if err != nil {
+			uc.log.Error("failed to update product", zap.Error(err), zap.String("product_id", request.ID.String()))
+			return helper.WrapInternalServerError(uc.log, "failed to update product", err)
+		}
+
+		adjustment := request.Quantity - product.OnHand
+		if adjustment == 0 {
+			return nil
+		}
+
+		if len(product.Options) > 0 {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductStockManagedByVariants)
+		}
+
+		if err := uc.inventoryRepository.Adjust(ctx, tx, product.ID, uuid.NullUUID{}, adjustment); err != nil {
+			if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.StockBelowReserved)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to adjust product stock", err)
+		}
+
+		product.OnHand = request.Quantity
+		product.Quantity = product.OnHand - product.Reserved
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return converter.ProductToResponse(product), nil
+	return converter.OwnerProductToResponse(product), nil
 }
 
 func (uc *productUseCase) OwnerDelete(ctx context.Context, request *model.DeleteProductRequest) error {
@@ -192,8 +312,9 @@ func (uc *productUseCase) OwnerSearch(ctx context.Context, request *model.OwnerS
 		return nil, metadata, nil
 	}
 
-	return converter.ProductsWithTotalToResponses(products), metadata, nil
+	return converter.OwnerProductsWithTotalToResponses(products), metadata, nil
 }
+
 func (uc *productUseCase) OwnerGet(ctx context.Context, request *model.OwnerGetProductRequest) (*model.ProductResponse, error) {
 	uc.log.Info("Owner get accessde", zap.Any("request", request))
 	product, err := uc.productRepository.FindByIDAndUserID(ctx, uc.databaseStore, request.ProductID, request.UserID)
@@ -203,18 +324,400 @@ func (uc *productUseCase) OwnerGet(ctx context.Context, request *model.OwnerGetP
 		}
 		return nil, helper.WrapInternalServerError(uc.log, "failed to find owner products by user id", err)
 	}
-	return converter.ProductToResponse(product), nil
+
+	variants, err := uc.productVariantRepository.FindManyByProductID(ctx, uc.databaseStore, product.ID)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find product variants by product id", err)
+	}
+
+	return converter.OwnerProductWithVariantsToResponse(product, variants), nil
+}
+
+// OwnerSearchInventoryMovements pages through the inventory ledger of a product of the owner, newest movement first.
+func (uc *productUseCase) OwnerSearchInventoryMovements(ctx context.Context,
+	request *model.OwnerSearchInventoryMovementsRequest) ([]*model.InventoryMovementResponse, *web.PageMetadata, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, nil, validatonErrs
+	}
+
+	if _, err := uc.productRepository.FindByIDAndUserID(ctx, uc.databaseStore, request.ProductID, request.UserID); err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductNotFound)
+		}
+		return nil, nil, helper.WrapInternalServerError(uc.log, "failed to find owner product by id", err)
+	}
+
+	movements, metadata, err := uc.inventoryRepository.FindManyByProductID(ctx, uc.databaseStore, request)
+	if err != nil {
+		return nil, nil, helper.WrapInternalServerError(uc.log, "failed to find inventory movements by product id", err)
+	}
+
+	if movements == nil {
+		return nil, metadata, nil
+	}
+
+	return converter.InventoryMovementsWithTotalToResponses(movements), metadata, nil
+}
+
+// OwnerUpdateOptions replaces the options the variants of the product are chosen by. A product only gets its first
+// options while it holds no stock of its own, and every variant must still pick one value of every new option.
+func (uc *productUseCase) OwnerUpdateOptions(ctx context.Context, request *model.UpdateProductOptionsRequest) (*model.ProductResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	options, err := toProductOptions(request.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var product *entity.Product
+	var variants []*entity.ProductVariant
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		product, err = uc.findOwnerProductForUpdate(ctx, tx, request.ID, request.UserID)
+		if err != nil {
+			return err
+		}
+
+		if len(product.Options) == 0 && len(options) > 0 && product.OnHand > 0 {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductStockMustBeEmpty)
+		}
+
+		variants, err = uc.productVariantRepository.FindManyByProductID(ctx, tx, product.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find product variants by product id", err)
+		}
+
+		for _, variant := range variants {
+			if !options.Match(variant.Options) {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductOptionsInUse)
+			}
+		}
+
+		product.Options = options
+		if _, err := uc.productRepository.UpdateOptionsByID(ctx, tx, product); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update product options", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return converter.OwnerProductWithVariantsToResponse(product, variants), nil
+}
+
+// OwnerCreateVariant adds a variant to a product with options and puts its initial stock on hand through the ledger.
+func (uc *productUseCase) OwnerCreateVariant(ctx context.Context, request *model.CreateProductVariantRequest) (*model.ProductVariantResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	variant := &entity.ProductVariant{
+		ProductID: request.ProductID,
+		SKU:       request.SKU,
+		Options:   request.Options,
+		Price:     request.Price,
+		Currency:  request.Price.Currency,
+	}
+
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		product, err := uc.findOwnerProductForUpdate(ctx, tx, request.ProductID, request.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.checkVariant(ctx, tx, product, variant); err != nil {
+			return err
+		}
+
+		isExistsBySKU, err := uc.productVariantRepository.ExistsBySKU(ctx, tx, variant.SKU)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to check product variant exists by sku", err)
+		}
+
+		if isExistsBySKU {
+			return helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.VariantIsExistsBySKUOrOptions)
+		}
+
+		if _, err := uc.productVariantRepository.Insert(ctx, tx, variant); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert product variant", err)
+		}
+
+		if request.Quantity == 0 {
+			return nil
+		}
+
+		if err := uc.inventoryRepository.Adjust(ctx, tx, product.ID, uuid.NullUUID{UUID: variant.ID, Valid: true}, request.Quantity); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to put product variant stock on hand", err)
+		}
+
+		variant.OnHand = request.Quantity
+		variant.Quantity = request.Quantity
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return converter.OwnerProductVariantToResponse(variant), nil
+}
+
+func (uc *productUseCase) OwnerUpdateVariant(ctx context.Context, request *model.UpdateProductVariantRequest) (*model.ProductVariantResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	var variant *entity.ProductVariant
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		product, err := uc.findOwnerProductForUpdate(ctx, tx, request.ProductID, request.UserID)
+		if err != nil {
+			return err
+		}
+
+		variant, err = uc.productVariantRepository.FindByIDAndProductID(ctx, tx, request.ID, product.ID, enum.LockTypeUpdateEnum)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductVariantNotFound)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find product variant by id", err)
+		}
+
+		variant.SKU = request.SKU
+		variant.Options = request.Options
+		variant.Price = request.Price
+		variant.Currency = request.Price.Currency
+		if err := uc.checkVariant(ctx, tx, product, variant); err != nil {
+			return err
+		}
+
+		isExistsBySKU, err := uc.productVariantRepository.ExistsBySKUExceptHerself(ctx, tx, variant.SKU, variant.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to check product variant exists by sku", err)
+		}
+
+		if isExistsBySKU {
+			return helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.VariantIsExistsBySKUOrOptions)
+		}
+
+		if _, err := uc.productVariantRepository.UpdateByID(ctx, tx, variant); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update product variant", err)
+		}
+
+		adjustment := request.Quantity - variant.OnHand
+		if adjustment == 0 {
+			return nil
+		}
+
+		if err := uc.inventoryRepository.Adjust(ctx, tx, product.ID, uuid.NullUUID{UUID: variant.ID, Valid: true}, adjustment); err != nil {
+			if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.StockBelowReserved)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to adjust product variant stock", err)
+		}
+
+		variant.OnHand = request.Quantity
+		variant.Quantity = variant.OnHand - variant.Reserved
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return converter.OwnerProductVariantToResponse(variant), nil
+}
+
+// OwnerDeleteVariant takes the stock of the variant off the shelf and deletes it, a variant still reserved by pending
+// transactions cannot be deleted.
+func (uc *productUseCase) OwnerDeleteVariant(ctx context.Context, request *model.DeleteProductVariantRequest) error {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return validatonErrs
+	}
+
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		product, err := uc.findOwnerProductForUpdate(ctx, tx, request.ProductID, request.UserID)
+		if err != nil {
+			return err
+		}
+
+		variant, err := uc.productVariantRepository.FindByIDAndProductID(ctx, tx, request.ID, product.ID, enum.LockTypeUpdateEnum)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductVariantNotFound)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find product variant by id", err)
+		}
+
+		if variant.Reserved > 0 {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.StockBelowReserved)
+		}
+
+		if variant.OnHand > 0 {
+			if err := uc.inventoryRepository.Adjust(ctx, tx, product.ID, uuid.NullUUID{UUID: variant.ID, Valid: true}, -variant.OnHand); err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to take product variant stock off hand", err)
+			}
+		}
+
+		if err := uc.productVariantRepository.DeleteByIDAndProductID(ctx, tx, variant.ID, product.ID); err != nil {
+			if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductVariantNotFound)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to delete product variant", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	uc.log.Info("Product variant deleted successfully", zap.String("product_id", request.ProductID.String()),
+		zap.String("variant_id", request.ID.String()))
+
+	return nil
+}
+
+// findOwnerProductForUpdate locks the product of the owner, so its options and variants change one request at a time.
+func (uc *productUseCase) findOwnerProductForUpdate(ctx context.Context, tx store.Transaction, productID, userID uuid.UUID) (*entity.Product, error) {
+	products, err := uc.productRepository.FindManyByIDs(ctx, tx, []uuid.UUID{productID}, enum.LockTypeUpdateEnum)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find product by id", err)
+	}
+
+	if len(products) == 0 || products[0].UserID != userID {
+		return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductNotFound)
+	}
+
+	return products[0], nil
+}
+
+// checkVariant validates the variant against its product and the other variants of the product.
+func (uc *productUseCase) checkVariant(ctx context.Context, tx store.Transaction, product *entity.Product, variant *entity.ProductVariant) error {
+	if len(product.Options) == 0 || !product.Options.Match(variant.Options) {
+		return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.VariantOptionsMismatch)
+	}
+
+	if variant.Currency != product.Currency {
+		return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.VariantCurrencyMismatch)
+	}
+
+	variants, err := uc.productVariantRepository.FindManyByProductID(ctx, tx, product.ID)
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to find product variants by product id", err)
+	}
+
+	for _, other := range variants {
+		if other.ID != variant.ID && maps.Equal(other.Options, variant.Options) {
+			return helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.VariantIsExistsBySKUOrOptions)
+		}
+	}
+
+	return nil
+}
+
+// toProductOptions checks that the option names are unique and every option has unique values.
+func toProductOptions(request []*model.ProductOption) (entity.ProductOptions, error) {
+	options := make(entity.ProductOptions, 0, len(request))
+	names := make(map[string]bool, len(request))
+	for _, option := range request {
+		if names[option.Name] {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductOptionsInvalid)
+		}
+		names[option.Name] = true
+
+		values := make(map[string]bool, len(option.Values))
+		for _, value := range option.Values {
+			if values[value] {
+				return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductOptionsInvalid)
+			}
+			values[value] = true
+		}
+
+		options = append(options, entity.ProductOption{Name: option.Name, Values: option.Values})
+	}
+	return options, nil
+}
+
+// checkCategory locks the category of the product, so it cannot be deleted while the product is put in it.
+func (uc *productUseCase) checkCategory(ctx context.Context, tx store.Querier, categoryID uuid.NullUUID) error {
+	if !categoryID.Valid {
+		return nil
+	}
+
+	if _, err := uc.categoryRepository.FindByID(ctx, tx, categoryID.UUID, enum.LockTypeShareEnum); err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CategoryNotFound)
+		}
+		return helper.WrapInternalServerError(uc.log, "failed to find category by id", err)
+	}
+	return nil
+}
+
+// normalizeTags lowercases the tags and drops the duplicates, keeping the order they were given in.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
 }
 
 func (uc *productUseCase) PublicSearch(ctx context.Context, request *model.PublicSearchProductsRequest) ([]*model.ProductResponse, *web.PageMetadata, error) {
-	products, metadata, err := uc.productRepository.PublicFindAll(ctx, uc.databaseStore, request.Limit, request.Page)
+	request.Query = strings.TrimSpace(request.Query)
+	request.Tag = strings.ToLower(strings.TrimSpace(request.Tag))
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, nil, validatonErrs
+	}
+
+	if request.Sort == "" {
+		request.Sort = enum.ProductCatalogSortNewest
+		if request.Query != "" {
+			request.Sort = enum.ProductCatalogSortRelevance
+		}
+	}
+
+	if request.Sort == enum.ProductCatalogSortRelevance && request.Query == "" {
+		return nil, nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CatalogRelevanceNeedsQuery)
+	}
+
+	// prices of different currencies cannot be compared, so a price bound only lists products of its currency
+	for _, bound := range []*money.Money{request.MinPrice, request.MaxPrice} {
+		if bound == nil {
+			continue
+		}
+		if request.Currency == "" {
+			request.Currency = bound.CurrencyCode()
+		}
+		if bound.CurrencyCode() != request.Currency {
+			return nil, nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CurrencyNotSupported)
+		}
+	}
+
+	if request.MinPrice != nil && request.MaxPrice != nil {
+		if cmp, err := request.MinPrice.Cmp(*request.MaxPrice); err != nil || cmp > 0 {
+			return nil, nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CatalogPriceRangeInvalid)
+		}
+	}
+
+	if request.Cursor != "" {
+		cursor, err := converter.DecodeProductCatalogCursor(request.Cursor)
+		if err != nil || cursor.Sort != request.Sort {
+			return nil, nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CatalogCursorInvalid)
+		}
+		request.After = cursor
+	}
+
+	products, err := uc.productRepository.PublicFindAll(ctx, uc.databaseStore, request)
 	if err != nil {
 		return nil, nil, helper.WrapInternalServerError(uc.log, "failed to find public products", err)
 	}
 
-	if products == nil {
-		return nil, metadata, nil
+	nextCursor := ""
+	if len(products) > request.Limit {
+		products = products[:request.Limit]
+		nextCursor = converter.EncodeProductCatalogCursor(request.Sort, products[len(products)-1])
 	}
 
-	return converter.ProductsWithTotalToResponses(products), metadata, nil
+	return converter.ProductsWithRankToResponses(products), helper.CalculateCursorPagination(request.Limit, nextCursor), nil
 }
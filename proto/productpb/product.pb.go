@@ -7,6 +7,7 @@
 package productpb
 
 import (
+	moneypb "go-saga-pattern/proto/moneypb"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
@@ -127,18 +128,63 @@ func (x *OwnerGetProductRequest) GetUserId() string {
 	return ""
 }
 
-type CheckProductQuantity struct {
+type GetProductsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	Price         float32                `protobuf:"fixed32,3,opt,name=price,proto3" json:"price,omitempty"`
+	ProductIds    []string               `protobuf:"bytes,1,rep,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsRequest) Reset() {
+	*x = GetProductsRequest{}
+	mi := &file_product_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsRequest) ProtoMessage() {}
+
+func (x *GetProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsRequest.ProtoReflect.Descriptor instead.
+func (*GetProductsRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetProductsRequest) GetProductIds() []string {
+	if x != nil {
+		return x.ProductIds
+	}
+	return nil
+}
+
+type CheckProductQuantity struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity  int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// required when the product has variants
+	VariantId     string `protobuf:"bytes,4,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CheckProductQuantity) Reset() {
 	*x = CheckProductQuantity{}
-	mi := &file_product_proto_msgTypes[2]
+	mi := &file_product_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -150,7 +196,7 @@ func (x *CheckProductQuantity) String() string {
 func (*CheckProductQuantity) ProtoMessage() {}
 
 func (x *CheckProductQuantity) ProtoReflect() protoreflect.Message {
-	mi := &file_product_proto_msgTypes[2]
+	mi := &file_product_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -163,7 +209,7 @@ func (x *CheckProductQuantity) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckProductQuantity.ProtoReflect.Descriptor instead.
 func (*CheckProductQuantity) Descriptor() ([]byte, []int) {
-	return file_product_proto_rawDescGZIP(), []int{2}
+	return file_product_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CheckProductQuantity) GetProductId() string {
@@ -180,11 +226,11 @@ func (x *CheckProductQuantity) GetQuantity() int32 {
 	return 0
 }
 
-func (x *CheckProductQuantity) GetPrice() float32 {
+func (x *CheckProductQuantity) GetVariantId() string {
 	if x != nil {
-		return x.Price
+		return x.VariantId
 	}
-	return 0
+	return ""
 }
 
 type CheckProductQuantityResponse struct {
@@ -198,7 +244,7 @@ type CheckProductQuantityResponse struct {
 
 func (x *CheckProductQuantityResponse) Reset() {
 	*x = CheckProductQuantityResponse{}
-	mi := &file_product_proto_msgTypes[3]
+	mi := &file_product_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -210,7 +256,7 @@ func (x *CheckProductQuantityResponse) String() string {
 func (*CheckProductQuantityResponse) ProtoMessage() {}
 
 func (x *CheckProductQuantityResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_product_proto_msgTypes[3]
+	mi := &file_product_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -223,7 +269,7 @@ func (x *CheckProductQuantityResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckProductQuantityResponse.ProtoReflect.Descriptor instead.
 func (*CheckProductQuantityResponse) Descriptor() ([]byte, []int) {
-	return file_product_proto_rawDescGZIP(), []int{3}
+	return file_product_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CheckProductQuantityResponse) GetStatus() int64 {
@@ -258,7 +304,7 @@ type OwnerGetProductResponse struct {
 
 func (x *OwnerGetProductResponse) Reset() {
 	*x = OwnerGetProductResponse{}
-	mi := &file_product_proto_msgTypes[4]
+	mi := &file_product_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -270,7 +316,7 @@ func (x *OwnerGetProductResponse) String() string {
 func (*OwnerGetProductResponse) ProtoMessage() {}
 
 func (x *OwnerGetProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_product_proto_msgTypes[4]
+	mi := &file_product_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -283,7 +329,7 @@ func (x *OwnerGetProductResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OwnerGetProductResponse.ProtoReflect.Descriptor instead.
 func (*OwnerGetProductResponse) Descriptor() ([]byte, []int) {
-	return file_product_proto_rawDescGZIP(), []int{4}
+	return file_product_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *OwnerGetProductResponse) GetStatus() int64 {
@@ -307,22 +353,90 @@ func (x *OwnerGetProductResponse) GetProduct() *Product {
 	return nil
 }
 
-type Product struct {
+type GetProductsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Price         float32                `protobuf:"fixed32,4,opt,name=price,proto3" json:"price,omitempty"`
-	Quantity      int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Status        int64                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Products      []*Product             `protobuf:"bytes,3,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsResponse) Reset() {
+	*x = GetProductsResponse{}
+	mi := &file_product_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsResponse) ProtoMessage() {}
+
+func (x *GetProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsResponse.ProtoReflect.Descriptor instead.
+func (*GetProductsResponse) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetProductsResponse) GetStatus() int64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *GetProductsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type Product struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Quantity    int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Price       *moneypb.Money         `protobuf:"bytes,9,opt,name=price,proto3" json:"price,omitempty"`
+	// owner of the product, only set on reserved products
+	UserId string `protobuf:"bytes,10,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// the reserved variant, only set on reserved products with variants, price and quantity are the ones of the variant
+	Variant *ProductVariant `protobuf:"bytes,11,opt,name=variant,proto3" json:"variant,omitempty"`
+	// path of the category slugs from the root, such as books/fiction, empty when the product has no category
+	Category string `protobuf:"bytes,12,opt,name=category,proto3" json:"category,omitempty"`
+	// the variants buyers can choose from, only set by GetProducts on products with variants
+	Variants      []*ProductVariant `protobuf:"bytes,13,rep,name=variants,proto3" json:"variants,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Product) Reset() {
 	*x = Product{}
-	mi := &file_product_proto_msgTypes[5]
+	mi := &file_product_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -334,7 +448,7 @@ func (x *Product) String() string {
 func (*Product) ProtoMessage() {}
 
 func (x *Product) ProtoReflect() protoreflect.Message {
-	mi := &file_product_proto_msgTypes[5]
+	mi := &file_product_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -347,7 +461,7 @@ func (x *Product) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Product.ProtoReflect.Descriptor instead.
 func (*Product) Descriptor() ([]byte, []int) {
-	return file_product_proto_rawDescGZIP(), []int{5}
+	return file_product_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Product) GetId() string {
@@ -371,13 +485,6 @@ func (x *Product) GetDescription() string {
 	return ""
 }
 
-func (x *Product) GetPrice() float32 {
-	if x != nil {
-		return x.Price
-	}
-	return 0
-}
-
 func (x *Product) GetQuantity() int32 {
 	if x != nil {
 		return x.Quantity
@@ -399,23 +506,138 @@ func (x *Product) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Product) GetPrice() *moneypb.Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *Product) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Product) GetVariant() *ProductVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+func (x *Product) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Product) GetVariants() []*ProductVariant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type ProductVariant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Sku           string                 `protobuf:"bytes,2,opt,name=sku,proto3" json:"sku,omitempty"`
+	Options       map[string]string      `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Price         *moneypb.Money         `protobuf:"bytes,4,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductVariant) Reset() {
+	*x = ProductVariant{}
+	mi := &file_product_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductVariant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductVariant) ProtoMessage() {}
+
+func (x *ProductVariant) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductVariant.ProtoReflect.Descriptor instead.
+func (*ProductVariant) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ProductVariant) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProductVariant) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ProductVariant) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ProductVariant) GetPrice() *moneypb.Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *ProductVariant) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
 var File_product_proto protoreflect.FileDescriptor
 
 const file_product_proto_rawDesc = "" +
 	"\n" +
-	"\rproduct.proto\x12\x05proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/wrappers.proto\"\x7f\n" +
+	"\rproduct.proto\x12\x05proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/wrappers.proto\x1a\vmoney.proto\"\x7f\n" +
 	"\x1dCheckProductAndReserveRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x127\n" +
 	"\bproducts\x18\x02 \x03(\v2\x1b.proto.CheckProductQuantityR\bproducts\"P\n" +
 	"\x16OwnerGetProductRequest\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\"g\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"5\n" +
+	"\x12GetProductsRequest\x12\x1f\n" +
+	"\vproduct_ids\x18\x01 \x03(\tR\n" +
+	"productIds\"v\n" +
 	"\x14CheckProductQuantity\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x02R\x05price\"x\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x04 \x01(\tR\tvariantIdJ\x04\b\x03\x10\x04\"x\n" +
 	"\x1cCheckProductQuantityResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\x03R\x06status\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x12*\n" +
@@ -423,20 +645,39 @@ const file_product_proto_rawDesc = "" +
 	"\x17OwnerGetProductResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\x03R\x06status\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x12(\n" +
-	"\aproduct\x18\x03 \x01(\v2\x0e.proto.ProductR\aproduct\"\xf7\x01\n" +
+	"\aproduct\x18\x03 \x01(\v2\x0e.proto.ProductR\aproduct\"o\n" +
+	"\x13GetProductsResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\x03R\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12*\n" +
+	"\bproducts\x18\x03 \x03(\v2\x0e.proto.ProductR\bproducts\"\xaa\x03\n" +
 	"\aProduct\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
-	"\x05price\x18\x04 \x01(\x02R\x05price\x12\x1a\n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
 	"\bquantity\x18\x05 \x01(\x05R\bquantity\x129\n" +
 	"\n" +
 	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2\xc7\x01\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\"\n" +
+	"\x05price\x18\t \x01(\v2\f.proto.MoneyR\x05price\x12\x17\n" +
+	"\auser_id\x18\n" +
+	" \x01(\tR\x06userId\x12/\n" +
+	"\avariant\x18\v \x01(\v2\x15.proto.ProductVariantR\avariant\x12\x1a\n" +
+	"\bcategory\x18\f \x01(\tR\bcategory\x121\n" +
+	"\bvariants\x18\r \x03(\v2\x15.proto.ProductVariantR\bvariantsJ\x04\b\x04\x10\x05J\x04\b\b\x10\t\"\xec\x01\n" +
+	"\x0eProductVariant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03sku\x18\x02 \x01(\tR\x03sku\x12<\n" +
+	"\aoptions\x18\x03 \x03(\v2\".proto.ProductVariant.OptionsEntryR\aoptions\x12\"\n" +
+	"\x05price\x18\x04 \x01(\v2\f.proto.MoneyR\x05price\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x05R\bquantity\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\x8d\x02\n" +
 	"\x0eProductService\x12c\n" +
 	"\x16CheckProductAndReserve\x12$.proto.CheckProductAndReserveRequest\x1a#.proto.CheckProductQuantityResponse\x12P\n" +
-	"\x0fOwnerGetProduct\x12\x1d.proto.OwnerGetProductRequest\x1a\x1e.proto.OwnerGetProductResponseB\fZ\n" +
+	"\x0fOwnerGetProduct\x12\x1d.proto.OwnerGetProductRequest\x1a\x1e.proto.OwnerGetProductResponse\x12D\n" +
+	"\vGetProducts\x12\x19.proto.GetProductsRequest\x1a\x1a.proto.GetProductsResponseB\fZ\n" +
 	"/productpbb\x06proto3"
 
 var (
@@ -451,31 +692,44 @@ func file_product_proto_rawDescGZIP() []byte {
 	return file_product_proto_rawDescData
 }
 
-var file_product_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_product_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_product_proto_goTypes = []any{
 	(*CheckProductAndReserveRequest)(nil), // 0: proto.CheckProductAndReserveRequest
 	(*OwnerGetProductRequest)(nil),        // 1: proto.OwnerGetProductRequest
-	(*CheckProductQuantity)(nil),          // 2: proto.CheckProductQuantity
-	(*CheckProductQuantityResponse)(nil),  // 3: proto.CheckProductQuantityResponse
-	(*OwnerGetProductResponse)(nil),       // 4: proto.OwnerGetProductResponse
-	(*Product)(nil),                       // 5: proto.Product
-	(*timestamppb.Timestamp)(nil),         // 6: google.protobuf.Timestamp
+	(*GetProductsRequest)(nil),            // 2: proto.GetProductsRequest
+	(*CheckProductQuantity)(nil),          // 3: proto.CheckProductQuantity
+	(*CheckProductQuantityResponse)(nil),  // 4: proto.CheckProductQuantityResponse
+	(*OwnerGetProductResponse)(nil),       // 5: proto.OwnerGetProductResponse
+	(*GetProductsResponse)(nil),           // 6: proto.GetProductsResponse
+	(*Product)(nil),                       // 7: proto.Product
+	(*ProductVariant)(nil),                // 8: proto.ProductVariant
+	nil,                                   // 9: proto.ProductVariant.OptionsEntry
+	(*timestamppb.Timestamp)(nil),         // 10: google.protobuf.Timestamp
+	(*moneypb.Money)(nil),                 // 11: proto.Money
 }
 var file_product_proto_depIdxs = []int32{
-	2, // 0: proto.CheckProductAndReserveRequest.products:type_name -> proto.CheckProductQuantity
-	5, // 1: proto.CheckProductQuantityResponse.products:type_name -> proto.Product
-	5, // 2: proto.OwnerGetProductResponse.product:type_name -> proto.Product
-	6, // 3: proto.Product.created_at:type_name -> google.protobuf.Timestamp
-	6, // 4: proto.Product.updated_at:type_name -> google.protobuf.Timestamp
-	0, // 5: proto.ProductService.CheckProductAndReserve:input_type -> proto.CheckProductAndReserveRequest
-	1, // 6: proto.ProductService.OwnerGetProduct:input_type -> proto.OwnerGetProductRequest
-	3, // 7: proto.ProductService.CheckProductAndReserve:output_type -> proto.CheckProductQuantityResponse
-	4, // 8: proto.ProductService.OwnerGetProduct:output_type -> proto.OwnerGetProductResponse
-	7, // [7:9] is the sub-list for method output_type
-	5, // [5:7] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	3,  // 0: proto.CheckProductAndReserveRequest.products:type_name -> proto.CheckProductQuantity
+	7,  // 1: proto.CheckProductQuantityResponse.products:type_name -> proto.Product
+	7,  // 2: proto.OwnerGetProductResponse.product:type_name -> proto.Product
+	7,  // 3: proto.GetProductsResponse.products:type_name -> proto.Product
+	10, // 4: proto.Product.created_at:type_name -> google.protobuf.Timestamp
+	10, // 5: proto.Product.updated_at:type_name -> google.protobuf.Timestamp
+	11, // 6: proto.Product.price:type_name -> proto.Money
+	8,  // 7: proto.Product.variant:type_name -> proto.ProductVariant
+	8,  // 8: proto.Product.variants:type_name -> proto.ProductVariant
+	9,  // 9: proto.ProductVariant.options:type_name -> proto.ProductVariant.OptionsEntry
+	11, // 10: proto.ProductVariant.price:type_name -> proto.Money
+	0,  // 11: proto.ProductService.CheckProductAndReserve:input_type -> proto.CheckProductAndReserveRequest
+	1,  // 12: proto.ProductService.OwnerGetProduct:input_type -> proto.OwnerGetProductRequest
+	2,  // 13: proto.ProductService.GetProducts:input_type -> proto.GetProductsRequest
+	4,  // 14: proto.ProductService.CheckProductAndReserve:output_type -> proto.CheckProductQuantityResponse
+	5,  // 15: proto.ProductService.OwnerGetProduct:output_type -> proto.OwnerGetProductResponse
+	6,  // 16: proto.ProductService.GetProducts:output_type -> proto.GetProductsResponse
+	14, // [14:17] is the sub-list for method output_type
+	11, // [11:14] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_product_proto_init() }
@@ -489,7 +743,7 @@ func file_product_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_product_proto_rawDesc), len(file_product_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
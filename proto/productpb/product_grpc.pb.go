@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	ProductService_CheckProductAndReserve_FullMethodName = "/proto.ProductService/CheckProductAndReserve"
 	ProductService_OwnerGetProduct_FullMethodName        = "/proto.ProductService/OwnerGetProduct"
+	ProductService_GetProducts_FullMethodName            = "/proto.ProductService/GetProducts"
 )
 
 // ProductServiceClient is the client API for ProductService service.
@@ -29,6 +30,7 @@ const (
 type ProductServiceClient interface {
 	CheckProductAndReserve(ctx context.Context, in *CheckProductAndReserveRequest, opts ...grpc.CallOption) (*CheckProductQuantityResponse, error)
 	OwnerGetProduct(ctx context.Context, in *OwnerGetProductRequest, opts ...grpc.CallOption) (*OwnerGetProductResponse, error)
+	GetProducts(ctx context.Context, in *GetProductsRequest, opts ...grpc.CallOption) (*GetProductsResponse, error)
 }
 
 type productServiceClient struct {
@@ -59,12 +61,23 @@ func (c *productServiceClient) OwnerGetProduct(ctx context.Context, in *OwnerGet
 	return out, nil
 }
 
+func (c *productServiceClient) GetProducts(ctx context.Context, in *GetProductsRequest, opts ...grpc.CallOption) (*GetProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProductsResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations must embed UnimplementedProductServiceServer
 // for forward compatibility.
 type ProductServiceServer interface {
 	CheckProductAndReserve(context.Context, *CheckProductAndReserveRequest) (*CheckProductQuantityResponse, error)
 	OwnerGetProduct(context.Context, *OwnerGetProductRequest) (*OwnerGetProductResponse, error)
+	GetProducts(context.Context, *GetProductsRequest) (*GetProductsResponse, error)
 	mustEmbedUnimplementedProductServiceServer()
 }
 
@@ -81,6 +94,9 @@ func (UnimplementedProductServiceServer) CheckProductAndReserve(context.Context,
 func (UnimplementedProductServiceServer) OwnerGetProduct(context.Context, *OwnerGetProductRequest) (*OwnerGetProductResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method OwnerGetProduct not implemented")
 }
+func (UnimplementedProductServiceServer) GetProducts(context.Context, *GetProductsRequest) (*GetProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProducts not implemented")
+}
 func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
 func (UnimplementedProductServiceServer) testEmbeddedByValue()                        {}
 
@@ -138,6 +154,24 @@ func _ProductService_OwnerGetProduct_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_GetProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetProducts(ctx, req.(*GetProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +187,10 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "OwnerGetProduct",
 			Handler:    _ProductService_OwnerGetProduct_Handler,
 		},
+		{
+			MethodName: "GetProducts",
+			Handler:    _ProductService_GetProducts_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "product.proto",
@@ -0,0 +1,1311 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: transaction.proto
+
+package transactionpb
+
+import (
+	moneypb "go-saga-pattern/proto/moneypb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateTransactionRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	UserId          string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Products        []*TransactionProduct  `protobuf:"bytes,2,rep,name=products,proto3" json:"products,omitempty"`
+	PaymentProvider string                 `protobuf:"bytes,3,opt,name=payment_provider,json=paymentProvider,proto3" json:"payment_provider,omitempty"`
+	// optional total the buyer was quoted, checked against the server priced total
+	ExpectedTotal *moneypb.Money `protobuf:"bytes,5,opt,name=expected_total,json=expectedTotal,proto3" json:"expected_total,omitempty"`
+	// currency to charge in, defaults to the currency the products are priced in
+	Currency string `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	// promo codes applied in order, each to what the ones before left of the order
+	PromoCodes    []string `protobuf:"bytes,7,rep,name=promo_codes,json=promoCodes,proto3" json:"promo_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransactionRequest) Reset() {
+	*x = CreateTransactionRequest{}
+	mi := &file_transaction_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransactionRequest) ProtoMessage() {}
+
+func (x *CreateTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransactionRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateTransactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetProducts() []*TransactionProduct {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *CreateTransactionRequest) GetPaymentProvider() string {
+	if x != nil {
+		return x.PaymentProvider
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetExpectedTotal() *moneypb.Money {
+	if x != nil {
+		return x.ExpectedTotal
+	}
+	return nil
+}
+
+func (x *CreateTransactionRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetPromoCodes() []string {
+	if x != nil {
+		return x.PromoCodes
+	}
+	return nil
+}
+
+type TransactionProduct struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity  int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// required when the product has variants
+	VariantId     string `protobuf:"bytes,4,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionProduct) Reset() {
+	*x = TransactionProduct{}
+	mi := &file_transaction_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionProduct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionProduct) ProtoMessage() {}
+
+func (x *TransactionProduct) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionProduct.ProtoReflect.Descriptor instead.
+func (*TransactionProduct) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TransactionProduct) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *TransactionProduct) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *TransactionProduct) GetVariantId() string {
+	if x != nil {
+		return x.VariantId
+	}
+	return ""
+}
+
+type GetTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionRequest) Reset() {
+	*x = GetTransactionRequest{}
+	mi := &file_transaction_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionRequest) ProtoMessage() {}
+
+func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetTransactionRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *GetTransactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListUserTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserTransactionsRequest) Reset() {
+	*x = ListUserTransactionsRequest{}
+	mi := &file_transaction_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserTransactionsRequest) ProtoMessage() {}
+
+func (x *ListUserTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListUserTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListUserTransactionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListUserTransactionsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListUserTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type CancelTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTransactionRequest) Reset() {
+	*x = CancelTransactionRequest{}
+	mi := &file_transaction_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTransactionRequest) ProtoMessage() {}
+
+func (x *CancelTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTransactionRequest.ProtoReflect.Descriptor instead.
+func (*CancelTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CancelTransactionRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *CancelTransactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type WatchTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTransactionRequest) Reset() {
+	*x = WatchTransactionRequest{}
+	mi := &file_transaction_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTransactionRequest) ProtoMessage() {}
+
+func (x *WatchTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTransactionRequest.ProtoReflect.Descriptor instead.
+func (*WatchTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchTransactionRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *WatchTransactionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type CreateTransactionResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Status          int64                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error           string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	TransactionId   string                 `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	PaymentProvider string                 `protobuf:"bytes,4,opt,name=payment_provider,json=paymentProvider,proto3" json:"payment_provider,omitempty"`
+	SnapToken       string                 `protobuf:"bytes,5,opt,name=snap_token,json=snapToken,proto3" json:"snap_token,omitempty"`
+	RedirectUrl     string                 `protobuf:"bytes,6,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`
+	TotalPrice      *moneypb.Money         `protobuf:"bytes,8,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	Discount        *moneypb.Money         `protobuf:"bytes,9,opt,name=discount,proto3" json:"discount,omitempty"`
+	Breakdown       *PriceBreakdown        `protobuf:"bytes,10,opt,name=breakdown,proto3" json:"breakdown,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateTransactionResponse) Reset() {
+	*x = CreateTransactionResponse{}
+	mi := &file_transaction_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransactionResponse) ProtoMessage() {}
+
+func (x *CreateTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransactionResponse.ProtoReflect.Descriptor instead.
+func (*CreateTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateTransactionResponse) GetStatus() int64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *CreateTransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CreateTransactionResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *CreateTransactionResponse) GetPaymentProvider() string {
+	if x != nil {
+		return x.PaymentProvider
+	}
+	return ""
+}
+
+func (x *CreateTransactionResponse) GetSnapToken() string {
+	if x != nil {
+		return x.SnapToken
+	}
+	return ""
+}
+
+func (x *CreateTransactionResponse) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
+
+func (x *CreateTransactionResponse) GetTotalPrice() *moneypb.Money {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return nil
+}
+
+func (x *CreateTransactionResponse) GetDiscount() *moneypb.Money {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *CreateTransactionResponse) GetBreakdown() *PriceBreakdown {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+type GetTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        int64                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Transaction   *Transaction           `protobuf:"bytes,3,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionResponse) Reset() {
+	*x = GetTransactionResponse{}
+	mi := &file_transaction_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionResponse) ProtoMessage() {}
+
+func (x *GetTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionResponse.ProtoReflect.Descriptor instead.
+func (*GetTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetTransactionResponse) GetStatus() int64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *GetTransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetTransactionResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+type ListUserTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        int64                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Transactions  []*Transaction         `protobuf:"bytes,3,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	PageMetadata  *PageMetadata          `protobuf:"bytes,4,opt,name=page_metadata,json=pageMetadata,proto3" json:"page_metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserTransactionsResponse) Reset() {
+	*x = ListUserTransactionsResponse{}
+	mi := &file_transaction_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserTransactionsResponse) ProtoMessage() {}
+
+func (x *ListUserTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListUserTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListUserTransactionsResponse) GetStatus() int64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *ListUserTransactionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ListUserTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListUserTransactionsResponse) GetPageMetadata() *PageMetadata {
+	if x != nil {
+		return x.PageMetadata
+	}
+	return nil
+}
+
+type CancelTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        int64                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTransactionResponse) Reset() {
+	*x = CancelTransactionResponse{}
+	mi := &file_transaction_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTransactionResponse) ProtoMessage() {}
+
+func (x *CancelTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTransactionResponse.ProtoReflect.Descriptor instead.
+func (*CancelTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CancelTransactionResponse) GetStatus() int64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *CancelTransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type WatchTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTransactionResponse) Reset() {
+	*x = WatchTransactionResponse{}
+	mi := &file_transaction_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTransactionResponse) ProtoMessage() {}
+
+func (x *WatchTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTransactionResponse.ProtoReflect.Descriptor instead.
+func (*WatchTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchTransactionResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+type Transaction struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId             string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TransactionStatus  string                 `protobuf:"bytes,4,opt,name=transaction_status,json=transactionStatus,proto3" json:"transaction_status,omitempty"`
+	CheckoutAt         string                 `protobuf:"bytes,5,opt,name=checkout_at,json=checkoutAt,proto3" json:"checkout_at,omitempty"`
+	PaymentAt          string                 `protobuf:"bytes,6,opt,name=payment_at,json=paymentAt,proto3" json:"payment_at,omitempty"`
+	UpdatedAt          string                 `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	TransactionDetails []*TransactionDetail   `protobuf:"bytes,8,rep,name=transaction_details,json=transactionDetails,proto3" json:"transaction_details,omitempty"`
+	TotalPrice         *moneypb.Money         `protobuf:"bytes,9,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	Discount           *moneypb.Money         `protobuf:"bytes,10,opt,name=discount,proto3" json:"discount,omitempty"`
+	Breakdown          *PriceBreakdown        `protobuf:"bytes,11,opt,name=breakdown,proto3" json:"breakdown,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_transaction_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Transaction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Transaction) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Transaction) GetTransactionStatus() string {
+	if x != nil {
+		return x.TransactionStatus
+	}
+	return ""
+}
+
+func (x *Transaction) GetCheckoutAt() string {
+	if x != nil {
+		return x.CheckoutAt
+	}
+	return ""
+}
+
+func (x *Transaction) GetPaymentAt() string {
+	if x != nil {
+		return x.PaymentAt
+	}
+	return ""
+}
+
+func (x *Transaction) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Transaction) GetTransactionDetails() []*TransactionDetail {
+	if x != nil {
+		return x.TransactionDetails
+	}
+	return nil
+}
+
+func (x *Transaction) GetTotalPrice() *moneypb.Money {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return nil
+}
+
+func (x *Transaction) GetDiscount() *moneypb.Money {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *Transaction) GetBreakdown() *PriceBreakdown {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+// total_price = subtotal - discount + tax + service_fee + payment_fee
+type PriceBreakdown struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subtotal      *moneypb.Money         `protobuf:"bytes,1,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Discount      *moneypb.Money         `protobuf:"bytes,2,opt,name=discount,proto3" json:"discount,omitempty"`
+	Tax           *moneypb.Money         `protobuf:"bytes,3,opt,name=tax,proto3" json:"tax,omitempty"`
+	ServiceFee    *moneypb.Money         `protobuf:"bytes,4,opt,name=service_fee,json=serviceFee,proto3" json:"service_fee,omitempty"`
+	PaymentFee    *moneypb.Money         `protobuf:"bytes,5,opt,name=payment_fee,json=paymentFee,proto3" json:"payment_fee,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceBreakdown) Reset() {
+	*x = PriceBreakdown{}
+	mi := &file_transaction_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceBreakdown) ProtoMessage() {}
+
+func (x *PriceBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceBreakdown.ProtoReflect.Descriptor instead.
+func (*PriceBreakdown) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PriceBreakdown) GetSubtotal() *moneypb.Money {
+	if x != nil {
+		return x.Subtotal
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetDiscount() *moneypb.Money {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetTax() *moneypb.Money {
+	if x != nil {
+		return x.Tax
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetServiceFee() *moneypb.Money {
+	if x != nil {
+		return x.ServiceFee
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetPaymentFee() *moneypb.Money {
+	if x != nil {
+		return x.PaymentFee
+	}
+	return nil
+}
+
+type TransactionDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Price         *moneypb.Money         `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	Discount      *moneypb.Money         `protobuf:"bytes,7,opt,name=discount,proto3" json:"discount,omitempty"`
+	Tax           *moneypb.Money         `protobuf:"bytes,8,opt,name=tax,proto3" json:"tax,omitempty"`
+	VariantId     string                 `protobuf:"bytes,9,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	Sku           string                 `protobuf:"bytes,10,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionDetail) Reset() {
+	*x = TransactionDetail{}
+	mi := &file_transaction_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionDetail) ProtoMessage() {}
+
+func (x *TransactionDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionDetail.ProtoReflect.Descriptor instead.
+func (*TransactionDetail) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TransactionDetail) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransactionDetail) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *TransactionDetail) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *TransactionDetail) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *TransactionDetail) GetPrice() *moneypb.Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *TransactionDetail) GetDiscount() *moneypb.Money {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *TransactionDetail) GetTax() *moneypb.Money {
+	if x != nil {
+		return x.Tax
+	}
+	return nil
+}
+
+func (x *TransactionDetail) GetVariantId() string {
+	if x != nil {
+		return x.VariantId
+	}
+	return ""
+}
+
+func (x *TransactionDetail) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+type PageMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	Size          int32                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	TotalItem     int64                  `protobuf:"varint,3,opt,name=total_item,json=totalItem,proto3" json:"total_item,omitempty"`
+	TotalPage     int64                  `protobuf:"varint,4,opt,name=total_page,json=totalPage,proto3" json:"total_page,omitempty"`
+	HasNext       bool                   `protobuf:"varint,5,opt,name=has_next,json=hasNext,proto3" json:"has_next,omitempty"`
+	HasPrevious   bool                   `protobuf:"varint,6,opt,name=has_previous,json=hasPrevious,proto3" json:"has_previous,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageMetadata) Reset() {
+	*x = PageMetadata{}
+	mi := &file_transaction_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageMetadata) ProtoMessage() {}
+
+func (x *PageMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageMetadata.ProtoReflect.Descriptor instead.
+func (*PageMetadata) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PageMetadata) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *PageMetadata) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *PageMetadata) GetTotalItem() int64 {
+	if x != nil {
+		return x.TotalItem
+	}
+	return 0
+}
+
+func (x *PageMetadata) GetTotalPage() int64 {
+	if x != nil {
+		return x.TotalPage
+	}
+	return 0
+}
+
+func (x *PageMetadata) GetHasNext() bool {
+	if x != nil {
+		return x.HasNext
+	}
+	return false
+}
+
+func (x *PageMetadata) GetHasPrevious() bool {
+	if x != nil {
+		return x.HasPrevious
+	}
+	return false
+}
+
+var File_transaction_proto protoreflect.FileDescriptor
+
+const file_transaction_proto_rawDesc = "" +
+	"\n" +
+	"\x11transaction.proto\x12\x05proto\x1a\vmoney.proto\"\x8d\x02\n" +
+	"\x18CreateTransactionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x125\n" +
+	"\bproducts\x18\x02 \x03(\v2\x19.proto.TransactionProductR\bproducts\x12)\n" +
+	"\x10payment_provider\x18\x03 \x01(\tR\x0fpaymentProvider\x123\n" +
+	"\x0eexpected_total\x18\x05 \x01(\v2\f.proto.MoneyR\rexpectedTotal\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12\x1f\n" +
+	"\vpromo_codes\x18\a \x03(\tR\n" +
+	"promoCodesJ\x04\b\x04\x10\x05\"t\n" +
+	"\x12TransactionProduct\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x04 \x01(\tR\tvariantIdJ\x04\b\x03\x10\x04\"W\n" +
+	"\x15GetTransactionRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"`\n" +
+	"\x1bListUserTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"Z\n" +
+	"\x18CancelTransactionRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"Y\n" +
+	"\x17WatchTransactionRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xf1\x02\n" +
+	"\x19CreateTransactionResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\x03R\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12%\n" +
+	"\x0etransaction_id\x18\x03 \x01(\tR\rtransactionId\x12)\n" +
+	"\x10payment_provider\x18\x04 \x01(\tR\x0fpaymentProvider\x12\x1d\n" +
+	"\n" +
+	"snap_token\x18\x05 \x01(\tR\tsnapToken\x12!\n" +
+	"\fredirect_url\x18\x06 \x01(\tR\vredirectUrl\x12-\n" +
+	"\vtotal_price\x18\b \x01(\v2\f.proto.MoneyR\n" +
+	"totalPrice\x12(\n" +
+	"\bdiscount\x18\t \x01(\v2\f.proto.MoneyR\bdiscount\x123\n" +
+	"\tbreakdown\x18\n" +
+	" \x01(\v2\x15.proto.PriceBreakdownR\tbreakdownJ\x04\b\a\x10\b\"|\n" +
+	"\x16GetTransactionResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\x03R\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x124\n" +
+	"\vtransaction\x18\x03 \x01(\v2\x12.proto.TransactionR\vtransaction\"\xbe\x01\n" +
+	"\x1cListUserTransactionsResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\x03R\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x126\n" +
+	"\ftransactions\x18\x03 \x03(\v2\x12.proto.TransactionR\ftransactions\x128\n" +
+	"\rpage_metadata\x18\x04 \x01(\v2\x13.proto.PageMetadataR\fpageMetadata\"I\n" +
+	"\x19CancelTransactionResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\x03R\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"P\n" +
+	"\x18WatchTransactionResponse\x124\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x12.proto.TransactionR\vtransaction\"\xa3\x03\n" +
+	"\vTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12-\n" +
+	"\x12transaction_status\x18\x04 \x01(\tR\x11transactionStatus\x12\x1f\n" +
+	"\vcheckout_at\x18\x05 \x01(\tR\n" +
+	"checkoutAt\x12\x1d\n" +
+	"\n" +
+	"payment_at\x18\x06 \x01(\tR\tpaymentAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\tR\tupdatedAt\x12I\n" +
+	"\x13transaction_details\x18\b \x03(\v2\x18.proto.TransactionDetailR\x12transactionDetails\x12-\n" +
+	"\vtotal_price\x18\t \x01(\v2\f.proto.MoneyR\n" +
+	"totalPrice\x12(\n" +
+	"\bdiscount\x18\n" +
+	" \x01(\v2\f.proto.MoneyR\bdiscount\x123\n" +
+	"\tbreakdown\x18\v \x01(\v2\x15.proto.PriceBreakdownR\tbreakdownJ\x04\b\x03\x10\x04\"\xe2\x01\n" +
+	"\x0ePriceBreakdown\x12(\n" +
+	"\bsubtotal\x18\x01 \x01(\v2\f.proto.MoneyR\bsubtotal\x12(\n" +
+	"\bdiscount\x18\x02 \x01(\v2\f.proto.MoneyR\bdiscount\x12\x1e\n" +
+	"\x03tax\x18\x03 \x01(\v2\f.proto.MoneyR\x03tax\x12-\n" +
+	"\vservice_fee\x18\x04 \x01(\v2\f.proto.MoneyR\n" +
+	"serviceFee\x12-\n" +
+	"\vpayment_fee\x18\x05 \x01(\v2\f.proto.MoneyR\n" +
+	"paymentFee\"\xa2\x02\n" +
+	"\x11TransactionDetail\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\"\n" +
+	"\x05price\x18\x06 \x01(\v2\f.proto.MoneyR\x05price\x12(\n" +
+	"\bdiscount\x18\a \x01(\v2\f.proto.MoneyR\bdiscount\x12\x1e\n" +
+	"\x03tax\x18\b \x01(\v2\f.proto.MoneyR\x03tax\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\t \x01(\tR\tvariantId\x12\x10\n" +
+	"\x03sku\x18\n" +
+	" \x01(\tR\x03skuJ\x04\b\x04\x10\x05\"\xb2\x01\n" +
+	"\fPageMetadata\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x05R\x04size\x12\x1d\n" +
+	"\n" +
+	"total_item\x18\x03 \x01(\x03R\ttotalItem\x12\x1d\n" +
+	"\n" +
+	"total_page\x18\x04 \x01(\x03R\ttotalPage\x12\x19\n" +
+	"\bhas_next\x18\x05 \x01(\bR\ahasNext\x12!\n" +
+	"\fhas_previous\x18\x06 \x01(\bR\vhasPrevious2\xcb\x03\n" +
+	"\x12TransactionService\x12V\n" +
+	"\x11CreateTransaction\x12\x1f.proto.CreateTransactionRequest\x1a .proto.CreateTransactionResponse\x12M\n" +
+	"\x0eGetTransaction\x12\x1c.proto.GetTransactionRequest\x1a\x1d.proto.GetTransactionResponse\x12_\n" +
+	"\x14ListUserTransactions\x12\".proto.ListUserTransactionsRequest\x1a#.proto.ListUserTransactionsResponse\x12V\n" +
+	"\x11CancelTransaction\x12\x1f.proto.CancelTransactionRequest\x1a .proto.CancelTransactionResponse\x12U\n" +
+	"\x10WatchTransaction\x12\x1e.proto.WatchTransactionRequest\x1a\x1f.proto.WatchTransactionResponse0\x01B\x10Z\x0e/transactionpbb\x06proto3"
+
+var (
+	file_transaction_proto_rawDescOnce sync.Once
+	file_transaction_proto_rawDescData []byte
+)
+
+func file_transaction_proto_rawDescGZIP() []byte {
+	file_transaction_proto_rawDescOnce.Do(func() {
+		file_transaction_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_transaction_proto_rawDesc), len(file_transaction_proto_rawDesc)))
+	})
+	return file_transaction_proto_rawDescData
+}
+
+var file_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_transaction_proto_goTypes = []any{
+	(*CreateTransactionRequest)(nil),     // 0: proto.CreateTransactionRequest
+	(*TransactionProduct)(nil),           // 1: proto.TransactionProduct
+	(*GetTransactionRequest)(nil),        // 2: proto.GetTransactionRequest
+	(*ListUserTransactionsRequest)(nil),  // 3: proto.ListUserTransactionsRequest
+	(*CancelTransactionRequest)(nil),     // 4: proto.CancelTransactionRequest
+	(*WatchTransactionRequest)(nil),      // 5: proto.WatchTransactionRequest
+	(*CreateTransactionResponse)(nil),    // 6: proto.CreateTransactionResponse
+	(*GetTransactionResponse)(nil),       // 7: proto.GetTransactionResponse
+	(*ListUserTransactionsResponse)(nil), // 8: proto.ListUserTransactionsResponse
+	(*CancelTransactionResponse)(nil),    // 9: proto.CancelTransactionResponse
+	(*WatchTransactionResponse)(nil),     // 10: proto.WatchTransactionResponse
+	(*Transaction)(nil),                  // 11: proto.Transaction
+	(*PriceBreakdown)(nil),               // 12: proto.PriceBreakdown
+	(*TransactionDetail)(nil),            // 13: proto.TransactionDetail
+	(*PageMetadata)(nil),                 // 14: proto.PageMetadata
+	(*moneypb.Money)(nil),                // 15: proto.Money
+}
+var file_transaction_proto_depIdxs = []int32{
+	1,  // 0: proto.CreateTransactionRequest.products:type_name -> proto.TransactionProduct
+	15, // 1: proto.CreateTransactionRequest.expected_total:type_name -> proto.Money
+	15, // 2: proto.CreateTransactionResponse.total_price:type_name -> proto.Money
+	15, // 3: proto.CreateTransactionResponse.discount:type_name -> proto.Money
+	12, // 4: proto.CreateTransactionResponse.breakdown:type_name -> proto.PriceBreakdown
+	11, // 5: proto.GetTransactionResponse.transaction:type_name -> proto.Transaction
+	11, // 6: proto.ListUserTransactionsResponse.transactions:type_name -> proto.Transaction
+	14, // 7: proto.ListUserTransactionsResponse.page_metadata:type_name -> proto.PageMetadata
+	11, // 8: proto.WatchTransactionResponse.transaction:type_name -> proto.Transaction
+	13, // 9: proto.Transaction.transaction_details:type_name -> proto.TransactionDetail
+	15, // 10: proto.Transaction.total_price:type_name -> proto.Money
+	15, // 11: proto.Transaction.discount:type_name -> proto.Money
+	12, // 12: proto.Transaction.breakdown:type_name -> proto.PriceBreakdown
+	15, // 13: proto.PriceBreakdown.subtotal:type_name -> proto.Money
+	15, // 14: proto.PriceBreakdown.discount:type_name -> proto.Money
+	15, // 15: proto.PriceBreakdown.tax:type_name -> proto.Money
+	15, // 16: proto.PriceBreakdown.service_fee:type_name -> proto.Money
+	15, // 17: proto.PriceBreakdown.payment_fee:type_name -> proto.Money
+	15, // 18: proto.TransactionDetail.price:type_name -> proto.Money
+	15, // 19: proto.TransactionDetail.discount:type_name -> proto.Money
+	15, // 20: proto.TransactionDetail.tax:type_name -> proto.Money
+	0,  // 21: proto.TransactionService.CreateTransaction:input_type -> proto.CreateTransactionRequest
+	2,  // 22: proto.TransactionService.GetTransaction:input_type -> proto.GetTransactionRequest
+	3,  // 23: proto.TransactionService.ListUserTransactions:input_type -> proto.ListUserTransactionsRequest
+	4,  // 24: proto.TransactionService.CancelTransaction:input_type -> proto.CancelTransactionRequest
+	5,  // 25: proto.TransactionService.WatchTransaction:input_type -> proto.WatchTransactionRequest
+	6,  // 26: proto.TransactionService.CreateTransaction:output_type -> proto.CreateTransactionResponse
+	7,  // 27: proto.TransactionService.GetTransaction:output_type -> proto.GetTransactionResponse
+	8,  // 28: proto.TransactionService.ListUserTransactions:output_type -> proto.ListUserTransactionsResponse
+	9,  // 29: proto.TransactionService.CancelTransaction:output_type -> proto.CancelTransactionResponse
+	10, // 30: proto.TransactionService.WatchTransaction:output_type -> proto.WatchTransactionResponse
+	26, // [26:31] is the sub-list for method output_type
+	21, // [21:26] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_transaction_proto_init() }
+func file_transaction_proto_init() {
+	if File_transaction_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transaction_proto_rawDesc), len(file_transaction_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_transaction_proto_goTypes,
+		DependencyIndexes: file_transaction_proto_depIdxs,
+		MessageInfos:      file_transaction_proto_msgTypes,
+	}.Build()
+	File_transaction_proto = out.File
+	file_transaction_proto_goTypes = nil
+	file_transaction_proto_depIdxs = nil
+}
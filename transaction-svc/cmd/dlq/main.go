@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go-saga-pattern/commoner/dlq"
+	"go-saga-pattern/commoner/logs"
+
+	"go-saga-pattern/transaction-svc/internal/config"
+
+	"os/signal"
+	"syscall"
+
+	"github.com/bytedance/sonic"
+)
+
+const usage = `Usage: dlq <command> [flags]
+
+Commands:
+  list     [-stream name] [-from sequence] [-limit n]   list dead letters
+  inspect  [-stream name] <sequence>                    show one dead letter with its headers and payload
+  replay   [-stream name] <sequence>                    re-publish a dead letter to its original subject
+
+Streams: TRANSACTION_DLQ (product-svc TransactionConsumer), WEBHOOK_NOTIFY_DLQ (transaction-svc WebhookConsumer)
+`
+
+func run(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing command")
+	}
+
+	command := args[0]
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	stream := flags.String("stream", "TRANSACTION_DLQ", "dead letter stream name")
+	fromSequence := flags.Uint64("from", 0, "first stream sequence to list")
+	limit := flags.Int("limit", 20, "maximum number of dead letters to list")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	logger, _ := logs.NewLogger()
+	js := config.NewJetStream(logger)
+	deadLetterQueue := dlq.NewDeadLetterQueue(js, *stream)
+
+	switch command {
+	case "list":
+		entries, err := deadLetterQueue.List(ctx, *fromSequence, *limit)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			fmt.Printf("%d\t%s\t%s\tdeliveries=%d\t%s\n", entry.Sequence, entry.FailedAt, entry.OriginalSubject,
+				entry.DeliveryCount, entry.FailureReason)
+		}
+		return nil
+
+	case "inspect", "replay":
+		if flags.NArg() != 1 {
+			return fmt.Errorf("%s expects exactly one sequence", command)
+		}
+
+		sequence, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid sequence %q: %w", flags.Arg(0), err)
+		}
+
+		var entry *dlq.Entry
+		if command == "inspect" {
+			entry, err = deadLetterQueue.Get(ctx, sequence)
+		} else {
+			entry, err = deadLetterQueue.Replay(ctx, sequence)
+		}
+		if err != nil {
+			return err
+		}
+
+		output, err := sonic.ConfigStd.MarshalIndent(entry, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(output))
+		if command == "replay" {
+			fmt.Printf("replayed %s:%d to %s\n", *stream, sequence, entry.OriginalSubject)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n%s", err, usage)
+		stop()
+		os.Exit(1)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"go-saga-pattern/commoner/discovery"
 	"go-saga-pattern/commoner/discovery/consul"
+	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
 
 	"go-saga-pattern/transaction-svc/internal/adapter"
@@ -65,7 +66,9 @@ func webServer(ctx context.Context) error {
 
 	go consul.StartHealthCheckLoop(ctx, registry, HTTPServiceID, serverConfig.ListenerSvcName+"-http", logger)
 
-	listenerUC := usecase.NewListenerUseCase(messagingAdapter, logger)
+	customValidator := helper.NewCustomValidator()
+
+	listenerUC := usecase.NewListenerUseCase(messagingAdapter, customValidator, logger)
 	listenerController := controller.NewListenerController(listenerUC, logger)
 
 	productRoute := route.NewListenerRoute(app, listenerController)
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/utils"
+
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// paymentsim is a local stand-in for Midtrans. Point the transaction service at it with
+// MIDTRANS_BASE_URL=http://<addr> and script outcomes through the /sim endpoints:
+//
+//	POST /sim/scenario                    {"order_id": "", "outcome": "settle", "delay_ms": 0, "notify": true, "failure": "", "fail_calls": 0}
+//	POST /sim/orders/{order_id}/{outcome} settle, deny, expire, cancel or pending, ?notify=false&delay_ms=n
+//	POST /sim/orders/{order_id}/notify    re-send the webhook for the current status
+//	GET  /sim/orders[/{order_id}]         inspect orders
+//	POST /sim/reset                       forget orders and the default scenario
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	defaultWebhookURL := utils.GetEnv("PAYMENT_SIM_WEBHOOK_URL")
+	if defaultWebhookURL == "" {
+		defaultWebhookURL = fmt.Sprintf("http://%s:%s/api/v1/transaction/webhook/notify",
+			utils.GetEnv("LISTENER_HTTP_ADDR"), utils.GetEnv("LISTENER_HTTP_PORT"))
+	}
+
+	defaultAddr := utils.GetEnv("PAYMENT_SIM_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = "localhost:8090"
+	}
+
+	addr := flag.String("addr", defaultAddr, "address the simulator listens on")
+	webhookURL := flag.String("webhook", defaultWebhookURL, "listener webhook the signed notifications are sent to")
+	serverKey := flag.String("server-key", utils.GetEnv("MIDTRANS_DEV_SERVER_KEY"), "server key used for basic auth and signature_key")
+	timeoutDelay := flag.Duration("timeout-delay", 30*time.Second, "how long an injected timeout hangs before answering")
+	flag.Parse()
+
+	logger, _ := logs.NewLogger()
+
+	if *serverKey == "" {
+		logger.Error("server key is empty, set MIDTRANS_DEV_SERVER_KEY or -server-key")
+		os.Exit(1)
+	}
+
+	simulator := newSimulator(*serverKey, "http://"+*addr, *webhookURL, *timeoutDelay, logger)
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           simulator.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutting down payment simulator...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shutdown payment simulator", zap.Error(err))
+		}
+	}()
+
+	logger.Info("Payment simulator listening", zap.String("addr", *addr), zap.String("webhook", *webhookURL))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Payment simulator stopped", zap.Error(err))
+		stop()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,566 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Outcomes a scenario can drive an order to, named after the Midtrans transaction_status they produce.
+const (
+	outcomeSettle  = "settle"
+	outcomeDeny    = "deny"
+	outcomeExpire  = "expire"
+	outcomeCancel  = "cancel"
+	outcomePending = "pending"
+)
+
+// API failures a scenario can inject into the Snap and Core API calls of an order.
+const (
+	failureError   = "error"
+	failureTimeout = "timeout"
+)
+
+// scenario scripts what happens to an order after its Snap transaction is created.
+type scenario struct {
+	// Outcome is applied DelayMs after the Snap transaction is created, pending leaves the order untouched.
+	Outcome string `json:"outcome"`
+	DelayMs int64  `json:"delay_ms"`
+	// Notify fires the signed webhook when the outcome is applied, defaults to true.
+	Notify *bool `json:"notify,omitempty"`
+	// Failure makes the API calls of the order answer 500 (error) or hang (timeout),
+	// for the first FailCalls calls only when FailCalls is set.
+	Failure   string `json:"failure,omitempty"`
+	FailCalls int    `json:"fail_calls,omitempty"`
+}
+
+func (s scenario) notify() bool {
+	return s.Notify == nil || *s.Notify
+}
+
+type order struct {
+	OrderID           string   `json:"order_id"`
+	TransactionID     string   `json:"transaction_id"`
+	GrossAmount       string   `json:"gross_amount"`
+	Token             string   `json:"token,omitempty"`
+	Created           bool     `json:"created"`
+	TransactionStatus string   `json:"transaction_status,omitempty"`
+	FraudStatus       string   `json:"fraud_status,omitempty"`
+	StatusCode        string   `json:"status_code,omitempty"`
+	TransactionTime   string   `json:"transaction_time,omitempty"`
+	SettlementTime    string   `json:"settlement_time,omitempty"`
+	Scenario          scenario `json:"scenario"`
+	FailedCalls       int      `json:"failed_calls"`
+	Notifications     int      `json:"notifications"`
+}
+
+type simulator struct {
+	mu              sync.Mutex
+	orders          map[string]*order
+	defaultScenario scenario
+	serverKey       string
+	baseURL         string
+	webhookURL      string
+	timeoutDelay    time.Duration
+	location        *time.Location
+	httpClient      *http.Client
+	logger          *zap.Logger
+}
+
+func newSimulator(serverKey, baseURL, webhookURL string, timeoutDelay time.Duration, logger *zap.Logger) *simulator {
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		logger.Warn("failed to load location Asia/Jakarta, using local time", zap.Error(err))
+		location = time.Local
+	}
+
+	return &simulator{
+		orders:          make(map[string]*order),
+		defaultScenario: scenario{Outcome: outcomePending},
+		serverKey:       serverKey,
+		baseURL:         baseURL,
+		webhookURL:      webhookURL,
+		timeoutDelay:    timeoutDelay,
+		location:        location,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+		logger:          logger,
+	}
+}
+
+func (s *simulator) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// endpoints used by midtrans-go, see config.NewMidtransClient with MIDTRANS_BASE_URL
+	mux.HandleFunc("POST /snap/v1/transactions", s.authorized(s.createTransaction))
+	mux.HandleFunc("GET /v2/{order_id}/status", s.authorized(s.transactionStatus))
+	mux.HandleFunc("POST /v2/{order_id}/cancel", s.authorized(s.cancelTransaction))
+	mux.HandleFunc("POST /v2/{order_id}/refund", s.authorized(s.refundTransaction))
+
+	// endpoints used by tests to script and inspect the simulator
+	mux.HandleFunc("POST /sim/scenario", s.setScenario)
+	mux.HandleFunc("GET /sim/orders", s.listOrders)
+	mux.HandleFunc("GET /sim/orders/{order_id}", s.getOrder)
+	mux.HandleFunc("POST /sim/orders/{order_id}/notify", s.resendNotification)
+	mux.HandleFunc("POST /sim/orders/{order_id}/{outcome}", s.applyOutcome)
+	mux.HandleFunc("POST /sim/reset", s.reset)
+
+	return mux
+}
+
+func (s *simulator) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serverKey, _, ok := r.BasicAuth()
+		if !ok || serverKey != s.serverKey {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{
+				"status_code":    "401",
+				"status_message": "Access denied due to unauthorized transaction, please check client or server key",
+			})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *simulator) createTransaction(w http.ResponseWriter, r *http.Request) {
+	request := new(struct {
+		TransactionDetails struct {
+			OrderID     string `json:"order_id"`
+			GrossAmount int64  `json:"gross_amount"`
+		} `json:"transaction_details"`
+	})
+	if err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(request); err != nil || request.TransactionDetails.OrderID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"status_code":    "400",
+			"error_messages": []string{"transaction_details.order_id is required"},
+		})
+		return
+	}
+
+	orderID := request.TransactionDetails.OrderID
+
+	s.mu.Lock()
+	current, ok := s.orders[orderID]
+	if !ok {
+		current = &order{
+			OrderID:       orderID,
+			TransactionID: uuid.NewString(),
+			GrossAmount:   strconv.FormatInt(request.TransactionDetails.GrossAmount, 10) + ".00",
+			Scenario:      s.defaultScenario,
+		}
+		s.orders[orderID] = current
+	}
+
+	if current.Created {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"status_code":    "400",
+			"error_messages": []string{"transaction_details.order_id has already been taken"},
+		})
+		return
+	}
+
+	failure := s.nextFailure(current)
+	if failure == "" {
+		current.Created = true
+		current.Token = uuid.NewString()
+		current.TransactionStatus = "pending"
+		current.StatusCode = "201"
+		current.TransactionTime = s.now()
+	}
+	snapshot := *current
+	s.mu.Unlock()
+
+	if s.fail(w, r, failure, orderID) {
+		return
+	}
+
+	s.logger.Info("snap transaction created", zap.String("order_id", orderID), zap.String("outcome", snapshot.Scenario.Outcome),
+		zap.Int64("delay_ms", snapshot.Scenario.DelayMs))
+
+	if snapshot.Scenario.Outcome != "" && snapshot.Scenario.Outcome != outcomePending {
+		go func() {
+			time.Sleep(time.Duration(snapshot.Scenario.DelayMs) * time.Millisecond)
+			if _, err := s.apply(orderID, snapshot.Scenario.Outcome, snapshot.Scenario.notify()); err != nil {
+				s.logger.Error("failed to apply scripted outcome", zap.String("order_id", orderID), zap.Error(err))
+			}
+		}()
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"token":        snapshot.Token,
+		"redirect_url": fmt.Sprintf("%s/snap/v4/redirection/%s", s.baseURL, snapshot.Token),
+	})
+}
+
+func (s *simulator) transactionStatus(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("order_id")
+
+	s.mu.Lock()
+	current, ok := s.orders[orderID]
+	if !ok || !current.Created {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"status_code":    "404",
+			"status_message": "Transaction doesn't exist.",
+			"id":             uuid.NewString(),
+		})
+		return
+	}
+	failure := s.nextFailure(current)
+	notification := s.notification(current)
+	s.mu.Unlock()
+
+	if s.fail(w, r, failure, orderID) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, notification)
+}
+
+func (s *simulator) cancelTransaction(w http.ResponseWriter, r *http.Request) {
+	notification, err := s.apply(r.PathValue("order_id"), outcomeCancel, true)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"status_code": "404", "status_message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, notification)
+}
+
+func (s *simulator) refundTransaction(w http.ResponseWriter, r *http.Request) {
+	request := new(struct {
+		RefundKey string `json:"refund_key"`
+		Amount    int64  `json:"amount"`
+		Reason    string `json:"reason"`
+	})
+	_ = sonic.ConfigDefault.NewDecoder(r.Body).Decode(request)
+
+	orderID := r.PathValue("order_id")
+
+	s.mu.Lock()
+	current, ok := s.orders[orderID]
+	if !ok || current.TransactionStatus != "settlement" {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusPreconditionFailed, map[string]any{
+			"status_code":    "412",
+			"status_message": "Merchant cannot modify the status of the transaction",
+		})
+		return
+	}
+
+	grossAmount, _ := strconv.ParseFloat(current.GrossAmount, 64)
+	if request.Amount == 0 || float64(request.Amount) >= grossAmount {
+		current.TransactionStatus = "refund"
+	} else {
+		current.TransactionStatus = "partial_refund"
+	}
+	current.StatusCode = "200"
+	notification := s.notification(current)
+	notification["refund_key"] = request.RefundKey
+	notification["refund_amount"] = strconv.FormatInt(request.Amount, 10) + ".00"
+	notification["status_message"] = "Success, refund request is approved"
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, notification)
+}
+
+func (s *simulator) setScenario(w http.ResponseWriter, r *http.Request) {
+	request := new(struct {
+		OrderID string `json:"order_id"`
+		scenario
+	})
+	if err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+
+	if err := validateScenario(request.scenario); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// without an order id the scenario applies to every order created from now on
+	if request.OrderID == "" {
+		s.defaultScenario = request.scenario
+		writeJSON(w, http.StatusOK, s.defaultScenario)
+		return
+	}
+
+	current, ok := s.orders[request.OrderID]
+	if !ok {
+		current = &order{OrderID: request.OrderID, TransactionID: uuid.NewString()}
+		s.orders[request.OrderID] = current
+	}
+	current.Scenario = request.scenario
+	current.FailedCalls = 0
+	writeJSON(w, http.StatusOK, current)
+}
+
+func (s *simulator) listOrders(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	orders := make([]order, 0, len(s.orders))
+	for _, current := range s.orders {
+		orders = append(orders, *current)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i].TransactionTime < orders[j].TransactionTime })
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (s *simulator) getOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	current, ok := s.orders[r.PathValue("order_id")]
+	var snapshot order
+	if ok {
+		snapshot = *current
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "order not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// applyOutcome drives an order to an outcome right away, ?notify=false skips the webhook and ?delay_ms= postpones it.
+func (s *simulator) applyOutcome(w http.ResponseWriter, r *http.Request) {
+	orderID, outcome := r.PathValue("order_id"), r.PathValue("outcome")
+	if err := validateScenario(scenario{Outcome: outcome}); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+
+	notify := r.URL.Query().Get("notify") != "false"
+	delayMs, _ := strconv.ParseInt(r.URL.Query().Get("delay_ms"), 10, 64)
+	if delayMs > 0 {
+		go func() {
+			time.Sleep(time.Duration(delayMs) * time.Millisecond)
+			if _, err := s.apply(orderID, outcome, notify); err != nil {
+				s.logger.Error("failed to apply delayed outcome", zap.String("order_id", orderID), zap.Error(err))
+			}
+		}()
+		writeJSON(w, http.StatusAccepted, map[string]any{"order_id": orderID, "outcome": outcome, "delay_ms": delayMs})
+		return
+	}
+
+	notification, err := s.apply(orderID, outcome, notify)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, notification)
+}
+
+func (s *simulator) resendNotification(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("order_id")
+
+	s.mu.Lock()
+	current, ok := s.orders[orderID]
+	if !ok || !current.Created {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "order not found"})
+		return
+	}
+	notification := s.notification(current)
+	current.Notifications++
+	s.mu.Unlock()
+
+	if err := s.sendNotification(r.Context(), notification); err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, notification)
+}
+
+func (s *simulator) reset(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.orders = make(map[string]*order)
+	s.defaultScenario = scenario{Outcome: outcomePending}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply moves an order to the Midtrans status of outcome and fires the webhook when notify is set.
+func (s *simulator) apply(orderID, outcome string, notify bool) (map[string]any, error) {
+	s.mu.Lock()
+	current, ok := s.orders[orderID]
+	if !ok || !current.Created {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+
+	current.FraudStatus, current.SettlementTime = "", ""
+	switch outcome {
+	case outcomeSettle:
+		current.TransactionStatus, current.StatusCode, current.FraudStatus = "settlement", "200", "accept"
+		current.SettlementTime = s.now()
+	case outcomeDeny:
+		current.TransactionStatus, current.StatusCode, current.FraudStatus = "deny", "202", "deny"
+	case outcomeExpire:
+		current.TransactionStatus, current.StatusCode = "expire", "407"
+	case outcomeCancel:
+		current.TransactionStatus, current.StatusCode = "cancel", "200"
+	case outcomePending:
+		current.TransactionStatus, current.StatusCode = "pending", "201"
+	}
+	notification := s.notification(current)
+	if notify {
+		current.Notifications++
+	}
+	s.mu.Unlock()
+
+	s.logger.Info("order outcome applied", zap.String("order_id", orderID), zap.String("transaction_status", notification["transaction_status"].(string)))
+
+	if notify {
+		if err := s.sendNotification(context.Background(), notification); err != nil {
+			s.logger.Error("failed to send webhook notification", zap.String("order_id", orderID), zap.Error(err))
+		}
+	}
+
+	return notification, nil
+}
+
+// notification renders an order the way Midtrans sends it in HTTP notifications and status responses,
+// signature_key is SHA512(order_id+status_code+gross_amount+server_key). Callers must hold s.mu.
+func (s *simulator) notification(current *order) map[string]any {
+	hash := sha512.New()
+	hash.Write([]byte(current.OrderID + current.StatusCode + current.GrossAmount + s.serverKey))
+
+	notification := map[string]any{
+		"transaction_time":   current.TransactionTime,
+		"transaction_status": current.TransactionStatus,
+		"transaction_id":     current.TransactionID,
+		"status_message":     "midtrans payment notification",
+		"status_code":        current.StatusCode,
+		"signature_key":      hex.EncodeToString(hash.Sum(nil)),
+		"payment_type":       "bank_transfer",
+		"order_id":           current.OrderID,
+		"merchant_id":        "PAYMENTSIM",
+		"gross_amount":       current.GrossAmount,
+		"currency":           "IDR",
+	}
+	if current.FraudStatus != "" {
+		notification["fraud_status"] = current.FraudStatus
+	}
+	if current.SettlementTime != "" {
+		notification["settlement_time"] = current.SettlementTime
+	}
+
+	return notification
+}
+
+func (s *simulator) sendNotification(ctx context.Context, notification map[string]any) error {
+	payload, err := sonic.ConfigFastest.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+
+	s.logger.Info("webhook notification sent", zap.Any("order_id", notification["order_id"]), zap.Any("transaction_status", notification["transaction_status"]))
+	return nil
+}
+
+// nextFailure returns the failure to inject into the current API call of an order. Callers must hold s.mu.
+func (s *simulator) nextFailure(current *order) string {
+	if current.Scenario.Failure == "" {
+		return ""
+	}
+	if current.Scenario.FailCalls > 0 && current.FailedCalls >= current.Scenario.FailCalls {
+		return ""
+	}
+	current.FailedCalls++
+	return current.Scenario.Failure
+}
+
+// fail writes the injected failure and reports whether the call was failed.
+func (s *simulator) fail(w http.ResponseWriter, r *http.Request, failure, orderID string) bool {
+	switch failure {
+	case failureError:
+		s.logger.Info("injecting error", zap.String("order_id", orderID), zap.String("path", r.URL.Path))
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"status_code":    "500",
+			"status_message": "Sorry. Our system is recovering from unexpected issues. Please retry.",
+		})
+		return true
+	case failureTimeout:
+		s.logger.Info("injecting timeout", zap.String("order_id", orderID), zap.String("path", r.URL.Path))
+		select {
+		case <-r.Context().Done():
+		case <-time.After(s.timeoutDelay):
+		}
+		writeJSON(w, http.StatusGatewayTimeout, map[string]any{
+			"status_code":    "504",
+			"status_message": "Gateway timeout",
+		})
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *simulator) now() string {
+	return time.Now().In(s.location).Format("2006-01-02 15:04:05")
+}
+
+func validateScenario(scenario scenario) error {
+	switch scenario.Outcome {
+	case outcomeSettle, outcomeDeny, outcomeExpire, outcomeCancel, outcomePending, "":
+	default:
+		return fmt.Errorf("unknown outcome %q", scenario.Outcome)
+	}
+
+	switch scenario.Failure {
+	case failureError, failureTimeout, "":
+	default:
+		return fmt.Errorf("unknown failure %q", scenario.Failure)
+	}
+
+	if scenario.DelayMs < 0 || scenario.FailCalls < 0 {
+		return fmt.Errorf("delay_ms and fail_calls must not be negative")
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	payload, err := sonic.ConfigFastest.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(payload)
+}
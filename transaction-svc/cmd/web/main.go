@@ -7,28 +7,36 @@ import (
 
 	"go-saga-pattern/commoner/discovery"
 	"go-saga-pattern/commoner/discovery/consul"
+	"go-saga-pattern/commoner/dlq"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
 
 	"go-saga-pattern/transaction-svc/internal/adapter"
 	"go-saga-pattern/transaction-svc/internal/config"
+	grpcHandler "go-saga-pattern/transaction-svc/internal/delivery/grpc/handler"
 	"go-saga-pattern/transaction-svc/internal/delivery/web/controller"
 	"go-saga-pattern/transaction-svc/internal/delivery/web/middleware"
 	"go-saga-pattern/transaction-svc/internal/delivery/web/route"
 	"go-saga-pattern/transaction-svc/internal/gateway/task"
+	"go-saga-pattern/transaction-svc/internal/pricing"
 	"go-saga-pattern/transaction-svc/internal/repository"
 	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
 	"go-saga-pattern/transaction-svc/internal/usecase"
 
+	"net"
 	"os/signal"
 	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 var (
-	app *fiber.App
+	grpcServer *grpc.Server
+	app        *fiber.App
 )
 
 func webServer(ctx context.Context) error {
@@ -38,20 +46,31 @@ func webServer(ctx context.Context) error {
 	logger, _ := logs.NewLogger()
 	db := config.NewPostgresDatabase()
 	defer db.Close()
-	js := config.NewJetStream(logger)
 	redis := config.NewRedisClient(logger)
+	js := config.NewJetStream(logger)
+	config.InitWebhookDLQStream(js, logger)
+	deadLetterQueue := dlq.NewDeadLetterQueue(js, "WEBHOOK_NOTIFY_DLQ")
 
 	asyncClient := config.NewAsyncConfig()
+	asyncInspector := config.NewAsyncInspector()
 	midtransClient := config.NewMidtransClient()
+	xenditClient := config.NewXenditClient()
 
 	databaseStore := store.NewDatabaseStore(db)
-	messagingAdapter := adapter.NewMessagingAdapter(js)
 	cacheAdapter := adapter.NewCacheAdapter(redis)
-	paymentAdapter := adapter.NewPaymentAdapter(midtransClient, cacheAdapter, logger)
+	statusAdapter := adapter.NewTransactionStatusAdapter(cacheAdapter, logger)
+	go statusAdapter.Listen(ctx)
 
 	customValidator := helper.NewCustomValidator()
 	timeParserHelper := helper.NewTimeParserHelper(logger)
 
+	paymentProviders := adapter.NewPaymentProviderRegistry(config.DefaultPaymentProvider(),
+		adapter.NewMidtransPaymentProvider(midtransClient, timeParserHelper, logger),
+		adapter.NewXenditPaymentProvider(xenditClient, timeParserHelper, logger),
+	)
+	exchangeRateProvider := adapter.NewStaticExchangeRateProvider(config.NewExchangeRateTable(logger))
+	checkoutPricing := pricing.NewCheckoutPipeline(config.CheckoutTaxRules(logger), config.CheckoutServiceFee(), config.CheckoutPaymentFees())
+
 	registry, err := consul.NewRegistry(serverConfig.ConsulAddr, serverConfig.TransactionSvcName)
 	if err != nil {
 		logger.Error("Failed to create consul registry for service" + err.Error())
@@ -60,6 +79,9 @@ func webServer(ctx context.Context) error {
 	HTTPServiceID := discovery.GenerateServiceID(serverConfig.TransactionSvcName + "-http")
 	httpPortInt, _ := strconv.Atoi(serverConfig.TransactionHTTPPort)
 
+	GRPCserviceID := discovery.GenerateServiceID(serverConfig.TransactionSvcName + "-grpc")
+	grpcPortInt, _ := strconv.Atoi(serverConfig.TransactionGRPCPort)
+
 	userAdapter, err := adapter.NewUserAdapter(ctx, registry, logger)
 	if err != nil {
 		logger.Error("Failed to create user adapter", zap.Error(err))
@@ -77,36 +99,95 @@ func webServer(ctx context.Context) error {
 		logger.Error("Failed to register transaction service to consul", zap.Error(err))
 	}
 
+	err = registry.RegisterService(ctx, serverConfig.TransactionSvcName+"-grpc", GRPCserviceID, serverConfig.TransactionGRPCAddr, grpcPortInt, []string{"grpc"})
+	if err != nil {
+		logger.Error("Failed to register transaction grpc service to consul", zap.Error(err))
+	}
+
 	go func() {
 		<-ctx.Done()
 		logger.Info("Context canceled. Deregistering services...")
 		registry.DeregisterService(context.Background(), HTTPServiceID)
+		registry.DeregisterService(context.Background(), GRPCserviceID)
 
 		logger.Info("Shutting down servers...")
 		if err := app.Shutdown(); err != nil {
 			logger.Error("Failed to shutdown app server", zap.Error(err))
 		}
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
 
 		logger.Info("Successfully shutdown...")
 	}()
 
 	go consul.StartHealthCheckLoop(ctx, registry, HTTPServiceID, serverConfig.TransactionSvcName+"-http", logger)
+	go consul.StartHealthCheckLoop(ctx, registry, GRPCserviceID, serverConfig.TransactionSvcName+"-grpc", logger)
 
 	transactionRepo := repository.NewTransactionRepository()
 	transactionTransactionRepo := repository.NewTransactionDetailRepository()
+	outboxRepo := repository.NewOutboxRepository()
+	sagaRepo := repository.NewSagaRepository()
+	refundRepo := repository.NewRefundRepository()
+	idempotencyRepo := repository.NewIdempotencyRepository()
+	cartRepo := repository.NewCartRepository()
+	promotionRepo := repository.NewPromotionRepository()
+
+	transactionTask := task.NewTransactionTask(asyncClient, asyncInspector)
+	sagaOrchestrator := saga.NewOrchestrator(databaseStore, sagaRepo, logger)
+
+	transactionUC := usecase.NewTransactionUseCase(transactionRepo, transactionTransactionRepo, promotionRepo, outboxRepo, databaseStore, sagaOrchestrator,
+		productAdapter, paymentProviders, cacheAdapter, statusAdapter, transactionTask, timeParserHelper, config.CheckoutPriceTolerance(),
+		exchangeRateProvider, config.CheckoutCurrencyPolicy(), checkoutPricing, customValidator, logger)
+
+	refundUC := usecase.NewRefundUseCase(transactionRepo, transactionTransactionRepo, refundRepo, outboxRepo, databaseStore, sagaOrchestrator,
+		productAdapter, paymentProviders, statusAdapter, customValidator, logger)
+	cancelationUC := usecase.NewCancelationUseCase(databaseStore, transactionRepo, outboxRepo, sagaOrchestrator,
+		paymentProviders, statusAdapter, transactionTask, logger)
+	idempotencyUC := usecase.NewIdempotencyUseCase(databaseStore, idempotencyRepo, cacheAdapter, logger)
+	deadLetterUC := dlq.NewDeadLetterUseCase(deadLetterQueue, customValidator, logger)
+	promotionUC := usecase.NewPromotionUseCase(databaseStore, promotionRepo, customValidator, logger)
+	cartUC := usecase.NewCartUseCase(databaseStore, cartRepo, productAdapter, cacheAdapter, transactionUC, customValidator, logger)
 
-	transactionTask := task.NewTransactionTask(asyncClient)
+	go func() {
+		grpcServer = grpc.NewServer()
+		reflection.Register(grpcServer)
+		l, err := net.Listen("tcp", fmt.Sprintf("%s:%s", serverConfig.TransactionGRPCAddr, serverConfig.TransactionGRPCPort))
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to listen: %v", err))
+			return
+		}
+
+		defer l.Close()
 
-	transactionUC := usecase.NewTransactionUseCase(transactionRepo, transactionTransactionRepo, databaseStore, productAdapter, messagingAdapter,
-		paymentAdapter, cacheAdapter, transactionTask, timeParserHelper, customValidator, logger)
+		grpcHandler.NewTransactionHandler(grpcServer, transactionUC, cancelationUC, statusAdapter, logger)
+
+		if err := grpcServer.Serve(l); err != nil {
+			logger.Error(fmt.Sprintf("Failed to start gRPC server: %v", err))
+		}
+	}()
 
-	transactionController := controller.NewTransactionController(transactionUC, logger)
+	transactionController := controller.NewTransactionController(transactionUC, cancelationUC, idempotencyUC, statusAdapter, logger)
+	refundController := controller.NewRefundController(refundUC, logger)
+	deadLetterController := dlq.NewDeadLetterController(deadLetterUC, logger)
+	promotionController := controller.NewPromotionController(promotionUC, logger)
+	cartController := controller.NewCartController(cartUC, logger)
 
 	userMiddleware := middleware.NewUserAuth(userAdapter, logger)
 
 	TransactionRoute := route.NewTransactionRoute(app, transactionController, userMiddleware)
 	TransactionRoute.RegisterRoutes()
 
+	refundRoute := route.NewRefundRoute(app, refundController, userMiddleware)
+	refundRoute.RegisterRoutes()
+
+	cartRoute := route.NewCartRoute(app, cartController, userMiddleware)
+	cartRoute.RegisterRoutes()
+
+	adminMiddleware := helper.NewAdminAuth(logger)
+	adminRoute := route.NewAdminRoute(app, deadLetterController, promotionController, adminMiddleware)
+	adminRoute.RegisterRoutes()
+
 	serverErrors := make(chan error, 1)
 
 	go func() {
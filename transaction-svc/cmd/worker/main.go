@@ -2,25 +2,33 @@ package main
 
 import (
 	"context"
+	_ "expvar"
 	"fmt"
+	"net/http"
 
+	"go-saga-pattern/commoner/dlq"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/utils"
 
 	"go-saga-pattern/transaction-svc/internal/adapter"
 	"go-saga-pattern/transaction-svc/internal/config"
 	consumer "go-saga-pattern/transaction-svc/internal/delivery/consumer/webhook"
+	"go-saga-pattern/transaction-svc/internal/delivery/relay"
 	"go-saga-pattern/transaction-svc/internal/delivery/scheduler"
 	taskhandler "go-saga-pattern/transaction-svc/internal/delivery/task"
 	"go-saga-pattern/transaction-svc/internal/gateway/task"
+	"go-saga-pattern/transaction-svc/internal/pricing"
 	"go-saga-pattern/transaction-svc/internal/repository"
 	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
 	"go-saga-pattern/transaction-svc/internal/usecase"
 
 	"os/signal"
 	"syscall"
 
 	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
 )
 
 // ISSUE : nil in some usecase (i think it was not a good idea and best practice)
@@ -31,43 +39,82 @@ func worker(ctx context.Context) error {
 	js := config.NewJetStream(logger)
 	redis := config.NewRedisClient(logger)
 	asyncClient := config.NewAsyncConfig()
+	asyncInspector := config.NewAsyncInspector()
 	midtransClient := config.NewMidtransClient()
+	xenditClient := config.NewXenditClient()
 	goCronConfig := config.NewGocron(logger)
-	asynqServer := config.NewAsynqServer()
+	asynqServer := config.NewAsynqServer(task.RetryDelay)
 
 	config.DeleteWebhookStream(js, logger)
 	config.InitWebhookStream(js, logger)
+	config.InitWebhookDLQStream(js, logger)
 
 	databaseStore := store.NewDatabaseStore(db)
 	messagingAdapter := adapter.NewMessagingAdapter(js)
+	deadLetterQueue := dlq.NewDeadLetterQueue(js, "WEBHOOK_NOTIFY_DLQ")
 	cacheAdapter := adapter.NewCacheAdapter(redis)
-	paymentAdapter := adapter.NewPaymentAdapter(midtransClient, cacheAdapter, logger)
+	statusAdapter := adapter.NewTransactionStatusAdapter(cacheAdapter, logger)
 
 	customValidator := helper.NewCustomValidator()
 	timeParserHelper := helper.NewTimeParserHelper(logger)
 
+	paymentProviders := adapter.NewPaymentProviderRegistry(config.DefaultPaymentProvider(),
+		adapter.NewMidtransPaymentProvider(midtransClient, timeParserHelper, logger),
+		adapter.NewXenditPaymentProvider(xenditClient, timeParserHelper, logger),
+	)
+	exchangeRateProvider := adapter.NewStaticExchangeRateProvider(config.NewExchangeRateTable(logger))
+	checkoutPricing := pricing.NewCheckoutPipeline(config.CheckoutTaxRules(logger), config.CheckoutServiceFee(), config.CheckoutPaymentFees())
+
 	transactionRepo := repository.NewTransactionRepository()
 	transactionTransactionRepo := repository.NewTransactionDetailRepository()
-
-	transactionTask := task.NewTransactionTask(asyncClient)
-
-	transactionUC := usecase.NewTransactionUseCase(transactionRepo, transactionTransactionRepo, databaseStore, nil, messagingAdapter,
-		paymentAdapter, cacheAdapter, transactionTask, timeParserHelper, customValidator, logger)
-	cancelationUC := usecase.NewCancelationUseCase(databaseStore, transactionRepo, messagingAdapter, logger)
-	schedulerUC := usecase.NewSchedulerUseCase(databaseStore, transactionRepo, transactionUC, cancelationUC, paymentAdapter, logger)
-
-	transactionConsumer := consumer.NewWebhookConsumer(transactionUC, js, logger)
+	outboxRepo := repository.NewOutboxRepository()
+	sagaRepo := repository.NewSagaRepository()
+	refundRepo := repository.NewRefundRepository()
+	idempotencyRepo := repository.NewIdempotencyRepository()
+	cartRepo := repository.NewCartRepository()
+	promotionRepo := repository.NewPromotionRepository()
+
+	transactionTask := task.NewTransactionTask(asyncClient, asyncInspector)
+	sagaOrchestrator := saga.NewOrchestrator(databaseStore, sagaRepo, logger)
+
+	transactionUC := usecase.NewTransactionUseCase(transactionRepo, transactionTransactionRepo, promotionRepo, outboxRepo, databaseStore, sagaOrchestrator, nil,
+		paymentProviders, cacheAdapter, statusAdapter, transactionTask, timeParserHelper, config.CheckoutPriceTolerance(),
+		exchangeRateProvider, config.CheckoutCurrencyPolicy(), checkoutPricing, customValidator, logger)
+	cancelationUC := usecase.NewCancelationUseCase(databaseStore, transactionRepo, outboxRepo, sagaOrchestrator,
+		paymentProviders, statusAdapter, transactionTask, logger)
+	refundUC := usecase.NewRefundUseCase(transactionRepo, transactionTransactionRepo, refundRepo, outboxRepo, databaseStore, sagaOrchestrator, nil,
+		paymentProviders, statusAdapter, customValidator, logger)
+	outboxUC := usecase.NewOutboxUseCase(databaseStore, outboxRepo, messagingAdapter, logger)
+	idempotencyUC := usecase.NewIdempotencyUseCase(databaseStore, idempotencyRepo, cacheAdapter, logger)
+	cartUC := usecase.NewCartUseCase(databaseStore, cartRepo, nil, cacheAdapter, transactionUC, customValidator, logger)
+	schedulerUC := usecase.NewSchedulerUseCase(databaseStore, transactionRepo, transactionUC, cancelationUC, refundUC, idempotencyUC, cartUC,
+		paymentProviders, sagaOrchestrator, logger)
+
+	transactionConsumer := consumer.NewWebhookConsumer(transactionUC, js, deadLetterQueue, logger)
 	go transactionConsumer.Start(ctx)
 	serverErrors := make(chan error, 1)
 
+	outboxRelay := relay.NewOutboxRelay(outboxUC, logger)
+	go outboxRelay.Start(ctx)
+
+	// expvar registers /debug/vars on the default mux, which carries the outbox lag metrics
+	if metricsAddr := utils.GetEnv("WORKER_METRICS_ADDR"); metricsAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(metricsAddr, nil); err != nil {
+				logger.Error("Failed to start worker metrics server", zap.Error(err))
+			}
+		}()
+	}
+
 	schedulerRunner := scheduler.NewSchedulerRunner(goCronConfig, schedulerUC, logger)
 	go schedulerRunner.Start()
 
-	expireTaskHandler := taskhandler.NewTransactionTaskHandler(cancelationUC, logger)
+	expireTaskHandler := taskhandler.NewTransactionTaskHandler(transactionUC, cancelationUC, logger)
 
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(task.TypeTransactionExpire, expireTaskHandler.HandleExpire)
 	mux.HandleFunc(task.TypeTransactionExpireFinal, expireTaskHandler.HandleFinalExpire)
+	mux.HandleFunc(task.TypeTransactionPaymentToken, expireTaskHandler.HandlePaymentToken)
 	go func() {
 		serverErrors <- asynqServer.Run(mux)
 	}()
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox_events (
+	id UUID NOT NULL default uuid_generate_v4(),
+	aggregate_id UUID NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	payload JSONB NOT NULL,
+	-- PENDING, SENT, FAILED
+	status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	sent_at TIMESTAMPTZ,
+	PRIMARY KEY(id)
+);COMMENT ON COLUMN outbox_events.status IS 'PENDING, SENT, FAILED';
+
+CREATE INDEX idx_outbox_events_status_next_attempt_at ON outbox_events (status, next_attempt_at, created_at);
+CREATE INDEX idx_outbox_events_aggregate_id ON outbox_events (aggregate_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox_events;
+DROP INDEX IF EXISTS idx_outbox_events_status_next_attempt_at;
+DROP INDEX IF EXISTS idx_outbox_events_aggregate_id;
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS saga_instances (
+	id UUID NOT NULL default uuid_generate_v4(),
+	saga_type VARCHAR(100) NOT NULL,
+	aggregate_id UUID NOT NULL,
+	-- RUNNING, WAITING, COMPLETED, COMPENSATING, COMPENSATED
+	status VARCHAR(20) NOT NULL DEFAULT 'RUNNING',
+	current_step VARCHAR(100),
+	payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+	last_error TEXT,
+	next_run_at TIMESTAMPTZ,
+	locked_until TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(id),
+	UNIQUE(saga_type, aggregate_id)
+);COMMENT ON COLUMN saga_instances.status IS 'RUNNING, WAITING, COMPLETED, COMPENSATING, COMPENSATED';
+
+CREATE INDEX idx_saga_instances_status_next_run_at ON saga_instances (status, next_run_at);
+
+CREATE TABLE IF NOT EXISTS saga_steps (
+	id UUID NOT NULL default uuid_generate_v4(),
+	saga_instance_id UUID NOT NULL REFERENCES saga_instances(id) ON DELETE CASCADE,
+	step_name VARCHAR(100) NOT NULL,
+	step_order INTEGER NOT NULL,
+	-- PENDING, RUNNING, WAITING, RETRYING, COMPLETED, FAILED, REJECTED, COMPENSATED
+	status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_retry_at TIMESTAMPTZ,
+	started_at TIMESTAMPTZ,
+	completed_at TIMESTAMPTZ,
+	compensated_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(id),
+	UNIQUE(saga_instance_id, step_name)
+);COMMENT ON COLUMN saga_steps.status IS 'PENDING, RUNNING, WAITING, RETRYING, COMPLETED, FAILED, REJECTED, COMPENSATED';
+
+CREATE INDEX idx_saga_steps_saga_instance_id ON saga_steps (saga_instance_id, step_order);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS saga_steps;
+DROP TABLE IF EXISTS saga_instances;
+DROP INDEX IF EXISTS idx_saga_steps_saga_instance_id;
+DROP INDEX IF EXISTS idx_saga_instances_status_next_run_at;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- external_status menyimpan status mentah dari masing-masing payment provider, jadi tidak lagi dibatasi enum Midtrans
+ALTER TABLE transactions ALTER COLUMN external_status TYPE VARCHAR(50) USING external_status::text;
+DROP TYPE IF EXISTS external_status;
+
+ALTER TABLE transactions
+	ADD COLUMN IF NOT EXISTS payment_provider VARCHAR(50) NOT NULL DEFAULT 'MIDTRANS',
+	ADD COLUMN IF NOT EXISTS payment_reference VARCHAR(255);
+
+COMMENT ON COLUMN transactions.payment_provider IS 'Payment gateway yang dipilih saat checkout (MIDTRANS, XENDIT)';
+COMMENT ON COLUMN transactions.payment_reference IS 'ID charge/invoice milik payment provider, kosong jika sama dengan order id';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+	DROP COLUMN IF EXISTS payment_reference,
+	DROP COLUMN IF EXISTS payment_provider;
+
+CREATE TYPE external_status AS ENUM (
+	'capture',
+	'settlement',
+	'pending',
+	'deny',
+	'cancel',
+	'expire',
+	'failure'
+);
+
+UPDATE transactions SET external_status = NULL
+WHERE external_status NOT IN ('capture', 'settlement', 'pending', 'deny', 'cancel', 'expire', 'failure');
+
+ALTER TABLE transactions ALTER COLUMN external_status TYPE external_status USING external_status::external_status;
+-- +goose StatementEnd
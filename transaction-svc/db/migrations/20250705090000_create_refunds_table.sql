@@ -0,0 +1,63 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- ALTER TYPE ... ADD VALUE tidak boleh dipakai di dalam transaksi yang sama dengan nilai barunya
+ALTER TYPE internal_status ADD VALUE IF NOT EXISTS 'REFUNDING';
+ALTER TYPE internal_status ADD VALUE IF NOT EXISTS 'PARTIALLY_REFUNDED';
+ALTER TYPE internal_status ADD VALUE IF NOT EXISTS 'REFUNDED';
+ALTER TYPE transaction_status ADD VALUE IF NOT EXISTS 'PARTIALLY_REFUNDED';
+
+-- +goose StatementBegin
+ALTER TABLE transaction_details
+	ADD COLUMN IF NOT EXISTS refunded_quantity INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details
+	ADD CONSTRAINT check_transaction_details_refunded_quantity CHECK(refunded_quantity >= 0 AND refunded_quantity <= quantity);
+
+COMMENT ON COLUMN transaction_details.refunded_quantity IS 'Jumlah item yang sudah dikembalikan dananya, tidak boleh melebihi quantity';
+
+CREATE TABLE IF NOT EXISTS refunds (
+	id UUID NOT NULL default uuid_generate_v4(),
+	transaction_id UUID NOT NULL,
+	-- USER, OWNER, SYSTEM
+	initiator VARCHAR(20) NOT NULL,
+	requested_by UUID,
+	-- REQUESTED, SUCCEEDED, FAILED
+	status VARCHAR(20) NOT NULL DEFAULT 'REQUESTED',
+	previous_status internal_status NOT NULL,
+	amount NUMERIC(19,2) NOT NULL CHECK(amount > 0),
+	reason TEXT,
+	payment_provider VARCHAR(50) NOT NULL,
+	provider_refund_id VARCHAR(255),
+	failure_reason TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	completed_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(id)
+);COMMENT ON COLUMN refunds.status IS 'REQUESTED, SUCCEEDED, FAILED';
+COMMENT ON COLUMN refunds.initiator IS 'USER, OWNER, SYSTEM (refund otomatis untuk LATE_SETTLEMENT)';
+COMMENT ON COLUMN refunds.previous_status IS 'internal_status transaksi sebelum REFUNDING, dipakai saat refund gagal';
+COMMENT ON COLUMN refunds.requested_by IS 'User yang meminta refund, kosong untuk refund dari SYSTEM';
+
+CREATE INDEX idx_refunds_transaction_id ON refunds (transaction_id, created_at);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+	refund_id UUID NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+	transaction_detail_id UUID NOT NULL,
+	product_id UUID NOT NULL,
+	quantity INTEGER NOT NULL CHECK(quantity > 0),
+	amount NUMERIC(19,2) NOT NULL CHECK(amount > 0),
+	PRIMARY KEY(refund_id, transaction_detail_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- nilai enum yang sudah ditambahkan tidak bisa dihapus dari internal_status dan transaction_status
+DROP TABLE IF EXISTS refund_items;
+DROP INDEX IF EXISTS idx_refunds_transaction_id;
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE transaction_details
+	DROP CONSTRAINT IF EXISTS check_transaction_details_refunded_quantity,
+	DROP COLUMN IF EXISTS refunded_quantity;
+-- +goose StatementEnd
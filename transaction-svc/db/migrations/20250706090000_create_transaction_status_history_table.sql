@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payment_redirect_url TEXT;
+
+COMMENT ON COLUMN transactions.payment_redirect_url IS 'URL halaman pembayaran dari payment provider, hanya berguna selama transaksi menunggu pembayaran';
+
+CREATE TABLE IF NOT EXISTS transaction_status_history (
+	id UUID NOT NULL default uuid_generate_v4(),
+	transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+	from_status internal_status,
+	to_status internal_status NOT NULL,
+	transaction_status transaction_status NOT NULL,
+	external_status VARCHAR(50),
+	-- clock_timestamp agar beberapa perubahan dalam satu database transaction tetap berurutan
+	created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+	PRIMARY KEY(id)
+);COMMENT ON COLUMN transaction_status_history.from_status IS 'Kosong untuk status awal saat checkout';
+
+CREATE INDEX idx_transaction_status_history_transaction_id ON transaction_status_history (transaction_id, created_at);
+
+-- transaksi lama hanya mendapatkan status terakhirnya sebagai awal timeline
+INSERT INTO transaction_status_history (transaction_id, to_status, transaction_status, external_status, created_at)
+SELECT id, internal_status, transaction_status, external_status, updated_at FROM transactions;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transaction_status_history_transaction_id;
+DROP TABLE IF EXISTS transaction_status_history;
+ALTER TABLE transactions DROP COLUMN IF EXISTS payment_redirect_url;
+-- +goose StatementEnd
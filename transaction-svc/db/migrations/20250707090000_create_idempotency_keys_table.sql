@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	user_id UUID NOT NULL,
+	idempotency_key VARCHAR(255) NOT NULL,
+	-- sha256 dari body request pertama
+	request_hash VARCHAR(64) NOT NULL,
+	-- PROCESSING, COMPLETED
+	status VARCHAR(20) NOT NULL DEFAULT 'PROCESSING',
+	response_status INTEGER,
+	response_body JSONB,
+	locked_until TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	completed_at TIMESTAMPTZ,
+	expires_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY(user_id, idempotency_key)
+);COMMENT ON COLUMN idempotency_keys.status IS 'PROCESSING selama request pertama berjalan, COMPLETED setelah response disimpan';
+COMMENT ON COLUMN idempotency_keys.locked_until IS 'Batas waktu request PROCESSING, setelah lewat key boleh diambil alih oleh request dengan body yang sama';
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Response diputar ulang sampai waktu ini (24 jam), setelahnya key boleh dipakai lagi';
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
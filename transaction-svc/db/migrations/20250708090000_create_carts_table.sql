@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS carts (
+	id UUID NOT NULL default uuid_generate_v4(),
+	user_id UUID,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(id)
+);COMMENT ON COLUMN carts.user_id IS 'Kosong untuk keranjang tamu, digabung ke keranjang user setelah login';
+
+-- satu keranjang per user, keranjang tamu tidak dibatasi
+CREATE UNIQUE INDEX idx_carts_user_id ON carts (user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_carts_guest_updated_at ON carts (updated_at) WHERE user_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS cart_items (
+	cart_id UUID NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+	product_id UUID NOT NULL,
+	quantity INTEGER NOT NULL CHECK(quantity > 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(cart_id, product_id)
+);COMMENT ON COLUMN cart_items.quantity IS 'Harga dan stok tidak disimpan, selalu diambil dari product-svc saat keranjang dibaca';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cart_items;
+DROP INDEX IF EXISTS idx_carts_guest_updated_at;
+DROP INDEX IF EXISTS idx_carts_user_id;
+DROP TABLE IF EXISTS carts;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+	ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR',
+	ADD COLUMN IF NOT EXISTS exchange_rates JSONB;
+
+ALTER TABLE refunds
+	ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+COMMENT ON COLUMN transactions.currency IS 'Kode mata uang ISO 4217 dari total_price dan price di transaction_details';
+COMMENT ON COLUMN transactions.exchange_rates IS 'Snapshot kurs yang dipakai saat checkout mengonversi harga produk ke currency, kosong jika tidak ada konversi';
+COMMENT ON COLUMN refunds.currency IS 'Kode mata uang ISO 4217 dari amount di refunds dan refund_items, sama dengan currency transaksi';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refunds
+	DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE transactions
+	DROP COLUMN IF EXISTS exchange_rates,
+	DROP COLUMN IF EXISTS currency;
+-- +goose StatementEnd
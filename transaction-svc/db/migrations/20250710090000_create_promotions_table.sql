@@ -0,0 +1,89 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS promotions (
+	id UUID NOT NULL default uuid_generate_v4(),
+	code VARCHAR(50) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	-- PERCENTAGE, FIXED, BUY_X_GET_Y
+	type VARCHAR(20) NOT NULL,
+	-- ALL, PRODUCT, OWNER
+	scope VARCHAR(20) NOT NULL DEFAULT 'ALL',
+	scope_id UUID,
+	percentage_basis_points INTEGER NOT NULL DEFAULT 0 CHECK(percentage_basis_points BETWEEN 0 AND 10000),
+	amount NUMERIC(19,2) NOT NULL DEFAULT 0 CHECK(amount >= 0),
+	currency CHAR(3) NOT NULL DEFAULT 'IDR',
+	buy_quantity INTEGER NOT NULL DEFAULT 0 CHECK(buy_quantity >= 0),
+	get_quantity INTEGER NOT NULL DEFAULT 0 CHECK(get_quantity >= 0),
+	usage_limit INTEGER CHECK(usage_limit > 0),
+	usage_limit_per_user INTEGER CHECK(usage_limit_per_user > 0),
+	usage_count INTEGER NOT NULL DEFAULT 0,
+	starts_at TIMESTAMPTZ,
+	ends_at TIMESTAMPTZ,
+	is_active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(id),
+	CONSTRAINT check_promotions_usage_count CHECK(usage_count >= 0 AND (usage_limit IS NULL OR usage_count <= usage_limit))
+);COMMENT ON COLUMN promotions.code IS 'Kode promo yang dikirim pembeli saat checkout, selalu disimpan dalam huruf besar';
+COMMENT ON COLUMN promotions.scope_id IS 'product_id untuk scope PRODUCT, user_id pemilik produk untuk scope OWNER';
+COMMENT ON COLUMN promotions.percentage_basis_points IS 'Diskon PERCENTAGE dalam seperseratus persen, 1250 = 12.5%';
+COMMENT ON COLUMN promotions.amount IS 'Potongan FIXED dalam currency promo, dibagi ke baris yang berlaku sesuai harganya';
+COMMENT ON COLUMN promotions.usage_count IS 'Pemakaian RESERVED dan REDEEMED, dikembalikan saat transaksi batal atau kedaluwarsa';
+
+CREATE UNIQUE INDEX idx_promotions_code ON promotions (code);
+
+CREATE TABLE IF NOT EXISTS promotion_usages (
+	promotion_id UUID NOT NULL REFERENCES promotions(id),
+	transaction_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	-- RESERVED, REDEEMED, RELEASED
+	status VARCHAR(20) NOT NULL DEFAULT 'RESERVED',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(promotion_id, transaction_id)
+);COMMENT ON COLUMN promotion_usages.status IS 'RESERVED selama checkout berjalan, REDEEMED saat transaksi settle, RELEASED saat saga dikompensasi';
+
+CREATE INDEX idx_promotion_usages_user_id ON promotion_usages (promotion_id, user_id) WHERE status <> 'RELEASED';
+CREATE INDEX idx_promotion_usages_transaction_id ON promotion_usages (transaction_id);
+
+ALTER TABLE transaction_details
+	ADD COLUMN IF NOT EXISTS discount NUMERIC(19,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details
+	ADD CONSTRAINT check_transaction_details_discount CHECK(discount >= 0 AND discount <= price);
+
+COMMENT ON COLUMN transaction_details.discount IS 'Total potongan promo pada baris ini, price tetap harga sebelum diskon';
+
+CREATE TABLE IF NOT EXISTS transaction_detail_discounts (
+	transaction_detail_id UUID NOT NULL REFERENCES transaction_details(id) ON DELETE CASCADE,
+	promotion_id UUID NOT NULL REFERENCES promotions(id),
+	promotion_code VARCHAR(50) NOT NULL,
+	amount NUMERIC(19,2) NOT NULL CHECK(amount > 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(transaction_detail_id, promotion_id)
+);COMMENT ON COLUMN transaction_detail_discounts.amount IS 'Potongan satu promo pada baris ini, dalam currency transaksi';
+
+-- item yang digratiskan promo dikembalikan dengan amount 0
+ALTER TABLE refund_items
+	DROP CONSTRAINT IF EXISTS refund_items_amount_check,
+	ADD CONSTRAINT refund_items_amount_check CHECK(amount >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refund_items
+	DROP CONSTRAINT IF EXISTS refund_items_amount_check,
+	ADD CONSTRAINT refund_items_amount_check CHECK(amount > 0) NOT VALID;
+
+DROP TABLE IF EXISTS transaction_detail_discounts;
+
+ALTER TABLE transaction_details
+	DROP CONSTRAINT IF EXISTS check_transaction_details_discount,
+	DROP COLUMN IF EXISTS discount;
+
+DROP INDEX IF EXISTS idx_promotion_usages_transaction_id;
+DROP INDEX IF EXISTS idx_promotion_usages_user_id;
+DROP TABLE IF EXISTS promotion_usages;
+DROP INDEX IF EXISTS idx_promotions_code;
+DROP TABLE IF EXISTS promotions;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+	ADD COLUMN IF NOT EXISTS subtotal NUMERIC(19,2) NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS discount NUMERIC(19,2) NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS tax NUMERIC(19,2) NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS service_fee NUMERIC(19,2) NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS payment_fee NUMERIC(19,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details
+	ADD COLUMN IF NOT EXISTS tax NUMERIC(19,2) NOT NULL DEFAULT 0 CHECK(tax >= 0);
+
+-- transaksi lama tidak punya pajak maupun biaya, subtotal dan diskonnya dihitung dari transaction_details
+UPDATE transactions AS t
+SET
+	subtotal = d.subtotal,
+	discount = d.discount
+FROM (
+	SELECT
+		transaction_id, SUM(price) AS subtotal, SUM(discount) AS discount
+	FROM
+		transaction_details
+	GROUP BY
+		transaction_id
+) AS d
+WHERE
+	t.id = d.transaction_id;
+
+COMMENT ON COLUMN transactions.subtotal IS 'Jumlah price di transaction_details sebelum diskon';
+COMMENT ON COLUMN transactions.discount IS 'Jumlah diskon promo, total_price = subtotal - discount + tax + service_fee + payment_fee';
+COMMENT ON COLUMN transactions.tax IS 'Jumlah pajak dari semua baris, dihitung dari harga setelah diskon sesuai kategori produk';
+COMMENT ON COLUMN transactions.service_fee IS 'Biaya layanan checkout';
+COMMENT ON COLUMN transactions.payment_fee IS 'Biaya payment provider, dihitung dari total setelah biaya layanan';
+COMMENT ON COLUMN transaction_details.tax IS 'Pajak baris ini, ikut dikembalikan saat refund';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transaction_details
+	DROP COLUMN IF EXISTS tax;
+
+ALTER TABLE transactions
+	DROP COLUMN IF EXISTS payment_fee,
+	DROP COLUMN IF EXISTS service_fee,
+	DROP COLUMN IF EXISTS tax,
+	DROP COLUMN IF EXISTS discount,
+	DROP COLUMN IF EXISTS subtotal;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transaction_details
+	ADD COLUMN IF NOT EXISTS variant_id UUID,
+	ADD COLUMN IF NOT EXISTS sku VARCHAR(100);
+
+COMMENT ON COLUMN transaction_details.variant_id IS 'Varian produk yang dibeli, kosong untuk produk tanpa varian';
+COMMENT ON COLUMN transaction_details.sku IS 'SKU varian saat checkout';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transaction_details
+	DROP COLUMN IF EXISTS sku,
+	DROP COLUMN IF EXISTS variant_id;
+-- +goose StatementEnd
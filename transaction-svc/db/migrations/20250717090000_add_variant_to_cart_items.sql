@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keranjang bisa berisi beberapa varian dari produk yang sama
+ALTER TABLE cart_items
+	ADD COLUMN IF NOT EXISTS variant_id UUID,
+	DROP CONSTRAINT IF EXISTS cart_items_pkey;
+
+COMMENT ON COLUMN cart_items.variant_id IS 'Varian produk di keranjang, kosong untuk produk tanpa varian';
+
+CREATE UNIQUE INDEX unique_cart_items_line ON cart_items (cart_id, product_id, variant_id) NULLS NOT DISTINCT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS unique_cart_items_line;
+
+-- item dengan varian dihapus agar primary key lama bisa dibuat lagi
+DELETE FROM cart_items WHERE variant_id IS NOT NULL;
+
+ALTER TABLE cart_items
+	DROP COLUMN IF EXISTS variant_id,
+	ADD PRIMARY KEY (cart_id, product_id);
+-- +goose StatementEnd
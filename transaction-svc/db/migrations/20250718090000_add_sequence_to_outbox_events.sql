@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- created_at sama untuk semua event yang ditulis dalam satu transaksi, sequence menjaga urutan penulisannya
+ALTER TABLE outbox_events
+	ADD COLUMN IF NOT EXISTS sequence BIGSERIAL;
+
+COMMENT ON COLUMN outbox_events.sequence IS 'Urutan event ditulis, event satu aggregate dikirim sesuai urutan ini';
+
+CREATE INDEX idx_outbox_events_pending_aggregate_sequence ON outbox_events (aggregate_id, sequence) WHERE status = 'PENDING';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_events_pending_aggregate_sequence;
+
+ALTER TABLE outbox_events
+	DROP COLUMN IF EXISTS sequence;
+-- +goose StatementEnd
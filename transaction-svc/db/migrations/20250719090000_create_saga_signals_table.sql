@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- signal dicatat di sini dan hanya diterapkan oleh proses yang memegang lease saga, sehingga tidak bisa ditimpa olehnya
+CREATE TABLE IF NOT EXISTS saga_signals (
+	id BIGSERIAL NOT NULL,
+	saga_instance_id UUID NOT NULL REFERENCES saga_instances(id) ON DELETE CASCADE,
+	step_name VARCHAR(100) NOT NULL,
+	error TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY(id)
+);COMMENT ON COLUMN saga_signals.error IS 'NULL jika step berhasil, selain itu alasan step gagal';
+
+CREATE INDEX idx_saga_signals_saga_instance_id ON saga_signals (saga_instance_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS saga_signals;
+DROP INDEX IF EXISTS idx_saga_signals_saga_instance_id;
+-- +goose StatementEnd
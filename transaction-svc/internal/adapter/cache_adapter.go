@@ -19,6 +19,7 @@ type CacheAdapter interface {
 	XRead(ctx context.Context, args *redis.XReadArgs) ([]redis.XStream, error)
 	XAdd(ctx context.Context, args *redis.XAddArgs) error
 	PSubscribe(ctx context.Context, channels ...string) *redis.PubSub
+	Publish(ctx context.Context, channel string, message interface{}) error
 	SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 }
 
@@ -76,6 +77,10 @@ func (a *cacheAdapter) PSubscribe(ctx context.Context, channels ...string) *redi
 	return a.redisClient.PSubscribe(ctx, channels...)
 }
 
+func (a *cacheAdapter) Publish(ctx context.Context, channel string, message interface{}) error {
+	return a.redisClient.Publish(ctx, channel, message).Err()
+}
+
 func (a *cacheAdapter) SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return a.redisClient.SetEx(ctx, key, value, expiration).Err()
 }
@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"math/big"
+	"strings"
+)
+
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+// exchangeRateDecimals is the precision quoted rates are rounded to, the quoted decimal is the rate prices are
+// converted at so the stored snapshot reproduces them exactly.
+const exchangeRateDecimals = 10
+
+// ExchangeRateProvider quotes the rate between two currencies, a feed backed provider can replace the static one
+// without touching the checkout.
+type ExchangeRateProvider interface {
+	Rate(ctx context.Context, from, to string) (*model.ExchangeRate, error)
+}
+
+type staticExchangeRateProvider struct {
+	table *model.ExchangeRateTable
+}
+
+// NewStaticExchangeRateProvider quotes the rates of a fixed table, read from the environment or a file, so checkouts
+// can be converted without reaching a rates service.
+func NewStaticExchangeRateProvider(table *model.ExchangeRateTable) ExchangeRateProvider {
+	return &staticExchangeRateProvider{table: table}
+}
+
+func (p *staticExchangeRateProvider) Rate(ctx context.Context, from, to string) (*model.ExchangeRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+
+	fromValue, err := p.value(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toValue, err := p.value(to)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := new(big.Rat).Quo(fromValue, toValue)
+	return &model.ExchangeRate{
+		From:      from,
+		To:        to,
+		Rate:      formatRate(rate),
+		Source:    p.table.Source,
+		UpdatedAt: p.table.UpdatedAt,
+	}, nil
+}
+
+// value is the price of one unit of the currency in the base currency of the table.
+func (p *staticExchangeRateProvider) value(currency string) (*big.Rat, error) {
+	if currency == p.table.Base {
+		return big.NewRat(1, 1), nil
+	}
+
+	value, ok := p.table.Rates[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s in %s", ErrExchangeRateNotFound, currency, p.table.Base)
+	}
+	return value, nil
+}
+
+// formatRate rounds the rate to exchangeRateDecimals and drops the trailing zeros.
+func formatRate(rate *big.Rat) string {
+	value := rate.FloatString(exchangeRateDecimals)
+	if strings.Contains(value, ".") {
+		value = strings.TrimRight(strings.TrimRight(value, "0"), ".")
+	}
+	return value
+}
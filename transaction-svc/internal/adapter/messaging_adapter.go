@@ -10,6 +10,7 @@ import (
 
 type MessagingAdapter interface {
 	Publish(ctx context.Context, subject string, data any) error
+	PublishRaw(ctx context.Context, subject, msgID string, payload []byte) error
 }
 
 type messagingAdapter struct {
@@ -33,3 +34,13 @@ func (n *messagingAdapter) Publish(ctx context.Context, subject string, data any
 
 	return nil
 }
+
+// PublishRaw sets msgID as the Nats-Msg-Id header so JetStream and consumers can drop redeliveries of the same message.
+func (n *messagingAdapter) PublishRaw(ctx context.Context, subject, msgID string, payload []byte) error {
+	_, err := n.js.Publish(subject, payload, nats.Context(ctx), nats.MsgId(msgID))
+	if err != nil {
+		return fmt.Errorf("failed to publish to subject %q: %w", subject, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package adapter
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/config"
+	"go-saga-pattern/transaction-svc/internal/model"
+
+	"github.com/bytedance/sonic"
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/midtrans/midtrans-go/snap"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// midtransNotification holds the fields of a Midtrans HTTP notification that are needed to verify and map it.
+type midtransNotification struct {
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	StatusCode        string `json:"status_code"`
+	SignatureKey      string `json:"signature_key"`
+	SettlementTime    string `json:"settlement_time"`
+	TransactionID     string `json:"transaction_id"`
+	OrderID           string `json:"order_id"`
+	GrossAmount       string `json:"gross_amount"`
+}
+
+const (
+	// midtransCurrency is the only currency Snap charges in.
+	midtransCurrency = "IDR"
+	// midtransItemNameMaxLength is the longest item name Snap accepts.
+	midtransItemNameMaxLength = 50
+)
+
+type midtransPaymentProvider struct {
+	midtransClient   *config.MidtransClient
+	circuitBreaker   *gobreaker.CircuitBreaker
+	timeParserHelper helper.TimeParserHelper
+	logs             logs.Log
+}
+
+func NewMidtransPaymentProvider(midtransClient *config.MidtransClient, timeParserHelper helper.TimeParserHelper,
+	logs logs.Log) PaymentProvider {
+	return &midtransPaymentProvider{
+		midtransClient:   midtransClient,
+		circuitBreaker:   newPaymentCircuitBreaker("MidtransPayment", logs),
+		timeParserHelper: timeParserHelper,
+		logs:             logs,
+	}
+}
+
+func (p *midtransPaymentProvider) Name() enum.PaymentProvider {
+	return enum.PaymentProviderMidtrans
+}
+
+func (p *midtransPaymentProvider) CreateCharge(ctx context.Context, request *model.CreateChargeRequest) (*model.ChargeResponse, error) {
+	grossAmount, err := midtransAmount(request.GrossAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	snapReq := &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  request.OrderID,
+			GrossAmt: grossAmount,
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			Email: request.Email,
+		},
+	}
+
+	// Items Snap cannot take in whole rupiah are left out rather than failing the charge, the gross amount is kept
+	if items, err := midtransItems(request.Items); err != nil {
+		p.logs.Warn("[MidtransPaymentProvider] CreateCharge item details left out", zap.String("order_id", request.OrderID), zap.Error(err))
+	} else if len(items) > 0 {
+		snapReq.Items = &items
+	}
+
+	resp, err := callPaymentProvider(ctx, p.circuitBreaker, func(context.Context) (*snap.Response, error) {
+		resp, err := p.midtransClient.Snap.CreateTransaction(snapReq)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+	if err != nil {
+		p.logs.Error("[MidtransPaymentProvider] CreateCharge error:", zap.Error(err))
+		return nil, fmt.Errorf("midtrans create snapshot error: %w", err)
+	}
+
+	return &model.ChargeResponse{
+		Provider:    enum.PaymentProviderMidtrans,
+		Token:       resp.Token,
+		RedirectURL: resp.RedirectURL,
+	}, nil
+}
+
+func (p *midtransPaymentProvider) GetStatus(ctx context.Context, request *model.PaymentStatusRequest) (*model.PaymentStatusResponse, error) {
+	p.logs.Info("[MidtransPaymentProvider] GetStatus called", zap.String("order_id", request.OrderID))
+
+	resp, err := callPaymentProvider(ctx, p.circuitBreaker, func(context.Context) (*coreapi.TransactionStatusResponse, error) {
+		resp, err := p.midtransClient.CoreApi.CheckTransaction(request.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+	if err != nil {
+		p.logs.Error("[MidtransPaymentProvider] GetStatus error:", zap.Error(err))
+		return nil, fmt.Errorf("midtrans check status transaction error: %w", err)
+	}
+
+	body, err := sonic.ConfigFastest.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal midtrans status response: %w", err)
+	}
+
+	return p.toPaymentStatus(&midtransNotification{
+		TransactionStatus: resp.TransactionStatus,
+		FraudStatus:       resp.FraudStatus,
+		SettlementTime:    resp.SettlementTime,
+		TransactionID:     resp.TransactionID,
+		OrderID:           resp.OrderID,
+		GrossAmount:       resp.GrossAmount,
+	}, body)
+}
+
+// VerifyNotification checks signature_key, which Midtrans computes as SHA512(order_id+status_code+gross_amount+server_key).
+func (p *midtransPaymentProvider) VerifyNotification(ctx context.Context, request *model.PaymentNotificationRequest) (*model.PaymentStatusResponse, error) {
+	notification := new(midtransNotification)
+	if err := sonic.ConfigFastest.Unmarshal(request.Body, notification); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentNotification, err)
+	}
+
+	if notification.OrderID == "" || notification.TransactionStatus == "" || notification.SignatureKey == "" {
+		return nil, fmt.Errorf("%w: missing order_id, transaction_status or signature_key", ErrInvalidPaymentNotification)
+	}
+
+	hash := sha512.New()
+	hash.Write([]byte(notification.OrderID + notification.StatusCode + notification.GrossAmount + p.midtransClient.Snap.ServerKey))
+	if hex.EncodeToString(hash.Sum(nil)) != notification.SignatureKey {
+		p.logs.Warn("[MidtransPaymentProvider] Invalid signature key", zap.String("order_id", notification.OrderID))
+		return nil, ErrInvalidPaymentSignature
+	}
+
+	return p.toPaymentStatus(notification, request.Body)
+}
+
+func (p *midtransPaymentProvider) Refund(ctx context.Context, request *model.RefundChargeRequest) (*model.RefundChargeResponse, error) {
+	amount, err := midtransAmount(request.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	refundReq := &coreapi.RefundReq{
+		RefundKey: request.RefundKey,
+		Amount:    amount,
+		Reason:    request.Reason,
+	}
+
+	resp, err := callPaymentProvider(ctx, p.circuitBreaker, func(context.Context) (*coreapi.RefundResponse, error) {
+		resp, err := p.midtransClient.CoreApi.RefundTransaction(request.OrderID, refundReq)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+	if err != nil {
+		p.logs.Error("[MidtransPaymentProvider] Refund error:", zap.Error(err))
+		return nil, fmt.Errorf("midtrans refund transaction error: %w", err)
+	}
+
+	return &model.RefundChargeResponse{
+		Provider:       enum.PaymentProviderMidtrans,
+		RefundID:       resp.RefundKey,
+		ExternalStatus: resp.TransactionStatus,
+	}, nil
+}
+
+func (p *midtransPaymentProvider) Cancel(ctx context.Context, request *model.CancelChargeRequest) error {
+	_, err := callPaymentProvider(ctx, p.circuitBreaker, func(context.Context) (*coreapi.CancelResponse, error) {
+		resp, err := p.midtransClient.CoreApi.CancelTransaction(request.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	})
+	if err != nil {
+		p.logs.Error("[MidtransPaymentProvider] Cancel error:", zap.Error(err))
+		return fmt.Errorf("midtrans cancel transaction error: %w", err)
+	}
+
+	return nil
+}
+
+func (p *midtransPaymentProvider) toPaymentStatus(notification *midtransNotification, body []byte) (*model.PaymentStatusResponse, error) {
+	response := &model.PaymentStatusResponse{
+		Provider:       enum.PaymentProviderMidtrans,
+		OrderID:        notification.OrderID,
+		Reference:      notification.TransactionID,
+		Status:         midtransToPaymentStatus(notification.TransactionStatus, notification.FraudStatus),
+		ExternalStatus: notification.TransactionStatus,
+		GrossAmount:    notification.GrossAmount,
+		Body:           body,
+	}
+
+	if response.Status == enum.PaymentStatusPaid && notification.SettlementTime != "" {
+		settlementTime, err := p.timeParserHelper.TimeParseInDefaultLocation(notification.SettlementTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse midtrans settlement time %q: %w", notification.SettlementTime, err)
+		}
+		response.SettlementTime = &settlementTime
+	}
+
+	return response, nil
+}
+
+func midtransToPaymentStatus(transactionStatus, fraudStatus string) enum.PaymentStatus {
+	switch enum.MidtransPaymentStatus(transactionStatus) {
+	case enum.MidtransPaymentStatusCapture:
+		// a card capture flagged as challenge still waits for a manual review
+		if fraudStatus == "challenge" {
+			return enum.PaymentStatusPending
+		}
+		return enum.PaymentStatusPaid
+	case enum.MidtransPaymentStatusSettlement:
+		return enum.PaymentStatusPaid
+	case enum.MidtransPaymentStatusPending:
+		return enum.PaymentStatusPending
+	case enum.MidtransPaymentStatusExpire:
+		return enum.PaymentStatusExpired
+	case enum.MidtransPaymentStatusDeny, enum.MidtransPaymentStatusFailure:
+		return enum.PaymentStatusFailed
+	case enum.MidtransPaymentStatusCancel:
+		return enum.PaymentStatusCanceled
+	case enum.MidtransPaymentStatusRefund, enum.MidtransPaymentStatusPartialRefund:
+		return enum.PaymentStatusRefunded
+	default:
+		return ""
+	}
+}
+
+// midtransItems converts the charge items into Snap item details, which must add up to the gross amount.
+func midtransItems(chargeItems []model.ChargeItem) ([]midtrans.ItemDetails, error) {
+	items := make([]midtrans.ItemDetails, 0, len(chargeItems))
+	for _, chargeItem := range chargeItems {
+		price, err := midtransAmount(chargeItem.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		name := chargeItem.Name
+		if len(name) > midtransItemNameMaxLength {
+			name = name[:midtransItemNameMaxLength]
+		}
+
+		items = append(items, midtrans.ItemDetails{
+			ID:    chargeItem.ID,
+			Name:  name,
+			Price: price,
+			Qty:   int32(chargeItem.Quantity),
+		})
+	}
+	return items, nil
+}
+
+// midtransAmount returns the amount in whole rupiah, Midtrans takes neither other currencies nor decimals.
+func midtransAmount(amount money.Money) (int64, error) {
+	if amount.CurrencyCode() != midtransCurrency {
+		return 0, fmt.Errorf("%w: midtrans charges %s only, got %s", ErrUnsupportedCurrency, midtransCurrency, amount.CurrencyCode())
+	}
+	return amount.MajorUnits()
+}
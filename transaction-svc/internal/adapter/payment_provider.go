@@ -0,0 +1,140 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+var (
+	ErrUnknownPaymentProvider     = errors.New("unknown payment provider")
+	ErrInvalidPaymentSignature    = errors.New("invalid payment notification signature")
+	ErrInvalidPaymentNotification = errors.New("invalid payment notification")
+	ErrUnsupportedCurrency        = errors.New("currency is not supported by the payment provider")
+)
+
+const paymentProviderTimeout = 5 * time.Second
+
+// PaymentCircuitBreakerTimeout is how long an open circuit breaker rejects calls before it lets a few through again.
+const PaymentCircuitBreakerTimeout = 10 * time.Second
+
+// PaymentProvider is implemented once per payment gateway, use cases only see provider-neutral models and statuses.
+type PaymentProvider interface {
+	Name() enum.PaymentProvider
+	CreateCharge(ctx context.Context, request *model.CreateChargeRequest) (*model.ChargeResponse, error)
+	GetStatus(ctx context.Context, request *model.PaymentStatusRequest) (*model.PaymentStatusResponse, error)
+	// VerifyNotification checks the webhook signature and parses the notification into a payment status
+	VerifyNotification(ctx context.Context, request *model.PaymentNotificationRequest) (*model.PaymentStatusResponse, error)
+	Refund(ctx context.Context, request *model.RefundChargeRequest) (*model.RefundChargeResponse, error)
+	Cancel(ctx context.Context, request *model.CancelChargeRequest) error
+}
+
+type PaymentProviderRegistry interface {
+	Get(provider enum.PaymentProvider) (PaymentProvider, error)
+	Default() PaymentProvider
+}
+
+type paymentProviderRegistry struct {
+	providers       map[enum.PaymentProvider]PaymentProvider
+	defaultProvider PaymentProvider
+}
+
+// NewPaymentProviderRegistry falls back to the first provider when defaultProvider is not registered.
+func NewPaymentProviderRegistry(defaultProvider enum.PaymentProvider, providers ...PaymentProvider) PaymentProviderRegistry {
+	registry := &paymentProviderRegistry{providers: make(map[enum.PaymentProvider]PaymentProvider, len(providers))}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+		if registry.defaultProvider == nil {
+			registry.defaultProvider = provider
+		}
+	}
+
+	if provider, ok := registry.providers[defaultProvider]; ok {
+		registry.defaultProvider = provider
+	}
+
+	return registry
+}
+
+func (r *paymentProviderRegistry) Get(provider enum.PaymentProvider) (PaymentProvider, error) {
+	paymentProvider, ok := r.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPaymentProvider, provider)
+	}
+	return paymentProvider, nil
+}
+
+func (r *paymentProviderRegistry) Default() PaymentProvider {
+	return r.defaultProvider
+}
+
+// newPaymentCircuitBreaker opens after 60% of at least 5 calls failed, calls are rejected right away while it is open.
+func newPaymentCircuitBreaker(name string, logs logs.Log) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        name,
+		MaxRequests: 3,
+		Interval:    60 * time.Second,
+		Timeout:     PaymentCircuitBreakerTimeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.Requests >= 5 && float64(counts.TotalFailures)/float64(counts.Requests) >= 0.6
+		},
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			logs.Error(fmt.Sprintf("[CircuitBreaker] %s: %s -> %s", name, from.String(), to.String()))
+		},
+	})
+}
+
+// IsPaymentProviderUnavailable reports whether a call was rejected by an open circuit breaker without reaching the
+// payment gateway, retrying before PaymentCircuitBreakerTimeout passed is pointless.
+func IsPaymentProviderUnavailable(err error) bool {
+	return errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests)
+}
+
+// IsPaymentAmountUnsupported reports whether the gateway cannot take the amount at all, because of its currency or
+// because it has a fraction the gateway cannot charge, retrying the call would fail the same way.
+func IsPaymentAmountUnsupported(err error) bool {
+	return errors.Is(err, ErrUnsupportedCurrency) || errors.Is(err, money.ErrNotWholeUnits)
+}
+
+// callPaymentProvider runs a gateway call through the circuit breaker and gives up after paymentProviderTimeout.
+func callPaymentProvider[T any](ctx context.Context, circuitBreaker *gobreaker.CircuitBreaker, call func(ctx context.Context) (T, error)) (T, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, paymentProviderTimeout)
+	defer cancel()
+
+	res, err := circuitBreaker.Execute(func() (interface{}, error) {
+		resultChan := make(chan T, 1)
+		errChan := make(chan error, 1)
+
+		go func() {
+			resp, err := call(timeoutCtx)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			resultChan <- resp
+		}()
+
+		select {
+		case <-timeoutCtx.Done():
+			return nil, fmt.Errorf("%s request timeout: %w", circuitBreaker.Name(), timeoutCtx.Err())
+		case err := <-errChan:
+			return nil, err
+		case resp := <-resultChan:
+			return resp, nil
+		}
+	})
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return res.(T), nil
+}
@@ -5,6 +5,7 @@ import (
 	"go-saga-pattern/commoner/discovery"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
 	"go-saga-pattern/commoner/utils"
 	"go-saga-pattern/proto/productpb"
 	"go-saga-pattern/transaction-svc/internal/model"
@@ -17,6 +18,8 @@ import (
 type ProductAdapter interface {
 	CheckProductAndReserve(ctx context.Context, transationID uuid.UUID, request []*model.CheckProductQuantity) ([]*model.ProductResponse, error)
 	OwnerGetProduct(ctx context.Context, userID, productID uuid.UUID) (*model.ProductResponse, error)
+	// GetProducts returns the current price and stock of the products, deleted products are left out
+	GetProducts(ctx context.Context, productIDs []uuid.UUID) ([]*model.ProductResponse, error)
 }
 
 type productAdapter struct {
@@ -42,11 +45,14 @@ func NewProductAdapter(ctx context.Context, registry discovery.Registry, logs lo
 func (a *productAdapter) CheckProductAndReserve(ctx context.Context, transationID uuid.UUID, request []*model.CheckProductQuantity) ([]*model.ProductResponse, error) {
 	requestPb := make([]*productpb.CheckProductQuantity, 0, len(request))
 	for _, product := range request {
-		requestPb = append(requestPb, &productpb.CheckProductQuantity{
+		productPb := &productpb.CheckProductQuantity{
 			ProductId: product.ProductID.String(),
 			Quantity:  int32(product.Quantity),
-			Price:     float32(product.Price),
-		})
+		}
+		if product.VariantID.Valid {
+			productPb.VariantId = product.VariantID.UUID.String()
+		}
+		requestPb = append(requestPb, productPb)
 	}
 
 	processPhotoRequest := &productpb.CheckProductAndReserveRequest{
@@ -63,10 +69,14 @@ func (a *productAdapter) CheckProductAndReserve(ctx context.Context, transationI
 	for _, product := range response.Products {
 		products = append(products, &model.ProductResponse{
 			ID:          product.Id,
+			UserID:      product.GetUserId(),
 			Quantity:    int(product.Quantity),
-			Price:       float64(product.Price),
+			Price:       money.FromProto(product.GetPrice()),
 			Name:        product.Name,
 			Description: product.Description,
+			Category:    product.GetCategory(),
+			VariantID:   product.GetVariant().GetId(),
+			SKU:         product.GetVariant().GetSku(),
 		})
 	}
 
@@ -87,9 +97,47 @@ func (a *productAdapter) OwnerGetProduct(ctx context.Context, userID, productID
 	return &model.ProductResponse{
 		ID:          response.Product.Id,
 		Quantity:    int(response.Product.Quantity),
-		Price:       float64(response.Product.Price),
+		Price:       money.FromProto(response.Product.GetPrice()),
 		Name:        response.Product.Name,
 		Description: response.Product.Description,
+		Category:    response.Product.GetCategory(),
 	}, nil
 
 }
+
+func (a *productAdapter) GetProducts(ctx context.Context, productIDs []uuid.UUID) ([]*model.ProductResponse, error) {
+	productIDsPb := make([]string, 0, len(productIDs))
+	for _, productID := range productIDs {
+		productIDsPb = append(productIDsPb, productID.String())
+	}
+
+	response, err := a.client.GetProducts(ctx, &productpb.GetProductsRequest{ProductIds: productIDsPb})
+	if err != nil {
+		return nil, helper.FromGRPCError(err)
+	}
+
+	products := make([]*model.ProductResponse, 0, len(response.Products))
+	for _, product := range response.Products {
+		productResponse := &model.ProductResponse{
+			ID:          product.Id,
+			Quantity:    int(product.Quantity),
+			Price:       money.FromProto(product.GetPrice()),
+			Name:        product.Name,
+			Description: product.Description,
+			Category:    product.GetCategory(),
+			Variants:    make([]*model.ProductVariantResponse, 0, len(product.GetVariants())),
+		}
+		for _, variant := range product.GetVariants() {
+			productResponse.Variants = append(productResponse.Variants, &model.ProductVariantResponse{
+				ID:       variant.GetId(),
+				SKU:      variant.GetSku(),
+				Options:  variant.GetOptions(),
+				Price:    money.FromProto(variant.GetPrice()),
+				Quantity: int(variant.GetQuantity()),
+			})
+		}
+		products = append(products, productResponse)
+	}
+
+	return products, nil
+}
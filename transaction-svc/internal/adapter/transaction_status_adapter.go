@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"context"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/transaction-svc/internal/model/event"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"go.uber.org/zap"
+)
+
+const (
+	transactionStatusChannelPrefix = "transaction:status:"
+	transactionStatusWatcherBuffer = 8
+)
+
+// TransactionStatusAdapter fans transaction status changes out to every instance through redis pub/sub. Each instance
+// keeps a single pattern subscription and hands the changes to the watchers of the transaction it serves.
+type TransactionStatusAdapter interface {
+	Publish(ctx context.Context, statusEvent *event.TransactionStatusEvent) error
+	// Subscribe returns the changes of a transaction until the returned cancel func is called or Listen stops
+	Subscribe(transactionID string) (<-chan *event.TransactionStatusEvent, func())
+	Listen(ctx context.Context)
+}
+
+type transactionStatusAdapter struct {
+	cacheAdapter CacheAdapter
+	logs         logs.Log
+	mu           sync.Mutex
+	watchers     map[string]map[chan *event.TransactionStatusEvent]struct{}
+	closed       bool
+}
+
+func NewTransactionStatusAdapter(cacheAdapter CacheAdapter, logs logs.Log) TransactionStatusAdapter {
+	return &transactionStatusAdapter{
+		cacheAdapter: cacheAdapter,
+		logs:         logs,
+		watchers:     make(map[string]map[chan *event.TransactionStatusEvent]struct{}),
+	}
+}
+
+func (a *transactionStatusAdapter) Publish(ctx context.Context, statusEvent *event.TransactionStatusEvent) error {
+	payload, err := sonic.ConfigFastest.Marshal(statusEvent)
+	if err != nil {
+		return err
+	}
+
+	return a.cacheAdapter.Publish(ctx, transactionStatusChannelPrefix+statusEvent.TransactionID, payload)
+}
+
+func (a *transactionStatusAdapter) Subscribe(transactionID string) (<-chan *event.TransactionStatusEvent, func()) {
+	watcher := make(chan *event.TransactionStatusEvent, transactionStatusWatcherBuffer)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		close(watcher)
+		return watcher, func() {}
+	}
+
+	if _, ok := a.watchers[transactionID]; !ok {
+		a.watchers[transactionID] = make(map[chan *event.TransactionStatusEvent]struct{})
+	}
+	a.watchers[transactionID][watcher] = struct{}{}
+
+	var once sync.Once
+	return watcher, func() {
+		once.Do(func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+
+			watchers, ok := a.watchers[transactionID]
+			if !ok {
+				return
+			}
+			if _, ok := watchers[watcher]; ok {
+				delete(watchers, watcher)
+				close(watcher)
+			}
+			if len(watchers) == 0 {
+				delete(a.watchers, transactionID)
+			}
+		})
+	}
+}
+
+// Listen dispatches the published changes to the local watchers until ctx is done, then closes every watcher.
+// A watcher that does not keep up misses the change instead of blocking the others.
+func (a *transactionStatusAdapter) Listen(ctx context.Context) {
+	pubsub := a.cacheAdapter.PSubscribe(ctx, transactionStatusChannelPrefix+"*")
+	defer pubsub.Close()
+	defer a.closeWatchers()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			statusEvent := new(event.TransactionStatusEvent)
+			if err := sonic.ConfigFastest.UnmarshalFromString(message.Payload, statusEvent); err != nil {
+				a.logs.Warn("failed to unmarshal transaction status event", zap.String("channel", message.Channel), zap.Error(err))
+				continue
+			}
+			statusEvent.TransactionID = strings.TrimPrefix(message.Channel, transactionStatusChannelPrefix)
+
+			a.dispatch(statusEvent)
+		}
+	}
+}
+
+func (a *transactionStatusAdapter) dispatch(statusEvent *event.TransactionStatusEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for watcher := range a.watchers[statusEvent.TransactionID] {
+		select {
+		case watcher <- statusEvent:
+		default:
+			a.logs.Warn("transaction status watcher is full, dropping status change",
+				zap.String("transaction_id", statusEvent.TransactionID), zap.String("status", string(statusEvent.Status)))
+		}
+	}
+}
+
+func (a *transactionStatusAdapter) closeWatchers() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.closed = true
+	for transactionID, watchers := range a.watchers {
+		for watcher := range watchers {
+			close(watcher)
+		}
+		delete(a.watchers, transactionID)
+	}
+}
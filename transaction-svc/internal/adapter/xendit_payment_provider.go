@@ -0,0 +1,307 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/config"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+const xenditCallbackTokenHeader = "x-callback-token"
+
+// xenditInvoice is the subset of a Xendit invoice, both from the API and from the invoice callback.
+type xenditInvoice struct {
+	ID         string  `json:"id"`
+	ExternalID string  `json:"external_id"`
+	Status     string  `json:"status"`
+	Amount     float64 `json:"amount"`
+	InvoiceURL string  `json:"invoice_url"`
+	PaidAt     string  `json:"paid_at"`
+}
+
+type xenditCreateInvoiceRequest struct {
+	ExternalID  string      `json:"external_id"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency"`
+	PayerEmail  string      `json:"payer_email,omitempty"`
+	Description string      `json:"description"`
+}
+
+type xenditRefundRequest struct {
+	InvoiceID   string      `json:"invoice_id"`
+	ReferenceID string      `json:"reference_id,omitempty"`
+	Amount      json.Number `json:"amount,omitempty"`
+	Currency    string      `json:"currency,omitempty"`
+	Reason      string      `json:"reason"`
+}
+
+type xenditRefund struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type xenditError struct {
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+type xenditPaymentProvider struct {
+	xenditClient     *config.XenditClient
+	circuitBreaker   *gobreaker.CircuitBreaker
+	timeParserHelper helper.TimeParserHelper
+	logs             logs.Log
+}
+
+func NewXenditPaymentProvider(xenditClient *config.XenditClient, timeParserHelper helper.TimeParserHelper,
+	logs logs.Log) PaymentProvider {
+	return &xenditPaymentProvider{
+		xenditClient:     xenditClient,
+		circuitBreaker:   newPaymentCircuitBreaker("XenditPayment", logs),
+		timeParserHelper: timeParserHelper,
+		logs:             logs,
+	}
+}
+
+func (p *xenditPaymentProvider) Name() enum.PaymentProvider {
+	return enum.PaymentProviderXendit
+}
+
+func (p *xenditPaymentProvider) CreateCharge(ctx context.Context, request *model.CreateChargeRequest) (*model.ChargeResponse, error) {
+	amount, err := xenditAmount(request.GrossAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceReq := &xenditCreateInvoiceRequest{
+		ExternalID:  request.OrderID,
+		Amount:      amount,
+		Currency:    request.GrossAmount.CurrencyCode(),
+		PayerEmail:  request.Email,
+		Description: fmt.Sprintf("Transaction %s", request.OrderID),
+	}
+
+	invoice := new(xenditInvoice)
+	if err := p.do(ctx, http.MethodPost, "/v2/invoices", request.OrderID, invoiceReq, invoice); err != nil {
+		p.logs.Error("[XenditPaymentProvider] CreateCharge error:", zap.Error(err))
+		return nil, fmt.Errorf("xendit create invoice error: %w", err)
+	}
+
+	return &model.ChargeResponse{
+		Provider:    enum.PaymentProviderXendit,
+		Token:       invoice.ID,
+		RedirectURL: invoice.InvoiceURL,
+		Reference:   invoice.ID,
+	}, nil
+}
+
+func (p *xenditPaymentProvider) GetStatus(ctx context.Context, request *model.PaymentStatusRequest) (*model.PaymentStatusResponse, error) {
+	p.logs.Info("[XenditPaymentProvider] GetStatus called", zap.String("order_id", request.OrderID))
+
+	invoice := new(xenditInvoice)
+	if request.Reference != "" {
+		if err := p.do(ctx, http.MethodGet, "/v2/invoices/"+url.PathEscape(request.Reference), "", nil, invoice); err != nil {
+			p.logs.Error("[XenditPaymentProvider] GetStatus error:", zap.Error(err))
+			return nil, fmt.Errorf("xendit get invoice error: %w", err)
+		}
+	} else {
+		// the token was never stored, fall back to the order id we sent as external_id
+		invoices := make([]xenditInvoice, 0)
+		if err := p.do(ctx, http.MethodGet, "/v2/invoices?external_id="+url.QueryEscape(request.OrderID), "", nil, &invoices); err != nil {
+			p.logs.Error("[XenditPaymentProvider] GetStatus error:", zap.Error(err))
+			return nil, fmt.Errorf("xendit get invoice error: %w", err)
+		}
+		if len(invoices) == 0 {
+			return nil, fmt.Errorf("xendit invoice for order %s not found", request.OrderID)
+		}
+		invoice = &invoices[len(invoices)-1]
+	}
+
+	body, err := sonic.ConfigFastest.Marshal(invoice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal xendit invoice: %w", err)
+	}
+
+	return p.toPaymentStatus(invoice, body)
+}
+
+// VerifyNotification compares the x-callback-token header with the verification token of the Xendit account.
+func (p *xenditPaymentProvider) VerifyNotification(ctx context.Context, request *model.PaymentNotificationRequest) (*model.PaymentStatusResponse, error) {
+	var callbackToken string
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, xenditCallbackTokenHeader) {
+			callbackToken = value
+			break
+		}
+	}
+
+	if p.xenditClient.CallbackToken == "" ||
+		subtle.ConstantTimeCompare([]byte(callbackToken), []byte(p.xenditClient.CallbackToken)) != 1 {
+		p.logs.Warn("[XenditPaymentProvider] Invalid callback token")
+		return nil, ErrInvalidPaymentSignature
+	}
+
+	invoice := new(xenditInvoice)
+	if err := sonic.ConfigFastest.Unmarshal(request.Body, invoice); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentNotification, err)
+	}
+
+	if invoice.ExternalID == "" || invoice.Status == "" {
+		return nil, fmt.Errorf("%w: missing external_id or status", ErrInvalidPaymentNotification)
+	}
+
+	return p.toPaymentStatus(invoice, request.Body)
+}
+
+func (p *xenditPaymentProvider) Refund(ctx context.Context, request *model.RefundChargeRequest) (*model.RefundChargeResponse, error) {
+	amount, err := xenditAmount(request.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	refundReq := &xenditRefundRequest{
+		InvoiceID:   request.Reference,
+		ReferenceID: request.RefundKey,
+		Amount:      amount,
+		Currency:    request.Amount.CurrencyCode(),
+		Reason:      request.Reason,
+	}
+
+	refund := new(xenditRefund)
+	if err := p.do(ctx, http.MethodPost, "/refunds", request.RefundKey, refundReq, refund); err != nil {
+		p.logs.Error("[XenditPaymentProvider] Refund error:", zap.Error(err))
+		return nil, fmt.Errorf("xendit refund error: %w", err)
+	}
+
+	return &model.RefundChargeResponse{
+		Provider:       enum.PaymentProviderXendit,
+		RefundID:       refund.ID,
+		ExternalStatus: refund.Status,
+	}, nil
+}
+
+func (p *xenditPaymentProvider) Cancel(ctx context.Context, request *model.CancelChargeRequest) error {
+	if request.Reference == "" {
+		return fmt.Errorf("xendit expire invoice error: order %s has no invoice reference", request.OrderID)
+	}
+
+	if err := p.do(ctx, http.MethodPost, "/invoices/"+url.PathEscape(request.Reference)+"/expire!", "", nil, nil); err != nil {
+		p.logs.Error("[XenditPaymentProvider] Cancel error:", zap.Error(err))
+		return fmt.Errorf("xendit expire invoice error: %w", err)
+	}
+
+	return nil
+}
+
+// do sends a JSON request authenticated with the secret key and decodes the response into out when it is not nil.
+func (p *xenditPaymentProvider) do(ctx context.Context, method, path, idempotencyKey string, in any, out any) error {
+	_, err := callPaymentProvider(ctx, p.circuitBreaker, func(ctx context.Context) (struct{}, error) {
+		var body io.Reader
+		if in != nil {
+			payload, err := sonic.ConfigFastest.Marshal(in)
+			if err != nil {
+				return struct{}{}, err
+			}
+			body = bytes.NewReader(payload)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, p.xenditClient.BaseURL+path, body)
+		if err != nil {
+			return struct{}{}, err
+		}
+		req.SetBasicAuth(p.xenditClient.SecretKey, "")
+		req.Header.Set("Content-Type", "application/json")
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+
+		resp, err := p.xenditClient.HTTPClient.Do(req)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			xenditErr := new(xenditError)
+			_ = sonic.ConfigFastest.Unmarshal(respBody, xenditErr)
+			return struct{}{}, fmt.Errorf("xendit responded %d %s: %s", resp.StatusCode, xenditErr.ErrorCode, xenditErr.Message)
+		}
+
+		if out != nil {
+			if err := sonic.ConfigFastest.Unmarshal(respBody, out); err != nil {
+				return struct{}{}, err
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func (p *xenditPaymentProvider) toPaymentStatus(invoice *xenditInvoice, body []byte) (*model.PaymentStatusResponse, error) {
+	response := &model.PaymentStatusResponse{
+		Provider:       enum.PaymentProviderXendit,
+		OrderID:        invoice.ExternalID,
+		Reference:      invoice.ID,
+		Status:         xenditToPaymentStatus(invoice.Status),
+		ExternalStatus: strings.ToLower(invoice.Status),
+		GrossAmount:    strconv.FormatFloat(invoice.Amount, 'f', 2, 64),
+		Body:           body,
+	}
+
+	if response.Status == enum.PaymentStatusPaid && invoice.PaidAt != "" {
+		paidAt, err := p.timeParserHelper.TimeParseRFC3339(invoice.PaidAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse xendit paid_at %q: %w", invoice.PaidAt, err)
+		}
+		response.SettlementTime = &paidAt
+	}
+
+	return response, nil
+}
+
+func xenditToPaymentStatus(status string) enum.PaymentStatus {
+	switch strings.ToUpper(status) {
+	case "PENDING":
+		return enum.PaymentStatusPending
+	case "PAID", "SETTLED":
+		return enum.PaymentStatusPaid
+	case "EXPIRED":
+		return enum.PaymentStatusExpired
+	default:
+		return ""
+	}
+}
+
+// xenditAmount formats the amount as a decimal in its currency, rupiah invoices take no decimals so they are sent in
+// whole units.
+func xenditAmount(amount money.Money) (json.Number, error) {
+	if amount.CurrencyCode() != "IDR" {
+		return json.Number(amount.Decimal()), nil
+	}
+
+	units, err := amount.MajorUnits()
+	if err != nil {
+		return "", err
+	}
+	return json.Number(strconv.FormatInt(units, 10)), nil
+}
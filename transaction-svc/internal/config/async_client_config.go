@@ -13,3 +13,11 @@ func NewAsyncConfig() *asynq.Client {
 
 	return asynq.NewClient(asynq.RedisClientOpt{Addr: address})
 }
+
+func NewAsyncInspector() *asynq.Inspector {
+	host := utils.GetEnv("REDIS_HOST")
+	port := utils.GetEnv("REDIS_PORT")
+	address := host + ":" + port
+
+	return asynq.NewInspector(asynq.RedisClientOpt{Addr: address})
+}
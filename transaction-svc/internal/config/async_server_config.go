@@ -2,7 +2,8 @@ package config
 
 import "github.com/hibiken/asynq"
 
-func NewAsynqServer() *asynq.Server {
+// NewAsynqServer retries failed tasks after retryDelayFunc, asynq's default delay when it is nil.
+func NewAsynqServer(retryDelayFunc asynq.RetryDelayFunc) *asynq.Server {
 	srv := asynq.NewServer(
 		asynq.RedisClientOpt{
 			Addr: "127.0.0.1:6379",
@@ -22,6 +23,7 @@ func NewAsynqServer() *asynq.Server {
 				"default":  3,
 				"low":      1,
 			},
+			RetryDelayFunc: retryDelayFunc,
 			// See the godoc for other configuration options
 		},
 	)
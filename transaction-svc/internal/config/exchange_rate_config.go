@@ -0,0 +1,72 @@
+package config
+
+import (
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/commoner/utils"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"go.uber.org/zap"
+)
+
+// exchangeRateFile is the layout of EXCHANGE_RATES_FILE, the rates are decimal strings so they are read exactly.
+type exchangeRateFile struct {
+	Base      string            `json:"base"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Rates     map[string]string `json:"rates"`
+}
+
+// NewExchangeRateTable reads the exchange rates for offline use from the JSON file at EXCHANGE_RATES_FILE, or else
+// from EXCHANGE_RATES such as "USD=16250,SGD=12100" priced in EXCHANGE_RATES_BASE, the default currency when empty.
+func NewExchangeRateTable(log logs.Log) *model.ExchangeRateTable {
+	file := exchangeRateFile{Rates: make(map[string]string)}
+	source := "env"
+
+	if path := utils.GetEnv("EXCHANGE_RATES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal("Failed to read exchange rates file", zap.String("path", path), zap.Error(err))
+		}
+
+		if err := sonic.ConfigFastest.Unmarshal(data, &file); err != nil {
+			log.Fatal("Failed to parse exchange rates file", zap.String("path", path), zap.Error(err))
+		}
+		source = "file:" + path
+	} else {
+		file.Base = utils.GetEnv("EXCHANGE_RATES_BASE")
+		for _, pair := range strings.Split(utils.GetEnv("EXCHANGE_RATES"), ",") {
+			if currency, rate, ok := strings.Cut(strings.TrimSpace(pair), "="); ok {
+				file.Rates[currency] = rate
+			}
+		}
+	}
+
+	table := &model.ExchangeRateTable{
+		Base:      strings.ToUpper(file.Base),
+		Rates:     make(map[string]*big.Rat, len(file.Rates)),
+		Source:    source,
+		UpdatedAt: file.UpdatedAt,
+	}
+	if table.Base == "" {
+		table.Base = money.DefaultCurrency
+	}
+	if table.UpdatedAt.IsZero() {
+		table.UpdatedAt = time.Now()
+	}
+
+	for currency, value := range file.Rates {
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+		if !ok || rate.Sign() <= 0 || !money.Supported(currency) {
+			log.Fatal("Invalid exchange rate", zap.String("currency", currency), zap.String("rate", value))
+		}
+		table.Rates[currency] = rate
+	}
+
+	return table
+}
@@ -48,3 +48,16 @@ func InitWebhookStream(js nats.JetStreamContext, log logs.Log) {
 		log.Fatal("failed to create stream", zap.Error(err))
 	}
 }
+
+// InitWebhookDLQStream is never deleted on startup so dead letters survive restarts until they are replayed.
+func InitWebhookDLQStream(js nats.JetStreamContext, log logs.Log) {
+	_, err := js.AddStream(&nats.StreamConfig{
+		Name:     "WEBHOOK_NOTIFY_DLQ",
+		Subjects: []string{"dlq.webhook.>"},
+		Storage:  nats.FileStorage,
+	})
+
+	if err != nil && err != nats.ErrStreamNameAlreadyInUse {
+		log.Fatal("failed to create stream", zap.Error(err))
+	}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"go-saga-pattern/commoner/utils"
+	"io"
+	"strings"
 
 	"github.com/midtrans/midtrans-go"
 
@@ -28,8 +30,32 @@ func NewMidtransClient() *MidtransClient {
 	coreApi := &coreapi.Client{}
 	coreApi.New(midtransKey, midtrans.Sandbox)
 
+	// MIDTRANS_BASE_URL points both the Snap and the Core API at another host, e.g. cmd/paymentsim
+	if baseURL := strings.TrimSuffix(utils.GetEnv("MIDTRANS_BASE_URL"), "/"); baseURL != "" {
+		snap.HttpClient = newMidtransBaseURLClient(snap.HttpClient, baseURL, midtrans.Sandbox)
+		coreApi.HttpClient = newMidtransBaseURLClient(coreApi.HttpClient, baseURL, midtrans.Sandbox)
+	}
+
 	return &MidtransClient{
 		Snap:    snap,
 		CoreApi: coreApi,
 	}
 }
+
+// midtransBaseURLClient rewrites the environment URLs the midtrans-go clients build before sending a request,
+// the library derives them from midtrans.EnvironmentType and offers no other way to change the host.
+type midtransBaseURLClient struct {
+	midtrans.HttpClient
+	replacer *strings.Replacer
+}
+
+func newMidtransBaseURLClient(httpClient midtrans.HttpClient, baseURL string, env midtrans.EnvironmentType) midtrans.HttpClient {
+	return &midtransBaseURLClient{
+		HttpClient: httpClient,
+		replacer:   strings.NewReplacer(env.BaseUrl(), baseURL, env.SnapURL(), baseURL),
+	}
+}
+
+func (c *midtransBaseURLClient) Call(method string, url string, apiKey *string, options *midtrans.ConfigOptions, body io.Reader, result interface{}) *midtrans.Error {
+	return c.HttpClient.Call(method, c.replacer.Replace(url), apiKey, options, body, result)
+}
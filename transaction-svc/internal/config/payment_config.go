@@ -0,0 +1,16 @@
+package config
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/utils"
+	"strings"
+)
+
+// DefaultPaymentProvider is used when a checkout does not choose a payment provider.
+func DefaultPaymentProvider() enum.PaymentProvider {
+	provider := strings.ToUpper(utils.GetEnv("PAYMENT_DEFAULT_PROVIDER"))
+	if provider == "" {
+		return enum.PaymentProviderMidtrans
+	}
+	return enum.PaymentProvider(provider)
+}
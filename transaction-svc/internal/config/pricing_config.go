@@ -0,0 +1,108 @@
+package config
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/commoner/utils"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/pricing"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// CheckoutPriceTolerance reads how far the checkout total may rise above the total the buyer expected, both values
+// default to zero so any increase is rejected.
+func CheckoutPriceTolerance() model.PriceTolerance {
+	amount, err := money.Parse(utils.GetEnv("CHECKOUT_PRICE_TOLERANCE_AMOUNT"), money.DefaultCurrency)
+	if err != nil || amount.IsNegative() {
+		amount = money.Zero(money.DefaultCurrency)
+	}
+
+	percent, err := strconv.ParseFloat(utils.GetEnv("CHECKOUT_PRICE_TOLERANCE_PERCENT"), 64)
+	if err != nil || percent < 0 {
+		percent = 0
+	}
+
+	return model.PriceTolerance{Amount: amount, BasisPoints: int64(math.Round(percent * 100))}
+}
+
+// CheckoutCurrencyPolicy reads CHECKOUT_CURRENCY_POLICY, products priced in another currency than the checkout are
+// rejected unless it is CONVERT.
+func CheckoutCurrencyPolicy() enum.CurrencyPolicy {
+	if enum.CurrencyPolicy(strings.ToUpper(utils.GetEnv("CHECKOUT_CURRENCY_POLICY"))) == enum.CurrencyPolicyConvert {
+		return enum.CurrencyPolicyConvert
+	}
+	return enum.CurrencyPolicyReject
+}
+
+// CheckoutTaxRules reads TAX_RULES such as "default=11,books=0,food=2.5", the tax percent of every product category
+// with up to two decimals. A category is the path of the product category, such as books/fiction, and takes the rate of
+// its closest parent without a rule of its own. The default rate applies to the other categories and is zero when
+// not set.
+func CheckoutTaxRules(log logs.Log) pricing.TaxRules {
+	rules := pricing.TaxRules{Categories: make(map[string]int64)}
+	for _, pair := range strings.Split(utils.GetEnv("TAX_RULES"), ",") {
+		category, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+
+		basisPoints, ok := percentToBasisPoints(value)
+		if !ok || basisPoints > 10000 {
+			log.Fatal("Invalid tax rule", zap.String("category", category), zap.String("percent", value))
+		}
+
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "default" {
+			rules.Default = basisPoints
+			continue
+		}
+		rules.Categories[category] = basisPoints
+	}
+	return rules
+}
+
+// CheckoutServiceFee reads the fee charged on every checkout, SERVICE_FEE_AMOUNT in the default currency plus
+// SERVICE_FEE_PERCENT of the total after taxes.
+func CheckoutServiceFee() pricing.FeeRule {
+	return feeRule("SERVICE_FEE")
+}
+
+// CheckoutPaymentFees reads the fee of every payment provider, PAYMENT_FEE_<PROVIDER>_AMOUNT in the default currency
+// plus PAYMENT_FEE_<PROVIDER>_PERCENT of the total after the service fee.
+func CheckoutPaymentFees() map[enum.PaymentProvider]pricing.FeeRule {
+	fees := make(map[enum.PaymentProvider]pricing.FeeRule)
+	for _, provider := range []enum.PaymentProvider{enum.PaymentProviderMidtrans, enum.PaymentProviderXendit} {
+		if fee := feeRule("PAYMENT_FEE_" + string(provider)); !fee.IsZero() {
+			fees[provider] = fee
+		}
+	}
+	return fees
+}
+
+// feeRule reads <prefix>_AMOUNT and <prefix>_PERCENT, invalid or negative values are read as zero.
+func feeRule(prefix string) pricing.FeeRule {
+	amount, err := money.Parse(utils.GetEnv(prefix+"_AMOUNT"), money.DefaultCurrency)
+	if err != nil || amount.IsNegative() {
+		amount = money.Zero(money.DefaultCurrency)
+	}
+
+	basisPoints, ok := percentToBasisPoints(utils.GetEnv(prefix + "_PERCENT"))
+	if !ok {
+		basisPoints = 0
+	}
+
+	return pricing.FeeRule{Amount: amount, BasisPoints: basisPoints}
+}
+
+func percentToBasisPoints(value string) (int64, bool) {
+	percent, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || percent < 0 {
+		return 0, false
+	}
+	return int64(math.Round(percent * 100)), true
+}
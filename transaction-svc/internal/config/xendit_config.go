@@ -0,0 +1,30 @@
+package config
+
+import (
+	"go-saga-pattern/commoner/utils"
+	"net/http"
+	"time"
+)
+
+const defaultXenditBaseURL = "https://api.xendit.co"
+
+type XenditClient struct {
+	BaseURL       string
+	SecretKey     string
+	CallbackToken string
+	HTTPClient    *http.Client
+}
+
+func NewXenditClient() *XenditClient {
+	baseURL := utils.GetEnv("XENDIT_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultXenditBaseURL
+	}
+
+	return &XenditClient{
+		BaseURL:       baseURL,
+		SecretKey:     utils.GetEnv("XENDIT_SECRET_KEY"),
+		CallbackToken: utils.GetEnv("XENDIT_CALLBACK_TOKEN"),
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/dlq"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/transaction-svc/internal/model"
@@ -20,19 +21,24 @@ import (
 type WebhookConsumer struct {
 	transactionUseCase contract.TransactionUseCase
 	js                 nats.JetStreamContext
+	deadLetterQueue    dlq.DeadLetterQueue
 	subject            string
 	consumerName       string
 	durableName        string
+	maxDeliver         int
 	logs               logs.Log
 }
 
-func NewWebhookConsumer(transactionUseCase contract.TransactionUseCase, js nats.JetStreamContext, logs logs.Log) *WebhookConsumer {
+func NewWebhookConsumer(transactionUseCase contract.TransactionUseCase, js nats.JetStreamContext, deadLetterQueue dlq.DeadLetterQueue,
+	logs logs.Log) *WebhookConsumer {
 	return &WebhookConsumer{
 		transactionUseCase: transactionUseCase,
 		js:                 js,
+		deadLetterQueue:    deadLetterQueue,
 		subject:            "webhook.notify",
 		consumerName:       "webhook_consumer",
 		durableName:        "webhook_durable",
+		maxDeliver:         5,
 		logs:               logs,
 	}
 }
@@ -43,6 +49,7 @@ func (s *WebhookConsumer) Start(ctx context.Context) error {
 		s.subject,
 		s.durableName,
 		nats.BindStream("WEBHOOK_NOTIFY_STREAM"), // ganti dengan stream name
+		nats.MaxDeliver(s.maxDeliver),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create pull subscription: %w", err)
@@ -64,41 +71,7 @@ func (s *WebhookConsumer) Start(ctx context.Context) error {
 				}
 
 				for _, msg := range msgs {
-					event := new(event.WebhookNotifyEvent)
-					if err := sonic.ConfigFastest.Unmarshal(msg.Data, event); err != nil {
-						s.logs.Error("failed to unmarshal message: %v", zap.Error(err))
-						_ = msg.Nak()
-						continue
-					}
-
-					s.logs.Info("Received message for order", zap.String("OrderID", event.OrderID))
-
-					request := &model.CheckAndUpdateTransactionRequest{
-						MidtransTransactionStatus: event.MidtransTransactionStatus,
-						StatusCode:                event.StatusCode,
-						SignatureKey:              event.SignatureKey,
-						SettlementTime:            event.SettlementTime,
-						OrderID:                   event.OrderID,
-						GrossAmount:               event.GrossAmount,
-						Body:                      event.Body,
-					}
-
-					if err := s.transactionUseCase.CheckAndUpdateTransaction(ctx, request); err != nil {
-						s.logs.Warn("failed to process transaction: %v", zap.Error(err), zap.String("OrderID", event.OrderID))
-						appErr, ok := err.(*helper.AppError)
-						if ok && appErr.Code == errorcode.ErrInternal {
-							s.logs.Error("internal server error, retrying message", zap.String("OrderID", event.OrderID))
-							_ = msg.Nak()
-							continue
-						}
-						s.logs.Warn("failed to process transaction, acknowledging message from usecase error", zap.String("OrderID", event.OrderID), zap.Error(err))
-					}
-
-					if err := msg.Ack(); err != nil {
-						s.logs.Error("failed to acknowledge message: %v", zap.Error(err))
-					} else {
-						s.logs.Info("Message acknowledged", zap.String("OrderID", event.OrderID))
-					}
+					s.handleMessage(ctx, msg)
 				}
 			}
 		}
@@ -106,3 +79,53 @@ func (s *WebhookConsumer) Start(ctx context.Context) error {
 
 	return nil
 }
+
+func (s *WebhookConsumer) handleMessage(ctx context.Context, msg *nats.Msg) {
+	event := new(event.WebhookNotifyEvent)
+	if err := sonic.ConfigFastest.Unmarshal(msg.Data, event); err != nil {
+		s.logs.Error("failed to unmarshal message: %v", zap.Error(err))
+		s.deadLetter(ctx, msg, fmt.Sprintf("failed to unmarshal message: %v", err), "")
+		return
+	}
+
+	s.logs.Info("Received payment notification", zap.String("provider", string(event.Provider)))
+
+	request := &model.PaymentNotificationRequest{
+		Provider: event.Provider,
+		Headers:  event.Headers,
+		Body:     event.Body,
+	}
+
+	if err := s.transactionUseCase.HandlePaymentNotification(ctx, request); err != nil {
+		s.logs.Warn("failed to process transaction: %v", zap.Error(err), zap.String("provider", string(event.Provider)))
+		appErr, ok := err.(*helper.AppError)
+		if ok && appErr.Code == errorcode.ErrInternal {
+			if meta, metaErr := msg.Metadata(); metaErr == nil && meta.NumDelivered >= uint64(s.maxDeliver) {
+				s.logs.Error("max deliveries reached, moving message to dead letter queue", zap.String("provider", string(event.Provider)))
+				s.deadLetter(ctx, msg, fmt.Sprintf("max deliveries (%d) exhausted: %v", s.maxDeliver, err), string(event.Provider))
+				return
+			}
+
+			s.logs.Error("internal server error, retrying message", zap.String("provider", string(event.Provider)))
+			_ = msg.Nak()
+			return
+		}
+		s.logs.Warn("failed to process transaction, acknowledging message from usecase error", zap.String("provider", string(event.Provider)), zap.Error(err))
+	}
+
+	if err := msg.Ack(); err != nil {
+		s.logs.Error("failed to acknowledge message: %v", zap.Error(err))
+	} else {
+		s.logs.Info("Message acknowledged", zap.String("provider", string(event.Provider)))
+	}
+}
+
+// deadLetter moves a terminally failed notification to WEBHOOK_NOTIFY_DLQ and terminates it on the original stream.
+// When the DLQ publish keeps failing the message is redelivered, or kept in progress on its last delivery, instead of
+// being lost.
+func (s *WebhookConsumer) deadLetter(ctx context.Context, msg *nats.Msg, reason string, provider string) {
+	if err := s.deadLetterQueue.Move(ctx, msg, reason, s.maxDeliver); err != nil {
+		s.logs.Error("failed to move message to dead letter queue", zap.String("provider", provider),
+			zap.ByteString("payload", msg.Data), zap.Error(err))
+	}
+}
@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"context"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/proto/transactionpb"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type TransactionHandler struct {
+	transactionUC contract.TransactionUseCase
+	cancelationUC contract.CancelationUseCase
+	statusAdapter adapter.TransactionStatusAdapter
+	logs          logs.Log
+	transactionpb.UnimplementedTransactionServiceServer
+}
+
+func NewTransactionHandler(server *grpc.Server, transactionUC contract.TransactionUseCase,
+	cancelationUC contract.CancelationUseCase, statusAdapter adapter.TransactionStatusAdapter, logs logs.Log) {
+	handler := &TransactionHandler{
+		transactionUC: transactionUC,
+		cancelationUC: cancelationUC,
+		statusAdapter: statusAdapter,
+		logs:          logs,
+	}
+	transactionpb.RegisterTransactionServiceServer(server, handler)
+}
+
+func (h *TransactionHandler) CreateTransaction(ctx context.Context, pbReq *transactionpb.CreateTransactionRequest,
+) (*transactionpb.CreateTransactionResponse, error) {
+	parsedUserID, err := uuid.Parse(pbReq.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
+	}
+
+	products := make([]model.TransactionProduct, 0, len(pbReq.GetProducts()))
+	for _, productPb := range pbReq.GetProducts() {
+		if productPb.GetQuantity() <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "Product quantity must be greater than zero")
+		}
+
+		productID, err := uuid.Parse(productPb.GetProductId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid product ID format")
+		}
+
+		var variantID uuid.NullUUID
+		if productPb.GetVariantId() != "" {
+			parsedVariantID, err := uuid.Parse(productPb.GetVariantId())
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "Invalid variant ID format")
+			}
+			variantID = uuid.NullUUID{UUID: parsedVariantID, Valid: true}
+		}
+
+		products = append(products, model.TransactionProduct{
+			ProductID: productID,
+			VariantID: variantID,
+			Quantity:  int(productPb.GetQuantity()),
+		})
+	}
+
+	request := &model.CreateTransactionRequest{
+		UserID:          parsedUserID,
+		Products:        products,
+		PaymentProvider: enum.PaymentProvider(pbReq.GetPaymentProvider()),
+		ExpectedTotal:   money.FromProto(pbReq.GetExpectedTotal()),
+		Currency:        pbReq.GetCurrency(),
+		PromoCodes:      pbReq.GetPromoCodes(),
+	}
+
+	response, err := h.transactionUC.CreateTransaction(ctx, request)
+	if err != nil {
+		return nil, helper.ErrGRPC(err)
+	}
+
+	return &transactionpb.CreateTransactionResponse{
+		Status:          int64(codes.OK),
+		TransactionId:   response.TransactionId,
+		PaymentProvider: string(response.PaymentProvider),
+		SnapToken:       response.SnapToken,
+		RedirectUrl:     response.RedirectURL,
+		TotalPrice:      money.ToProto(response.TotalPrice),
+		Discount:        money.ToProto(response.Discount),
+		Breakdown:       priceBreakdownToPb(response.Breakdown),
+	}, nil
+}
+
+func (h *TransactionHandler) GetTransaction(ctx context.Context, pbReq *transactionpb.GetTransactionRequest,
+) (*transactionpb.GetTransactionResponse, error) {
+	request, err := parseGetTransactionRequest(pbReq.GetTransactionId(), pbReq.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := h.transactionUC.UserGet(ctx, request)
+	if err != nil {
+		return nil, helper.ErrGRPC(err)
+	}
+
+	return &transactionpb.GetTransactionResponse{
+		Status:      int64(codes.OK),
+		Transaction: transactionToPb(response),
+	}, nil
+}
+
+func (h *TransactionHandler) ListUserTransactions(ctx context.Context, pbReq *transactionpb.ListUserTransactionsRequest,
+) (*transactionpb.ListUserTransactionsResponse, error) {
+	parsedUserID, err := uuid.Parse(pbReq.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
+	}
+
+	request := &model.UserSearchTransactionRequest{
+		UserID: parsedUserID,
+		Page:   int(pbReq.GetPage()),
+		Limit:  int(pbReq.GetLimit()),
+	}
+	if request.Page <= 0 {
+		request.Page = 1
+	}
+	if request.Limit <= 0 {
+		request.Limit = 10
+	}
+
+	response, pageMetadata, err := h.transactionUC.UserSearchWithDetail(ctx, request)
+	if err != nil {
+		return nil, helper.ErrGRPC(err)
+	}
+
+	transactionsPb := make([]*transactionpb.Transaction, 0, len(response))
+	for _, transaction := range response {
+		transactionsPb = append(transactionsPb, transactionToPb(transaction))
+	}
+
+	pbResponse := &transactionpb.ListUserTransactionsResponse{
+		Status:       int64(codes.OK),
+		Transactions: transactionsPb,
+	}
+	if pageMetadata != nil {
+		pbResponse.PageMetadata = &transactionpb.PageMetadata{
+			Page:        int32(pageMetadata.Page),
+			Size:        int32(pageMetadata.Size),
+			TotalItem:   pageMetadata.TotalItem,
+			TotalPage:   pageMetadata.TotalPage,
+			HasNext:     pageMetadata.HasNext,
+			HasPrevious: pageMetadata.HasPrevious,
+		}
+	}
+
+	return pbResponse, nil
+}
+
+func (h *TransactionHandler) CancelTransaction(ctx context.Context, pbReq *transactionpb.CancelTransactionRequest,
+) (*transactionpb.CancelTransactionResponse, error) {
+	parsedTransactionID, err := uuid.Parse(pbReq.GetTransactionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid transaction ID format")
+	}
+
+	parsedUserID, err := uuid.Parse(pbReq.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
+	}
+
+	request := &model.CancelTransactionRequest{
+		UserID:        parsedUserID,
+		TransactionID: parsedTransactionID,
+	}
+
+	if err := h.cancelationUC.UserCancelTransaction(ctx, request); err != nil {
+		return nil, helper.ErrGRPC(err)
+	}
+
+	return &transactionpb.CancelTransactionResponse{
+		Status: int64(codes.OK),
+	}, nil
+}
+
+// WatchTransaction sends the current transaction right away and then again on every change of its status,
+// the stream stays open until the client cancels it or the server shuts down.
+func (h *TransactionHandler) WatchTransaction(pbReq *transactionpb.WatchTransactionRequest,
+	stream grpc.ServerStreamingServer[transactionpb.WatchTransactionResponse]) error {
+	request, err := parseGetTransactionRequest(pbReq.GetTransactionId(), pbReq.GetUserId())
+	if err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	statusEvents, cancel := h.statusAdapter.Subscribe(request.TransacitonID.String())
+	defer cancel()
+
+	for {
+		response, err := h.transactionUC.UserGet(ctx, request)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return helper.ErrGRPC(err)
+		}
+
+		if err := stream.Send(&transactionpb.WatchTransactionResponse{Transaction: transactionToPb(response)}); err != nil {
+			h.logs.Warn("failed to send transaction to watcher", zap.String("transaction_id", response.ID), zap.Error(err))
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-statusEvents:
+			if !ok {
+				return status.Error(codes.Unavailable, "Transaction service is shutting down")
+			}
+		}
+	}
+}
+
+func parseGetTransactionRequest(transactionID, userID string) (*model.GetTransactionRequest, error) {
+	parsedTransactionID, err := uuid.Parse(transactionID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid transaction ID format")
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
+	}
+
+	return &model.GetTransactionRequest{
+		UserID:        parsedUserID,
+		TransacitonID: parsedTransactionID,
+	}, nil
+}
+
+func transactionToPb(transaction *model.TransactionResponse) *transactionpb.Transaction {
+	transactionDetailsPb := make([]*transactionpb.TransactionDetail, 0, len(transaction.TransactionDetails))
+	for _, transactionDetail := range transaction.TransactionDetails {
+		transactionDetailsPb = append(transactionDetailsPb, &transactionpb.TransactionDetail{
+			Id:        transactionDetail.ID,
+			ProductId: transactionDetail.ProductID,
+			VariantId: transactionDetail.VariantID,
+			Sku:       transactionDetail.SKU,
+			Quantity:  int32(transactionDetail.Quantity),
+			Price:     money.ToProto(transactionDetail.Price),
+			Discount:  money.ToProto(transactionDetail.Discount),
+			Tax:       money.ToProto(transactionDetail.Tax),
+			CreatedAt: transactionDetail.CreatedAt,
+		})
+	}
+
+	return &transactionpb.Transaction{
+		Id:                 transaction.ID,
+		UserId:             transaction.UserID,
+		TotalPrice:         money.ToProto(transaction.TotalPrice),
+		Discount:           money.ToProto(transaction.Discount),
+		Breakdown:          priceBreakdownToPb(transaction.Breakdown),
+		TransactionStatus:  string(transaction.TransactionStatus),
+		CheckoutAt:         transaction.CheckoutAt,
+		PaymentAt:          transaction.PaymentAt,
+		UpdatedAt:          transaction.UpdatedAt,
+		TransactionDetails: transactionDetailsPb,
+	}
+}
+
+func priceBreakdownToPb(breakdown *model.PriceBreakdown) *transactionpb.PriceBreakdown {
+	if breakdown == nil {
+		return nil
+	}
+
+	return &transactionpb.PriceBreakdown{
+		Subtotal:   money.ToProto(breakdown.Subtotal),
+		Discount:   money.ToProto(breakdown.Discount),
+		Tax:        money.ToProto(breakdown.Tax),
+		ServiceFee: money.ToProto(breakdown.ServiceFee),
+		PaymentFee: money.ToProto(breakdown.PaymentFee),
+	}
+}
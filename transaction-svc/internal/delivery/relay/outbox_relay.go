@@ -0,0 +1,102 @@
+package relay
+
+import (
+	"context"
+	"expvar"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/utils"
+	"go-saga-pattern/transaction-svc/internal/usecase"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Lag metrics are exported through expvar and served on /debug/vars by the worker metrics server.
+var (
+	outboxPublishedTotal       = expvar.NewInt("outbox_published_total")
+	outboxRetriedTotal         = expvar.NewInt("outbox_retried_total")
+	outboxFailedTotal          = expvar.NewInt("outbox_failed_total")
+	outboxPendingEvents        = expvar.NewInt("outbox_pending_events")
+	outboxFailedEvents         = expvar.NewInt("outbox_failed_events")
+	outboxOldestPendingSeconds = expvar.NewFloat("outbox_oldest_pending_age_seconds")
+)
+
+type OutboxRelay struct {
+	outboxUseCase usecase.OutboxUseCase
+	interval      time.Duration
+	lagInterval   time.Duration
+	logs          logs.Log
+}
+
+func NewOutboxRelay(outboxUseCase usecase.OutboxUseCase, logs logs.Log) *OutboxRelay {
+	intervalInt, err := strconv.Atoi(utils.GetEnv("OUTBOX_RELAY_INTERVAL_IN_MILLIS"))
+	if err != nil || intervalInt <= 0 {
+		intervalInt = 500
+	}
+
+	return &OutboxRelay{
+		outboxUseCase: outboxUseCase,
+		interval:      time.Duration(intervalInt) * time.Millisecond,
+		lagInterval:   10 * time.Second,
+		logs:          logs,
+	}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	r.logs.Info("Started outbox relay", zap.Duration("interval", r.interval))
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	lagTicker := time.NewTicker(r.lagInterval)
+	defer lagTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logs.Info("Context done, stopping outbox relay")
+			return
+		case <-lagTicker.C:
+			r.recordLag(ctx)
+		case <-ticker.C:
+			r.relay(ctx)
+		}
+	}
+}
+
+// relay drains due events batch by batch so a backlog is not limited to one batch per tick.
+func (r *OutboxRelay) relay(ctx context.Context) {
+	for {
+		result, err := r.outboxUseCase.RelayPendingEvents(ctx)
+		if err != nil {
+			r.logs.Error("failed to relay outbox events", zap.Error(err))
+			return
+		}
+
+		outboxPublishedTotal.Add(int64(result.Sent))
+		outboxRetriedTotal.Add(int64(result.Retried))
+		outboxFailedTotal.Add(int64(result.Failed))
+
+		if result.Sent == 0 || result.Retried > 0 || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) recordLag(ctx context.Context) {
+	lag, err := r.outboxUseCase.GetLag(ctx)
+	if err != nil {
+		r.logs.Error("failed to record outbox lag", zap.Error(err))
+		return
+	}
+
+	outboxPendingEvents.Set(lag.PendingCount)
+	outboxFailedEvents.Set(lag.FailedCount)
+	outboxOldestPendingSeconds.Set(lag.OldestPendingAgeSeconds)
+
+	if lag.PendingCount > 0 || lag.FailedCount > 0 {
+		r.logs.Info("Outbox lag", zap.Int64("pending", lag.PendingCount), zap.Int64("failed", lag.FailedCount),
+			zap.Float64("oldest_pending_age_seconds", lag.OldestPendingAgeSeconds))
+	}
+}
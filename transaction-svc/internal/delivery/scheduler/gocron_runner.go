@@ -21,6 +21,7 @@ type schedulerRunner struct {
 	usecase                usecase.SchedulerUseCase
 	logs                   logs.Log
 	checkSchedulerDuration time.Duration
+	sagaSchedulerDuration  time.Duration
 }
 
 func NewSchedulerRunner(
@@ -33,11 +34,17 @@ func NewSchedulerRunner(
 	if err != nil || schedulerInt <= 0 {
 		schedulerInt = 60
 	}
+
+	sagaSchedulerInt, err := strconv.Atoi(utils.GetEnv("SAGA_RESUME_SCHEDULER_IN_SECONDS"))
+	if err != nil || sagaSchedulerInt <= 0 {
+		sagaSchedulerInt = 15
+	}
 	return &schedulerRunner{
 		scheduler:              s,
 		usecase:                usecase,
 		logs:                   logs,
 		checkSchedulerDuration: time.Duration(schedulerInt) * time.Second,
+		sagaSchedulerDuration:  time.Duration(sagaSchedulerInt) * time.Second,
 	}
 }
 
@@ -61,5 +68,77 @@ func (r *schedulerRunner) Start() {
 		return
 	}
 	r.logs.Info("Scheduler job created to check transaction status every 100 seconds", zap.String("job", "CheckTransactionStatus"))
+
+	_, err = r.scheduler.NewJob(
+		gocron.DurationJob(r.sagaSchedulerDuration),
+		gocron.NewTask(func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, 4*time.Minute)
+			defer cancel()
+
+			if err := r.usecase.ResumeSagas(ctx); err != nil {
+				r.logs.Error("Failed to resume sagas", zap.Error(err))
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		r.logs.Error("Failed to create job", zap.Error(err))
+		return
+	}
+	r.logs.Info("Scheduler job created to resume sagas", zap.String("job", "ResumeSagas"), zap.Duration("interval", r.sagaSchedulerDuration))
+
+	_, err = r.scheduler.NewJob(
+		gocron.DurationJob(r.checkSchedulerDuration),
+		gocron.NewTask(func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, 4*time.Minute)
+			defer cancel()
+
+			if err := r.usecase.RefundLateSettlements(ctx); err != nil {
+				r.logs.Error("Failed to refund late settlements", zap.Error(err))
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		r.logs.Error("Failed to create job", zap.Error(err))
+		return
+	}
+	r.logs.Info("Scheduler job created to refund late settlements", zap.String("job", "RefundLateSettlements"), zap.Duration("interval", r.checkSchedulerDuration))
+
+	_, err = r.scheduler.NewJob(
+		gocron.DurationJob(time.Hour),
+		gocron.NewTask(func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, 4*time.Minute)
+			defer cancel()
+
+			if err := r.usecase.DeleteExpiredIdempotencyKeys(ctx); err != nil {
+				r.logs.Error("Failed to delete expired idempotency keys", zap.Error(err))
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		r.logs.Error("Failed to create job", zap.Error(err))
+		return
+	}
+	r.logs.Info("Scheduler job created to delete expired idempotency keys", zap.String("job", "DeleteExpiredIdempotencyKeys"), zap.Duration("interval", time.Hour))
+
+	_, err = r.scheduler.NewJob(
+		gocron.DurationJob(24*time.Hour),
+		gocron.NewTask(func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, 4*time.Minute)
+			defer cancel()
+
+			if err := r.usecase.DeleteStaleGuestCarts(ctx); err != nil {
+				r.logs.Error("Failed to delete stale guest carts", zap.Error(err))
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		r.logs.Error("Failed to create job", zap.Error(err))
+		return
+	}
+	r.logs.Info("Scheduler job created to delete stale guest carts", zap.String("job", "DeleteStaleGuestCarts"), zap.Duration("interval", 24*time.Hour))
 	r.scheduler.Start()
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/transaction-svc/internal/gateway/task"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/saga"
 	"go-saga-pattern/transaction-svc/internal/usecase/contract"
 
 	"github.com/bytedance/sonic"
@@ -16,15 +18,19 @@ import (
 type TransactionTaskHandler interface {
 	HandleExpire(ctx context.Context, t *asynq.Task) error
 	HandleFinalExpire(ctx context.Context, t *asynq.Task) error
+	HandlePaymentToken(ctx context.Context, t *asynq.Task) error
 }
 
 type transactionTaskHandler struct {
+	transactionUseCase contract.TransactionUseCase
 	cancelationUseCase contract.CancelationUseCase
 	log                logs.Log
 }
 
-func NewTransactionTaskHandler(cancelationUseCase contract.CancelationUseCase, log logs.Log) TransactionTaskHandler {
+func NewTransactionTaskHandler(transactionUseCase contract.TransactionUseCase, cancelationUseCase contract.CancelationUseCase,
+	log logs.Log) TransactionTaskHandler {
 	return &transactionTaskHandler{
+		transactionUseCase: transactionUseCase,
 		cancelationUseCase: cancelationUseCase,
 		log:                log,
 	}
@@ -65,3 +71,32 @@ func (th *transactionTaskHandler) HandleFinalExpire(ctx context.Context, t *asyn
 
 	return nil
 }
+
+func (th *transactionTaskHandler) HandlePaymentToken(ctx context.Context, t *asynq.Task) error {
+	var p task.TransactionPaymentTokenPayload
+	if err := sonic.ConfigFastest.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if p.TransactionID == uuid.Nil {
+		return fmt.Errorf("transaction_id is required: %w", asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	err := th.transactionUseCase.ObtainPaymentToken(ctx, &model.ObtainPaymentTokenRequest{
+		TransactionID: p.TransactionID,
+		LastAttempt:   retried >= maxRetry,
+	})
+	if err != nil {
+		th.log.Warn("failed to obtain payment token", zap.Error(err), zap.String("transaction_id", p.TransactionID.String()),
+			zap.Int("retried", retried), zap.Int("max_retry", maxRetry))
+		if saga.IsPermanent(err) {
+			return fmt.Errorf("failed to obtain payment token: %v: %w", err, asynq.SkipRetry)
+		}
+		// keep the provider error wrapped, the retry delay waits longer for an open circuit breaker
+		return fmt.Errorf("failed to obtain payment token: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/transaction-svc/internal/delivery/web/middleware"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// cartIDHeader carries the id of a guest cart, it is returned on every guest cart response so the client can keep it.
+const cartIDHeader = "X-Cart-ID"
+
+type CartController interface {
+	UserGet(ctx *fiber.Ctx) error
+	UserAddItem(ctx *fiber.Ctx) error
+	UserUpdateItem(ctx *fiber.Ctx) error
+	UserRemoveItem(ctx *fiber.Ctx) error
+	Merge(ctx *fiber.Ctx) error
+	Checkout(ctx *fiber.Ctx) error
+	GuestGet(ctx *fiber.Ctx) error
+	GuestAddItem(ctx *fiber.Ctx) error
+	GuestUpdateItem(ctx *fiber.Ctx) error
+	GuestRemoveItem(ctx *fiber.Ctx) error
+}
+
+type cartController struct {
+	cartUseCase contract.CartUseCase
+	logs        logs.Log
+}
+
+func NewCartController(cartUseCase contract.CartUseCase, logs logs.Log) CartController {
+	return &cartController{cartUseCase: cartUseCase, logs: logs}
+}
+
+func (c *cartController) UserGet(ctx *fiber.Ctx) error {
+	return c.get(ctx, userCartOwner(ctx))
+}
+
+func (c *cartController) UserAddItem(ctx *fiber.Ctx) error {
+	return c.addItem(ctx, userCartOwner(ctx))
+}
+
+func (c *cartController) UserUpdateItem(ctx *fiber.Ctx) error {
+	return c.updateItem(ctx, userCartOwner(ctx))
+}
+
+func (c *cartController) UserRemoveItem(ctx *fiber.Ctx) error {
+	return c.removeItem(ctx, userCartOwner(ctx))
+}
+
+func (c *cartController) GuestGet(ctx *fiber.Ctx) error {
+	owner, ok := guestCartOwner(ctx)
+	if !ok {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid cart id")
+	}
+	return c.get(ctx, owner)
+}
+
+func (c *cartController) GuestAddItem(ctx *fiber.Ctx) error {
+	owner, ok := guestCartOwner(ctx)
+	if !ok {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid cart id")
+	}
+	return c.addItem(ctx, owner)
+}
+
+func (c *cartController) GuestUpdateItem(ctx *fiber.Ctx) error {
+	owner, ok := guestCartOwner(ctx)
+	if !ok {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid cart id")
+	}
+	return c.updateItem(ctx, owner)
+}
+
+func (c *cartController) GuestRemoveItem(ctx *fiber.Ctx) error {
+	owner, ok := guestCartOwner(ctx)
+	if !ok {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid cart id")
+	}
+	return c.removeItem(ctx, owner)
+}
+
+func (c *cartController) get(ctx *fiber.Ctx, owner model.CartOwner) error {
+	response, err := c.cartUseCase.Get(ctx.UserContext(), &model.GetCartRequest{CartOwner: owner})
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get cart error : ", err, c.logs)
+	}
+
+	return c.cartResponseJSON(ctx, http.StatusOK, response)
+}
+
+func (c *cartController) addItem(ctx *fiber.Ctx, owner model.CartOwner) error {
+	request := new(model.AddCartItemRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+
+	request.CartOwner = owner
+	response, err := c.cartUseCase.AddItem(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Add cart item error : ", err, c.logs)
+	}
+
+	return c.cartResponseJSON(ctx, http.StatusOK, response)
+}
+
+func (c *cartController) updateItem(ctx *fiber.Ctx, owner model.CartOwner) error {
+	productID, err := uuid.Parse(ctx.Params("product_id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid product id")
+	}
+
+	variantID, ok := variantIDQuery(ctx)
+	if !ok {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid variant id")
+	}
+
+	request := new(model.UpdateCartItemRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+
+	request.CartOwner = owner
+	request.ProductID = productID
+	request.VariantID = variantID
+	response, err := c.cartUseCase.UpdateItem(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Update cart item error : ", err, c.logs)
+	}
+
+	return c.cartResponseJSON(ctx, http.StatusOK, response)
+}
+
+func (c *cartController) removeItem(ctx *fiber.Ctx, owner model.CartOwner) error {
+	productID, err := uuid.Parse(ctx.Params("product_id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid product id")
+	}
+
+	variantID, ok := variantIDQuery(ctx)
+	if !ok {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid variant id")
+	}
+
+	response, err := c.cartUseCase.RemoveItem(ctx.UserContext(), &model.RemoveCartItemRequest{
+		CartOwner: owner,
+		ProductID: productID,
+		VariantID: variantID,
+	})
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Remove cart item error : ", err, c.logs)
+	}
+
+	return c.cartResponseJSON(ctx, http.StatusOK, response)
+}
+
+func (c *cartController) Merge(ctx *fiber.Ctx) error {
+	request := new(model.MergeCartRequest)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			return helper.ErrBodyParserResponseJSON(ctx, err)
+		}
+	}
+
+	if request.GuestCartID == uuid.Nil && ctx.Get(cartIDHeader) != "" {
+		guestCartID, err := uuid.Parse(ctx.Get(cartIDHeader))
+		if err != nil {
+			return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid cart id")
+		}
+		request.GuestCartID = guestCartID
+	}
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+	response, err := c.cartUseCase.Merge(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Merge cart error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.CartResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (c *cartController) Checkout(ctx *fiber.Ctx) error {
+	request := new(model.CheckoutCartRequest)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			return helper.ErrBodyParserResponseJSON(ctx, err)
+		}
+	}
+
+	user := middleware.GetUser(ctx)
+	request.UserID = uuid.MustParse(user.ID)
+	response, err := c.cartUseCase.Checkout(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Checkout cart error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(web.WebResponse[*model.CreateTransactionResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (c *cartController) cartResponseJSON(ctx *fiber.Ctx, status int, response *model.CartResponse) error {
+	ctx.Set(cartIDHeader, response.ID)
+	return ctx.Status(status).JSON(web.WebResponse[*model.CartResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func userCartOwner(ctx *fiber.Ctx) model.CartOwner {
+	user := middleware.GetUser(ctx)
+	return model.CartOwner{UserID: uuid.MustParse(user.ID)}
+}
+
+// guestCartOwner reads the guest cart id from the header, a missing header means the guest has no cart yet.
+func guestCartOwner(ctx *fiber.Ctx) (model.CartOwner, bool) {
+	owner := model.CartOwner{}
+	if header := ctx.Get(cartIDHeader); header != "" {
+		cartID, err := uuid.Parse(header)
+		if err != nil {
+			return owner, false
+		}
+		owner.CartID = cartID
+	}
+	return owner, true
+}
+
+// variantIDQuery reads the variant of the cart item from the variant_id query, items of products without variants
+// have none.
+func variantIDQuery(ctx *fiber.Ctx) (uuid.NullUUID, bool) {
+	query := ctx.Query("variant_id")
+	if query == "" {
+		return uuid.NullUUID{}, true
+	}
+
+	variantID, err := uuid.Parse(query)
+	if err != nil {
+		return uuid.NullUUID{}, false
+	}
+	return uuid.NullUUID{UUID: variantID, Valid: true}, true
+}
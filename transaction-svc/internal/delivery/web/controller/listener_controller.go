@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"bytes"
+	"go-saga-pattern/commoner/constant/enum"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/commoner/web"
@@ -8,9 +10,9 @@ import (
 	"go-saga-pattern/transaction-svc/internal/usecase"
 
 	"net/http"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -28,18 +30,28 @@ func NewListenerController(listernerUseCase usecase.ListenerUseCase,
 	return &listenerController{listernerUseCase: listernerUseCase, logs: logs}
 }
 
+// NotifyTransaction accepts /webhook/notify for Midtrans and /webhook/:provider/notify for any registered payment provider.
 func (c *listenerController) NotifyTransaction(ctx *fiber.Ctx) error {
-	webhookRequest := new(model.WebhookNotifyRequest)
-	if err := ctx.BodyParser(webhookRequest); err != nil {
-		return helper.ErrBodyParserResponseJSON(ctx, err)
+	provider := enum.PaymentProviderMidtrans
+	if param := ctx.Params("provider"); param != "" {
+		provider = enum.PaymentProvider(strings.ToUpper(param))
 	}
-	if _, err := uuid.Parse(webhookRequest.OrderID); err != nil {
-		return fiber.NewError(http.StatusBadRequest, "invalid order id")
+
+	headers := make(map[string]string)
+	for key, values := range ctx.GetReqHeaders() {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
 	}
 
-	webhookRequest.Body = ctx.Body()
+	webhookRequest := &model.WebhookNotifyRequest{
+		Provider: provider,
+		Headers:  headers,
+		// fasthttp reuses the request body buffer once the handler returns
+		Body: bytes.Clone(ctx.Body()),
+	}
 
-	c.logs.Info("Notify transaction", zap.String("order_id", webhookRequest.OrderID), zap.String("extermal status", webhookRequest.MidtransTransactionStatus))
+	c.logs.Info("Notify transaction", zap.String("provider", string(webhookRequest.Provider)))
 	if err := c.listernerUseCase.ConsumeAndProduceWebhook(ctx.Context(), webhookRequest); err != nil {
 		return helper.ErrUseCaseResponseJSON(ctx, "Notify webhook : ", err, c.logs)
 	}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PromotionController interface {
+	Create(ctx *fiber.Ctx) error
+	Search(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	Deactivate(ctx *fiber.Ctx) error
+}
+
+type promotionController struct {
+	promotionUseCase contract.PromotionUseCase
+	logs             logs.Log
+}
+
+func NewPromotionController(promotionUseCase contract.PromotionUseCase, logs logs.Log) PromotionController {
+	return &promotionController{promotionUseCase: promotionUseCase, logs: logs}
+}
+
+func (c *promotionController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreatePromotionRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return helper.ErrBodyParserResponseJSON(ctx, err)
+	}
+
+	response, err := c.promotionUseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Create promotion error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(web.WebResponse[*model.PromotionResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (c *promotionController) Search(ctx *fiber.Ctx) error {
+	request := new(model.SearchPromotionsRequest)
+	request.ActiveOnly = ctx.QueryBool("active", false)
+	request.Page = ctx.QueryInt("page", 1)
+	request.Limit = ctx.QueryInt("limit", 10)
+
+	response, pageMetadata, err := c.promotionUseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Search promotions error : ", err, c.logs)
+	}
+
+	baseURL := ctx.BaseURL() + ctx.Path()
+	helper.GeneratePageURLs(baseURL, pageMetadata)
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[[]*model.PromotionResponse]{
+		Success:      true,
+		Data:         response,
+		PageMetadata: pageMetadata,
+	})
+}
+
+func (c *promotionController) Get(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid promotion id format")
+	}
+
+	response, err := c.promotionUseCase.Get(ctx.UserContext(), &model.GetPromotionRequest{ID: id})
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get promotion error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.PromotionResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (c *promotionController) Deactivate(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid promotion id format")
+	}
+
+	response, err := c.promotionUseCase.Deactivate(ctx.UserContext(), &model.DeactivatePromotionRequest{ID: id})
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Deactivate promotion error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.PromotionResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/transaction-svc/internal/delivery/web/middleware"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RefundController interface {
+	UserRefund(ctx *fiber.Ctx) error
+	OwnerRefund(ctx *fiber.Ctx) error
+	SearchRefunds(ctx *fiber.Ctx) error
+}
+
+type refundController struct {
+	refundUseCase contract.RefundUseCase
+	logs          logs.Log
+}
+
+func NewRefundController(refundUseCase contract.RefundUseCase, logs logs.Log) RefundController {
+	return &refundController{refundUseCase: refundUseCase, logs: logs}
+}
+
+func (c *refundController) UserRefund(ctx *fiber.Ctx) error {
+	return c.refund(ctx, enum.RefundInitiatorUser)
+}
+
+func (c *refundController) OwnerRefund(ctx *fiber.Ctx) error {
+	return c.refund(ctx, enum.RefundInitiatorOwner)
+}
+
+func (c *refundController) refund(ctx *fiber.Ctx, initiator enum.RefundInitiator) error {
+	transactionID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid transaction id")
+	}
+
+	request := new(model.RefundTransactionRequest)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			return helper.ErrBodyParserResponseJSON(ctx, err)
+		}
+	}
+
+	user := middleware.GetUser(ctx)
+	request.TransactionID = transactionID
+	request.UserID = uuid.MustParse(user.ID)
+	request.Initiator = initiator
+	response, err := c.refundUseCase.RefundTransaction(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Refund transaction error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusAccepted).JSON(web.WebResponse[*model.RefundResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (c *refundController) SearchRefunds(ctx *fiber.Ctx) error {
+	transactionID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid transaction id")
+	}
+
+	user := middleware.GetUser(ctx)
+	request := &model.GetTransactionRequest{
+		UserID:        uuid.MustParse(user.ID),
+		TransacitonID: transactionID,
+	}
+
+	response, err := c.refundUseCase.SearchRefunds(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Search refunds error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[[]*model.RefundResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
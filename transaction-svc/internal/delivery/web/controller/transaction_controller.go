@@ -1,36 +1,117 @@
 package controller
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/transaction-svc/internal/adapter"
 	"go-saga-pattern/transaction-svc/internal/delivery/web/middleware"
 	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/model/event"
 	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+	"time"
 
 	"net/http"
 
+	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
+// headerIdempotencyKey makes retries of POST /buy replay the first response instead of checking out again.
+const headerIdempotencyKey = "Idempotency-Key"
+
+// transactionEventsHeartbeat keeps idle status streams open behind proxies and detects clients that went away.
+const transactionEventsHeartbeat = 15 * time.Second
+
 type TransactionController interface {
 	CreateTransaction(ctx *fiber.Ctx) error
 	UserSearch(ctx *fiber.Ctx) error
 	UserSearchWithDetail(ctx *fiber.Ctx) error
 	OwnerSearchWithDetail(ctx *fiber.Ctx) error
+	UserGet(ctx *fiber.Ctx) error
+	GetCheckoutSaga(ctx *fiber.Ctx) error
+	CancelTransaction(ctx *fiber.Ctx) error
+	StreamEvents(ctx *fiber.Ctx) error
 }
 
 type transactionController struct {
 	transactionUseCase contract.TransactionUseCase
+	cancelationUseCase contract.CancelationUseCase
+	idempotencyUseCase contract.IdempotencyUseCase
+	statusAdapter      adapter.TransactionStatusAdapter
 	logs               logs.Log
 }
 
-func NewTransactionController(transactionUseCase contract.TransactionUseCase, logs logs.Log) TransactionController {
-	return &transactionController{transactionUseCase: transactionUseCase, logs: logs}
+func NewTransactionController(transactionUseCase contract.TransactionUseCase, cancelationUseCase contract.CancelationUseCase,
+	idempotencyUseCase contract.IdempotencyUseCase, statusAdapter adapter.TransactionStatusAdapter, logs logs.Log) TransactionController {
+	return &transactionController{transactionUseCase: transactionUseCase, cancelationUseCase: cancelationUseCase,
+		idempotencyUseCase: idempotencyUseCase, statusAdapter: statusAdapter, logs: logs}
 }
 
+// CreateTransaction checks out at most once per Idempotency-Key. The first response is stored and replayed for 24
+// hours, a duplicate that arrives while the first request still runs gets 409. Server errors release the key so the
+// client can retry with it.
 func (c *transactionController) CreateTransaction(ctx *fiber.Ctx) error {
+	key := ctx.Get(headerIdempotencyKey)
+	if key == "" {
+		return c.createTransaction(ctx)
+	}
+
+	user := middleware.GetUser(ctx)
+	requestHash := sha256.Sum256(ctx.Body())
+	idempotencyRequest := &model.IdempotencyRequest{
+		UserID:      uuid.MustParse(user.ID),
+		Key:         key,
+		RequestHash: hex.EncodeToString(requestHash[:]),
+	}
+
+	replay, err := c.idempotencyUseCase.Begin(ctx.UserContext(), idempotencyRequest)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Idempotency key error : ", err, c.logs)
+	}
+
+	if replay != nil {
+		ctx.Set("Idempotent-Replayed", "true")
+		ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return ctx.Status(replay.StatusCode).Send(replay.Body)
+	}
+
+	if err := c.createTransaction(ctx); err != nil {
+		c.releaseIdempotencyKey(idempotencyRequest)
+		return err
+	}
+
+	statusCode := ctx.Response().StatusCode()
+	if statusCode >= http.StatusInternalServerError {
+		c.releaseIdempotencyKey(idempotencyRequest)
+		return nil
+	}
+
+	// fasthttp reuses the response body buffer once the handler returns
+	body := append([]byte(nil), ctx.Response().Body()...)
+	if err := c.idempotencyUseCase.Complete(context.Background(), idempotencyRequest, &model.IdempotentResponse{
+		StatusCode: statusCode,
+		Body:       body,
+	}); err != nil {
+		c.logs.Error("failed to store idempotent response", zap.String("idempotency_key", key), zap.Error(err))
+	}
+	return nil
+}
+
+func (c *transactionController) releaseIdempotencyKey(request *model.IdempotencyRequest) {
+	if err := c.idempotencyUseCase.Release(context.Background(), request); err != nil {
+		c.logs.Error("failed to release idempotency key", zap.String("idempotency_key", request.Key), zap.Error(err))
+	}
+}
+
+func (c *transactionController) createTransaction(ctx *fiber.Ctx) error {
 	request := new(model.CreateTransactionRequest)
 	if err := ctx.BodyParser(request); err != nil {
 		return helper.ErrBodyParserResponseJSON(ctx, err)
@@ -121,3 +202,151 @@ func (c *transactionController) OwnerSearchWithDetail(ctx *fiber.Ctx) error {
 		PageMetadata: pageMetadata,
 	})
 }
+
+func (c *transactionController) UserGet(ctx *fiber.Ctx) error {
+	transactionID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid transaction id")
+	}
+
+	user := middleware.GetUser(ctx)
+	request := &model.GetTransactionRequest{
+		UserID:        uuid.MustParse(user.ID),
+		TransacitonID: transactionID,
+	}
+
+	response, err := c.transactionUseCase.UserGet(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get transaction error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.TransactionResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (c *transactionController) GetCheckoutSaga(ctx *fiber.Ctx) error {
+	transactionID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid transaction id")
+	}
+
+	user := middleware.GetUser(ctx)
+	request := &model.GetTransactionRequest{
+		UserID:        uuid.MustParse(user.ID),
+		TransacitonID: transactionID,
+	}
+
+	response, err := c.transactionUseCase.GetCheckoutSaga(ctx.UserContext(), request)
+	if err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Get checkout saga error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[*model.SagaResponse]{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (c *transactionController) CancelTransaction(ctx *fiber.Ctx) error {
+	transactionID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid transaction id")
+	}
+
+	user := middleware.GetUser(ctx)
+	request := &model.CancelTransactionRequest{
+		UserID:        uuid.MustParse(user.ID),
+		TransactionID: transactionID,
+	}
+
+	if err := c.cancelationUseCase.UserCancelTransaction(ctx.UserContext(), request); err != nil {
+		return helper.ErrUseCaseResponseJSON(ctx, "Cancel transaction error : ", err, c.logs)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(web.WebResponse[any]{
+		Success: true,
+	})
+}
+
+// StreamEvents streams the status changes of a transaction as server-sent events. The current status is sent first,
+// the subscription is made before it is loaded so a change in between is not lost.
+func (c *transactionController) StreamEvents(ctx *fiber.Ctx) error {
+	transactionID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return helper.ErrCustomResponseJSON(ctx, http.StatusBadRequest, "Invalid transaction id")
+	}
+
+	statusEvents, cancel := c.statusAdapter.Subscribe(transactionID.String())
+
+	user := middleware.GetUser(ctx)
+	response, err := c.transactionUseCase.UserGet(ctx.UserContext(), &model.GetTransactionRequest{
+		UserID:        uuid.MustParse(user.ID),
+		TransacitonID: transactionID,
+	})
+	if err != nil {
+		cancel()
+		return helper.ErrUseCaseResponseJSON(ctx, "Stream transaction events error : ", err, c.logs)
+	}
+
+	current := &event.TransactionStatusEvent{
+		TransactionID:     response.ID,
+		TransactionStatus: response.TransactionStatus,
+		UpdatedAt:         response.UpdatedAt,
+	}
+	if len(response.Timeline) > 0 {
+		current.Status = response.Timeline[len(response.Timeline)-1].Status
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		heartbeat := time.NewTicker(transactionEventsHeartbeat)
+		defer heartbeat.Stop()
+
+		if err := writeTransactionStatusEvent(w, current); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case statusEvent, ok := <-statusEvents:
+				if !ok {
+					return
+				}
+				if err := writeTransactionStatusEvent(w, statusEvent); err != nil {
+					c.logs.Info("transaction events client disconnected", zap.String("transaction_id", transactionID.String()))
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": heartbeat\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					c.logs.Info("transaction events client disconnected", zap.String("transaction_id", transactionID.String()))
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeTransactionStatusEvent(w *bufio.Writer, statusEvent *event.TransactionStatusEvent) error {
+	payload, err := sonic.ConfigFastest.Marshal(statusEvent)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,34 @@
+package route
+
+import (
+	"go-saga-pattern/commoner/dlq"
+	"go-saga-pattern/transaction-svc/internal/delivery/web/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminRoute struct {
+	app                  *fiber.App
+	deadLetterController dlq.DeadLetterController
+	promotionController  controller.PromotionController
+	adminMiddleware      fiber.Handler
+}
+
+func NewAdminRoute(app *fiber.App, deadLetterController dlq.DeadLetterController, promotionController controller.PromotionController,
+	adminMiddleware fiber.Handler) *AdminRoute {
+	return &AdminRoute{
+		app:                  app,
+		deadLetterController: deadLetterController,
+		promotionController:  promotionController,
+		adminMiddleware:      adminMiddleware,
+	}
+}
+
+func (r *AdminRoute) RegisterRoutes() {
+	adminRoutes := r.app.Group("/api/v1/admin", r.adminMiddleware)
+	dlq.RegisterRoutes(adminRoutes, r.deadLetterController)
+	adminRoutes.Post("/promotions", r.promotionController.Create)
+	adminRoutes.Get("/promotions", r.promotionController.Search)
+	adminRoutes.Get("/promotions/:id", r.promotionController.Get)
+	adminRoutes.Delete("/promotions/:id", r.promotionController.Deactivate)
+}
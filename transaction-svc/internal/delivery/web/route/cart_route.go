@@ -0,0 +1,37 @@
+package route
+
+import (
+	"go-saga-pattern/transaction-svc/internal/delivery/web/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartRoute struct {
+	app            *fiber.App
+	cartController controller.CartController
+	userMiddleware fiber.Handler
+}
+
+func NewCartRoute(app *fiber.App, cartController controller.CartController, userMiddleware fiber.Handler) *CartRoute {
+	return &CartRoute{
+		app:            app,
+		cartController: cartController,
+		userMiddleware: userMiddleware,
+	}
+}
+
+func (r *CartRoute) RegisterRoutes() {
+	userRoutes := r.app.Group("/api/v1/cart", r.userMiddleware)
+	userRoutes.Get("/", r.cartController.UserGet)
+	userRoutes.Post("/items", r.cartController.UserAddItem)
+	userRoutes.Put("/items/:product_id", r.cartController.UserUpdateItem)
+	userRoutes.Delete("/items/:product_id", r.cartController.UserRemoveItem)
+	userRoutes.Post("/merge", r.cartController.Merge)
+	userRoutes.Post("/checkout", r.cartController.Checkout)
+
+	guestRoutes := r.app.Group("/api/v1/guest/cart")
+	guestRoutes.Get("/", r.cartController.GuestGet)
+	guestRoutes.Post("/items", r.cartController.GuestAddItem)
+	guestRoutes.Put("/items/:product_id", r.cartController.GuestUpdateItem)
+	guestRoutes.Delete("/items/:product_id", r.cartController.GuestRemoveItem)
+}
@@ -21,4 +21,5 @@ func NewListenerRoute(app *fiber.App, listenerController controller.ListenerCont
 func (r *ListenerRoute) RegisterRoutes() {
 	listenerRoute := r.app.Group("/api/v1/transaction")
 	listenerRoute.Post("/webhook/notify", r.listenerController.NotifyTransaction)
+	listenerRoute.Post("/webhook/:provider/notify", r.listenerController.NotifyTransaction)
 }
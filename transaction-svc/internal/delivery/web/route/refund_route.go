@@ -0,0 +1,28 @@
+package route
+
+import (
+	"go-saga-pattern/transaction-svc/internal/delivery/web/controller"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RefundRoute struct {
+	app              *fiber.App
+	refundController controller.RefundController
+	userMiddleware   fiber.Handler
+}
+
+func NewRefundRoute(app *fiber.App, refundController controller.RefundController, userMiddleware fiber.Handler) *RefundRoute {
+	return &RefundRoute{
+		app:              app,
+		refundController: refundController,
+		userMiddleware:   userMiddleware,
+	}
+}
+
+func (r *RefundRoute) RegisterRoutes() {
+	userRoutes := r.app.Group("/api/v1/transaction", r.userMiddleware)
+	userRoutes.Post("/:id/refund", r.refundController.UserRefund)
+	userRoutes.Get("/:id/refunds", r.refundController.SearchRefunds)
+	userRoutes.Post("/owner/:id/refund", r.refundController.OwnerRefund)
+}
@@ -26,4 +26,8 @@ func (r *TransactionRoute) RegisterRoutes() {
 	userRoutes.Get("/", r.transactionController.UserSearch)
 	userRoutes.Get("/detail", r.transactionController.UserSearchWithDetail)
 	userRoutes.Get("/owner/detail", r.transactionController.OwnerSearchWithDetail)
+	userRoutes.Get("/:id", r.transactionController.UserGet)
+	userRoutes.Get("/:id/saga", r.transactionController.GetCheckoutSaga)
+	userRoutes.Get("/:id/events", r.transactionController.StreamEvents)
+	userRoutes.Post("/:id/cancel", r.transactionController.CancelTransaction)
 }
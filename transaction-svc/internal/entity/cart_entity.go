@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cart belongs to a user, or to a guest when UserID is empty.
+type Cart struct {
+	ID        uuid.UUID     `db:"id"`
+	UserID    uuid.NullUUID `db:"user_id"`
+	CreatedAt *time.Time    `db:"created_at"`
+	UpdatedAt *time.Time    `db:"updated_at"`
+}
+
+// CartItem is a product in the cart, or one of its variants when VariantID is set.
+type CartItem struct {
+	CartID    uuid.UUID     `db:"cart_id"`
+	ProductID uuid.UUID     `db:"product_id"`
+	VariantID uuid.NullUUID `db:"variant_id"`
+	Quantity  int           `db:"quantity"`
+	CreatedAt *time.Time    `db:"created_at"`
+	UpdatedAt *time.Time    `db:"updated_at"`
+}
+
+// MergeCartItems adds up the quantities of the items of the same product and variant, in the order they first
+// appear. An insert cannot touch the same cart item twice, so items are merged before they are added to a cart.
+func MergeCartItems(itemSets ...[]*CartItem) []*CartItem {
+	type cartItemKey struct {
+		productID uuid.UUID
+		variantID uuid.NullUUID
+	}
+
+	merged := make([]*CartItem, 0)
+	positions := make(map[cartItemKey]int)
+	for _, items := range itemSets {
+		for _, item := range items {
+			key := cartItemKey{productID: item.ProductID, variantID: item.VariantID}
+			if position, ok := positions[key]; ok {
+				merged[position].Quantity += item.Quantity
+				continue
+			}
+
+			positions[key] = len(merged)
+			mergedItem := *item
+			merged = append(merged, &mergedItem)
+		}
+	}
+	return merged
+}
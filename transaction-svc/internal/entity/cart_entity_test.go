@@ -0,0 +1,74 @@
+package entity_test
+
+import (
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	smallVariantID = uuid.NullUUID{UUID: uuid.MustParse("00000000-0000-0000-0000-0000000000b1"), Valid: true}
+	largeVariantID = uuid.NullUUID{UUID: uuid.MustParse("00000000-0000-0000-0000-0000000000b2"), Valid: true}
+)
+
+func TestMergeCartItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		itemSets [][]*entity.CartItem
+		want     []*entity.CartItem
+	}{
+		{
+			name: "a guest cart adds to the quantities of the user cart",
+			itemSets: [][]*entity.CartItem{
+				{{ProductID: promotedProductID, Quantity: 2}, {ProductID: otherProductID, Quantity: 1}},
+				{{ProductID: otherProductID, Quantity: 3}},
+			},
+			want: []*entity.CartItem{{ProductID: promotedProductID, Quantity: 2}, {ProductID: otherProductID, Quantity: 4}},
+		},
+		{
+			name: "the variants of a product are kept apart",
+			itemSets: [][]*entity.CartItem{
+				{{ProductID: promotedProductID, VariantID: smallVariantID, Quantity: 1}},
+				{
+					{ProductID: promotedProductID, VariantID: largeVariantID, Quantity: 2},
+					{ProductID: promotedProductID, VariantID: smallVariantID, Quantity: 5},
+				},
+			},
+			want: []*entity.CartItem{
+				{ProductID: promotedProductID, VariantID: smallVariantID, Quantity: 6},
+				{ProductID: promotedProductID, VariantID: largeVariantID, Quantity: 2},
+			},
+		},
+		{
+			name: "duplicates within one set are added up",
+			itemSets: [][]*entity.CartItem{
+				{{ProductID: otherProductID, Quantity: 1}, {ProductID: promotedProductID, Quantity: 1}, {ProductID: otherProductID, Quantity: 1}},
+			},
+			want: []*entity.CartItem{{ProductID: otherProductID, Quantity: 2}, {ProductID: promotedProductID, Quantity: 1}},
+		},
+		{
+			name:     "no items",
+			itemSets: [][]*entity.CartItem{nil, {}},
+			want:     []*entity.CartItem{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, entity.MergeCartItems(tt.itemSets...))
+		})
+	}
+}
+
+func TestMergeCartItems_LeavesTheItemsAlone(t *testing.T) {
+	userItems := []*entity.CartItem{{ProductID: promotedProductID, Quantity: 2}}
+	guestItems := []*entity.CartItem{{ProductID: promotedProductID, Quantity: 3}}
+
+	merged := entity.MergeCartItems(userItems, guestItems)
+
+	assert.Equal(t, 5, merged[0].Quantity)
+	assert.Equal(t, 2, userItems[0].Quantity)
+	assert.Equal(t, 3, guestItems[0].Quantity)
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"database/sql"
+	"go-saga-pattern/commoner/constant/enum"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IdempotencyKey struct {
+	UserID         uuid.UUID              `db:"user_id"`
+	IdempotencyKey string                 `db:"idempotency_key"`
+	RequestHash    string                 `db:"request_hash"`
+	Status         enum.IdempotencyStatus `db:"status"`
+	ResponseStatus sql.NullInt32          `db:"response_status"`
+	ResponseBody   []byte                 `db:"response_body"`
+	LockedUntil    sql.NullTime           `db:"locked_until"`
+	CreatedAt      *time.Time             `db:"created_at"`
+	CompletedAt    sql.NullTime           `db:"completed_at"`
+	ExpiresAt      *time.Time             `db:"expires_at"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"database/sql"
+	"encoding/json"
+	"go-saga-pattern/commoner/constant/enum"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxEvent struct {
+	ID            uuid.UUID         `db:"id"`
+	AggregateID   uuid.UUID         `db:"aggregate_id"`
+	Subject       string            `db:"subject"`
+	Payload       json.RawMessage   `db:"payload"`
+	Status        enum.OutboxStatus `db:"status"`
+	Attempts      int               `db:"attempts"`
+	LastError     sql.NullString    `db:"last_error"`
+	NextAttemptAt *time.Time        `db:"next_attempt_at"`
+	CreatedAt     *time.Time        `db:"created_at"`
+	SentAt        sql.NullTime      `db:"sent_at"`
+}
+
+type OutboxLag struct {
+	PendingCount    int64        `db:"pending_count"`
+	FailedCount     int64        `db:"failed_count"`
+	OldestPendingAt sql.NullTime `db:"oldest_pending_at"`
+}
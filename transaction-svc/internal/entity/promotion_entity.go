@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"database/sql"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Promotion struct {
+	ID                    uuid.UUID           `db:"id"`
+	Code                  string              `db:"code"`
+	Name                  string              `db:"name"`
+	Type                  enum.PromotionType  `db:"type"`
+	Scope                 enum.PromotionScope `db:"scope"`
+	ScopeID               uuid.NullUUID       `db:"scope_id"`
+	PercentageBasisPoints int64               `db:"percentage_basis_points"`
+	Amount                money.Money         `db:"amount"`
+	Currency              string              `db:"currency"`
+	BuyQuantity           int                 `db:"buy_quantity"`
+	GetQuantity           int                 `db:"get_quantity"`
+	UsageLimit            sql.NullInt64       `db:"usage_limit"`
+	UsageLimitPerUser     sql.NullInt64       `db:"usage_limit_per_user"`
+	UsageCount            int                 `db:"usage_count"`
+	StartsAt              sql.NullTime        `db:"starts_at"`
+	EndsAt                sql.NullTime        `db:"ends_at"`
+	IsActive              bool                `db:"is_active"`
+	CreatedAt             *time.Time          `db:"created_at"`
+	UpdatedAt             *time.Time          `db:"updated_at"`
+}
+
+func (p *Promotion) ApplyCurrency() {
+	p.Amount = p.Amount.WithCurrency(p.Currency)
+}
+
+// IsRedeemableAt tells whether the promotion is active and within its period at the given time.
+func (p *Promotion) IsRedeemableAt(now time.Time) bool {
+	if !p.IsActive {
+		return false
+	}
+	if p.StartsAt.Valid && now.Before(p.StartsAt.Time) {
+		return false
+	}
+	return !p.EndsAt.Valid || now.Before(p.EndsAt.Time)
+}
+
+type PromotionUsage struct {
+	PromotionID   uuid.UUID                 `db:"promotion_id"`
+	TransactionID uuid.UUID                 `db:"transaction_id"`
+	UserID        uuid.UUID                 `db:"user_id"`
+	Status        enum.PromotionUsageStatus `db:"status"`
+	CreatedAt     *time.Time                `db:"created_at"`
+	UpdatedAt     *time.Time                `db:"updated_at"`
+}
+
+// TransactionDetailDiscount is what one promotion took off one transaction detail, in the currency of the transaction.
+type TransactionDetailDiscount struct {
+	TransactionDetailID uuid.UUID   `db:"transaction_detail_id"`
+	PromotionID         uuid.UUID   `db:"promotion_id"`
+	PromotionCode       string      `db:"promotion_code"`
+	Amount              money.Money `db:"amount"`
+	CreatedAt           *time.Time  `db:"created_at"`
+}
+
+type PromotionWithTotal struct {
+	Promotion
+	Total int `db:"total"`
+}
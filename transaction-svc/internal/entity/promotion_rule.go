@@ -0,0 +1,138 @@
+package entity
+
+import (
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+
+	"github.com/google/uuid"
+)
+
+// PromotionLine is an order line as promotions see it. Price is the unit price times the quantity and Discount is
+// what the promotions applied before took off the line already.
+type PromotionLine struct {
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	OwnerID   uuid.UUID
+	Quantity  int
+	Price     money.Money
+	Discount  money.Money
+}
+
+// AppliesTo tells whether the line is in the scope of the promotion.
+func (p *Promotion) AppliesTo(line *PromotionLine) bool {
+	switch p.Scope {
+	case enum.PromotionScopeProduct:
+		return p.ScopeID.Valid && line.ProductID == p.ScopeID.UUID
+	case enum.PromotionScopeOwner:
+		return p.ScopeID.Valid && line.OwnerID == p.ScopeID.UUID
+	default:
+		return true
+	}
+}
+
+// Discounts returns what the promotion takes off every line, zero for the lines out of its scope. A line never gets
+// more off than what the promotions applied before left of it, so stacked promotions cannot make a line negative.
+//   - PERCENTAGE takes the percentage off what is left of the line.
+//   - FIXED spreads its amount over the lines in proportion to what is left of them, at most down to zero.
+//   - BUY_X_GET_Y gives get_quantity items of a line for free for every buy_quantity items paid for.
+func (p *Promotion) Discounts(lines []*PromotionLine) ([]money.Money, error) {
+	discounts := make([]money.Money, len(lines))
+	remaining := make([]money.Money, len(lines))
+	eligible := make([]int, 0, len(lines))
+	for i, line := range lines {
+		left, err := line.Price.Sub(line.Discount)
+		if err != nil {
+			return nil, err
+		}
+
+		discounts[i] = money.Zero(line.Price.Currency)
+		remaining[i] = left
+		if p.AppliesTo(line) && left.IsPositive() {
+			eligible = append(eligible, i)
+		}
+	}
+
+	switch p.Type {
+	case enum.PromotionTypePercentage:
+		for _, i := range eligible {
+			discount, err := remaining[i].Prorate(p.PercentageBasisPoints, 10000)
+			if err != nil {
+				return nil, err
+			}
+			discounts[i] = discount
+		}
+
+	case enum.PromotionTypeBuyXGetY:
+		group := p.BuyQuantity + p.GetQuantity
+		if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+			return nil, fmt.Errorf("promotion %s buys %d and gets %d items", p.Code, p.BuyQuantity, p.GetQuantity)
+		}
+
+		for _, i := range eligible {
+			free := lines[i].Quantity / group * p.GetQuantity
+			discount, err := lines[i].Price.Prorate(int64(free), int64(lines[i].Quantity))
+			if err != nil {
+				return nil, err
+			}
+
+			if cmp, err := discount.Cmp(remaining[i]); err != nil {
+				return nil, err
+			} else if cmp > 0 {
+				discount = remaining[i]
+			}
+			discounts[i] = discount
+		}
+
+	case enum.PromotionTypeFixed:
+		if len(eligible) == 0 {
+			return discounts, nil
+		}
+
+		eligibleRemaining := make([]money.Money, 0, len(eligible))
+		for _, i := range eligible {
+			eligibleRemaining = append(eligibleRemaining, remaining[i])
+		}
+
+		total, err := money.Sum(remaining[eligible[0]].Currency, eligibleRemaining...)
+		if err != nil {
+			return nil, err
+		}
+
+		amount := p.Amount
+		if cmp, err := amount.Cmp(total); err != nil {
+			return nil, err
+		} else if cmp > 0 {
+			amount = total
+		}
+
+		// Every line but the last gets its share rounded, the last one gets what is left so the shares add up
+		allocated := money.Zero(amount.Currency)
+		for k, i := range eligible {
+			share := amount
+			if k < len(eligible)-1 {
+				if share, err = amount.Prorate(remaining[i].Amount, total.Amount); err != nil {
+					return nil, err
+				}
+			} else if share, err = amount.Sub(allocated); err != nil {
+				return nil, err
+			}
+
+			if cmp, err := share.Cmp(remaining[i]); err != nil {
+				return nil, err
+			} else if cmp > 0 {
+				share = remaining[i]
+			}
+
+			if allocated, err = allocated.Add(share); err != nil {
+				return nil, err
+			}
+			discounts[i] = share
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown promotion type %q", p.Type)
+	}
+
+	return discounts, nil
+}
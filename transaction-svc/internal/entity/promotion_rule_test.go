@@ -0,0 +1,166 @@
+package entity_test
+
+import (
+	"database/sql"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	promotedProductID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	otherProductID    = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	promotedOwnerID   = uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+	otherOwnerID      = uuid.MustParse("00000000-0000-0000-0000-0000000000a2")
+)
+
+func idr(amount int64) money.Money {
+	return money.New(amount, "IDR")
+}
+
+// promotionLines is an order of 3 promoted items at Rp100.00 from the promoted owner and 1 other item at Rp50.00.
+func promotionLines() []*entity.PromotionLine {
+	return []*entity.PromotionLine{
+		{ProductID: promotedProductID, OwnerID: promotedOwnerID, Quantity: 3, Price: idr(30000), Discount: idr(0)},
+		{ProductID: otherProductID, OwnerID: otherOwnerID, Quantity: 1, Price: idr(5000), Discount: idr(0)},
+	}
+}
+
+func TestPromotion_Discounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion *entity.Promotion
+		lines     []*entity.PromotionLine
+		want      []money.Money
+	}{
+		{
+			name:      "percentage on every line",
+			promotion: &entity.Promotion{Type: enum.PromotionTypePercentage, Scope: enum.PromotionScopeAll, PercentageBasisPoints: 1250},
+			lines:     promotionLines(),
+			want:      []money.Money{idr(3750), idr(625)},
+		},
+		{
+			name: "percentage scoped to a product",
+			promotion: &entity.Promotion{Type: enum.PromotionTypePercentage, Scope: enum.PromotionScopeProduct,
+				ScopeID: uuid.NullUUID{UUID: promotedProductID, Valid: true}, PercentageBasisPoints: 1000},
+			lines: promotionLines(),
+			want:  []money.Money{idr(3000), idr(0)},
+		},
+		{
+			name: "percentage scoped to an owner takes off what earlier promotions left",
+			promotion: &entity.Promotion{Type: enum.PromotionTypePercentage, Scope: enum.PromotionScopeOwner,
+				ScopeID: uuid.NullUUID{UUID: promotedOwnerID, Valid: true}, PercentageBasisPoints: 5000},
+			lines: []*entity.PromotionLine{
+				{ProductID: promotedProductID, OwnerID: promotedOwnerID, Quantity: 3, Price: idr(30000), Discount: idr(10000)},
+				{ProductID: otherProductID, OwnerID: otherOwnerID, Quantity: 1, Price: idr(5000), Discount: idr(0)},
+			},
+			want: []money.Money{idr(10000), idr(0)},
+		},
+		{
+			name:      "fixed amount is spread in proportion to the lines",
+			promotion: &entity.Promotion{Type: enum.PromotionTypeFixed, Scope: enum.PromotionScopeAll, Amount: idr(7000)},
+			lines:     promotionLines(),
+			want:      []money.Money{idr(6000), idr(1000)},
+		},
+		{
+			name:      "fixed amount above the order takes it down to zero",
+			promotion: &entity.Promotion{Type: enum.PromotionTypeFixed, Scope: enum.PromotionScopeAll, Amount: idr(100000)},
+			lines:     promotionLines(),
+			want:      []money.Money{idr(30000), idr(5000)},
+		},
+		{
+			name: "fixed amount out of scope",
+			promotion: &entity.Promotion{Type: enum.PromotionTypeFixed, Scope: enum.PromotionScopeProduct,
+				ScopeID: uuid.NullUUID{UUID: uuid.New(), Valid: true}, Amount: idr(1000)},
+			lines: promotionLines(),
+			want:  []money.Money{idr(0), idr(0)},
+		},
+		{
+			name: "buy 2 get 1 frees one item of every three",
+			promotion: &entity.Promotion{Type: enum.PromotionTypeBuyXGetY, Scope: enum.PromotionScopeAll,
+				BuyQuantity: 2, GetQuantity: 1},
+			lines: promotionLines(),
+			want:  []money.Money{idr(10000), idr(0)},
+		},
+		{
+			name: "buy 1 get 1 is capped at what is left of the line",
+			promotion: &entity.Promotion{Type: enum.PromotionTypeBuyXGetY, Scope: enum.PromotionScopeAll,
+				BuyQuantity: 1, GetQuantity: 1},
+			lines: []*entity.PromotionLine{
+				{ProductID: promotedProductID, OwnerID: promotedOwnerID, Quantity: 4, Price: idr(40000), Discount: idr(35000)},
+			},
+			want: []money.Money{idr(5000)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discounts, err := tt.promotion.Discounts(tt.lines)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, discounts)
+		})
+	}
+}
+
+func TestPromotion_DiscountsRejectsAnAmountInAnotherCurrency(t *testing.T) {
+	promotion := &entity.Promotion{Type: enum.PromotionTypeFixed, Scope: enum.PromotionScopeAll, Amount: money.New(500, "USD")}
+
+	_, err := promotion.Discounts(promotionLines())
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
+func TestPromotion_FixedSharesAddUpToTheAmount(t *testing.T) {
+	promotion := &entity.Promotion{Type: enum.PromotionTypeFixed, Scope: enum.PromotionScopeAll, Amount: idr(1000)}
+	lines := []*entity.PromotionLine{
+		{Quantity: 1, Price: idr(3333), Discount: idr(0)},
+		{Quantity: 1, Price: idr(3333), Discount: idr(0)},
+		{Quantity: 1, Price: idr(3334), Discount: idr(0)},
+	}
+
+	discounts, err := promotion.Discounts(lines)
+	require.NoError(t, err)
+
+	total, err := money.Sum("IDR", discounts...)
+	require.NoError(t, err)
+	assert.Equal(t, idr(1000), total)
+}
+
+func TestPromotion_IsRedeemableAt(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		promotion *entity.Promotion
+		want      bool
+	}{
+		{name: "active without a period", promotion: &entity.Promotion{IsActive: true}, want: true},
+		{name: "inactive", promotion: &entity.Promotion{IsActive: false}, want: false},
+		{
+			name:      "not started yet",
+			promotion: &entity.Promotion{IsActive: true, StartsAt: sql.NullTime{Time: now.Add(time.Hour), Valid: true}},
+			want:      false,
+		},
+		{
+			name:      "ended",
+			promotion: &entity.Promotion{IsActive: true, EndsAt: sql.NullTime{Time: now, Valid: true}},
+			want:      false,
+		},
+		{
+			name: "within its period",
+			promotion: &entity.Promotion{IsActive: true, StartsAt: sql.NullTime{Time: now.Add(-time.Hour), Valid: true},
+				EndsAt: sql.NullTime{Time: now.Add(time.Hour), Valid: true}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.promotion.IsRedeemableAt(now))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"database/sql"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Refund struct {
+	ID               uuid.UUID              `db:"id"`
+	TransactionID    uuid.UUID              `db:"transaction_id"`
+	Initiator        enum.RefundInitiator   `db:"initiator"`
+	RequestedBy      uuid.NullUUID          `db:"requested_by"`
+	Status           enum.RefundStatus      `db:"status"`
+	PreviousStatus   enum.TrxInternalStatus `db:"previous_status"`
+	Amount           money.Money            `db:"amount"`
+	Currency         string                 `db:"currency"`
+	Reason           sql.NullString         `db:"reason"`
+	PaymentProvider  enum.PaymentProvider   `db:"payment_provider"`
+	ProviderRefundID sql.NullString         `db:"provider_refund_id"`
+	FailureReason    sql.NullString         `db:"failure_reason"`
+	CreatedAt        *time.Time             `db:"created_at"`
+	CompletedAt      sql.NullTime           `db:"completed_at"`
+	UpdatedAt        *time.Time             `db:"updated_at"`
+}
+
+// RefundItem is a refunded transaction detail, VariantID is read from the transaction detail and not stored on the item.
+type RefundItem struct {
+	RefundID            uuid.UUID     `db:"refund_id"`
+	TransactionDetailID uuid.UUID     `db:"transaction_detail_id"`
+	ProductID           uuid.UUID     `db:"product_id"`
+	VariantID           uuid.NullUUID `db:"variant_id"`
+	Quantity            int           `db:"quantity"`
+	Amount              money.Money   `db:"amount"`
+	Currency            string        `db:"currency"`
+}
+
+func (r *Refund) ApplyCurrency() {
+	r.Amount = r.Amount.WithCurrency(r.Currency)
+}
+
+// ApplyCurrency puts the currency of the refund, selected along with the item, on the scanned amount.
+func (i *RefundItem) ApplyCurrency() {
+	i.Amount = i.Amount.WithCurrency(i.Currency)
+}
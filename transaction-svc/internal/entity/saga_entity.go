@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"database/sql"
+	"encoding/json"
+	"go-saga-pattern/commoner/constant/enum"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SagaInstance struct {
+	ID          uuid.UUID       `db:"id"`
+	SagaType    string          `db:"saga_type"`
+	AggregateID uuid.UUID       `db:"aggregate_id"`
+	Status      enum.SagaStatus `db:"status"`
+	CurrentStep sql.NullString  `db:"current_step"`
+	Payload     json.RawMessage `db:"payload"`
+	LastError   sql.NullString  `db:"last_error"`
+	NextRunAt   sql.NullTime    `db:"next_run_at"`
+	CreatedAt   *time.Time      `db:"created_at"`
+	UpdatedAt   *time.Time      `db:"updated_at"`
+}
+
+type SagaStep struct {
+	ID             uuid.UUID           `db:"id"`
+	SagaInstanceID uuid.UUID           `db:"saga_instance_id"`
+	StepName       string              `db:"step_name"`
+	StepOrder      int                 `db:"step_order"`
+	Status         enum.SagaStepStatus `db:"status"`
+	Attempts       int                 `db:"attempts"`
+	LastError      sql.NullString      `db:"last_error"`
+	NextRetryAt    sql.NullTime        `db:"next_retry_at"`
+	StartedAt      sql.NullTime        `db:"started_at"`
+	CompletedAt    sql.NullTime        `db:"completed_at"`
+	CompensatedAt  sql.NullTime        `db:"compensated_at"`
+	UpdatedAt      *time.Time          `db:"updated_at"`
+}
+
+type SagaSignal struct {
+	ID             int64          `db:"id"`
+	SagaInstanceID uuid.UUID      `db:"saga_instance_id"`
+	StepName       string         `db:"step_name"`
+	Error          sql.NullString `db:"error"`
+	CreatedAt      *time.Time     `db:"created_at"`
+}
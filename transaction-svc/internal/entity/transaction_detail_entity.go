@@ -1,16 +1,45 @@
 package entity
 
 import (
+	"database/sql"
+	"go-saga-pattern/commoner/money"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type TransactionDetail struct {
-	ID            uuid.UUID  `db:"id"`
-	TransactionID uuid.UUID  `db:"transaction_id"`
-	ProductID     uuid.UUID  `db:"product_id"`
-	Quantity      int        `db:"quantity"`
-	Price         float64    `db:"price"`
-	CreatedAt     *time.Time `db:"created_at"`
+	ID               uuid.UUID      `db:"id"`
+	TransactionID    uuid.UUID      `db:"transaction_id"`
+	ProductID        uuid.UUID      `db:"product_id"`
+	VariantID        uuid.NullUUID  `db:"variant_id"`
+	SKU              sql.NullString `db:"sku"`
+	Quantity         int            `db:"quantity"`
+	RefundedQuantity int            `db:"refunded_quantity"`
+	Price            money.Money    `db:"price"`
+	Discount         money.Money    `db:"discount"`
+	Tax              money.Money    `db:"tax"`
+	Currency         string         `db:"currency"`
+	CreatedAt        *time.Time     `db:"created_at"`
+}
+
+// ApplyCurrency puts the currency of the transaction, selected along with the line, on the scanned price.
+func (d *TransactionDetail) ApplyCurrency() {
+	d.Price = d.Price.WithCurrency(d.Currency)
+	d.Discount = d.Discount.WithCurrency(d.Currency)
+	d.Tax = d.Tax.WithCurrency(d.Currency)
+}
+
+// NetPrice is the line price after the promotions plus its tax, what the buyer paid for the line. The service and
+// payment fees of the transaction are not part of any line.
+func (d *TransactionDetail) NetPrice() (money.Money, error) {
+	net, err := d.Price.Sub(d.Discount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return net.Add(d.Tax)
+}
+
+func (d *TransactionDetail) RefundableQuantity() int {
+	return d.Quantity - d.RefundedQuantity
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,13 +13,23 @@ import (
 type Transaction struct {
 	ID                       uuid.UUID              `db:"id"`
 	UserID                   uuid.UUID              `db:"user_id"`
-	TotalPrice               float64                `db:"total_price"`
+	TotalPrice               money.Money            `db:"total_price"`
+	Subtotal                 money.Money            `db:"subtotal"`
+	Discount                 money.Money            `db:"discount"`
+	Tax                      money.Money            `db:"tax"`
+	ServiceFee               money.Money            `db:"service_fee"`
+	PaymentFee               money.Money            `db:"payment_fee"`
+	Currency                 string                 `db:"currency"`
+	ExchangeRates            *json.RawMessage       `db:"exchange_rates"`
 	TransactionStatus        enum.TransactionStatus `db:"transaction_status"`
 	InternalStatus           enum.TrxInternalStatus `db:"internal_status"`
 	ExternalStatus           sql.NullString         `db:"external_status"`
 	ExternalSettlementAt     sql.NullTime           `db:"external_settlement_at"`
 	ExternalCallbackResponse *json.RawMessage       `db:"external_callback_response"`
 	SnapToken                sql.NullString         `db:"snap_token"`
+	PaymentProvider          enum.PaymentProvider   `db:"payment_provider"`
+	PaymentReference         sql.NullString         `db:"payment_reference"`
+	PaymentRedirectURL       sql.NullString         `db:"payment_redirect_url"`
 	CheckoutAt               *time.Time             `db:"checkout_at"`
 	PaymentAt                sql.NullTime           `db:"payment_at"`
 	UpdatedAt                *time.Time             `db:"updated_at"`
@@ -27,7 +38,8 @@ type Transaction struct {
 type TransactionWithTotal struct {
 	ID                       uuid.UUID              `db:"id"`
 	UserID                   uuid.UUID              `db:"user_id"`
-	TotalPrice               float64                `db:"total_price"`
+	TotalPrice               money.Money            `db:"total_price"`
+	Currency                 string                 `db:"currency"`
 	TransactionStatus        enum.TransactionStatus `db:"transaction_status"`
 	InternalStatus           enum.TrxInternalStatus `db:"internal_status"`
 	ExternalStatus           sql.NullString         `db:"external_status"`
@@ -41,29 +53,49 @@ type TransactionWithTotal struct {
 }
 
 type TransactionWithDetail struct {
-	TransactionID                       uuid.UUID                  `db:"transaction_id"`
-	TransactionUserID                   uuid.UUID                  `db:"transaction_user_id"`
-	TransactionTotalPrice               float64                    `db:"transaction_total_price"`
-	TransactionStatus                   enum.TransactionStatus     `db:"transaction_transaction_status"`
-	TransactionInternalStatus           enum.TrxInternalStatus     `db:"transaction_internal_status"`
-	TransactionExternalStatus           enum.MidtransPaymentStatus `db:"transaction_external_status"`
-	TransactionExternalSettlementAt     *time.Time                 `db:"transaction_external_settlement_at"`
-	TransactionExternalCallbackResponse json.RawMessage            `db:"transaction_external_callback_response"`
-	TransactionCheckoutAt               *time.Time                 `db:"transaction_checkout_at"`
-	TransactionPaymentAt                *time.Time                 `db:"transaction_payment_at"`
-	TransactionUpdatedAt                *time.Time                 `db:"transaction_updated_at"`
-	TransactionDetailID                 uuid.UUID                  `db:"transaction_detail_id"`
-	TransactionDetailProductID          uuid.UUID                  `db:"transaction_detail_product_id"`
-	TransactionDetailTransactionID      uuid.UUID                  `db:"transaction_detail_transaction_id"`
-	TransactionDetailQuantity           int                        `db:"transaction_detail_quantity"`
-	TransactionDetailPrice              float64                    `db:"transaction_detail_price"`
-	TransactionDetailCreatedAt          *time.Time                 `db:"transaction_detail_created_at"`
+	TransactionID                       uuid.UUID              `db:"transaction_id"`
+	TransactionUserID                   uuid.UUID              `db:"transaction_user_id"`
+	TransactionTotalPrice               money.Money            `db:"transaction_total_price"`
+	TransactionSubtotal                 money.Money            `db:"transaction_subtotal"`
+	TransactionDiscount                 money.Money            `db:"transaction_discount"`
+	TransactionTax                      money.Money            `db:"transaction_tax"`
+	TransactionServiceFee               money.Money            `db:"transaction_service_fee"`
+	TransactionPaymentFee               money.Money            `db:"transaction_payment_fee"`
+	TransactionCurrency                 string                 `db:"transaction_currency"`
+	TransactionExchangeRates            json.RawMessage        `db:"transaction_exchange_rates"`
+	TransactionStatus                   enum.TransactionStatus `db:"transaction_transaction_status"`
+	TransactionInternalStatus           enum.TrxInternalStatus `db:"transaction_internal_status"`
+	TransactionExternalStatus           *string                `db:"transaction_external_status"`
+	TransactionExternalSettlementAt     *time.Time             `db:"transaction_external_settlement_at"`
+	TransactionExternalCallbackResponse json.RawMessage        `db:"transaction_external_callback_response"`
+	TransactionSnapToken                *string                `db:"transaction_snap_token"`
+	TransactionPaymentProvider          enum.PaymentProvider   `db:"transaction_payment_provider"`
+	TransactionPaymentRedirectURL       *string                `db:"transaction_payment_redirect_url"`
+	TransactionCheckoutAt               *time.Time             `db:"transaction_checkout_at"`
+	TransactionPaymentAt                *time.Time             `db:"transaction_payment_at"`
+	TransactionUpdatedAt                *time.Time             `db:"transaction_updated_at"`
+	TransactionDetailID                 uuid.UUID              `db:"transaction_detail_id"`
+	TransactionDetailProductID          uuid.UUID              `db:"transaction_detail_product_id"`
+	TransactionDetailVariantID          uuid.NullUUID          `db:"transaction_detail_variant_id"`
+	TransactionDetailSKU                sql.NullString         `db:"transaction_detail_sku"`
+	TransactionDetailTransactionID      uuid.UUID              `db:"transaction_detail_transaction_id"`
+	TransactionDetailQuantity           int                    `db:"transaction_detail_quantity"`
+	TransactionDetailPrice              money.Money            `db:"transaction_detail_price"`
+	TransactionDetailDiscount           money.Money            `db:"transaction_detail_discount"`
+	TransactionDetailTax                money.Money            `db:"transaction_detail_tax"`
+	TransactionDetailCreatedAt          *time.Time             `db:"transaction_detail_created_at"`
 }
 
 type TransactionWithDetailAndTotal struct {
 	TransactionID                       uuid.UUID              `db:"transaction_id"`
 	TransactionUserID                   uuid.UUID              `db:"transaction_user_id"`
-	TransactionTotalPrice               float64                `db:"transaction_total_price"`
+	TransactionTotalPrice               money.Money            `db:"transaction_total_price"`
+	TransactionSubtotal                 money.Money            `db:"transaction_subtotal"`
+	TransactionDiscount                 money.Money            `db:"transaction_discount"`
+	TransactionTax                      money.Money            `db:"transaction_tax"`
+	TransactionServiceFee               money.Money            `db:"transaction_service_fee"`
+	TransactionPaymentFee               money.Money            `db:"transaction_payment_fee"`
+	TransactionCurrency                 string                 `db:"transaction_currency"`
 	TransactionStatus                   enum.TransactionStatus `db:"transaction_transaction_status"`
 	TransactionInternalStatus           enum.TrxInternalStatus `db:"transaction_internal_status"`
 	TransactionExternalStatus           *string                `db:"transaction_external_status"`
@@ -75,8 +107,50 @@ type TransactionWithDetailAndTotal struct {
 	TransactionDetailID                 uuid.UUID              `db:"transaction_detail_id"`
 	TransactionDetailTransactionID      uuid.UUID              `db:"transaction_detail_transaction_id"`
 	TransactionDetailProductID          uuid.UUID              `db:"transaction_detail_product_id"`
+	TransactionDetailVariantID          uuid.NullUUID          `db:"transaction_detail_variant_id"`
+	TransactionDetailSKU                sql.NullString         `db:"transaction_detail_sku"`
 	TransactionDetailQuantity           int                    `db:"transaction_detail_quantity"`
-	TransactionDetailPrice              float64                `db:"transaction_detail_price"`
+	TransactionDetailPrice              money.Money            `db:"transaction_detail_price"`
+	TransactionDetailDiscount           money.Money            `db:"transaction_detail_discount"`
+	TransactionDetailTax                money.Money            `db:"transaction_detail_tax"`
 	TransactionDetailCreatedAt          *time.Time             `db:"transaction_detail_created_at"`
 	Total                               int                    `db:"total"`
 }
+
+// The amounts of a transaction are kept in its currency column, ApplyCurrency puts it on the scanned amounts.
+func (t *Transaction) ApplyCurrency() {
+	t.TotalPrice = t.TotalPrice.WithCurrency(t.Currency)
+	t.Subtotal = t.Subtotal.WithCurrency(t.Currency)
+	t.Discount = t.Discount.WithCurrency(t.Currency)
+	t.Tax = t.Tax.WithCurrency(t.Currency)
+	t.ServiceFee = t.ServiceFee.WithCurrency(t.Currency)
+	t.PaymentFee = t.PaymentFee.WithCurrency(t.Currency)
+}
+
+func (t *TransactionWithTotal) ApplyCurrency() {
+	t.TotalPrice = t.TotalPrice.WithCurrency(t.Currency)
+}
+
+func (t *TransactionWithDetail) ApplyCurrency() {
+	t.TransactionTotalPrice = t.TransactionTotalPrice.WithCurrency(t.TransactionCurrency)
+	t.TransactionSubtotal = t.TransactionSubtotal.WithCurrency(t.TransactionCurrency)
+	t.TransactionDiscount = t.TransactionDiscount.WithCurrency(t.TransactionCurrency)
+	t.TransactionTax = t.TransactionTax.WithCurrency(t.TransactionCurrency)
+	t.TransactionServiceFee = t.TransactionServiceFee.WithCurrency(t.TransactionCurrency)
+	t.TransactionPaymentFee = t.TransactionPaymentFee.WithCurrency(t.TransactionCurrency)
+	t.TransactionDetailPrice = t.TransactionDetailPrice.WithCurrency(t.TransactionCurrency)
+	t.TransactionDetailDiscount = t.TransactionDetailDiscount.WithCurrency(t.TransactionCurrency)
+	t.TransactionDetailTax = t.TransactionDetailTax.WithCurrency(t.TransactionCurrency)
+}
+
+func (t *TransactionWithDetailAndTotal) ApplyCurrency() {
+	t.TransactionTotalPrice = t.TransactionTotalPrice.WithCurrency(t.TransactionCurrency)
+	t.TransactionSubtotal = t.TransactionSubtotal.WithCurrency(t.TransactionCurrency)
+	t.TransactionDiscount = t.TransactionDiscount.WithCurrency(t.TransactionCurrency)
+	t.TransactionTax = t.TransactionTax.WithCurrency(t.TransactionCurrency)
+	t.TransactionServiceFee = t.TransactionServiceFee.WithCurrency(t.TransactionCurrency)
+	t.TransactionPaymentFee = t.TransactionPaymentFee.WithCurrency(t.TransactionCurrency)
+	t.TransactionDetailPrice = t.TransactionDetailPrice.WithCurrency(t.TransactionCurrency)
+	t.TransactionDetailDiscount = t.TransactionDetailDiscount.WithCurrency(t.TransactionCurrency)
+	t.TransactionDetailTax = t.TransactionDetailTax.WithCurrency(t.TransactionCurrency)
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"database/sql"
+	"go-saga-pattern/commoner/constant/enum"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransactionStatusHistory struct {
+	ID                uuid.UUID              `db:"id"`
+	TransactionID     uuid.UUID              `db:"transaction_id"`
+	FromStatus        sql.NullString         `db:"from_status"`
+	ToStatus          enum.TrxInternalStatus `db:"to_status"`
+	TransactionStatus enum.TransactionStatus `db:"transaction_status"`
+	ExternalStatus    sql.NullString         `db:"external_status"`
+	CreatedAt         *time.Time             `db:"created_at"`
+}
@@ -0,0 +1,177 @@
+package entity
+
+import (
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+)
+
+// TransactionTransitionEvent is the saga event a transition publishes to TRANSACTION_STREAM through the outbox.
+type TransactionTransitionEvent struct {
+	Subject string
+	Status  string
+}
+
+var (
+	transactionEventCanceled = &TransactionTransitionEvent{Subject: "transaction.canceled", Status: enum.TransactionEventCancelled}
+	transactionEventExpired  = &TransactionTransitionEvent{Subject: "transaction.expired", Status: enum.TransactionEventExpired}
+	transactionEventSettled  = &TransactionTransitionEvent{Subject: "transaction.settled", Status: enum.TransactionEventSettled}
+	transactionEventRefunded = &TransactionTransitionEvent{Subject: "transaction.refunded", Status: enum.TransactionEventRefunded}
+)
+
+type transactionState struct {
+	transactionStatus enum.TransactionStatus
+	// next maps every status reachable from this one to the event the transition emits, nil when it emits none
+	next map[enum.TrxInternalStatus]*TransactionTransitionEvent
+}
+
+// transactionStates is the single source of truth for internal status changes. PENDING, TOKEN_READY and EXPIRED
+// can move to themselves because a payment callback that changes nothing is still recorded.
+var transactionStates = map[enum.TrxInternalStatus]transactionState{
+	enum.TrxInternalStatusPending: {
+		transactionStatus: enum.TransactionStatusPending,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusPending:               nil,
+			enum.TrxInternalStatusTokenReady:            nil,
+			enum.TrxInternalStatusExpired:               nil,
+			enum.TrxInternalStatusExpiredCheckedInvalid: transactionEventExpired,
+			enum.TrxInternalStatusSettled:               transactionEventSettled,
+			enum.TrxInternalStatusFailed:                transactionEventExpired,
+			enum.TrxInternalStatusCancelledBySystem:     transactionEventCanceled,
+			enum.TrxInternalStatusCancelledByUser:       transactionEventCanceled,
+		},
+	},
+	enum.TrxInternalStatusTokenReady: {
+		transactionStatus: enum.TransactionStatusPending,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusTokenReady:            nil,
+			enum.TrxInternalStatusExpired:               nil,
+			enum.TrxInternalStatusExpiredCheckedInvalid: transactionEventExpired,
+			enum.TrxInternalStatusSettled:               transactionEventSettled,
+			enum.TrxInternalStatusFailed:                transactionEventExpired,
+			enum.TrxInternalStatusCancelledBySystem:     transactionEventCanceled,
+			enum.TrxInternalStatusCancelledByUser:       transactionEventCanceled,
+		},
+	},
+	enum.TrxInternalStatusExpired: {
+		transactionStatus: enum.TransactionStatusExpired,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusExpired:               nil,
+			enum.TrxInternalStatusExpiredCheckedInvalid: transactionEventExpired,
+			enum.TrxInternalStatusExpiredCheckedValid:   transactionEventSettled,
+			enum.TrxInternalStatusLateSettlement:        transactionEventExpired,
+			enum.TrxInternalStatusCancelledBySystem:     transactionEventCanceled,
+			enum.TrxInternalStatusCancelledByUser:       transactionEventCanceled,
+		},
+	},
+	enum.TrxInternalStatusSettled: {
+		transactionStatus: enum.TransactionStatusSuccess,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusRefunding: nil,
+		},
+	},
+	enum.TrxInternalStatusExpiredCheckedValid: {
+		transactionStatus: enum.TransactionStatusSuccess,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusRefunding: nil,
+		},
+	},
+	enum.TrxInternalStatusLateSettlement: {
+		transactionStatus: enum.TransactionStatusExpired,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusRefunding: nil,
+		},
+	},
+	enum.TrxInternalStatusPartiallyRefunded: {
+		transactionStatus: enum.TransactionStatusPartiallyRefunded,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusRefunding: nil,
+		},
+	},
+	// REFUNDING holds a single refund at a time. A refund the payment provider rejected moves the transaction
+	// back to the status it came from without publishing anything, even when that status is PARTIALLY_REFUNDED.
+	enum.TrxInternalStatusRefunding: {
+		transactionStatus: enum.TransactionStatusRefunding,
+		next: map[enum.TrxInternalStatus]*TransactionTransitionEvent{
+			enum.TrxInternalStatusRefunded:            transactionEventRefunded,
+			enum.TrxInternalStatusPartiallyRefunded:   transactionEventRefunded,
+			enum.TrxInternalStatusSettled:             nil,
+			enum.TrxInternalStatusExpiredCheckedValid: nil,
+			enum.TrxInternalStatusLateSettlement:      nil,
+		},
+	},
+	enum.TrxInternalStatusExpiredCheckedInvalid: {transactionStatus: enum.TransactionStatusExpired},
+	enum.TrxInternalStatusCancelledBySystem:     {transactionStatus: enum.TransactionStatusCancelled},
+	enum.TrxInternalStatusCancelledByUser:       {transactionStatus: enum.TransactionStatusCancelled},
+	enum.TrxInternalStatusFailed:                {transactionStatus: enum.TransactionStatusFailed},
+	enum.TrxInternalStatusRefunded:              {transactionStatus: enum.TransactionStatusRefunded},
+}
+
+type InvalidTransitionError struct {
+	From enum.TrxInternalStatus
+	To   enum.TrxInternalStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("invalid transaction status transition from %q to %q", e.From, e.To)
+}
+
+type TransactionTransition struct {
+	From              enum.TrxInternalStatus
+	To                enum.TrxInternalStatus
+	TransactionStatus enum.TransactionStatus
+	Event             *TransactionTransitionEvent
+}
+
+func NewTransactionTransition(from, to enum.TrxInternalStatus) (*TransactionTransition, error) {
+	state, ok := transactionStates[from]
+	if !ok {
+		return nil, &InvalidTransitionError{From: from, To: to}
+	}
+
+	event, ok := state.next[to]
+	if !ok {
+		return nil, &InvalidTransitionError{From: from, To: to}
+	}
+
+	return &TransactionTransition{
+		From:              from,
+		To:                to,
+		TransactionStatus: transactionStates[to].transactionStatus,
+		Event:             event,
+	}, nil
+}
+
+// Validate guards repositories against transitions that were not built by NewTransactionTransition.
+func (t *TransactionTransition) Validate() error {
+	if t == nil {
+		return &InvalidTransitionError{}
+	}
+
+	expected, err := NewTransactionTransition(t.From, t.To)
+	if err != nil {
+		return err
+	}
+
+	if expected.TransactionStatus != t.TransactionStatus {
+		return &InvalidTransitionError{From: t.From, To: t.To}
+	}
+
+	return nil
+}
+
+func TransactionStatusOf(status enum.TrxInternalStatus) (enum.TransactionStatus, bool) {
+	state, ok := transactionStates[status]
+	return state.transactionStatus, ok
+}
+
+// TransitionTo moves the transaction to the given internal status and the external status it maps to.
+func (t *Transaction) TransitionTo(to enum.TrxInternalStatus) (*TransactionTransition, error) {
+	transition, err := NewTransactionTransition(t.InternalStatus, to)
+	if err != nil {
+		return nil, err
+	}
+
+	t.InternalStatus = transition.To
+	t.TransactionStatus = transition.TransactionStatus
+	return transition, nil
+}
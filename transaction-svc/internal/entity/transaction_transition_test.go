@@ -0,0 +1,277 @@
+package entity_test
+
+import (
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var allTrxInternalStatuses = []enum.TrxInternalStatus{
+	enum.TrxInternalStatusPending,
+	enum.TrxInternalStatusTokenReady,
+	enum.TrxInternalStatusExpired,
+	enum.TrxInternalStatusExpiredCheckedInvalid,
+	enum.TrxInternalStatusExpiredCheckedValid,
+	enum.TrxInternalStatusLateSettlement,
+	enum.TrxInternalStatusSettled,
+	enum.TrxInternalStatusCancelledBySystem,
+	enum.TrxInternalStatusCancelledByUser,
+	enum.TrxInternalStatusRefunding,
+	enum.TrxInternalStatusPartiallyRefunded,
+	enum.TrxInternalStatusRefunded,
+	enum.TrxInternalStatusFailed,
+}
+
+type transitionKey struct {
+	from enum.TrxInternalStatus
+	to   enum.TrxInternalStatus
+}
+
+// allowedTransitions lists every legal pair with the event subject it emits, "" when it emits none.
+var allowedTransitions = map[transitionKey]string{
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusPending}:               "",
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusTokenReady}:            "",
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusExpired}:               "",
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusExpiredCheckedInvalid}: "transaction.expired",
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusSettled}:               "transaction.settled",
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusFailed}:                "transaction.expired",
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusCancelledBySystem}:     "transaction.canceled",
+	{enum.TrxInternalStatusPending, enum.TrxInternalStatusCancelledByUser}:       "transaction.canceled",
+
+	{enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusTokenReady}:            "",
+	{enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusExpired}:               "",
+	{enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusExpiredCheckedInvalid}: "transaction.expired",
+	{enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusSettled}:               "transaction.settled",
+	{enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusFailed}:                "transaction.expired",
+	{enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusCancelledBySystem}:     "transaction.canceled",
+	{enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusCancelledByUser}:       "transaction.canceled",
+
+	{enum.TrxInternalStatusExpired, enum.TrxInternalStatusExpired}:               "",
+	{enum.TrxInternalStatusExpired, enum.TrxInternalStatusExpiredCheckedInvalid}: "transaction.expired",
+	{enum.TrxInternalStatusExpired, enum.TrxInternalStatusExpiredCheckedValid}:   "transaction.settled",
+	{enum.TrxInternalStatusExpired, enum.TrxInternalStatusLateSettlement}:        "transaction.expired",
+	{enum.TrxInternalStatusExpired, enum.TrxInternalStatusCancelledBySystem}:     "transaction.canceled",
+	{enum.TrxInternalStatusExpired, enum.TrxInternalStatusCancelledByUser}:       "transaction.canceled",
+
+	{enum.TrxInternalStatusSettled, enum.TrxInternalStatusRefunding}:             "",
+	{enum.TrxInternalStatusExpiredCheckedValid, enum.TrxInternalStatusRefunding}: "",
+	{enum.TrxInternalStatusLateSettlement, enum.TrxInternalStatusRefunding}:      "",
+	{enum.TrxInternalStatusPartiallyRefunded, enum.TrxInternalStatusRefunding}:   "",
+
+	{enum.TrxInternalStatusRefunding, enum.TrxInternalStatusRefunded}:            "transaction.refunded",
+	{enum.TrxInternalStatusRefunding, enum.TrxInternalStatusPartiallyRefunded}:   "transaction.refunded",
+	{enum.TrxInternalStatusRefunding, enum.TrxInternalStatusSettled}:             "",
+	{enum.TrxInternalStatusRefunding, enum.TrxInternalStatusExpiredCheckedValid}: "",
+	{enum.TrxInternalStatusRefunding, enum.TrxInternalStatusLateSettlement}:      "",
+}
+
+var expectedTransactionStatuses = map[enum.TrxInternalStatus]enum.TransactionStatus{
+	enum.TrxInternalStatusPending:               enum.TransactionStatusPending,
+	enum.TrxInternalStatusTokenReady:            enum.TransactionStatusPending,
+	enum.TrxInternalStatusExpired:               enum.TransactionStatusExpired,
+	enum.TrxInternalStatusExpiredCheckedInvalid: enum.TransactionStatusExpired,
+	enum.TrxInternalStatusExpiredCheckedValid:   enum.TransactionStatusSuccess,
+	enum.TrxInternalStatusLateSettlement:        enum.TransactionStatusExpired,
+	enum.TrxInternalStatusSettled:               enum.TransactionStatusSuccess,
+	enum.TrxInternalStatusCancelledBySystem:     enum.TransactionStatusCancelled,
+	enum.TrxInternalStatusCancelledByUser:       enum.TransactionStatusCancelled,
+	enum.TrxInternalStatusRefunding:             enum.TransactionStatusRefunding,
+	enum.TrxInternalStatusPartiallyRefunded:     enum.TransactionStatusPartiallyRefunded,
+	enum.TrxInternalStatusRefunded:              enum.TransactionStatusRefunded,
+	enum.TrxInternalStatusFailed:                enum.TransactionStatusFailed,
+}
+
+func TestNewTransactionTransition(t *testing.T) {
+	type testCase struct {
+		name    string
+		from    enum.TrxInternalStatus
+		to      enum.TrxInternalStatus
+		allowed bool
+		subject string
+	}
+
+	tests := make([]testCase, 0, len(allTrxInternalStatuses)*len(allTrxInternalStatuses))
+	for _, from := range allTrxInternalStatuses {
+		for _, to := range allTrxInternalStatuses {
+			subject, allowed := allowedTransitions[transitionKey{from, to}]
+			tests = append(tests, testCase{
+				name:    string(from) + "->" + string(to),
+				from:    from,
+				to:      to,
+				allowed: allowed,
+				subject: subject,
+			})
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transition, err := entity.NewTransactionTransition(tt.from, tt.to)
+
+			if !tt.allowed {
+				var invalidTransition *entity.InvalidTransitionError
+				assert.True(t, errors.As(err, &invalidTransition))
+				assert.Equal(t, tt.from, invalidTransition.From)
+				assert.Equal(t, tt.to, invalidTransition.To)
+				assert.Nil(t, transition)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.from, transition.From)
+			assert.Equal(t, tt.to, transition.To)
+			assert.Equal(t, expectedTransactionStatuses[tt.to], transition.TransactionStatus)
+			assert.NoError(t, transition.Validate())
+
+			if tt.subject == "" {
+				assert.Nil(t, transition.Event)
+				return
+			}
+			assert.Equal(t, tt.subject, transition.Event.Subject)
+		})
+	}
+}
+
+func TestTransactionStatusOf(t *testing.T) {
+	for _, status := range allTrxInternalStatuses {
+		t.Run(string(status), func(t *testing.T) {
+			transactionStatus, ok := entity.TransactionStatusOf(status)
+			assert.True(t, ok)
+			assert.Equal(t, expectedTransactionStatuses[status], transactionStatus)
+		})
+	}
+
+	_, ok := entity.TransactionStatusOf("UNKNOWN")
+	assert.False(t, ok)
+}
+
+func TestTransaction_TransitionTo(t *testing.T) {
+	tests := []struct {
+		name                      string
+		from                      enum.TrxInternalStatus
+		to                        enum.TrxInternalStatus
+		expectedErr               bool
+		expectedInternalStatus    enum.TrxInternalStatus
+		expectedTransactionStatus enum.TransactionStatus
+	}{
+		{
+			name:                      "pending transaction is cancelled by user",
+			from:                      enum.TrxInternalStatusPending,
+			to:                        enum.TrxInternalStatusCancelledByUser,
+			expectedInternalStatus:    enum.TrxInternalStatusCancelledByUser,
+			expectedTransactionStatus: enum.TransactionStatusCancelled,
+		},
+		{
+			name:                      "expired transaction settles within grace period",
+			from:                      enum.TrxInternalStatusExpired,
+			to:                        enum.TrxInternalStatusExpiredCheckedValid,
+			expectedInternalStatus:    enum.TrxInternalStatusExpiredCheckedValid,
+			expectedTransactionStatus: enum.TransactionStatusSuccess,
+		},
+		{
+			name:                      "settled transaction cannot expire and is left untouched",
+			from:                      enum.TrxInternalStatusSettled,
+			to:                        enum.TrxInternalStatusExpiredCheckedInvalid,
+			expectedErr:               true,
+			expectedInternalStatus:    enum.TrxInternalStatusSettled,
+			expectedTransactionStatus: enum.TransactionStatusSuccess,
+		},
+		{
+			name:                      "late settlement is refunded",
+			from:                      enum.TrxInternalStatusLateSettlement,
+			to:                        enum.TrxInternalStatusRefunding,
+			expectedInternalStatus:    enum.TrxInternalStatusRefunding,
+			expectedTransactionStatus: enum.TransactionStatusRefunding,
+		},
+		{
+			name:                      "settled transaction cannot skip refunding",
+			from:                      enum.TrxInternalStatusSettled,
+			to:                        enum.TrxInternalStatusRefunded,
+			expectedErr:               true,
+			expectedInternalStatus:    enum.TrxInternalStatusSettled,
+			expectedTransactionStatus: enum.TransactionStatusSuccess,
+		},
+		{
+			name:                      "empty target status is rejected",
+			from:                      enum.TrxInternalStatusTokenReady,
+			to:                        "",
+			expectedErr:               true,
+			expectedInternalStatus:    enum.TrxInternalStatusTokenReady,
+			expectedTransactionStatus: enum.TransactionStatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactionStatus, _ := entity.TransactionStatusOf(tt.from)
+			transaction := &entity.Transaction{InternalStatus: tt.from, TransactionStatus: transactionStatus}
+
+			transition, err := transaction.TransitionTo(tt.to)
+			if tt.expectedErr {
+				var invalidTransition *entity.InvalidTransitionError
+				assert.True(t, errors.As(err, &invalidTransition))
+				assert.Nil(t, transition)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, transition)
+			}
+
+			assert.Equal(t, tt.expectedInternalStatus, transaction.InternalStatus)
+			assert.Equal(t, tt.expectedTransactionStatus, transaction.TransactionStatus)
+		})
+	}
+}
+
+func TestTransactionTransition_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		transition *entity.TransactionTransition
+		wantErr    bool
+	}{
+		{
+			name:    "nil transition",
+			wantErr: true,
+		},
+		{
+			name: "hand built illegal transition",
+			transition: &entity.TransactionTransition{
+				From:              enum.TrxInternalStatusCancelledByUser,
+				To:                enum.TrxInternalStatusSettled,
+				TransactionStatus: enum.TransactionStatusSuccess,
+			},
+			wantErr: true,
+		},
+		{
+			name: "legal pair with mismatched external status",
+			transition: &entity.TransactionTransition{
+				From:              enum.TrxInternalStatusPending,
+				To:                enum.TrxInternalStatusSettled,
+				TransactionStatus: enum.TransactionStatusPending,
+			},
+			wantErr: true,
+		},
+		{
+			name: "legal transition",
+			transition: &entity.TransactionTransition{
+				From:              enum.TrxInternalStatusPending,
+				To:                enum.TrxInternalStatusSettled,
+				TransactionStatus: enum.TransactionStatusSuccess,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.transition.Validate()
+			if tt.wantErr {
+				var invalidTransition *entity.InvalidTransitionError
+				assert.True(t, errors.As(err, &invalidTransition))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
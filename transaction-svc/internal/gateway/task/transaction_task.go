@@ -2,7 +2,11 @@ package task
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/utils"
+	"go-saga-pattern/transaction-svc/internal/adapter"
 	"log"
 	"strconv"
 	"time"
@@ -14,25 +18,41 @@ import (
 type transactionTask struct {
 	transactionTTL      time.Duration
 	transactionFinalTTL time.Duration
+	paymentTokenRetries int
 	asynqClient         *asynq.Client
+	asynqInspector      *asynq.Inspector
 }
 
 // A list of task types.
 const (
-	TypeTransactionExpire      = "transaction:expire"
-	TypeTransactionExpireFinal = "transaction:expire:final"
+	TypeTransactionExpire       = "transaction:expire"
+	TypeTransactionExpireFinal  = "transaction:expire:final"
+	TypeTransactionPaymentToken = "transaction:payment-token"
+)
+
+const (
+	// transactionTaskQueue is the queue the expire tasks are enqueued on, asynq's default when no queue is given.
+	transactionTaskQueue = "default"
+	// paymentTokenTaskQueue is processed first, the buyer is waiting for the payment token.
+	paymentTokenTaskQueue = "critical"
+
+	paymentTokenMaxRetryDelay = 2 * time.Minute
 )
 
 type TransactionExpirePayload struct {
 	TransactionID uuid.UUID `json:"transaction_id"`
 }
 
+type TransactionPaymentTokenPayload struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+}
+
 func NewTransactionExpireTask(transactionID uuid.UUID) (*asynq.Task, error) {
 	payload, err := json.Marshal(TransactionExpirePayload{TransactionID: transactionID})
 	if err != nil {
 		return nil, err
 	}
-	return asynq.NewTask(TypeTransactionExpire, payload), nil
+	return asynq.NewTask(TypeTransactionExpire, payload, asynq.TaskID(transactionTaskID(TypeTransactionExpire, transactionID))), nil
 }
 
 func NewTransactionExpireFinalTask(transactionID uuid.UUID) (*asynq.Task, error) {
@@ -40,15 +60,50 @@ func NewTransactionExpireFinalTask(transactionID uuid.UUID) (*asynq.Task, error)
 	if err != nil {
 		return nil, err
 	}
-	return asynq.NewTask(TypeTransactionExpireFinal, payload), nil
+	return asynq.NewTask(TypeTransactionExpireFinal, payload, asynq.TaskID(transactionTaskID(TypeTransactionExpireFinal, transactionID))), nil
+}
+
+func NewTransactionPaymentTokenTask(transactionID uuid.UUID) (*asynq.Task, error) {
+	payload, err := json.Marshal(TransactionPaymentTokenPayload{TransactionID: transactionID})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeTransactionPaymentToken, payload, asynq.TaskID(transactionTaskID(TypeTransactionPaymentToken, transactionID))), nil
+}
+
+// RetryDelay backs the payment token task off exponentially and never retries it while the payment circuit breaker
+// is still open, other tasks keep asynq's default delay.
+func RetryDelay(n int, err error, t *asynq.Task) time.Duration {
+	if t.Type() != TypeTransactionPaymentToken {
+		return asynq.DefaultRetryDelayFunc(n, err, t)
+	}
+	return PaymentTokenRetryDelay(n, err)
+}
+
+// PaymentTokenRetryDelay returns 2s, 4s, 8s, ... capped at paymentTokenMaxRetryDelay, and at least the circuit breaker
+// timeout when the call was rejected by an open breaker.
+func PaymentTokenRetryDelay(n int, err error) time.Duration {
+	delay := helper.ExponentialBackoff(n, 2*time.Second, paymentTokenMaxRetryDelay)
+	if adapter.IsPaymentProviderUnavailable(err) {
+		return max(delay, adapter.PaymentCircuitBreakerTimeout)
+	}
+	return delay
+}
+
+// transactionTaskID keeps one task of a type per transaction, so it can be found again and enqueuing twice is a no-op.
+func transactionTaskID(taskType string, transactionID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", taskType, transactionID)
 }
 
 type TransactionTask interface {
 	EnqueueTransactionExpire(transactionID uuid.UUID) error
 	EnqueueTransactionExpireFinal(transactionID uuid.UUID) error
+	DeleteTransactionExpireTasks(transactionID uuid.UUID) error
+	// EnqueuePaymentToken retries obtaining the payment token after processIn, at most once per transaction
+	EnqueuePaymentToken(transactionID uuid.UUID, processIn time.Duration) error
 }
 
-func NewTransactionTask(asynqClient *asynq.Client) TransactionTask {
+func NewTransactionTask(asynqClient *asynq.Client, asynqInspector *asynq.Inspector) TransactionTask {
 	ttlStr := utils.GetEnv("TRANSACTION_EXPIRATION_TTL")
 	ttlInt, err := strconv.Atoi(ttlStr)
 	if err != nil || ttlInt <= 0 {
@@ -63,10 +118,17 @@ func NewTransactionTask(asynqClient *asynq.Client) TransactionTask {
 		ttlInt = 120
 	}
 
+	paymentTokenRetries, err := strconv.Atoi(utils.GetEnv("PAYMENT_TOKEN_MAX_RETRY"))
+	if err != nil || paymentTokenRetries <= 0 {
+		paymentTokenRetries = 8
+	}
+
 	return &transactionTask{
 		transactionTTL:      time.Duration(ttlInt) * time.Second,
 		transactionFinalTTL: time.Duration(ttlFinalInt) * time.Second,
+		paymentTokenRetries: paymentTokenRetries,
 		asynqClient:         asynqClient,
+		asynqInspector:      asynqInspector,
 	}
 }
 
@@ -76,7 +138,7 @@ func (t *transactionTask) EnqueueTransactionExpire(transactionID uuid.UUID) erro
 		return err
 	}
 	_, err = t.asynqClient.Enqueue(task, asynq.ProcessIn(t.transactionTTL))
-	if err != nil {
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
 		return err
 	}
 	return nil
@@ -88,8 +150,32 @@ func (t *transactionTask) EnqueueTransactionExpireFinal(transactionID uuid.UUID)
 		return err
 	}
 	_, err = t.asynqClient.Enqueue(task, asynq.ProcessIn(t.transactionFinalTTL))
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
+	return nil
+}
+
+// DeleteTransactionExpireTasks removes the expire tasks that have not run yet, tasks already gone are ignored.
+func (t *transactionTask) DeleteTransactionExpireTasks(transactionID uuid.UUID) error {
+	for _, taskType := range []string{TypeTransactionExpire, TypeTransactionExpireFinal} {
+		err := t.asynqInspector.DeleteTask(transactionTaskQueue, transactionTaskID(taskType, transactionID))
+		if err != nil && !errors.Is(err, asynq.ErrTaskNotFound) && !errors.Is(err, asynq.ErrQueueNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *transactionTask) EnqueuePaymentToken(transactionID uuid.UUID, processIn time.Duration) error {
+	task, err := NewTransactionPaymentTokenTask(transactionID)
 	if err != nil {
 		return err
 	}
+	_, err = t.asynqClient.Enqueue(task, asynq.Queue(paymentTokenTaskQueue), asynq.MaxRetry(t.paymentTokenRetries),
+		asynq.ProcessIn(processIn))
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,39 @@
+package task_test
+
+import (
+	"errors"
+	"fmt"
+	"go-saga-pattern/transaction-svc/internal/gateway/task"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentTokenRetryDelay(t *testing.T) {
+	gatewayErr := errors.New("midtrans returned 500")
+	openBreakerErr := fmt.Errorf("midtrans create charge error: %w", gobreaker.ErrOpenState)
+
+	tests := []struct {
+		name    string
+		retried int
+		err     error
+		want    time.Duration
+	}{
+		{name: "first retry", retried: 0, err: gatewayErr, want: 2 * time.Second},
+		{name: "doubles every retry", retried: 3, err: gatewayErr, want: 16 * time.Second},
+		{name: "last retry before the cap", retried: 5, err: gatewayErr, want: 64 * time.Second},
+		{name: "capped", retried: 6, err: gatewayErr, want: 2 * time.Minute},
+		{name: "capped long after", retried: 40, err: gatewayErr, want: 2 * time.Minute},
+		{name: "waits out an open breaker", retried: 0, err: openBreakerErr, want: 10 * time.Second},
+		{name: "half open breaker", retried: 1, err: gobreaker.ErrTooManyRequests, want: 10 * time.Second},
+		{name: "backoff past the breaker timeout", retried: 4, err: openBreakerErr, want: 32 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, task.PaymentTokenRetryDelay(tt.retried, tt.err))
+		})
+	}
+}
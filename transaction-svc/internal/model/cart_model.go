@@ -0,0 +1,76 @@
+package model
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+
+	"github.com/google/uuid"
+)
+
+// CartOwner points at the cart of a signed in user, or at a guest cart by its id when UserID is empty.
+type CartOwner struct {
+	UserID uuid.UUID `json:"-"`
+	CartID uuid.UUID `json:"-"`
+}
+
+type GetCartRequest struct {
+	CartOwner
+}
+
+// AddCartItemRequest adds a product to the cart, VariantID is required when the product has variants.
+type AddCartItemRequest struct {
+	CartOwner
+	ProductID uuid.UUID     `json:"product_id" validate:"required"`
+	VariantID uuid.NullUUID `json:"variant_id"`
+	Quantity  int           `json:"quantity" validate:"required,min=1"`
+}
+
+// UpdateCartItemRequest sets the quantity of a product or one of its variants in the cart, zero removes it.
+type UpdateCartItemRequest struct {
+	CartOwner
+	ProductID uuid.UUID     `json:"-" validate:"required"`
+	VariantID uuid.NullUUID `json:"-"`
+	Quantity  int           `json:"quantity" validate:"min=0"`
+}
+
+type RemoveCartItemRequest struct {
+	CartOwner
+	ProductID uuid.UUID `validate:"required"`
+	VariantID uuid.NullUUID
+}
+
+type MergeCartRequest struct {
+	UserID      uuid.UUID `json:"-" validate:"required"`
+	GuestCartID uuid.UUID `json:"guest_cart_id" validate:"required"`
+}
+
+type CheckoutCartRequest struct {
+	UserID          uuid.UUID            `json:"-" validate:"required"`
+	PaymentProvider enum.PaymentProvider `json:"payment_provider" validate:"omitempty,oneof=MIDTRANS XENDIT"`
+	ExpectedTotal   money.Money          `json:"expected_total" validate:"omitempty,gt=0"`
+	Currency        string               `json:"currency" validate:"omitempty,currency"`
+	PromoCodes      []string             `json:"promo_codes" validate:"omitempty,max=5,dive,required,max=50"`
+}
+
+type CartResponse struct {
+	ID            string              `json:"id"`
+	Items         []*CartItemResponse `json:"items"`
+	TotalQuantity int                 `json:"total_quantity"`
+	TotalPrice    money.Money         `json:"total_price"`
+	UpdatedAt     string              `json:"updated_at,omitempty"`
+}
+
+// CartItemResponse carries the current price and stock of the product, or of its variant when the item has one.
+// Available is false when the product or the variant is gone, or its stock no longer covers the quantity.
+type CartItemResponse struct {
+	ProductID         string            `json:"product_id"`
+	VariantID         string            `json:"variant_id,omitempty"`
+	SKU               string            `json:"sku,omitempty"`
+	Options           map[string]string `json:"options,omitempty"`
+	Name              string            `json:"name,omitempty"`
+	Price             money.Money       `json:"price"`
+	Quantity          int               `json:"quantity"`
+	Subtotal          money.Money       `json:"subtotal"`
+	AvailableQuantity int               `json:"available_quantity"`
+	Available         bool              `json:"available"`
+}
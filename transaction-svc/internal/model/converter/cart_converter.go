@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+)
+
+// CartToResponse prices the cart with the current products from product-svc, keyed by product id, an item of a variant
+// gets the price and stock of the variant. Items whose product or variant is gone, or whose subtotal cannot be added
+// up, stay in the cart but are not counted in the totals. The totals are in the currency of the first priced item,
+// items priced in another currency are left out of them.
+func CartToResponse(cart *entity.Cart, items []*entity.CartItem, products map[string]*model.ProductResponse) *model.CartResponse {
+	response := &model.CartResponse{
+		ID:         cart.ID.String(),
+		Items:      make([]*model.CartItemResponse, 0, len(items)),
+		TotalPrice: money.Zero(cartCurrency(items, products)),
+		UpdatedAt:  formatTime(cart.UpdatedAt),
+	}
+
+	for _, item := range items {
+		itemResponse := &model.CartItemResponse{
+			ProductID: item.ProductID.String(),
+			Quantity:  item.Quantity,
+		}
+		if item.VariantID.Valid {
+			itemResponse.VariantID = item.VariantID.UUID.String()
+		}
+
+		product, ok := products[itemResponse.ProductID]
+		if ok {
+			itemResponse.Name = product.Name
+		}
+
+		var variant *model.ProductVariantResponse
+		if ok {
+			variant, ok = CartItemVariant(item, product)
+		}
+
+		if ok {
+			itemResponse.Price = product.Price
+			itemResponse.AvailableQuantity = product.Quantity
+			if variant != nil {
+				itemResponse.SKU = variant.SKU
+				itemResponse.Options = variant.Options
+				itemResponse.Price = variant.Price
+				itemResponse.AvailableQuantity = variant.Quantity
+			}
+			itemResponse.Available = itemResponse.AvailableQuantity >= item.Quantity
+
+			if subtotal, err := itemResponse.Price.Mul(int64(item.Quantity)); err == nil {
+				if totalPrice, err := response.TotalPrice.Add(subtotal); err == nil {
+					itemResponse.Subtotal = subtotal
+					response.TotalQuantity += item.Quantity
+					response.TotalPrice = totalPrice
+				}
+			}
+		}
+
+		response.Items = append(response.Items, itemResponse)
+	}
+
+	return response
+}
+
+// CartItemVariant finds the variant of the product the item points at, it is nil for an item of a product without
+// variants. ok is false when the variant is gone, or when the product has variants and the item chose none of them.
+func CartItemVariant(item *entity.CartItem, product *model.ProductResponse) (*model.ProductVariantResponse, bool) {
+	if !item.VariantID.Valid {
+		return nil, len(product.Variants) == 0
+	}
+
+	for _, variant := range product.Variants {
+		if variant.ID == item.VariantID.UUID.String() {
+			return variant, true
+		}
+	}
+	return nil, false
+}
+
+// cartCurrency is the currency of the first item whose product is still around.
+func cartCurrency(items []*entity.CartItem, products map[string]*model.ProductResponse) string {
+	for _, item := range items {
+		if product, ok := products[item.ProductID.String()]; ok {
+			return product.Price.CurrencyCode()
+		}
+	}
+	return money.DefaultCurrency
+}
@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"strconv"
+)
+
+func PromotionToResponse(promotion *entity.Promotion) *model.PromotionResponse {
+	response := &model.PromotionResponse{
+		ID:                promotion.ID.String(),
+		Code:              promotion.Code,
+		Name:              promotion.Name,
+		Type:              promotion.Type,
+		Scope:             promotion.Scope,
+		BuyQuantity:       promotion.BuyQuantity,
+		GetQuantity:       promotion.GetQuantity,
+		UsageLimit:        promotion.UsageLimit.Int64,
+		UsageLimitPerUser: promotion.UsageLimitPerUser.Int64,
+		UsageCount:        promotion.UsageCount,
+		StartsAt:          nullable.SQLtoTime(promotion.StartsAt),
+		EndsAt:            nullable.SQLtoTime(promotion.EndsAt),
+		IsActive:          promotion.IsActive,
+		CreatedAt:         formatTime(promotion.CreatedAt),
+		UpdatedAt:         formatTime(promotion.UpdatedAt),
+	}
+
+	if promotion.ScopeID.Valid {
+		response.ScopeID = promotion.ScopeID.UUID.String()
+	}
+
+	switch promotion.Type {
+	case enum.PromotionTypePercentage:
+		response.Percentage = strconv.FormatFloat(float64(promotion.PercentageBasisPoints)/100, 'f', -1, 64)
+	case enum.PromotionTypeFixed:
+		amount := promotion.Amount
+		response.Amount = &amount
+	}
+
+	return response
+}
+
+func PromotionsWithTotalToResponses(promotions []*entity.PromotionWithTotal) []*model.PromotionResponse {
+	responses := make([]*model.PromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		responses = append(responses, PromotionToResponse(&promotion.Promotion))
+	}
+	return responses
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func RefundsToResponses(refunds []*entity.Refund, refundItems []*entity.RefundItem) []*model.RefundResponse {
+	itemResponses := make(map[uuid.UUID][]*model.RefundItemResponse, len(refunds))
+	for _, item := range refundItems {
+		itemResponses[item.RefundID] = append(itemResponses[item.RefundID], &model.RefundItemResponse{
+			TransactionDetailID: item.TransactionDetailID.String(),
+			ProductID:           item.ProductID.String(),
+			Quantity:            item.Quantity,
+			Amount:              item.Amount,
+		})
+	}
+
+	responses := make([]*model.RefundResponse, 0, len(refunds))
+	for _, refund := range refunds {
+		responses = append(responses, &model.RefundResponse{
+			ID:               refund.ID.String(),
+			TransactionID:    refund.TransactionID.String(),
+			Initiator:        refund.Initiator,
+			Status:           refund.Status,
+			Amount:           refund.Amount,
+			Reason:           refund.Reason.String,
+			PaymentProvider:  refund.PaymentProvider,
+			ProviderRefundID: refund.ProviderRefundID.String,
+			FailureReason:    refund.FailureReason.String,
+			CreatedAt:        formatTime(refund.CreatedAt),
+			CompletedAt:      nullable.SQLtoTime(refund.CompletedAt),
+			Items:            itemResponses[refund.ID],
+		})
+	}
+
+	return responses
+}
@@ -0,0 +1,38 @@
+package converter
+
+import (
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"time"
+)
+
+func SagaToResponse(instance *entity.SagaInstance, steps []*entity.SagaStep) *model.SagaResponse {
+	stepResponses := make([]*model.SagaStepResponse, 0, len(steps))
+	for _, step := range steps {
+		stepResponses = append(stepResponses, &model.SagaStepResponse{
+			Name:          step.StepName,
+			Order:         step.StepOrder,
+			Status:        step.Status,
+			Attempts:      step.Attempts,
+			LastError:     step.LastError.String,
+			NextRetryAt:   nullable.SQLtoTime(step.NextRetryAt),
+			StartedAt:     nullable.SQLtoTime(step.StartedAt),
+			CompletedAt:   nullable.SQLtoTime(step.CompletedAt),
+			CompensatedAt: nullable.SQLtoTime(step.CompensatedAt),
+		})
+	}
+
+	return &model.SagaResponse{
+		ID:          instance.ID.String(),
+		SagaType:    instance.SagaType,
+		AggregateID: instance.AggregateID.String(),
+		Status:      instance.Status,
+		CurrentStep: instance.CurrentStep.String,
+		LastError:   instance.LastError.String,
+		NextRunAt:   nullable.SQLtoTime(instance.NextRunAt),
+		CreatedAt:   instance.CreatedAt.Format(time.RFC1123),
+		UpdatedAt:   instance.UpdatedAt.Format(time.RFC1123),
+		Steps:       stepResponses,
+	}
+}
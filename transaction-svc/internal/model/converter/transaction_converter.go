@@ -1,20 +1,23 @@
 package converter
 
 import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/commoner/money"
 	"go-saga-pattern/transaction-svc/internal/entity"
 	"go-saga-pattern/transaction-svc/internal/model"
 	"log"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/midtrans/midtrans-go/coreapi"
 )
 
 func TransactionToCreateResponse(transaction *entity.Transaction, redirectUrl string) *model.CreateTransactionResponse {
 	return &model.CreateTransactionResponse{
-		TransactionId: transaction.ID.String(),
-		SnapToken:     transaction.SnapToken.String,
-		RedirectURL:   redirectUrl,
+		TransactionId:   transaction.ID.String(),
+		PaymentProvider: transaction.PaymentProvider,
+		SnapToken:       transaction.SnapToken.String,
+		RedirectURL:     redirectUrl,
 	}
 }
 
@@ -57,8 +60,12 @@ func TransactionDetailToResponses(transactionDetails []*entity.TransactionDetail
 		transactionDetailResponses = append(transactionDetailResponses, &model.TransactionDetailResponse{
 			ID:        transactionDetail.ID.String(),
 			ProductID: transactionDetail.ProductID.String(),
+			VariantID: nullable.UUIDtoString(transactionDetail.VariantID),
+			SKU:       transactionDetail.SKU.String,
 			Quantity:  transactionDetail.Quantity,
 			Price:     transactionDetail.Price,
+			Discount:  transactionDetail.Discount,
+			Tax:       transactionDetail.Tax,
 			CreatedAt: transactionDetail.CreatedAt.Local().Format(time.RFC1123),
 		})
 	}
@@ -66,17 +73,14 @@ func TransactionDetailToResponses(transactionDetails []*entity.TransactionDetail
 	return transactionDetailResponses
 }
 
-func SchedulerReqToCheckAndUpdate(schedulerReq *coreapi.TransactionStatusResponse, body []byte) *model.CheckAndUpdateTransactionRequest {
+func PaymentStatusToCheckAndUpdate(paymentStatus *model.PaymentStatusResponse) *model.CheckAndUpdateTransactionRequest {
 	return &model.CheckAndUpdateTransactionRequest{
-		MidtransTransactionStatus: schedulerReq.TransactionStatus,
-		StatusCode:                schedulerReq.StatusCode,
-		SignatureKey:              schedulerReq.SignatureKey,
-		SettlementTime:            schedulerReq.SettlementTime,
-		// ReferenceID:               schedulerReq.ReferenceID,
-		OrderID: schedulerReq.OrderID,
-		// Metadata:                  schedulerReq.Metadata,
-		GrossAmount: schedulerReq.GrossAmount,
-		Body:        body,
+		Provider:       paymentStatus.Provider,
+		OrderID:        paymentStatus.OrderID,
+		PaymentStatus:  paymentStatus.Status,
+		ExternalStatus: paymentStatus.ExternalStatus,
+		SettlementTime: paymentStatus.SettlementTime,
+		Body:           paymentStatus.Body,
 	}
 }
 
@@ -105,6 +109,73 @@ func formatTime(t *time.Time) string {
 	return t.Format(time.RFC1123)
 }
 
+// TransactionWithDetailToResponse builds a single transaction with its details and timeline, the payment token and
+// redirect url are only returned while the transaction is still waiting for payment.
+func TransactionWithDetailToResponse(transactionWithDetails []*entity.TransactionWithDetail, histories []*entity.TransactionStatusHistory) *model.TransactionResponse {
+	if len(transactionWithDetails) == 0 {
+		return nil
+	}
+
+	row := transactionWithDetails[0]
+	response := &model.TransactionResponse{
+		ID:                row.TransactionID.String(),
+		UserID:            row.TransactionUserID.String(),
+		TotalPrice:        row.TransactionTotalPrice,
+		Discount:          money.Zero(row.TransactionCurrency),
+		TransactionStatus: row.TransactionStatus,
+		CheckoutAt:        formatTime(row.TransactionCheckoutAt),
+		PaymentAt:         formatTime(row.TransactionPaymentAt),
+		UpdatedAt:         formatTime(row.TransactionUpdatedAt),
+		Breakdown: &model.PriceBreakdown{
+			Subtotal:   row.TransactionSubtotal,
+			Discount:   row.TransactionDiscount,
+			Tax:        row.TransactionTax,
+			ServiceFee: row.TransactionServiceFee,
+			PaymentFee: row.TransactionPaymentFee,
+		},
+		PaymentProvider:    row.TransactionPaymentProvider,
+		TransactionDetails: make([]*model.TransactionDetailResponse, 0, len(transactionWithDetails)),
+		Timeline:           make([]*model.TransactionStatusResponse, 0, len(histories)),
+		ExchangeRates:      row.TransactionExchangeRates,
+	}
+
+	if row.TransactionInternalStatus == enum.TrxInternalStatusTokenReady {
+		if row.TransactionSnapToken != nil {
+			response.SnapToken = *row.TransactionSnapToken
+		}
+		if row.TransactionPaymentRedirectURL != nil {
+			response.RedirectURL = *row.TransactionPaymentRedirectURL
+		}
+	}
+
+	for _, transactionWithDetail := range transactionWithDetails {
+		response.TransactionDetails = append(response.TransactionDetails, &model.TransactionDetailResponse{
+			ID:        transactionWithDetail.TransactionDetailID.String(),
+			ProductID: transactionWithDetail.TransactionDetailProductID.String(),
+			VariantID: nullable.UUIDtoString(transactionWithDetail.TransactionDetailVariantID),
+			SKU:       transactionWithDetail.TransactionDetailSKU.String,
+			Quantity:  transactionWithDetail.TransactionDetailQuantity,
+			Price:     transactionWithDetail.TransactionDetailPrice,
+			Discount:  transactionWithDetail.TransactionDetailDiscount,
+			Tax:       transactionWithDetail.TransactionDetailTax,
+			CreatedAt: formatTime(transactionWithDetail.TransactionDetailCreatedAt),
+		})
+		response.Discount = addDiscount(response.Discount, transactionWithDetail.TransactionDetailDiscount)
+	}
+
+	for _, history := range histories {
+		response.Timeline = append(response.Timeline, &model.TransactionStatusResponse{
+			FromStatus:        history.FromStatus.String,
+			Status:            history.ToStatus,
+			TransactionStatus: history.TransactionStatus,
+			ExternalStatus:    history.ExternalStatus.String,
+			CreatedAt:         formatTime(history.CreatedAt),
+		})
+	}
+
+	return response
+}
+
 func TransactionWithDetailAndTotalToResponse(transactionDetailTotal []*entity.TransactionWithDetailAndTotal, isOwner bool) []*model.TransactionResponse {
 	transactionMap := make(map[string]*model.TransactionResponse)
 
@@ -112,7 +183,7 @@ func TransactionWithDetailAndTotalToResponse(transactionDetailTotal []*entity.Tr
 		txID := row.TransactionID.String()
 		//Owner dont have to know user's main transaction data
 		if isOwner {
-			row.TransactionTotalPrice = 0
+			row.TransactionTotalPrice = money.Money{}
 		}
 
 		// Jika transaksi belum ada di map, buatkan
@@ -121,12 +192,23 @@ func TransactionWithDetailAndTotalToResponse(transactionDetailTotal []*entity.Tr
 				ID:                 txID,
 				UserID:             row.TransactionUserID.String(),
 				TotalPrice:         row.TransactionTotalPrice,
+				Discount:           money.Zero(row.TransactionCurrency),
 				TransactionStatus:  row.TransactionStatus,
 				CheckoutAt:         formatTime(row.TransactionCheckoutAt),
 				PaymentAt:          formatTime(row.TransactionPaymentAt),
 				UpdatedAt:          formatTime(row.TransactionUpdatedAt),
 				TransactionDetails: make([]*model.TransactionDetailResponse, 0),
 			}
+
+			if !isOwner {
+				transactionMap[txID].Breakdown = &model.PriceBreakdown{
+					Subtotal:   row.TransactionSubtotal,
+					Discount:   row.TransactionDiscount,
+					Tax:        row.TransactionTax,
+					ServiceFee: row.TransactionServiceFee,
+					PaymentFee: row.TransactionPaymentFee,
+				}
+			}
 		}
 
 		// Tambahkan detail jika ada detail yang valid
@@ -134,10 +216,15 @@ func TransactionWithDetailAndTotalToResponse(transactionDetailTotal []*entity.Tr
 			transactionMap[txID].TransactionDetails = append(transactionMap[txID].TransactionDetails, &model.TransactionDetailResponse{
 				ID:        row.TransactionDetailID.String(),
 				ProductID: row.TransactionDetailProductID.String(),
+				VariantID: nullable.UUIDtoString(row.TransactionDetailVariantID),
+				SKU:       row.TransactionDetailSKU.String,
 				Quantity:  row.TransactionDetailQuantity,
 				Price:     row.TransactionDetailPrice,
+				Discount:  row.TransactionDetailDiscount,
+				Tax:       row.TransactionDetailTax,
 				CreatedAt: formatTime(row.TransactionDetailCreatedAt),
 			})
+			transactionMap[txID].Discount = addDiscount(transactionMap[txID].Discount, row.TransactionDetailDiscount)
 		}
 	}
 
@@ -149,3 +236,12 @@ func TransactionWithDetailAndTotalToResponse(transactionDetailTotal []*entity.Tr
 
 	return result
 }
+
+// addDiscount adds the discount of a line to the discount of its transaction, the lines share the currency of the
+// transaction so a failed sum only leaves the total as it was.
+func addDiscount(total, discount money.Money) money.Money {
+	if sum, err := total.Add(discount); err == nil {
+		return sum
+	}
+	return total
+}
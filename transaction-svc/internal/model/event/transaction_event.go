@@ -1,6 +1,26 @@
 package event
 
+import "go-saga-pattern/commoner/constant/enum"
+
 type TransactionEvent struct {
 	TransactionID string `json:"transaction_id"`
-	Status        string `json:"status"` // e.g., "committed", "settled", "cancelled", "expired"
+	Status        string `json:"status"` // e.g., "committed", "settled", "cancelled", "expired", "refunded"
+	// RefundID and Items are only set on transaction.refunded, Items holds the refunded quantity per product
+	RefundID string                  `json:"refund_id,omitempty"`
+	Items    []*TransactionEventItem `json:"items,omitempty"`
+}
+
+// TransactionEventItem is a refunded line, VariantID is empty when the product has no variants.
+type TransactionEventItem struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+// TransactionStatusEvent is published on redis pub/sub every time a transaction changes status.
+type TransactionStatusEvent struct {
+	TransactionID     string                 `json:"transaction_id"`
+	Status            enum.TrxInternalStatus `json:"status"`
+	TransactionStatus enum.TransactionStatus `json:"transaction_status"`
+	UpdatedAt         string                 `json:"updated_at"`
 }
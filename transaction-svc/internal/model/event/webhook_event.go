@@ -1,11 +1,9 @@
 package event
 
+import "go-saga-pattern/commoner/constant/enum"
+
 type WebhookNotifyEvent struct {
-	MidtransTransactionStatus string `json:"transaction_status"`
-	StatusCode                string `json:"status_code"`
-	SignatureKey              string `json:"signature_key"`
-	SettlementTime            string `json:"settlement_time"`
-	OrderID                   string `json:"order_id"`
-	GrossAmount               string `json:"gross_amount"`
-	Body                      []byte `json:"body"`
+	Provider enum.PaymentProvider `json:"provider"`
+	Headers  map[string]string    `json:"headers"`
+	Body     []byte               `json:"body"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"math/big"
+	"time"
+)
+
+// ExchangeRateTable prices every currency in the base currency, a USD rate of 16250 in an IDR table means one US
+// dollar costs Rp16,250.
+type ExchangeRateTable struct {
+	Base      string
+	Rates     map[string]*big.Rat
+	Source    string
+	UpdatedAt time.Time
+}
+
+// ExchangeRate is the price of one unit of From in To, it is stored with a transaction as a snapshot of the rate its
+// prices were converted at.
+type ExchangeRate struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Rate      string    `json:"rate"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
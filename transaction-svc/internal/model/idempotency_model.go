@@ -0,0 +1,16 @@
+package model
+
+import "github.com/google/uuid"
+
+type IdempotencyRequest struct {
+	UserID      uuid.UUID
+	Key         string
+	RequestHash string
+}
+
+// IdempotentResponse is the response of the first request of an Idempotency-Key, replayed for its duplicates.
+type IdempotentResponse struct {
+	RequestHash string `json:"request_hash"`
+	StatusCode  int    `json:"status_code"`
+	Body        []byte `json:"body"`
+}
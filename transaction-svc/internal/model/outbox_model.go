@@ -0,0 +1,15 @@
+package model
+
+type OutboxRelayResult struct {
+	Sent    int `json:"sent"`
+	Retried int `json:"retried"`
+	Failed  int `json:"failed"`
+	// Skipped counts the events left for a later batch because an earlier event of their aggregate was rescheduled
+	Skipped int `json:"skipped"`
+}
+
+type OutboxLagResponse struct {
+	PendingCount            int64   `json:"pending_count"`
+	FailedCount             int64   `json:"failed_count"`
+	OldestPendingAgeSeconds float64 `json:"oldest_pending_age_seconds"`
+}
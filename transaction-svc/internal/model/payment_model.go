@@ -1,7 +1,71 @@
 package model
 
-type PaymentSnapshotRequest struct {
-	OrderID     string `json:"order_id,omitempty"`
-	GrossAmount int64  `json:"gross_amount,omitempty"`
-	Email       string `json:"email,omitempty"`
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"time"
+)
+
+type CreateChargeRequest struct {
+	OrderID     string      `json:"order_id,omitempty"`
+	GrossAmount money.Money `json:"gross_amount"`
+	Email       string      `json:"email,omitempty"`
+	// Items add up to GrossAmount: the products, then the discount as a negative item, the tax and the fees
+	Items []ChargeItem `json:"items,omitempty"`
+}
+
+type ChargeItem struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Price    money.Money `json:"price"`
+	Quantity int         `json:"quantity"`
+}
+
+type ChargeResponse struct {
+	Provider    enum.PaymentProvider `json:"provider"`
+	Token       string               `json:"token"`
+	RedirectURL string               `json:"redirect_url"`
+	// Reference is the provider's own id for the charge when it differs from the order id
+	Reference string `json:"reference,omitempty"`
+}
+
+type PaymentStatusRequest struct {
+	OrderID   string
+	Reference string
+}
+
+type PaymentStatusResponse struct {
+	Provider       enum.PaymentProvider
+	OrderID        string
+	Reference      string
+	Status         enum.PaymentStatus
+	ExternalStatus string
+	GrossAmount    string
+	SettlementTime *time.Time
+	Body           []byte
+}
+
+type PaymentNotificationRequest struct {
+	Provider enum.PaymentProvider `validate:"required"`
+	Headers  map[string]string
+	Body     []byte `validate:"required"`
+}
+
+type RefundChargeRequest struct {
+	OrderID   string
+	Reference string
+	RefundKey string
+	Amount    money.Money
+	Reason    string
+}
+
+type RefundChargeResponse struct {
+	Provider       enum.PaymentProvider
+	RefundID       string
+	ExternalStatus string
+}
+
+type CancelChargeRequest struct {
+	OrderID   string
+	Reference string
 }
@@ -1,20 +1,39 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"go-saga-pattern/commoner/money"
+
+	"github.com/google/uuid"
+)
 
 type CheckProductQuantity struct {
 	ProductID uuid.UUID
+	VariantID uuid.NullUUID
 	Quantity  int
-	Price     float64
 }
 
 type ProductResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	CreatedAt   string  `json:"created_at,omitempty"`
-	UpdatedAt   string  `json:"updated_at,omitempty"`
-	DeletedAt   string  `json:"deleted_at,omitempty"`
+	ID          string `json:"id"`
+	UserID      string `json:"user_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category,omitempty"`
+	// VariantID and SKU are only set on reserved products with variants, the price is the one of the variant
+	VariantID string      `json:"variant_id,omitempty"`
+	SKU       string      `json:"sku,omitempty"`
+	Price     money.Money `json:"price"`
+	Quantity  int         `json:"quantity"`
+	CreatedAt string      `json:"created_at,omitempty"`
+	UpdatedAt string      `json:"updated_at,omitempty"`
+	DeletedAt string      `json:"deleted_at,omitempty"`
+	// Variants are the variants buyers can choose from, only set on products looked up by id
+	Variants []*ProductVariantResponse `json:"variants,omitempty"`
+}
+
+type ProductVariantResponse struct {
+	ID       string            `json:"id"`
+	SKU      string            `json:"sku"`
+	Options  map[string]string `json:"options"`
+	Price    money.Money       `json:"price"`
+	Quantity int               `json:"quantity"`
 }
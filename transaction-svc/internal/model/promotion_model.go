@@ -0,0 +1,71 @@
+package model
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreatePromotionRequest describes a promo code. Percentage is in percent with up to two decimals, Amount is the
+// discount of FIXED promotions and BuyQuantity/GetQuantity the items paid for and given away by BUY_X_GET_Y ones.
+type CreatePromotionRequest struct {
+	Code              string              `json:"code" validate:"required,alphanum,max=50"`
+	Name              string              `json:"name" validate:"required,max=255"`
+	Type              enum.PromotionType  `json:"type" validate:"required,oneof=PERCENTAGE FIXED BUY_X_GET_Y"`
+	Scope             enum.PromotionScope `json:"scope" validate:"omitempty,oneof=ALL PRODUCT OWNER"`
+	ScopeID           uuid.UUID           `json:"scope_id"`
+	Percentage        float64             `json:"percentage" validate:"omitempty,gt=0,lte=100"`
+	Amount            money.Money         `json:"amount" validate:"omitempty,gt=0"`
+	BuyQuantity       int                 `json:"buy_quantity" validate:"omitempty,gt=0"`
+	GetQuantity       int                 `json:"get_quantity" validate:"omitempty,gt=0"`
+	UsageLimit        int                 `json:"usage_limit" validate:"omitempty,gt=0"`
+	UsageLimitPerUser int                 `json:"usage_limit_per_user" validate:"omitempty,gt=0"`
+	StartsAt          *time.Time          `json:"starts_at"`
+	EndsAt            *time.Time          `json:"ends_at"`
+}
+
+type GetPromotionRequest struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type DeactivatePromotionRequest struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type SearchPromotionsRequest struct {
+	ActiveOnly bool
+	Page       int `validate:"required,min=1"`
+	Limit      int `validate:"required,min=1,max=100"`
+}
+
+type PromotionResponse struct {
+	ID                string              `json:"id"`
+	Code              string              `json:"code"`
+	Name              string              `json:"name"`
+	Type              enum.PromotionType  `json:"type"`
+	Scope             enum.PromotionScope `json:"scope"`
+	ScopeID           string              `json:"scope_id,omitempty"`
+	Percentage        string              `json:"percentage,omitempty"`
+	Amount            *money.Money        `json:"amount,omitempty"`
+	BuyQuantity       int                 `json:"buy_quantity,omitempty"`
+	GetQuantity       int                 `json:"get_quantity,omitempty"`
+	UsageLimit        int64               `json:"usage_limit,omitempty"`
+	UsageLimitPerUser int64               `json:"usage_limit_per_user,omitempty"`
+	UsageCount        int                 `json:"usage_count"`
+	StartsAt          string              `json:"starts_at,omitempty"`
+	EndsAt            string              `json:"ends_at,omitempty"`
+	IsActive          bool                `json:"is_active"`
+	CreatedAt         string              `json:"created_at,omitempty"`
+	UpdatedAt         string              `json:"updated_at,omitempty"`
+}
+
+// CheckoutDiscount is what a promo code took off a line of the checkout, in the checkout currency.
+type CheckoutDiscount struct {
+	PromotionID uuid.UUID     `json:"promotion_id"`
+	Code        string        `json:"code"`
+	ProductID   uuid.UUID     `json:"product_id"`
+	VariantID   uuid.NullUUID `json:"variant_id"`
+	Amount      money.Money   `json:"amount"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+
+	"github.com/google/uuid"
+)
+
+// RefundTransactionRequest refunds the listed transaction details, or everything not refunded yet when Items is empty.
+type RefundTransactionRequest struct {
+	TransactionID uuid.UUID            `json:"-" validate:"required"`
+	UserID        uuid.UUID            `json:"-" validate:"required_unless=Initiator SYSTEM"`
+	Initiator     enum.RefundInitiator `json:"-" validate:"required,oneof=USER OWNER SYSTEM"`
+	Reason        string               `json:"reason" validate:"omitempty,max=255"`
+	Items         []RefundItemRequest  `json:"items" validate:"omitempty,dive"`
+}
+
+type RefundItemRequest struct {
+	TransactionDetailID uuid.UUID `json:"transaction_detail_id" validate:"required"`
+	Quantity            int       `json:"quantity" validate:"required,gt=0"`
+}
+
+type RefundResponse struct {
+	ID               string                `json:"id"`
+	TransactionID    string                `json:"transaction_id"`
+	Initiator        enum.RefundInitiator  `json:"initiator"`
+	Status           enum.RefundStatus     `json:"status"`
+	Amount           money.Money           `json:"amount"`
+	Reason           string                `json:"reason,omitempty"`
+	PaymentProvider  enum.PaymentProvider  `json:"payment_provider"`
+	ProviderRefundID string                `json:"provider_refund_id,omitempty"`
+	FailureReason    string                `json:"failure_reason,omitempty"`
+	CreatedAt        string                `json:"created_at,omitempty"`
+	CompletedAt      string                `json:"completed_at,omitempty"`
+	Items            []*RefundItemResponse `json:"items"`
+}
+
+type RefundItemResponse struct {
+	TransactionDetailID string      `json:"transaction_detail_id"`
+	ProductID           string      `json:"product_id"`
+	Quantity            int         `json:"quantity"`
+	Amount              money.Money `json:"amount"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+
+	"github.com/google/uuid"
+)
+
+// CheckoutSagaData is the payload carried by the checkout saga between its steps.
+type CheckoutSagaData struct {
+	TransactionID   uuid.UUID            `json:"transaction_id"`
+	UserID          uuid.UUID            `json:"user_id"`
+	Products        []TransactionProduct `json:"products"`
+	PaymentProvider enum.PaymentProvider `json:"payment_provider,omitempty"`
+	// Currency is the currency the checkout is paid in, the currency of the first product when none was requested
+	Currency      string         `json:"currency,omitempty"`
+	ExchangeRates []ExchangeRate `json:"exchange_rates,omitempty"`
+	ExpectedTotal money.Money    `json:"expected_total"`
+	PromoCodes    []string       `json:"promo_codes,omitempty"`
+	// Discounts are what the promo codes took off the products, reserved by the apply promotions step
+	Discounts   []CheckoutDiscount `json:"discounts,omitempty"`
+	Discount    money.Money        `json:"discount"`
+	Breakdown   *PriceBreakdown    `json:"breakdown,omitempty"`
+	TotalPrice  money.Money        `json:"total_price"`
+	SnapToken   string             `json:"snap_token,omitempty"`
+	RedirectURL string             `json:"redirect_url,omitempty"`
+}
+
+type SagaResponse struct {
+	ID          string              `json:"id"`
+	SagaType    string              `json:"saga_type"`
+	AggregateID string              `json:"aggregate_id"`
+	Status      enum.SagaStatus     `json:"status"`
+	CurrentStep string              `json:"current_step,omitempty"`
+	LastError   string              `json:"last_error,omitempty"`
+	NextRunAt   string              `json:"next_run_at,omitempty"`
+	CreatedAt   string              `json:"created_at,omitempty"`
+	UpdatedAt   string              `json:"updated_at,omitempty"`
+	Steps       []*SagaStepResponse `json:"steps"`
+}
+
+type SagaStepResponse struct {
+	Name          string              `json:"name"`
+	Order         int                 `json:"order"`
+	Status        enum.SagaStepStatus `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"last_error,omitempty"`
+	NextRetryAt   string              `json:"next_retry_at,omitempty"`
+	StartedAt     string              `json:"started_at,omitempty"`
+	CompletedAt   string              `json:"completed_at,omitempty"`
+	CompensatedAt string              `json:"compensated_at,omitempty"`
+}
+
+// RefundSagaData is the payload carried by the refund saga between its steps.
+type RefundSagaData struct {
+	RefundID      uuid.UUID            `json:"refund_id"`
+	TransactionID uuid.UUID            `json:"transaction_id"`
+	Initiator     enum.RefundInitiator `json:"initiator"`
+	RequestedBy   uuid.UUID            `json:"requested_by,omitempty"`
+	Reason        string               `json:"reason,omitempty"`
+	// Items is empty for a full refund, the remaining quantity of every line is refunded
+	Items []RefundItemRequest `json:"items,omitempty"`
+}
@@ -3,13 +3,30 @@ package model
 import (
 	"encoding/json"
 	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type CreateTransactionRequest struct {
-	UserID   uuid.UUID            `json:"user_id" validate:"required,uuid"`
-	Products []TransactionProduct `json:"products" validate:"required"`
+	UserID          uuid.UUID            `json:"user_id" validate:"required,uuid"`
+	Products        []TransactionProduct `json:"products" validate:"required"`
+	PaymentProvider enum.PaymentProvider `json:"payment_provider" validate:"omitempty,oneof=MIDTRANS XENDIT"`
+	// ExpectedTotal is the total the buyer was quoted, the checkout is rejected when the server priced total rises
+	// above it by more than the price tolerance
+	ExpectedTotal money.Money `json:"expected_total" validate:"omitempty,gt=0"`
+	// Currency is the currency to pay in, products priced in another currency follow the checkout currency policy
+	Currency string `json:"currency" validate:"omitempty,currency"`
+	// PromoCodes are applied in the given order, each one to what the ones before left of the order
+	PromoCodes []string `json:"promo_codes" validate:"omitempty,max=5,dive,required,max=50"`
+}
+
+// PriceTolerance is how far a checkout total may rise above the expected total, the larger of Amount and BasisPoints
+// (hundredths of a percent) of the expected total applies. A lower total is always accepted.
+type PriceTolerance struct {
+	Amount      money.Money
+	BasisPoints int64
 }
 
 type GetTransactionRequest struct {
@@ -17,6 +34,11 @@ type GetTransactionRequest struct {
 	TransacitonID uuid.UUID `json:"transaction_id" validate:"required,uuid"`
 }
 
+type CancelTransactionRequest struct {
+	UserID        uuid.UUID `json:"user_id" validate:"required,uuid"`
+	TransactionID uuid.UUID `json:"transaction_id" validate:"required,uuid"`
+}
+
 type UserSearchTransactionRequest struct {
 	UserID uuid.UUID `json:"user_id" validate:"required,uuid"`
 	Page   int       `validate:"required,min=1"`
@@ -30,65 +52,96 @@ type OwnerSearchTransactionRequest struct {
 	Limit     int       `validate:"required,min=1,max=100"`
 }
 
+// TransactionProduct is a line of the order. The price, owner and category sent by the client are ignored, the
+// checkout saga fills them in from product-svc when the stock is reserved.
 type TransactionProduct struct {
 	ProductID uuid.UUID `json:"product_id" validate:"required,uuid"`
-	Price     float64   `json:"price" validate:"required,gt=0"`
-	Quantity  int       `json:"quantity" validate:"required,gt=0"`
+	// VariantID is required when the product has variants, the SKU is filled in along with the price
+	VariantID uuid.NullUUID `json:"variant_id"`
+	SKU       string        `json:"sku,omitempty"`
+	OwnerID   uuid.UUID     `json:"owner_id,omitempty"`
+	Category  string        `json:"category,omitempty"`
+	Price     money.Money   `json:"price" validate:"omitempty,gt=0"`
+	Quantity  int           `json:"quantity" validate:"required,gt=0"`
 }
 
 type CreateTransactionResponse struct {
-	TransactionId string `json:"transaction_id"`
-	SnapToken     string `json:"snap_token,omitempty"`
-	RedirectURL   string `json:"redirect_url,omitempty"`
+	TransactionId   string               `json:"transaction_id"`
+	PaymentProvider enum.PaymentProvider `json:"payment_provider,omitempty"`
+	SnapToken       string               `json:"snap_token,omitempty"`
+	RedirectURL     string               `json:"redirect_url,omitempty"`
+	TotalPrice      money.Money          `json:"total_price"`
+	Discount        money.Money          `json:"discount"`
+	Breakdown       *PriceBreakdown      `json:"breakdown,omitempty"`
+}
+
+// PriceBreakdown is how the total price of a transaction was reached, the total is the subtotal less the discount
+// plus the tax and the fees.
+type PriceBreakdown struct {
+	Subtotal   money.Money `json:"subtotal"`
+	Discount   money.Money `json:"discount"`
+	Tax        money.Money `json:"tax"`
+	ServiceFee money.Money `json:"service_fee"`
+	PaymentFee money.Money `json:"payment_fee"`
+}
+
+type ObtainPaymentTokenRequest struct {
+	TransactionID uuid.UUID
+	// LastAttempt cancels the checkout when the payment token still cannot be obtained
+	LastAttempt bool
 }
 
 type TransactionResponse struct {
 	ID                 string                       `json:"id"`
 	UserID             string                       `json:"user_id"`
-	TotalPrice         float64                      `json:"total_price,omitempty"`
+	TotalPrice         money.Money                  `json:"total_price,omitempty"`
+	Discount           money.Money                  `json:"discount"`
+	Breakdown          *PriceBreakdown              `json:"breakdown,omitempty"`
 	TransactionStatus  enum.TransactionStatus       `json:"transaction_status"`
 	CheckoutAt         string                       `json:"checkout_at,omitempty"`
 	PaymentAt          string                       `json:"payment_at,omitempty"`
 	UpdatedAt          string                       `json:"update_at,omitempty"`
+	PaymentProvider    enum.PaymentProvider         `json:"payment_provider,omitempty"`
+	SnapToken          string                       `json:"snap_token,omitempty"`
+	RedirectURL        string                       `json:"redirect_url,omitempty"`
 	TransactionDetails []*TransactionDetailResponse `json:"transaction_details,omitempty"`
+	Timeline           []*TransactionStatusResponse `json:"timeline,omitempty"`
+	// ExchangeRates are the rates the products were converted at during checkout, if any
+	ExchangeRates json.RawMessage `json:"exchange_rates,omitempty"`
+}
+
+type TransactionStatusResponse struct {
+	FromStatus        string                 `json:"from_status,omitempty"`
+	Status            enum.TrxInternalStatus `json:"status"`
+	TransactionStatus enum.TransactionStatus `json:"transaction_status"`
+	ExternalStatus    string                 `json:"external_status,omitempty"`
+	CreatedAt         string                 `json:"created_at"`
 }
 
 type TransactionDetailResponse struct {
-	ID        string  `json:"id"`
-	ProductID string  `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
-	CreatedAt string  `json:"created_at,omitempty"`
+	ID        string      `json:"id"`
+	ProductID string      `json:"product_id"`
+	VariantID string      `json:"variant_id,omitempty"`
+	SKU       string      `json:"sku,omitempty"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+	Discount  money.Money `json:"discount"`
+	Tax       money.Money `json:"tax"`
+	CreatedAt string      `json:"created_at,omitempty"`
 }
 
+// CheckAndUpdateTransactionRequest is a provider-neutral payment status, built from a verified webhook or a status poll.
 type CheckAndUpdateTransactionRequest struct {
-	MidtransTransactionStatus string `json:"transaction_status" validate:"required"`
-	StatusCode                string `json:"status_code" validate:"required"`
-	SignatureKey              string `json:"signature_key" validate:"required"`
-	SettlementTime            string `json:"settlement_time" validate:"required"`
-	OrderID                   string `json:"order_id" validate:"required"`
-	GrossAmount               string `json:"gross_amount" validate:"required"`
-	Body                      []byte `json:"-"`
+	Provider       enum.PaymentProvider `json:"provider" validate:"required"`
+	OrderID        string               `json:"order_id" validate:"required,uuid"`
+	PaymentStatus  enum.PaymentStatus   `json:"payment_status" validate:"required"`
+	ExternalStatus string               `json:"external_status" validate:"required"`
+	SettlementTime *time.Time           `json:"settlement_time"`
+	Body           []byte               `json:"-"`
 }
 
 type WebhookNotifyRequest struct {
-	MidtransTransactionType   string          `json:"transaction_type"`
-	MidtransTransactionTime   string          `json:"transaction_time"`
-	MidtransTransactionStatus string          `json:"transaction_status" validate:"required"`
-	MidtransTransactionID     string          `json:"transaction_id"`
-	StatusMessage             string          `json:"status_message"`
-	StatusCode                string          `json:"status_code" validate:"required"`
-	SignatureKey              string          `json:"signature_key" validate:"required"`
-	SettlementTime            string          `json:"settlement_time" validate:"required"`
-	ReferenceID               string          `json:"reference_id"`
-	PaymentType               string          `json:"payment_type"`
-	OrderID                   string          `json:"order_id" validate:"required"`
-	Metadata                  json.RawMessage `json:"metadata"`
-	MerchantID                string          `json:"merchant_id"`
-	GrossAmount               string          `json:"gross_amount" validate:"required"`
-	FraudStatus               string          `json:"fraud_status"`
-	ExpiryTime                string          `json:"expiry_time"`
-	Currency                  string          `json:"currency"`
-	Acquirer                  string          `json:"acquirer"`
-	Body                      []byte          `json:"-"`
+	Provider enum.PaymentProvider `json:"provider" validate:"required,oneof=MIDTRANS XENDIT"`
+	Headers  map[string]string    `json:"headers"`
+	Body     []byte               `json:"-" validate:"required"`
 }
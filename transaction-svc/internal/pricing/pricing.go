@@ -0,0 +1,120 @@
+// Package pricing prices a checkout in stages: the subtotal of the lines, the discounts the promotions took off them,
+// the taxes on what is left and the service and payment fees, the grand total is what the buyer is charged.
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+
+	"github.com/google/uuid"
+)
+
+// Line is a checkout line being priced. Price is the unit price times the quantity, Discount is what the promotions
+// took off it and Tax is filled in by the tax stage.
+type Line struct {
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Category  string
+	Quantity  int
+	Price     money.Money
+	Discount  money.Money
+	Tax       money.Money
+}
+
+// Net is the line price after the discounts, the amount taxes are charged on.
+func (l *Line) Net() (money.Money, error) {
+	return l.Price.Sub(l.Discount)
+}
+
+// Breakdown is a checkout as it goes through the stages, every amount is in Currency.
+type Breakdown struct {
+	Currency        string
+	PaymentProvider enum.PaymentProvider
+	Lines           []*Line
+	Subtotal        money.Money
+	Discount        money.Money
+	Tax             money.Money
+	ServiceFee      money.Money
+	PaymentFee      money.Money
+	GrandTotal      money.Money
+}
+
+// NewBreakdown starts the breakdown of the lines with every amount at zero, a line without a discount gets a zero one.
+func NewBreakdown(currency string, paymentProvider enum.PaymentProvider, lines []*Line) *Breakdown {
+	for _, line := range lines {
+		if line.Discount.Currency == "" {
+			line.Discount = money.Zero(currency)
+		}
+		line.Tax = money.Zero(currency)
+	}
+
+	zero := money.Zero(currency)
+	return &Breakdown{
+		Currency:        zero.Currency,
+		PaymentProvider: paymentProvider,
+		Lines:           lines,
+		Subtotal:        zero,
+		Discount:        zero,
+		Tax:             zero,
+		ServiceFee:      zero,
+		PaymentFee:      zero,
+		GrandTotal:      zero,
+	}
+}
+
+// Total is the running total of the stages applied so far: the subtotal less the discounts plus the taxes and fees.
+func (b *Breakdown) Total() (money.Money, error) {
+	total, err := b.Subtotal.Sub(b.Discount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return money.Sum(b.Currency, total, b.Tax, b.ServiceFee, b.PaymentFee)
+}
+
+// Stage is one step of the pipeline, it reads what the stages before it filled in and adds its own amounts.
+type Stage interface {
+	Name() string
+	Apply(ctx context.Context, breakdown *Breakdown) error
+}
+
+type Pipeline interface {
+	// Price runs every stage in order and sets the grand total of the breakdown.
+	Price(ctx context.Context, breakdown *Breakdown) error
+}
+
+type pipeline struct {
+	stages []Stage
+}
+
+func NewPipeline(stages ...Stage) Pipeline {
+	return &pipeline{stages: stages}
+}
+
+// NewCheckoutPipeline is the pipeline checkouts are priced with: subtotal, discounts, taxes, service fee and payment
+// fee, in that order.
+func NewCheckoutPipeline(taxRules TaxRules, serviceFee FeeRule, paymentFees map[enum.PaymentProvider]FeeRule) Pipeline {
+	return NewPipeline(
+		NewSubtotalStage(),
+		NewDiscountStage(),
+		NewTaxStage(taxRules),
+		NewServiceFeeStage(serviceFee),
+		NewPaymentFeeStage(paymentFees),
+	)
+}
+
+func (p *pipeline) Price(ctx context.Context, breakdown *Breakdown) error {
+	for _, stage := range p.stages {
+		if err := stage.Apply(ctx, breakdown); err != nil {
+			return fmt.Errorf("pricing stage %s: %w", stage.Name(), err)
+		}
+	}
+
+	grandTotal, err := breakdown.Total()
+	if err != nil {
+		return fmt.Errorf("pricing grand total: %w", err)
+	}
+	breakdown.GrandTotal = grandTotal
+	return nil
+}
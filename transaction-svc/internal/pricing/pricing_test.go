@@ -0,0 +1,108 @@
+package pricing_test
+
+import (
+	"context"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/pricing"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func idr(amount int64) money.Money {
+	return money.New(amount, "IDR")
+}
+
+// checkoutLines is a book at Rp100.00 discounted by Rp10.00 and 2 snacks at Rp25.00.
+func checkoutLines() []*pricing.Line {
+	return []*pricing.Line{
+		{ProductID: uuid.New(), Category: "Books", Quantity: 1, Price: idr(10000), Discount: idr(1000)},
+		{ProductID: uuid.New(), Quantity: 2, Price: idr(5000)},
+	}
+}
+
+func TestCheckoutPipeline_Price(t *testing.T) {
+	pipeline := pricing.NewCheckoutPipeline(
+		pricing.TaxRules{Default: 1100, Categories: map[string]int64{"books": 0}},
+		pricing.FeeRule{Amount: idr(200)},
+		map[enum.PaymentProvider]pricing.FeeRule{enum.PaymentProviderMidtrans: {BasisPoints: 100}},
+	)
+
+	breakdown := pricing.NewBreakdown("IDR", enum.PaymentProviderMidtrans, checkoutLines())
+	require.NoError(t, pipeline.Price(context.Background(), breakdown))
+
+	assert.Equal(t, idr(15000), breakdown.Subtotal)
+	assert.Equal(t, idr(1000), breakdown.Discount)
+	// 11% of the snacks only, books are not taxed, Rp5.50 is charged as Rp6
+	assert.Equal(t, idr(600), breakdown.Tax)
+	assert.Equal(t, idr(0), breakdown.Lines[0].Tax)
+	assert.Equal(t, idr(600), breakdown.Lines[1].Tax)
+	assert.Equal(t, idr(200), breakdown.ServiceFee)
+	// 1% of 14000 + 600 + 200, Rp1.48 is charged as Rp1
+	assert.Equal(t, idr(100), breakdown.PaymentFee)
+	assert.Equal(t, idr(14900), breakdown.GrandTotal)
+}
+
+func TestCheckoutPipeline_RoundsToWholeRupiah(t *testing.T) {
+	pipeline := pricing.NewCheckoutPipeline(
+		pricing.TaxRules{Default: 1100},
+		pricing.FeeRule{Amount: idr(250)},
+		map[enum.PaymentProvider]pricing.FeeRule{enum.PaymentProviderMidtrans: {BasisPoints: 150}},
+	)
+
+	// Rp1001.00 discounted by Rp0.40, what the promotions leave is charged in whole rupiah
+	lines := []*pricing.Line{{ProductID: uuid.New(), Quantity: 1, Price: idr(100100), Discount: idr(40)}}
+	breakdown := pricing.NewBreakdown("IDR", enum.PaymentProviderMidtrans, lines)
+	require.NoError(t, pipeline.Price(context.Background(), breakdown))
+
+	assert.Equal(t, idr(0), breakdown.Discount)
+	// 11% of Rp1001.00 is Rp110.11
+	assert.Equal(t, idr(11000), breakdown.Tax)
+	// Rp2.50 is charged as Rp3
+	assert.Equal(t, idr(300), breakdown.ServiceFee)
+	// 1.5% of Rp1114.00 is Rp16.71
+	assert.Equal(t, idr(1700), breakdown.PaymentFee)
+	assert.Equal(t, idr(113100), breakdown.GrandTotal)
+
+	_, err := breakdown.GrandTotal.MajorUnits()
+	assert.NoError(t, err)
+}
+
+func TestCheckoutPipeline_FeesOnlyApplyToTheirProviderAndCurrency(t *testing.T) {
+	pipeline := pricing.NewCheckoutPipeline(
+		pricing.TaxRules{},
+		pricing.FeeRule{Amount: idr(200)},
+		map[enum.PaymentProvider]pricing.FeeRule{enum.PaymentProviderMidtrans: {BasisPoints: 100}},
+	)
+
+	lines := []*pricing.Line{{ProductID: uuid.New(), Quantity: 1, Price: money.New(1000, "USD")}}
+	breakdown := pricing.NewBreakdown("USD", enum.PaymentProviderXendit, lines)
+	require.NoError(t, pipeline.Price(context.Background(), breakdown))
+
+	assert.Equal(t, money.New(0, "USD"), breakdown.ServiceFee)
+	assert.Equal(t, money.New(0, "USD"), breakdown.PaymentFee)
+	assert.Equal(t, money.New(1000, "USD"), breakdown.GrandTotal)
+}
+
+func TestCheckoutPipeline_RejectsALineInAnotherCurrency(t *testing.T) {
+	pipeline := pricing.NewCheckoutPipeline(pricing.TaxRules{}, pricing.FeeRule{}, nil)
+
+	lines := append(checkoutLines(), &pricing.Line{ProductID: uuid.New(), Quantity: 1, Price: money.New(1000, "USD")})
+	breakdown := pricing.NewBreakdown("IDR", enum.PaymentProviderMidtrans, lines)
+	assert.ErrorIs(t, pipeline.Price(context.Background(), breakdown), money.ErrCurrencyMismatch)
+}
+
+func TestTaxRules_Rate(t *testing.T) {
+	rules := pricing.TaxRules{Default: 1100, Categories: map[string]int64{"food": 250, "food/snacks/imported": 1500}}
+
+	assert.Equal(t, int64(250), rules.Rate("Food"))
+	assert.Equal(t, int64(1100), rules.Rate("toys"))
+	assert.Equal(t, int64(1100), rules.Rate(""))
+	assert.Equal(t, int64(250), rules.Rate("food/snacks"))
+	assert.Equal(t, int64(1500), rules.Rate("food/snacks/imported"))
+	assert.Equal(t, int64(1500), rules.Rate("food/snacks/imported/chips"))
+	assert.Equal(t, int64(1100), rules.Rate("toys/food"))
+}
@@ -0,0 +1,234 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/money"
+	"strings"
+)
+
+// basisPointsWhole is 100%, rates are kept in hundredths of a percent.
+const basisPointsWhole = 10000
+
+// TaxRules are the tax rates in basis points per product category, Default applies to the categories without a rate
+// of their own and to products without a category.
+type TaxRules struct {
+	Default    int64
+	Categories map[string]int64
+}
+
+// Rate returns the rate of the category, categories are matched case-insensitively. A category path such as
+// books/fiction without a rate of its own falls back to the rate of its closest parent.
+func (r TaxRules) Rate(category string) int64 {
+	category = strings.ToLower(strings.TrimSpace(category))
+	for {
+		if rate, ok := r.Categories[category]; ok {
+			return rate
+		}
+
+		index := strings.LastIndex(category, "/")
+		if index < 0 {
+			return r.Default
+		}
+		category = category[:index]
+	}
+}
+
+// FeeRule is a fee of Amount plus BasisPoints of the running total. Amount only applies to checkouts in its currency.
+type FeeRule struct {
+	Amount      money.Money
+	BasisPoints int64
+}
+
+// IsZero tells whether the rule never charges anything.
+func (r FeeRule) IsZero() bool {
+	return r.Amount.IsZero() && r.BasisPoints == 0
+}
+
+// fee charges the rule on the running total of the breakdown, rounded to the unit the payment gateway charges.
+func (r FeeRule) fee(breakdown *Breakdown) (money.Money, error) {
+	fee := money.Zero(breakdown.Currency)
+	if r.IsZero() {
+		return fee, nil
+	}
+
+	if r.Amount.IsPositive() && r.Amount.CurrencyCode() == breakdown.Currency {
+		amount, err := r.Amount.RoundPayable()
+		if err != nil {
+			return money.Money{}, err
+		}
+		fee = amount
+	}
+
+	if r.BasisPoints > 0 {
+		total, err := breakdown.Total()
+		if err != nil {
+			return money.Money{}, err
+		}
+
+		percentage, err := total.ProratePayable(r.BasisPoints, basisPointsWhole)
+		if err != nil {
+			return money.Money{}, err
+		}
+
+		if fee, err = fee.Add(percentage); err != nil {
+			return money.Money{}, err
+		}
+	}
+
+	return fee, nil
+}
+
+// RoundDiscount rounds a discount to the unit the payment gateway charges, so a gateway that only takes whole rupiah
+// can charge the discounted line. The discount never gets more than left, what is left of the line before it.
+func RoundDiscount(discount, left money.Money) (money.Money, error) {
+	rounded, err := discount.RoundPayable()
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if cmp, err := rounded.Cmp(left); err != nil {
+		return money.Money{}, err
+	} else if cmp > 0 {
+		return left, nil
+	}
+	return rounded, nil
+}
+
+type subtotalStage struct{}
+
+// NewSubtotalStage sums the line prices.
+func NewSubtotalStage() Stage {
+	return subtotalStage{}
+}
+
+func (subtotalStage) Name() string {
+	return "SUBTOTAL"
+}
+
+func (subtotalStage) Apply(_ context.Context, breakdown *Breakdown) error {
+	subtotal := money.Zero(breakdown.Currency)
+	for _, line := range breakdown.Lines {
+		var err error
+		if subtotal, err = subtotal.Add(line.Price); err != nil {
+			return err
+		}
+	}
+	breakdown.Subtotal = subtotal
+	return nil
+}
+
+type discountStage struct{}
+
+// NewDiscountStage sums what the promotions took off the lines, rounded to the unit the payment gateway charges. A
+// line cannot be discounted below zero.
+func NewDiscountStage() Stage {
+	return discountStage{}
+}
+
+func (discountStage) Name() string {
+	return "DISCOUNT"
+}
+
+func (discountStage) Apply(_ context.Context, breakdown *Breakdown) error {
+	discount := money.Zero(breakdown.Currency)
+	for _, line := range breakdown.Lines {
+		net, err := line.Net()
+		if err != nil {
+			return err
+		}
+
+		if net.IsNegative() || line.Discount.IsNegative() {
+			return fmt.Errorf("%w: discount %s on a line of %s", money.ErrInvalidAmount, line.Discount, line.Price)
+		}
+
+		if line.Discount, err = RoundDiscount(line.Discount, line.Price); err != nil {
+			return err
+		}
+
+		if discount, err = discount.Add(line.Discount); err != nil {
+			return err
+		}
+	}
+	breakdown.Discount = discount
+	return nil
+}
+
+type taxStage struct {
+	rules TaxRules
+}
+
+// NewTaxStage charges the tax rate of the category of every line on the line price after its discounts, rounded to
+// the unit the payment gateway charges.
+func NewTaxStage(rules TaxRules) Stage {
+	return &taxStage{rules: rules}
+}
+
+func (s *taxStage) Name() string {
+	return "TAX"
+}
+
+func (s *taxStage) Apply(_ context.Context, breakdown *Breakdown) error {
+	tax := money.Zero(breakdown.Currency)
+	for _, line := range breakdown.Lines {
+		net, err := line.Net()
+		if err != nil {
+			return err
+		}
+
+		if line.Tax, err = net.ProratePayable(s.rules.Rate(line.Category), basisPointsWhole); err != nil {
+			return err
+		}
+
+		if tax, err = tax.Add(line.Tax); err != nil {
+			return err
+		}
+	}
+	breakdown.Tax = tax
+	return nil
+}
+
+type serviceFeeStage struct {
+	rule FeeRule
+}
+
+// NewServiceFeeStage charges the service fee of every checkout.
+func NewServiceFeeStage(rule FeeRule) Stage {
+	return &serviceFeeStage{rule: rule}
+}
+
+func (s *serviceFeeStage) Name() string {
+	return "SERVICE_FEE"
+}
+
+func (s *serviceFeeStage) Apply(_ context.Context, breakdown *Breakdown) error {
+	fee, err := s.rule.fee(breakdown)
+	if err != nil {
+		return err
+	}
+	breakdown.ServiceFee = fee
+	return nil
+}
+
+type paymentFeeStage struct {
+	rules map[enum.PaymentProvider]FeeRule
+}
+
+// NewPaymentFeeStage charges the fee of the payment provider of the checkout, on the total including the service fee.
+func NewPaymentFeeStage(rules map[enum.PaymentProvider]FeeRule) Stage {
+	return &paymentFeeStage{rules: rules}
+}
+
+func (s *paymentFeeStage) Name() string {
+	return "PAYMENT_FEE"
+}
+
+func (s *paymentFeeStage) Apply(_ context.Context, breakdown *Breakdown) error {
+	fee, err := s.rules[breakdown.PaymentProvider].fee(breakdown)
+	if err != nil {
+		return err
+	}
+	breakdown.PaymentFee = fee
+	return nil
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"strings"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+type CartRepository interface {
+	Insert(ctx context.Context, db store.Querier, cart *entity.Cart) (*entity.Cart, error)
+	// FindOrInsertByUserID returns the cart of the user, creating it on first use
+	FindOrInsertByUserID(ctx context.Context, db store.Querier, userID uuid.UUID) (*entity.Cart, error)
+	FindByID(ctx context.Context, db store.Querier, id uuid.UUID, forUpdate bool) (*entity.Cart, error)
+	FindByUserID(ctx context.Context, db store.Querier, userID uuid.UUID, forUpdate bool) (*entity.Cart, error)
+	FindItemsByCartID(ctx context.Context, db store.Querier, cartID uuid.UUID) ([]*entity.CartItem, error)
+	// AddItems adds the quantities to the items already in the cart
+	AddItems(ctx context.Context, db store.Querier, cartID uuid.UUID, items []*entity.CartItem) error
+	SetItemQuantity(ctx context.Context, db store.Querier, item *entity.CartItem) error
+	DeleteItem(ctx context.Context, db store.Querier, cartID uuid.UUID, productID uuid.UUID, variantID uuid.NullUUID) (bool, error)
+	// DeleteItems empties the cart and returns what was in it
+	DeleteItems(ctx context.Context, db store.Querier, cartID uuid.UUID) ([]*entity.CartItem, error)
+	Touch(ctx context.Context, db store.Querier, id uuid.UUID) error
+	Delete(ctx context.Context, db store.Querier, id uuid.UUID) error
+	DeleteGuestCartsUpdatedBefore(ctx context.Context, db store.Querier, before time.Time) (int64, error)
+}
+
+type cartRepository struct {
+}
+
+func NewCartRepository() CartRepository {
+	return &cartRepository{}
+}
+
+func (r *cartRepository) Insert(ctx context.Context, db store.Querier, cart *entity.Cart) (*entity.Cart, error) {
+	query := `
+	INSERT INTO carts
+		(id, user_id)
+	VALUES
+		($1, $2)
+	RETURNING
+		created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, cart, query, cart.ID, cart.UserID); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func (r *cartRepository) FindOrInsertByUserID(ctx context.Context, db store.Querier, userID uuid.UUID) (*entity.Cart, error) {
+	// the no-op update makes RETURNING give back the existing cart as well
+	query := `
+	INSERT INTO carts
+		(user_id)
+	VALUES
+		($1)
+	ON CONFLICT (user_id) WHERE user_id IS NOT NULL DO UPDATE SET
+		user_id = EXCLUDED.user_id
+	RETURNING
+		id, user_id, created_at, updated_at
+	`
+	cart := new(entity.Cart)
+	if err := pgxscan.Get(ctx, db, cart, query, userID); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func (r *cartRepository) FindByID(ctx context.Context, db store.Querier, id uuid.UUID, forUpdate bool) (*entity.Cart, error) {
+	query := `
+	SELECT
+		id, user_id, created_at, updated_at
+	FROM
+		carts
+	WHERE
+		id = $1
+	`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	cart := new(entity.Cart)
+	if err := pgxscan.Get(ctx, db, cart, query, id); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func (r *cartRepository) FindByUserID(ctx context.Context, db store.Querier, userID uuid.UUID, forUpdate bool) (*entity.Cart, error) {
+	query := `
+	SELECT
+		id, user_id, created_at, updated_at
+	FROM
+		carts
+	WHERE
+		user_id = $1
+	`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	cart := new(entity.Cart)
+	if err := pgxscan.Get(ctx, db, cart, query, userID); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+func (r *cartRepository) FindItemsByCartID(ctx context.Context, db store.Querier, cartID uuid.UUID) ([]*entity.CartItem, error) {
+	query := `
+	SELECT
+		cart_id, product_id, variant_id, quantity, created_at, updated_at
+	FROM
+		cart_items
+	WHERE
+		cart_id = $1
+	ORDER BY
+		created_at, product_id, variant_id
+	`
+	var items []*entity.CartItem
+	if err := pgxscan.Select(ctx, db, &items, query, cartID); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *cartRepository) AddItems(ctx context.Context, db store.Querier, cartID uuid.UUID, items []*entity.CartItem) error {
+	query := `
+	INSERT INTO
+		cart_items
+		(cart_id, product_id, variant_id, quantity)
+	VALUES `
+
+	var args []interface{}
+	var valueStrings []string
+	argPos := 1
+
+	for _, item := range items {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", argPos, argPos+1, argPos+2, argPos+3))
+		args = append(args, cartID, item.ProductID, item.VariantID, item.Quantity)
+		argPos += 4
+	}
+
+	query += strings.Join(valueStrings, ",")
+	query += `
+	ON CONFLICT (cart_id, product_id, variant_id) DO UPDATE SET
+		quantity = cart_items.quantity + EXCLUDED.quantity,
+		updated_at = current_timestamp
+	`
+
+	_, err := db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *cartRepository) SetItemQuantity(ctx context.Context, db store.Querier, item *entity.CartItem) error {
+	query := `
+	INSERT INTO
+		cart_items
+		(cart_id, product_id, variant_id, quantity)
+	VALUES
+		($1, $2, $3, $4)
+	ON CONFLICT (cart_id, product_id, variant_id) DO UPDATE SET
+		quantity = EXCLUDED.quantity,
+		updated_at = current_timestamp
+	`
+	_, err := db.Exec(ctx, query, item.CartID, item.ProductID, item.VariantID, item.Quantity)
+	return err
+}
+
+func (r *cartRepository) DeleteItem(ctx context.Context, db store.Querier, cartID uuid.UUID, productID uuid.UUID,
+	variantID uuid.NullUUID) (bool, error) {
+	query := `
+	DELETE FROM
+		cart_items
+	WHERE
+		cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3
+	`
+	tag, err := db.Exec(ctx, query, cartID, productID, variantID)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *cartRepository) DeleteItems(ctx context.Context, db store.Querier, cartID uuid.UUID) ([]*entity.CartItem, error) {
+	query := `
+	DELETE FROM
+		cart_items
+	WHERE
+		cart_id = $1
+	RETURNING
+		cart_id, product_id, variant_id, quantity, created_at, updated_at
+	`
+	var items []*entity.CartItem
+	if err := pgxscan.Select(ctx, db, &items, query, cartID); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *cartRepository) Touch(ctx context.Context, db store.Querier, id uuid.UUID) error {
+	query := `
+	UPDATE
+		carts
+	SET
+		updated_at = current_timestamp
+	WHERE
+		id = $1
+	`
+	_, err := db.Exec(ctx, query, id)
+	return err
+}
+
+func (r *cartRepository) Delete(ctx context.Context, db store.Querier, id uuid.UUID) error {
+	query := `
+	DELETE FROM
+		carts
+	WHERE
+		id = $1
+	`
+	_, err := db.Exec(ctx, query, id)
+	return err
+}
+
+func (r *cartRepository) DeleteGuestCartsUpdatedBefore(ctx context.Context, db store.Querier, before time.Time) (int64, error) {
+	query := `
+	DELETE FROM
+		carts
+	WHERE
+		user_id IS NULL AND updated_at < $1
+	`
+	tag, err := db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
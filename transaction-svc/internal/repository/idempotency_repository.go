@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+type IdempotencyRepository interface {
+	Acquire(ctx context.Context, db store.Querier, idempotencyKey *entity.IdempotencyKey, now time.Time) (bool, error)
+	FindByKey(ctx context.Context, db store.Querier, userID uuid.UUID, key string) (*entity.IdempotencyKey, error)
+	Complete(ctx context.Context, db store.Querier, idempotencyKey *entity.IdempotencyKey) error
+	Delete(ctx context.Context, db store.Querier, userID uuid.UUID, key string) error
+	DeleteExpired(ctx context.Context, db store.Querier, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+}
+
+func NewIdempotencyRepository() IdempotencyRepository {
+	return &idempotencyRepository{}
+}
+
+// Acquire claims the key for a new request. An expired key, or a PROCESSING key of the same request whose lock ran
+// out, is taken over; any other existing key is left untouched and false is returned.
+func (r *idempotencyRepository) Acquire(ctx context.Context, db store.Querier, idempotencyKey *entity.IdempotencyKey, now time.Time) (bool, error) {
+	query := `
+	INSERT INTO idempotency_keys
+		(user_id, idempotency_key, request_hash, status, locked_until, created_at, expires_at)
+	VALUES
+		($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (user_id, idempotency_key) DO UPDATE SET
+		request_hash = EXCLUDED.request_hash,
+		status = EXCLUDED.status,
+		response_status = NULL,
+		response_body = NULL,
+		locked_until = EXCLUDED.locked_until,
+		created_at = EXCLUDED.created_at,
+		completed_at = NULL,
+		expires_at = EXCLUDED.expires_at
+	WHERE
+		idempotency_keys.expires_at < $6
+		OR (idempotency_keys.status = $4 AND idempotency_keys.locked_until < $6 AND idempotency_keys.request_hash = EXCLUDED.request_hash)
+	`
+	tag, err := db.Exec(ctx, query, idempotencyKey.UserID, idempotencyKey.IdempotencyKey, idempotencyKey.RequestHash,
+		enum.IdempotencyStatusProcessing, idempotencyKey.LockedUntil, now, idempotencyKey.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *idempotencyRepository) FindByKey(ctx context.Context, db store.Querier, userID uuid.UUID, key string) (*entity.IdempotencyKey, error) {
+	query := `
+	SELECT
+		user_id, idempotency_key, request_hash, status, response_status, response_body, locked_until, created_at,
+		completed_at, expires_at
+	FROM
+		idempotency_keys
+	WHERE
+		user_id = $1 AND idempotency_key = $2
+	`
+	idempotencyKey := new(entity.IdempotencyKey)
+	if err := pgxscan.Get(ctx, db, idempotencyKey, query, userID, key); err != nil {
+		return nil, err
+	}
+
+	return idempotencyKey, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, db store.Querier, idempotencyKey *entity.IdempotencyKey) error {
+	query := `
+	UPDATE
+		idempotency_keys
+	SET
+		status = $1,
+		response_status = $2,
+		response_body = $3,
+		locked_until = NULL,
+		completed_at = $4
+	WHERE
+		user_id = $5 AND idempotency_key = $6 AND status = $7
+	`
+	_, err := db.Exec(ctx, query, enum.IdempotencyStatusCompleted, idempotencyKey.ResponseStatus, idempotencyKey.ResponseBody,
+		idempotencyKey.CompletedAt, idempotencyKey.UserID, idempotencyKey.IdempotencyKey, enum.IdempotencyStatusProcessing)
+	return err
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, db store.Querier, userID uuid.UUID, key string) error {
+	query := `
+	DELETE FROM
+		idempotency_keys
+	WHERE
+		user_id = $1 AND idempotency_key = $2 AND status = $3
+	`
+	_, err := db.Exec(ctx, query, userID, key, enum.IdempotencyStatusProcessing)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, db store.Querier, now time.Time) (int64, error) {
+	query := `
+	DELETE FROM
+		idempotency_keys
+	WHERE
+		expires_at < $1
+	`
+	tag, err := db.Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+type OutboxRepository interface {
+	Insert(ctx context.Context, db store.Querier, event *entity.OutboxEvent) (*entity.OutboxEvent, error)
+	FindManyPending(ctx context.Context, tx store.Querier, limit int) ([]*entity.OutboxEvent, error)
+	MarkSent(ctx context.Context, tx store.Querier, id uuid.UUID) error
+	MarkRetry(ctx context.Context, tx store.Querier, id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, tx store.Querier, id uuid.UUID, attempts int, lastError string) error
+	FindLag(ctx context.Context, db store.Querier) (*entity.OutboxLag, error)
+}
+
+type outboxRepository struct {
+}
+
+func NewOutboxRepository() OutboxRepository {
+	return &outboxRepository{}
+}
+
+func (r *outboxRepository) Insert(ctx context.Context, db store.Querier, event *entity.OutboxEvent) (*entity.OutboxEvent, error) {
+	query := `
+	INSERT INTO outbox_events
+		(aggregate_id, subject, payload)
+	VALUES
+		($1, $2, $3)
+	RETURNING
+		id, status, attempts, next_attempt_at, created_at
+	`
+	if err := pgxscan.Get(ctx, db, event, query, event.AggregateID, event.Subject, event.Payload); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// FindManyPending locks a batch of due events so that several relay instances never publish the same row concurrently.
+// An event is left out while an earlier event of its aggregate is still pending, so the events of an aggregate are
+// published in the order they were written even when one of them is rescheduled or locked by another relay.
+func (r *outboxRepository) FindManyPending(ctx context.Context, tx store.Querier, limit int) ([]*entity.OutboxEvent, error) {
+	events := make([]*entity.OutboxEvent, 0)
+	query := `
+	SELECT
+		id, aggregate_id, subject, payload, status, attempts, last_error, next_attempt_at, created_at, sent_at
+	FROM
+		outbox_events AS pending
+	WHERE
+		pending.status = $1 AND pending.next_attempt_at <= now()
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			outbox_events AS earlier
+		WHERE
+			earlier.aggregate_id = pending.aggregate_id AND earlier.status = $1 AND earlier.sequence < pending.sequence
+	)
+	ORDER BY
+		pending.sequence ASC
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+	`
+	if err := pgxscan.Select(ctx, tx, &events, query, enum.OutboxStatusPending, limit); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepository) MarkSent(ctx context.Context, tx store.Querier, id uuid.UUID) error {
+	query := `UPDATE outbox_events SET status = $1, attempts = attempts + 1, last_error = NULL, sent_at = now() WHERE id = $2`
+
+	row, err := tx.Exec(ctx, query, enum.OutboxStatusSent, id)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for outbox event ID: %s", id)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkRetry(ctx context.Context, tx store.Querier, id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	query := `UPDATE outbox_events SET attempts = $1, next_attempt_at = $2, last_error = $3 WHERE id = $4`
+
+	row, err := tx.Exec(ctx, query, attempts, nextAttemptAt, lastError, id)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for outbox event ID: %s", id)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, tx store.Querier, id uuid.UUID, attempts int, lastError string) error {
+	query := `UPDATE outbox_events SET status = $1, attempts = $2, last_error = $3 WHERE id = $4`
+
+	row, err := tx.Exec(ctx, query, enum.OutboxStatusFailed, attempts, lastError, id)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for outbox event ID: %s", id)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) FindLag(ctx context.Context, db store.Querier) (*entity.OutboxLag, error) {
+	query := `
+	SELECT
+		COUNT(*) FILTER (WHERE status = $1) AS pending_count,
+		COUNT(*) FILTER (WHERE status = $2) AS failed_count,
+		MIN(created_at) FILTER (WHERE status = $1) AS oldest_pending_at
+	FROM
+		outbox_events
+	WHERE
+		status IN ($1, $2)
+	`
+	lag := new(entity.OutboxLag)
+	if err := pgxscan.Get(ctx, db, lag, query, enum.OutboxStatusPending, enum.OutboxStatusFailed); err != nil {
+		return nil, err
+	}
+
+	return lag, nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+type PromotionRepository interface {
+	Insert(ctx context.Context, db store.Querier, promotion *entity.Promotion) (*entity.Promotion, error)
+	FindByID(ctx context.Context, db store.Querier, id uuid.UUID) (*entity.Promotion, error)
+	FindByCode(ctx context.Context, db store.Querier, code string, forUpdate bool) (*entity.Promotion, error)
+	FindMany(ctx context.Context, db store.Querier, request *model.SearchPromotionsRequest) ([]*entity.PromotionWithTotal, *web.PageMetadata, error)
+	Deactivate(ctx context.Context, db store.Querier, id uuid.UUID) (*entity.Promotion, error)
+	// InsertUsage reserves the promotion for the transaction, false means an earlier attempt already reserved it
+	InsertUsage(ctx context.Context, db store.Querier, usage *entity.PromotionUsage) (bool, error)
+	// CountUserUsages counts the usages of the user that were not released
+	CountUserUsages(ctx context.Context, db store.Querier, promotionID, userID uuid.UUID) (int64, error)
+	// IncrementUsageCount counts one more usage, false means the usage limit is reached
+	IncrementUsageCount(ctx context.Context, db store.Querier, id uuid.UUID) (bool, error)
+	// ReleaseUsages releases the reserved usages of the transaction and gives them back to the usage limits
+	ReleaseUsages(ctx context.Context, db store.Querier, transactionID uuid.UUID) (int64, error)
+	RedeemUsages(ctx context.Context, db store.Querier, transactionID uuid.UUID) error
+}
+
+type promotionRepository struct {
+}
+
+func NewPromotionRepository() PromotionRepository {
+	return &promotionRepository{}
+}
+
+const promotionColumns = `
+		id, code, name, type, scope, scope_id, percentage_basis_points, amount, currency, buy_quantity, get_quantity,
+		usage_limit, usage_limit_per_user, usage_count, starts_at, ends_at, is_active, created_at, updated_at`
+
+func (r *promotionRepository) Insert(ctx context.Context, db store.Querier, promotion *entity.Promotion) (*entity.Promotion, error) {
+	query := `
+	INSERT INTO promotions
+		(code, name, type, scope, scope_id, percentage_basis_points, amount, currency, buy_quantity, get_quantity,
+		usage_limit, usage_limit_per_user, starts_at, ends_at)
+	VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	RETURNING
+		id, usage_count, is_active, created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, promotion, query, promotion.Code, promotion.Name, promotion.Type, promotion.Scope,
+		promotion.ScopeID, promotion.PercentageBasisPoints, promotion.Amount, promotion.Currency, promotion.BuyQuantity,
+		promotion.GetQuantity, promotion.UsageLimit, promotion.UsageLimitPerUser, promotion.StartsAt, promotion.EndsAt); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+func (r *promotionRepository) FindByID(ctx context.Context, db store.Querier, id uuid.UUID) (*entity.Promotion, error) {
+	query := `
+	SELECT` + promotionColumns + `
+	FROM
+		promotions
+	WHERE
+		id = $1
+	`
+	promotion := new(entity.Promotion)
+	if err := pgxscan.Get(ctx, db, promotion, query, id); err != nil {
+		return nil, err
+	}
+	promotion.ApplyCurrency()
+	return promotion, nil
+}
+
+func (r *promotionRepository) FindByCode(ctx context.Context, db store.Querier, code string, forUpdate bool) (*entity.Promotion, error) {
+	query := `
+	SELECT` + promotionColumns + `
+	FROM
+		promotions
+	WHERE
+		code = $1
+	`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	promotion := new(entity.Promotion)
+	if err := pgxscan.Get(ctx, db, promotion, query, code); err != nil {
+		return nil, err
+	}
+	promotion.ApplyCurrency()
+	return promotion, nil
+}
+
+func (r *promotionRepository) FindMany(ctx context.Context, db store.Querier, request *model.SearchPromotionsRequest) ([]*entity.PromotionWithTotal, *web.PageMetadata, error) {
+	promotions := make([]*entity.PromotionWithTotal, 0)
+	query := `
+	SELECT` + promotionColumns + `,
+		COUNT (*) OVER () AS total
+	FROM
+		promotions
+	WHERE
+		($1 = FALSE OR is_active)
+	ORDER BY
+		created_at DESC, id
+	LIMIT $2
+	OFFSET $3
+	`
+	if err := pgxscan.Select(ctx, db, &promotions, query, request.ActiveOnly, request.Limit, (request.Page-1)*request.Limit); err != nil {
+		return nil, nil, err
+	}
+
+	if len(promotions) == 0 {
+		return nil, &web.PageMetadata{}, nil
+	}
+
+	for _, promotion := range promotions {
+		promotion.ApplyCurrency()
+	}
+
+	pageMetadata := helper.CalculatePagination(int64(promotions[0].Total), request.Page, request.Limit)
+	return promotions, pageMetadata, nil
+}
+
+func (r *promotionRepository) Deactivate(ctx context.Context, db store.Querier, id uuid.UUID) (*entity.Promotion, error) {
+	query := `
+	UPDATE promotions
+	SET
+		is_active = FALSE,
+		updated_at = NOW()
+	WHERE
+		id = $1
+	RETURNING` + promotionColumns
+	promotion := new(entity.Promotion)
+	if err := pgxscan.Get(ctx, db, promotion, query, id); err != nil {
+		return nil, err
+	}
+	promotion.ApplyCurrency()
+	return promotion, nil
+}
+
+func (r *promotionRepository) InsertUsage(ctx context.Context, db store.Querier, usage *entity.PromotionUsage) (bool, error) {
+	query := `
+	INSERT INTO promotion_usages
+		(promotion_id, transaction_id, user_id, status)
+	VALUES
+		($1, $2, $3, $4)
+	ON CONFLICT (promotion_id, transaction_id) DO NOTHING
+	`
+	row, err := db.Exec(ctx, query, usage.PromotionID, usage.TransactionID, usage.UserID, usage.Status)
+	if err != nil {
+		return false, err
+	}
+	return row.RowsAffected() > 0, nil
+}
+
+func (r *promotionRepository) CountUserUsages(ctx context.Context, db store.Querier, promotionID, userID uuid.UUID) (int64, error) {
+	query := `
+	SELECT
+		COUNT(*)
+	FROM
+		promotion_usages
+	WHERE
+		promotion_id = $1 AND user_id = $2 AND status <> 'RELEASED'
+	`
+	var count int64
+	if err := db.QueryRow(ctx, query, promotionID, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *promotionRepository) IncrementUsageCount(ctx context.Context, db store.Querier, id uuid.UUID) (bool, error) {
+	query := `
+	UPDATE promotions
+	SET
+		usage_count = usage_count + 1,
+		updated_at = NOW()
+	WHERE
+		id = $1 AND (usage_limit IS NULL OR usage_count < usage_limit)
+	`
+	row, err := db.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return row.RowsAffected() > 0, nil
+}
+
+func (r *promotionRepository) ReleaseUsages(ctx context.Context, db store.Querier, transactionID uuid.UUID) (int64, error) {
+	// a transaction holds at most one usage per promotion, so every released usage gives back exactly one count
+	query := `
+	WITH released AS (
+		UPDATE promotion_usages
+		SET
+			status = 'RELEASED',
+			updated_at = NOW()
+		WHERE
+			transaction_id = $1 AND status = 'RESERVED'
+		RETURNING
+			promotion_id
+	)
+	UPDATE promotions
+	SET
+		usage_count = usage_count - 1,
+		updated_at = NOW()
+	WHERE
+		id IN (SELECT promotion_id FROM released)
+	`
+	row, err := db.Exec(ctx, query, transactionID)
+	if err != nil {
+		return 0, err
+	}
+	return row.RowsAffected(), nil
+}
+
+func (r *promotionRepository) RedeemUsages(ctx context.Context, db store.Querier, transactionID uuid.UUID) error {
+	query := `
+	UPDATE promotion_usages
+	SET
+		status = 'REDEEMED',
+		updated_at = NOW()
+	WHERE
+		transaction_id = $1 AND status = 'RESERVED'
+	`
+	_, err := db.Exec(ctx, query, transactionID)
+	return err
+}
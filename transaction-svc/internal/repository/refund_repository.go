@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"strings"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type RefundRepository interface {
+	Insert(ctx context.Context, db store.Querier, refund *entity.Refund) (*entity.Refund, error)
+	InsertItems(ctx context.Context, db store.Querier, refundItems []*entity.RefundItem) error
+	FindByID(ctx context.Context, db store.Querier, id uuid.UUID, forUpdate bool) (*entity.Refund, error)
+	FindManyByTransactionID(ctx context.Context, db store.Querier, transactionID uuid.UUID) ([]*entity.Refund, error)
+	FindItemsByRefundIDs(ctx context.Context, db store.Querier, refundIDs []uuid.UUID) ([]*entity.RefundItem, error)
+	UpdateProviderRefundID(ctx context.Context, db store.Querier, id uuid.UUID, providerRefundID string) error
+	UpdateStatus(ctx context.Context, db store.Querier, refund *entity.Refund, from enum.RefundStatus) error
+}
+
+type refundRepository struct {
+}
+
+func NewRefundRepository() RefundRepository {
+	return &refundRepository{}
+}
+
+func (r *refundRepository) Insert(ctx context.Context, db store.Querier, refund *entity.Refund) (*entity.Refund, error) {
+	query := `
+	INSERT INTO refunds
+		(id, transaction_id, initiator, requested_by, status, previous_status, amount, currency, reason, payment_provider)
+	VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING
+		created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, refund, query, refund.ID, refund.TransactionID, refund.Initiator, refund.RequestedBy,
+		refund.Status, refund.PreviousStatus, refund.Amount, refund.Currency, refund.Reason, refund.PaymentProvider); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (r *refundRepository) InsertItems(ctx context.Context, db store.Querier, refundItems []*entity.RefundItem) error {
+	query := `
+	INSERT INTO
+		refund_items
+		(refund_id, transaction_detail_id, product_id, quantity, amount)
+	VALUES `
+
+	var args []interface{}
+	var valueStrings []string
+	argPos := 1
+
+	for _, item := range refundItems {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)",
+			argPos, argPos+1, argPos+2, argPos+3, argPos+4))
+
+		args = append(args, item.RefundID, item.TransactionDetailID, item.ProductID, item.Quantity, item.Amount)
+		argPos += 5
+	}
+
+	query += strings.Join(valueStrings, ",")
+
+	_, err := db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *refundRepository) FindByID(ctx context.Context, db store.Querier, id uuid.UUID, forUpdate bool) (*entity.Refund, error) {
+	query := `
+	SELECT
+		id, transaction_id, initiator, requested_by, status, previous_status, amount, currency, reason, payment_provider,
+		provider_refund_id, failure_reason, created_at, completed_at, updated_at
+	FROM
+		refunds
+	WHERE
+		id = $1
+	`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	refund := new(entity.Refund)
+	if err := pgxscan.Get(ctx, db, refund, query, id); err != nil {
+		return nil, err
+	}
+	refund.ApplyCurrency()
+
+	return refund, nil
+}
+
+func (r *refundRepository) FindManyByTransactionID(ctx context.Context, db store.Querier, transactionID uuid.UUID) ([]*entity.Refund, error) {
+	query := `
+	SELECT
+		id, transaction_id, initiator, requested_by, status, previous_status, amount, currency, reason, payment_provider,
+		provider_refund_id, failure_reason, created_at, completed_at, updated_at
+	FROM
+		refunds
+	WHERE
+		transaction_id = $1
+	ORDER BY
+		created_at DESC
+	`
+	refunds := make([]*entity.Refund, 0)
+	if err := pgxscan.Select(ctx, db, &refunds, query, transactionID); err != nil {
+		return nil, err
+	}
+
+	for _, refund := range refunds {
+		refund.ApplyCurrency()
+	}
+
+	return refunds, nil
+}
+
+func (r *refundRepository) FindItemsByRefundIDs(ctx context.Context, db store.Querier, refundIDs []uuid.UUID) ([]*entity.RefundItem, error) {
+	query := `
+	SELECT
+		ri.refund_id, ri.transaction_detail_id, ri.product_id, td.variant_id, ri.quantity, ri.amount, r.currency
+	FROM
+		refund_items AS ri
+	JOIN
+		refunds AS r
+	ON
+		r.id = ri.refund_id
+	JOIN
+		transaction_details AS td
+	ON
+		td.id = ri.transaction_detail_id
+	WHERE
+		ri.refund_id = ANY($1)
+	`
+	refundItems := make([]*entity.RefundItem, 0)
+	if err := pgxscan.Select(ctx, db, &refundItems, query, pq.Array(refundIDs)); err != nil {
+		return nil, err
+	}
+
+	for _, refundItem := range refundItems {
+		refundItem.ApplyCurrency()
+	}
+
+	return refundItems, nil
+}
+
+func (r *refundRepository) UpdateProviderRefundID(ctx context.Context, db store.Querier, id uuid.UUID, providerRefundID string) error {
+	query := `UPDATE refunds SET provider_refund_id = $1, updated_at = now() WHERE id = $2`
+
+	row, err := db.Exec(ctx, query, providerRefundID, id)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for refund ID: %s", id)
+	}
+
+	return nil
+}
+
+// UpdateStatus moves a refund out of its from status, so a refund is only completed or failed once.
+func (r *refundRepository) UpdateStatus(ctx context.Context, db store.Querier, refund *entity.Refund, from enum.RefundStatus) error {
+	query := `
+	UPDATE refunds
+	SET
+		status = $1,
+		failure_reason = $2,
+		completed_at = $3,
+		updated_at = now()
+	WHERE
+		id = $4 AND status = $5
+	`
+	row, err := db.Exec(ctx, query, refund.Status, refund.FailureReason, refund.CompletedAt, refund.ID, from)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for refund ID: %s", refund.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+type SagaRepository interface {
+	InsertInstance(ctx context.Context, db store.Querier, instance *entity.SagaInstance) (*entity.SagaInstance, error)
+	InsertStep(ctx context.Context, db store.Querier, step *entity.SagaStep) (*entity.SagaStep, error)
+	FindInstanceByID(ctx context.Context, db store.Querier, id uuid.UUID) (*entity.SagaInstance, error)
+	FindInstanceByAggregateID(ctx context.Context, db store.Querier, sagaType string, aggregateID uuid.UUID) (*entity.SagaInstance, error)
+	FindStepsByInstanceID(ctx context.Context, db store.Querier, instanceID uuid.UUID) ([]*entity.SagaStep, error)
+	FindManyResumable(ctx context.Context, db store.Querier, limit int) ([]*entity.SagaInstance, error)
+	AcquireLease(ctx context.Context, db store.Querier, id uuid.UUID, leaseDuration time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, db store.Querier, id uuid.UUID) error
+	UpdateInstance(ctx context.Context, db store.Querier, instance *entity.SagaInstance) error
+	UpdateStep(ctx context.Context, db store.Querier, step *entity.SagaStep) error
+	InsertSignal(ctx context.Context, db store.Querier, signal *entity.SagaSignal) error
+	TakeSignals(ctx context.Context, db store.Querier, instanceID uuid.UUID) ([]*entity.SagaSignal, error)
+}
+
+type sagaRepository struct {
+}
+
+func NewSagaRepository() SagaRepository {
+	return &sagaRepository{}
+}
+
+func (r *sagaRepository) InsertInstance(ctx context.Context, db store.Querier, instance *entity.SagaInstance) (*entity.SagaInstance, error) {
+	query := `
+	INSERT INTO saga_instances
+		(saga_type, aggregate_id, status, current_step, payload)
+	VALUES
+		($1, $2, $3, $4, $5)
+	RETURNING
+		id, created_at, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, instance, query, instance.SagaType, instance.AggregateID, instance.Status,
+		instance.CurrentStep, instance.Payload); err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (r *sagaRepository) InsertStep(ctx context.Context, db store.Querier, step *entity.SagaStep) (*entity.SagaStep, error) {
+	query := `
+	INSERT INTO saga_steps
+		(saga_instance_id, step_name, step_order, status)
+	VALUES
+		($1, $2, $3, $4)
+	RETURNING
+		id, attempts, updated_at
+	`
+	if err := pgxscan.Get(ctx, db, step, query, step.SagaInstanceID, step.StepName, step.StepOrder, step.Status); err != nil {
+		return nil, err
+	}
+
+	return step, nil
+}
+
+func (r *sagaRepository) FindInstanceByID(ctx context.Context, db store.Querier, id uuid.UUID) (*entity.SagaInstance, error) {
+	query := `
+	SELECT
+		id, saga_type, aggregate_id, status, current_step, payload, last_error, next_run_at, created_at, updated_at
+	FROM
+		saga_instances
+	WHERE
+		id = $1
+	`
+	instance := new(entity.SagaInstance)
+	if err := pgxscan.Get(ctx, db, instance, query, id); err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (r *sagaRepository) FindInstanceByAggregateID(ctx context.Context, db store.Querier, sagaType string, aggregateID uuid.UUID) (*entity.SagaInstance, error) {
+	query := `
+	SELECT
+		id, saga_type, aggregate_id, status, current_step, payload, last_error, next_run_at, created_at, updated_at
+	FROM
+		saga_instances
+	WHERE
+		saga_type = $1 AND aggregate_id = $2
+	`
+	instance := new(entity.SagaInstance)
+	if err := pgxscan.Get(ctx, db, instance, query, sagaType, aggregateID); err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (r *sagaRepository) FindStepsByInstanceID(ctx context.Context, db store.Querier, instanceID uuid.UUID) ([]*entity.SagaStep, error) {
+	steps := make([]*entity.SagaStep, 0)
+	query := `
+	SELECT
+		id, saga_instance_id, step_name, step_order, status, attempts, last_error, next_retry_at,
+		started_at, completed_at, compensated_at, updated_at
+	FROM
+		saga_steps
+	WHERE
+		saga_instance_id = $1
+	ORDER BY
+		step_order ASC
+	`
+	if err := pgxscan.Select(ctx, db, &steps, query, instanceID); err != nil {
+		return nil, err
+	}
+
+	return steps, nil
+}
+
+// FindManyResumable returns sagas that still have work to do or a signal to apply and are not leased by another
+// process, this includes sagas whose previous owner crashed while it was holding the lease.
+func (r *sagaRepository) FindManyResumable(ctx context.Context, db store.Querier, limit int) ([]*entity.SagaInstance, error) {
+	instances := make([]*entity.SagaInstance, 0)
+	query := `
+	SELECT
+		id, saga_type, aggregate_id, status, current_step, payload, last_error, next_run_at, created_at, updated_at
+	FROM
+		saga_instances
+	WHERE
+		(locked_until IS NULL OR locked_until < now())
+		AND (
+			(status IN ($1, $2) AND (next_run_at IS NULL OR next_run_at <= now()))
+			OR (
+				status NOT IN ($3, $4)
+				AND EXISTS (SELECT 1 FROM saga_signals WHERE saga_signals.saga_instance_id = saga_instances.id)
+			)
+		)
+	ORDER BY
+		updated_at ASC
+	LIMIT $5
+	`
+	if err := pgxscan.Select(ctx, db, &instances, query, enum.SagaStatusRunning, enum.SagaStatusCompensating,
+		enum.SagaStatusCompleted, enum.SagaStatusCompensated, limit); err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+func (r *sagaRepository) AcquireLease(ctx context.Context, db store.Querier, id uuid.UUID, leaseDuration time.Duration) (bool, error) {
+	query := `
+	UPDATE saga_instances
+	SET
+		locked_until = now() + make_interval(secs => $1)
+	WHERE
+		id = $2 AND (locked_until IS NULL OR locked_until < now())
+	`
+	row, err := db.Exec(ctx, query, leaseDuration.Seconds(), id)
+	if err != nil {
+		return false, err
+	}
+
+	return row.RowsAffected() > 0, nil
+}
+
+func (r *sagaRepository) ReleaseLease(ctx context.Context, db store.Querier, id uuid.UUID) error {
+	query := `UPDATE saga_instances SET locked_until = NULL WHERE id = $1`
+
+	if _, err := db.Exec(ctx, query, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *sagaRepository) UpdateInstance(ctx context.Context, db store.Querier, instance *entity.SagaInstance) error {
+	query := `
+	UPDATE saga_instances
+	SET
+		status = $1,
+		current_step = $2,
+		payload = $3,
+		last_error = $4,
+		next_run_at = $5,
+		updated_at = now()
+	WHERE
+		id = $6
+	`
+	row, err := db.Exec(ctx, query, instance.Status, instance.CurrentStep, instance.Payload, instance.LastError,
+		instance.NextRunAt, instance.ID)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for saga instance ID: %s", instance.ID)
+	}
+
+	return nil
+}
+
+func (r *sagaRepository) UpdateStep(ctx context.Context, db store.Querier, step *entity.SagaStep) error {
+	query := `
+	UPDATE saga_steps
+	SET
+		status = $1,
+		attempts = $2,
+		last_error = $3,
+		next_retry_at = $4,
+		started_at = $5,
+		completed_at = $6,
+		compensated_at = $7,
+		updated_at = now()
+	WHERE
+		id = $8
+	`
+	row, err := db.Exec(ctx, query, step.Status, step.Attempts, step.LastError, step.NextRetryAt, step.StartedAt,
+		step.CompletedAt, step.CompensatedAt, step.ID)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for saga step ID: %s", step.ID)
+	}
+
+	return nil
+}
+
+func (r *sagaRepository) InsertSignal(ctx context.Context, db store.Querier, signal *entity.SagaSignal) error {
+	query := `
+	INSERT INTO saga_signals
+		(saga_instance_id, step_name, error)
+	VALUES
+		($1, $2, $3)
+	RETURNING
+		id, created_at
+	`
+	if err := pgxscan.Get(ctx, db, signal, query, signal.SagaInstanceID, signal.StepName, signal.Error); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TakeSignals deletes and returns the signals recorded for a saga, oldest first.
+func (r *sagaRepository) TakeSignals(ctx context.Context, db store.Querier, instanceID uuid.UUID) ([]*entity.SagaSignal, error) {
+	signals := make([]*entity.SagaSignal, 0)
+	query := `
+	WITH taken AS (
+		DELETE FROM saga_signals
+		WHERE
+			saga_instance_id = $1
+		RETURNING
+			id, saga_instance_id, step_name, error, created_at
+	)
+	SELECT
+		id, saga_instance_id, step_name, error, created_at
+	FROM
+		taken
+	ORDER BY
+		id ASC
+	`
+	if err := pgxscan.Select(ctx, db, &signals, query, instanceID); err != nil {
+		return nil, err
+	}
+
+	return signals, nil
+}
@@ -8,10 +8,14 @@ import (
 	"strings"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
 )
 
 type TransactionDetailRepository interface {
 	InsertMany(ctx context.Context, db store.Querier, transactionDetails []*entity.TransactionDetail) ([]*entity.TransactionDetail, error)
+	FindManyByTransactionID(ctx context.Context, db store.Querier, transactionID uuid.UUID, forUpdate bool) ([]*entity.TransactionDetail, error)
+	AddRefundedQuantity(ctx context.Context, db store.Querier, id uuid.UUID, quantity int) error
+	InsertDiscounts(ctx context.Context, db store.Querier, discounts []*entity.TransactionDetailDiscount) error
 	// FindByID(ctx context.Context, db store.Querier, id string) (*entity.TransactionDetail, error)
 	// FindByUserID(ctx context.Context, db store.Querier, userID string) ([]*entity.TransactionDetail, error)
 }
@@ -27,7 +31,7 @@ func (transactionDetailRepository) InsertMany(ctx context.Context, db store.Quer
 	query := `
 	INSERT INTO 
 		transaction_details
-		(transaction_id, product_id, quantity, price)
+		(transaction_id, product_id, variant_id, sku, quantity, price, discount, tax)
 	VALUES `
 
 	var args []interface{}
@@ -35,23 +39,109 @@ func (transactionDetailRepository) InsertMany(ctx context.Context, db store.Quer
 	argPos := 1
 
 	for _, td := range transactionDetails {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)",
-			argPos, argPos+1, argPos+2, argPos+3))
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			argPos, argPos+1, argPos+2, argPos+3, argPos+4, argPos+5, argPos+6, argPos+7))
 
-		args = append(args, td.TransactionID, td.ProductID, td.Quantity, td.Price)
-		argPos += 4
+		args = append(args, td.TransactionID, td.ProductID, td.VariantID, td.SKU, td.Quantity, td.Price, td.Discount, td.Tax)
+		argPos += 8
 	}
 
 	query += strings.Join(valueStrings, ",")
-	query += " RETURNING id, transaction_id, product_id, quantity, price, created_at"
+	query += ` RETURNING id, transaction_id, product_id, variant_id, sku, quantity, price, discount, tax, created_at,
+		(SELECT currency FROM transactions WHERE transactions.id = transaction_id) AS currency`
 
 	if err := pgxscan.Select(ctx, db, &transactionDetails, query, args...); err != nil {
 		return nil, err
 	}
 
+	for _, transactionDetail := range transactionDetails {
+		transactionDetail.ApplyCurrency()
+	}
+
 	return transactionDetails, nil
 }
 
+func (transactionDetailRepository) FindManyByTransactionID(ctx context.Context, db store.Querier, transactionID uuid.UUID, forUpdate bool) ([]*entity.TransactionDetail, error) {
+	query := `
+	SELECT
+		td.id, td.transaction_id, td.product_id, td.variant_id, td.sku, td.quantity, td.refunded_quantity, td.price, td.discount, td.tax, t.currency, td.created_at
+	FROM
+		transaction_details AS td
+	JOIN
+		transactions AS t
+	ON
+		t.id = td.transaction_id
+	WHERE
+		td.transaction_id = $1
+	ORDER BY
+		td.created_at, td.id
+	`
+	if forUpdate {
+		query += " FOR UPDATE OF td"
+	}
+
+	transactionDetails := make([]*entity.TransactionDetail, 0)
+	if err := pgxscan.Select(ctx, db, &transactionDetails, query, transactionID); err != nil {
+		return nil, err
+	}
+
+	for _, transactionDetail := range transactionDetails {
+		transactionDetail.ApplyCurrency()
+	}
+
+	return transactionDetails, nil
+}
+
+// AddRefundedQuantity adds quantity to refunded_quantity, a negative quantity gives it back when a refund failed.
+// It fails instead of refunding more items than the line holds.
+func (transactionDetailRepository) AddRefundedQuantity(ctx context.Context, db store.Querier, id uuid.UUID, quantity int) error {
+	query := `
+	UPDATE transaction_details
+	SET
+		refunded_quantity = refunded_quantity + $1
+	WHERE
+		id = $2 AND refunded_quantity + $1 BETWEEN 0 AND quantity
+	`
+	row, err := db.Exec(ctx, query, quantity, id)
+	if err != nil {
+		return err
+	}
+
+	if row.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected for transaction detail ID: %s", id)
+	}
+
+	return nil
+}
+
+func (transactionDetailRepository) InsertDiscounts(ctx context.Context, db store.Querier, discounts []*entity.TransactionDetailDiscount) error {
+	if len(discounts) == 0 {
+		return nil
+	}
+
+	query := `
+	INSERT INTO
+		transaction_detail_discounts
+		(transaction_detail_id, promotion_id, promotion_code, amount)
+	VALUES `
+
+	var args []interface{}
+	var valueStrings []string
+	argPos := 1
+
+	for _, discount := range discounts {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)",
+			argPos, argPos+1, argPos+2, argPos+3))
+
+		args = append(args, discount.TransactionDetailID, discount.PromotionID, discount.PromotionCode, discount.Amount)
+		argPos += 4
+	}
+
+	query += strings.Join(valueStrings, ",")
+	_, err := db.Exec(ctx, query, args...)
+	return err
+}
+
 // func (transactionDetailRepository) FindByID(ctx context.Context, db store.Querier, id string) (*entity.TransactionDetail, error) {
 
 // }
@@ -18,14 +18,16 @@ type TransactionRepository interface {
 	Insert(ctx context.Context, db store.Querier, transaction *entity.Transaction) (*entity.Transaction, error)
 	FindByID(ctx context.Context, db store.Querier, id string, forUpdate bool) (*entity.Transaction, error)
 	FindByUserID(ctx context.Context, db store.Querier, userID string) ([]*entity.Transaction, error)
-	FindDetailByID(ctx context.Context, db store.Querier, userID string) ([]*entity.TransactionWithDetail, error)
+	FindDetailByID(ctx context.Context, db store.Querier, id string) ([]*entity.TransactionWithDetail, error)
+	FindStatusHistoryByID(ctx context.Context, db store.Querier, id string) ([]*entity.TransactionStatusHistory, error)
 	FindManyCheckable(ctx context.Context, tx store.Querier) ([]*entity.Transaction, error)
+	FindManyLateSettlement(ctx context.Context, db store.Querier, maxFailedRefunds int) ([]*entity.Transaction, error)
 	FindManyByUserID(ctx context.Context, db store.Querier, request *model.UserSearchTransactionRequest) ([]*entity.TransactionWithTotal, *web.PageMetadata, error)
 	FindManyWithDetailByUserID(ctx context.Context, db store.Querier, request *model.UserSearchTransactionRequest) ([]*entity.TransactionWithDetailAndTotal, *web.PageMetadata, error)
 	FindManyWithDetailByProductID(ctx context.Context, db store.Querier, request *model.OwnerSearchTransactionRequest) ([]*entity.TransactionWithDetailAndTotal, *web.PageMetadata, error)
-	UpdateCallback(ctx context.Context, db store.Querier, transaction *entity.Transaction) error
-	UpdateToken(ctx context.Context, tx store.Querier, transaction *entity.Transaction) error
-	UpdateStatus(ctx context.Context, tx store.Querier, transaction *entity.Transaction) error
+	UpdateCallback(ctx context.Context, db store.Querier, transaction *entity.Transaction, transition *entity.TransactionTransition) error
+	UpdateToken(ctx context.Context, tx store.Querier, transaction *entity.Transaction, transition *entity.TransactionTransition) error
+	UpdateStatus(ctx context.Context, tx store.Querier, transaction *entity.Transaction, transition *entity.TransactionTransition) error
 	// FindByProductID(ctx context.Context, db store.Querier, productID string) ([]*entity.Transaction, error)
 	// UpdateByID(ctx context.Context, db store.Querier, transaction *entity.Transaction) (*entity.Transaction, error)
 	// DeleteByID(ctx context.Context, db store.Querier, id string) error
@@ -40,15 +42,27 @@ func NewTransactionRepository() TransactionRepository {
 
 func (r *transactionRepository) Insert(ctx context.Context, db store.Querier, transaction *entity.Transaction) (*entity.Transaction, error) {
 	query := `
-	INSERT INTO transactions
-		(id, user_id, total_price, transaction_status, internal_status)
-	VALUES
-		($1, $2, $3, $4, $5)
-	RETURNING
-		checkout_at, updated_at
+	WITH inserted AS (
+		INSERT INTO transactions
+			(id, user_id, total_price, subtotal, discount, tax, service_fee, payment_fee, currency, exchange_rates,
+			transaction_status, internal_status, payment_provider)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING
+			id, internal_status, transaction_status, checkout_at, updated_at
+	), history AS (
+		INSERT INTO transaction_status_history
+			(transaction_id, to_status, transaction_status)
+		SELECT
+			id, internal_status, transaction_status
+		FROM
+			inserted
+	)
+	SELECT checkout_at, updated_at FROM inserted
 	`
 	if err := pgxscan.Get(ctx, db, transaction, query, transaction.ID, transaction.UserID, transaction.TotalPrice,
-		transaction.TransactionStatus, transaction.InternalStatus); err != nil {
+		transaction.Subtotal, transaction.Discount, transaction.Tax, transaction.ServiceFee, transaction.PaymentFee, transaction.Currency, transaction.ExchangeRates, transaction.TransactionStatus, transaction.InternalStatus,
+		transaction.PaymentProvider); err != nil {
 		return nil, err
 	}
 
@@ -58,9 +72,9 @@ func (r *transactionRepository) Insert(ctx context.Context, db store.Querier, tr
 func (r *transactionRepository) FindByID(ctx context.Context, db store.Querier, id string, forUpdate bool) (*entity.Transaction, error) {
 	query := `
 	SELECT
-		id, user_id, total_price, transaction_status, internal_status,
-		external_status, external_settlement_at, external_callback_response,
-		checkout_at, payment_at, updated_at
+		id, user_id, total_price, subtotal, discount, tax, service_fee, payment_fee, currency, exchange_rates,
+		transaction_status, internal_status, external_status, external_settlement_at, external_callback_response,
+		snap_token, payment_provider, payment_reference, payment_redirect_url, checkout_at, payment_at, updated_at
 	FROM
 		transactions
 	WHERE
@@ -74,6 +88,7 @@ func (r *transactionRepository) FindByID(ctx context.Context, db store.Querier,
 	if err := pgxscan.Get(ctx, db, transaction, query, id); err != nil {
 		return nil, err
 	}
+	transaction.ApplyCurrency()
 
 	return transaction, nil
 }
@@ -81,7 +96,7 @@ func (r *transactionRepository) FindByID(ctx context.Context, db store.Querier,
 func (r *transactionRepository) FindByUserID(ctx context.Context, db store.Querier, userID string) ([]*entity.Transaction, error) {
 	query := `
 	SELECT
-		id, user_id, total_price, transaction_status, internal_status,
+		id, user_id, total_price, currency, transaction_status, internal_status,
 		external_status, external_settlement_at, external_callback_response,
 		checkout_at, payment_at, updated_at
 	FROM
@@ -94,90 +109,208 @@ func (r *transactionRepository) FindByUserID(ctx context.Context, db store.Queri
 		return nil, err
 	}
 
+	for _, transaction := range transactions {
+		transaction.ApplyCurrency()
+	}
+
 	return transactions, nil
 }
 
-func (r *transactionRepository) FindDetailByID(ctx context.Context, db store.Querier, userID string) ([]*entity.TransactionWithDetail, error) {
+func (r *transactionRepository) FindDetailByID(ctx context.Context, db store.Querier, id string) ([]*entity.TransactionWithDetail, error) {
 	var transactionWithDetail []*entity.TransactionWithDetail
 	query := `
 	SELECT
-		t.id AS transaction_id
-		t.user_id AS transaction_user_id
-		t.total_price AS transaction_total_price
-		t.transaction_status AS transaction_transaction_status
-		t.internal_status AS transaction_internal_status
-		t.external_status AS transaction_external_status
-		t.external_settlement_at AS transaction_external_settlement_at
-		t.external_callback_response AS transaction_external_callback_response
-		t.checkout_at AS transaction_checkout_at
-		t.payment_at AS transaction_payment_at
-		t.updated_at AS transaction_updated_at
-		td.id AS transaction_detail_id
-		td.transaction_id AS transaction_detail_transaction_id
-		td.product_id AS transaction_detail_product_id
-		td.price AS transaction_detail_price
-		td.quantity AS transaction_detail_quantity
+		t.id AS transaction_id,
+		t.user_id AS transaction_user_id,
+		t.total_price AS transaction_total_price,
+		t.subtotal AS transaction_subtotal,
+		t.discount AS transaction_discount,
+		t.tax AS transaction_tax,
+		t.service_fee AS transaction_service_fee,
+		t.payment_fee AS transaction_payment_fee,
+		t.currency AS transaction_currency,
+		t.exchange_rates AS transaction_exchange_rates,
+		t.transaction_status AS transaction_transaction_status,
+		t.internal_status AS transaction_internal_status,
+		t.external_status AS transaction_external_status,
+		t.external_settlement_at AS transaction_external_settlement_at,
+		t.external_callback_response AS transaction_external_callback_response,
+		t.snap_token AS transaction_snap_token,
+		t.payment_provider AS transaction_payment_provider,
+		t.payment_redirect_url AS transaction_payment_redirect_url,
+		t.checkout_at AS transaction_checkout_at,
+		t.payment_at AS transaction_payment_at,
+		t.updated_at AS transaction_updated_at,
+		td.id AS transaction_detail_id,
+		td.transaction_id AS transaction_detail_transaction_id,
+		td.product_id AS transaction_detail_product_id,
+		td.variant_id AS transaction_detail_variant_id,
+		td.sku AS transaction_detail_sku,
+		td.price AS transaction_detail_price,
+		td.discount AS transaction_detail_discount,
+		td.tax AS transaction_detail_tax,
+		td.quantity AS transaction_detail_quantity,
 		td.created_at AS transaction_detail_created_at
 	FROM 
 		transactions AS t
-	LEFT JOIN
+	JOIN
 		transaction_details AS td
 	ON
 		t.id = td.transaction_id
 	WHERE 
 		t.id = $1
+	ORDER BY
+		td.created_at ASC
 	`
-	if err := pgxscan.Select(ctx, db, &transactionWithDetail, query, userID); err != nil {
+	if err := pgxscan.Select(ctx, db, &transactionWithDetail, query, id); err != nil {
 		return nil, err
 	}
 
+	for _, row := range transactionWithDetail {
+		row.ApplyCurrency()
+	}
+
 	return transactionWithDetail, nil
 }
 
-func (r *transactionRepository) UpdateCallback(ctx context.Context, db store.Querier, transaction *entity.Transaction) error {
+func (r *transactionRepository) FindStatusHistoryByID(ctx context.Context, db store.Querier, id string) ([]*entity.TransactionStatusHistory, error) {
 	query := `
-	UPDATE transactions
-	SET
-		internal_status = $1,
-		transaction_status = $2,
-		external_status = $3,
-		external_settlement_at = $4,
-		external_callback_response = $5,
-		payment_at = $6,
-		updated_at = now()
+	SELECT
+		id, transaction_id, from_status, to_status, transaction_status, external_status, created_at
+	FROM
+		transaction_status_history
 	WHERE
-		id = $7
-	RETURNING
-		checkout_at, updated_at
+		transaction_id = $1
+	ORDER BY
+		created_at ASC
+	`
+	histories := make([]*entity.TransactionStatusHistory, 0)
+	if err := pgxscan.Select(ctx, db, &histories, query, id); err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+// Status updates only apply a validated transition and only when the row is still in its source status,
+// a concurrent change makes the update fail instead of silently overwriting it. A change of the internal status is
+// written to transaction_status_history in the same statement.
+func (r *transactionRepository) UpdateCallback(ctx context.Context, db store.Querier, transaction *entity.Transaction, transition *entity.TransactionTransition) error {
+	if err := transition.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	WITH updated AS (
+		UPDATE transactions
+		SET
+			internal_status = $1,
+			transaction_status = $2,
+			external_status = $3,
+			external_settlement_at = $4,
+			external_callback_response = $5,
+			payment_at = $6,
+			updated_at = now()
+		WHERE
+			id = $7 AND internal_status = $8
+		RETURNING
+			id, internal_status, transaction_status, external_status, checkout_at, updated_at
+	), history AS (
+		INSERT INTO transaction_status_history
+			(transaction_id, from_status, to_status, transaction_status, external_status)
+		SELECT
+			id, $8, internal_status, transaction_status, external_status
+		FROM
+			updated
+		WHERE
+			internal_status <> $8
+	)
+	SELECT checkout_at, updated_at FROM updated
 	`
-	if err := pgxscan.Get(ctx, db, transaction, query, transaction.InternalStatus, transaction.TransactionStatus, transaction.ExternalStatus,
-		transaction.ExternalSettlementAt, transaction.ExternalCallbackResponse, transaction.PaymentAt, transaction.ID); err != nil {
+	if err := pgxscan.Get(ctx, db, transaction, query, transition.To, transition.TransactionStatus, transaction.ExternalStatus,
+		transaction.ExternalSettlementAt, transaction.ExternalCallbackResponse, transaction.PaymentAt, transaction.ID, transition.From); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (r *transactionRepository) UpdateToken(ctx context.Context, tx store.Querier, transaction *entity.Transaction) error {
-	query := `UPDATE transactions SET internal_status = $1, snap_token = $2, updated_at = now() WHERE id = $3`
+func (r *transactionRepository) UpdateToken(ctx context.Context, tx store.Querier, transaction *entity.Transaction, transition *entity.TransactionTransition) error {
+	if err := transition.Validate(); err != nil {
+		return err
+	}
 
-	row, err := tx.Exec(ctx, query, transaction.InternalStatus, transaction.SnapToken, transaction.ID)
-	affected := row.RowsAffected()
+	query := `
+	WITH updated AS (
+		UPDATE transactions
+		SET
+			internal_status = $1,
+			transaction_status = $2,
+			snap_token = $3,
+			payment_reference = $4,
+			payment_redirect_url = $5,
+			updated_at = now()
+		WHERE
+			id = $6 AND internal_status = $7
+		RETURNING
+			id, internal_status, transaction_status, external_status
+	), history AS (
+		INSERT INTO transaction_status_history
+			(transaction_id, from_status, to_status, transaction_status, external_status)
+		SELECT
+			id, $7, internal_status, transaction_status, external_status
+		FROM
+			updated
+		WHERE
+			internal_status <> $7
+	)
+	SELECT id FROM updated
+	`
+
+	row, err := tx.Exec(ctx, query, transition.To, transition.TransactionStatus, transaction.SnapToken, transaction.PaymentReference,
+		transaction.PaymentRedirectURL, transaction.ID, transition.From)
 	if err != nil {
 		return err
 	}
 
-	if affected == 0 {
+	if row.RowsAffected() == 0 {
 		return fmt.Errorf("no rows affected")
 	}
 
 	return nil
 }
 
-func (r *transactionRepository) UpdateStatus(ctx context.Context, tx store.Querier, transaction *entity.Transaction) error {
-	query := `UPDATE transactions SET transaction_status = $1, internal_status = $2, snap_token = COALESCE($3, snap_token), updated_at = $4 WHERE id = $5`
+func (r *transactionRepository) UpdateStatus(ctx context.Context, tx store.Querier, transaction *entity.Transaction, transition *entity.TransactionTransition) error {
+	if err := transition.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+	WITH updated AS (
+		UPDATE transactions
+		SET
+			transaction_status = $1,
+			internal_status = $2,
+			snap_token = COALESCE($3, snap_token),
+			updated_at = $4
+		WHERE
+			id = $5 AND internal_status = $6
+		RETURNING
+			id, internal_status, transaction_status, external_status
+	), history AS (
+		INSERT INTO transaction_status_history
+			(transaction_id, from_status, to_status, transaction_status, external_status)
+		SELECT
+			id, $6, internal_status, transaction_status, external_status
+		FROM
+			updated
+		WHERE
+			internal_status <> $6
+	)
+	SELECT id FROM updated
+	`
 
-	row, err := tx.Exec(ctx, query, transaction.TransactionStatus, transaction.InternalStatus, transaction.SnapToken, transaction.UpdatedAt, transaction.ID)
+	row, err := tx.Exec(ctx, query, transition.TransactionStatus, transition.To, transaction.SnapToken, transaction.UpdatedAt, transaction.ID, transition.From)
 	if err != nil {
 		return err
 	}
@@ -199,7 +332,8 @@ func (r *transactionRepository) FindManyCheckable(ctx context.Context, tx store.
 
 	query := `
 	SELECT
-		id, user_id, transaction_status, checkout_at, payment_at, total_price, internal_status
+		id, user_id, transaction_status, checkout_at, payment_at, total_price, currency, internal_status,
+		payment_provider, payment_reference
 	FROM
 		transactions
 	WHERE
@@ -210,6 +344,40 @@ func (r *transactionRepository) FindManyCheckable(ctx context.Context, tx store.
 		return nil, err
 	}
 
+	for _, transaction := range transactions {
+		transaction.ApplyCurrency()
+	}
+
+	return transactions, nil
+}
+
+// FindManyLateSettlement returns late settlements waiting for their automatic refund, transactions whose system
+// refund already failed maxFailedRefunds times are left for an operator.
+func (r *transactionRepository) FindManyLateSettlement(ctx context.Context, db store.Querier, maxFailedRefunds int) ([]*entity.Transaction, error) {
+	transactions := make([]*entity.Transaction, 0)
+	query := `
+	SELECT
+		t.id, t.user_id, t.transaction_status, t.checkout_at, t.payment_at, t.total_price, t.currency, t.internal_status,
+		t.payment_provider, t.payment_reference
+	FROM
+		transactions AS t
+	WHERE
+		t.internal_status = $1
+		AND (
+			SELECT COUNT(*) FROM refunds AS r
+			WHERE r.transaction_id = t.id AND r.initiator = $2 AND r.status = $3
+		) < $4
+	`
+
+	if err := pgxscan.Select(ctx, db, &transactions, query, enum.TrxInternalStatusLateSettlement, enum.RefundInitiatorSystem,
+		enum.RefundStatusFailed, maxFailedRefunds); err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range transactions {
+		transaction.ApplyCurrency()
+	}
+
 	return transactions, nil
 }
 
@@ -220,6 +388,7 @@ func (r *transactionRepository) FindManyByUserID(ctx context.Context, db store.Q
 		id,
 		user_id,
 		total_price,
+		currency,
 		transaction_status,
 		internal_status,
 		external_status,
@@ -246,6 +415,10 @@ func (r *transactionRepository) FindManyByUserID(ctx context.Context, db store.Q
 		return nil, &web.PageMetadata{}, nil
 	}
 
+	for _, transaction := range transactions {
+		transaction.ApplyCurrency()
+	}
+
 	totalItems := transactions[0].Total
 
 	pageMetadata := helper.CalculatePagination(int64(totalItems), request.Page, request.Limit)
@@ -280,6 +453,12 @@ func (r *transactionRepository) FindManyWithDetailByUserID(
 			t.id AS transaction_id,
 			t.user_id AS transaction_user_id,
 			t.total_price AS transaction_total_price,
+			t.subtotal AS transaction_subtotal,
+			t.discount AS transaction_discount,
+			t.tax AS transaction_tax,
+			t.service_fee AS transaction_service_fee,
+			t.payment_fee AS transaction_payment_fee,
+			t.currency AS transaction_currency,
 			t.transaction_status AS transaction_transaction_status,
 			t.internal_status AS transaction_internal_status,
 			t.external_status AS transaction_external_status,
@@ -291,8 +470,12 @@ func (r *transactionRepository) FindManyWithDetailByUserID(
 			td.id AS transaction_detail_id,
 			td.transaction_id AS transaction_detail_transaction_id,
 			td.product_id AS transaction_detail_product_id,
+			td.variant_id AS transaction_detail_variant_id,
+			td.sku AS transaction_detail_sku,
 			td.quantity AS transaction_detail_quantity,
 			td.price AS transaction_detail_price,
+			td.discount AS transaction_detail_discount,
+			td.tax AS transaction_detail_tax,
 			td.created_at AS transaction_detail_created_at
 		FROM (
 			SELECT *
@@ -317,6 +500,10 @@ func (r *transactionRepository) FindManyWithDetailByUserID(
 		return nil, &web.PageMetadata{}, nil
 	}
 
+	for _, transaction := range transactions {
+		transaction.ApplyCurrency()
+	}
+
 	pageMetadata := helper.CalculatePagination(int64(totalItems), request.Page, request.Limit)
 
 	return transactions, pageMetadata, nil
@@ -329,6 +516,12 @@ func (r *transactionRepository) FindManyWithDetailByProductID(ctx context.Contex
 			t.id AS transaction_id,
 			t.user_id AS transaction_user_id,
 			t.total_price AS transaction_total_price,
+			t.subtotal AS transaction_subtotal,
+			t.discount AS transaction_discount,
+			t.tax AS transaction_tax,
+			t.service_fee AS transaction_service_fee,
+			t.payment_fee AS transaction_payment_fee,
+			t.currency AS transaction_currency,
 			t.transaction_status AS transaction_transaction_status,
 			t.internal_status AS transaction_internal_status,
 			t.external_status AS transaction_external_status,
@@ -340,8 +533,12 @@ func (r *transactionRepository) FindManyWithDetailByProductID(ctx context.Contex
 			td.id AS transaction_detail_id,
 			td.transaction_id AS transaction_detail_transaction_id,
 			td.product_id AS transaction_detail_product_id,
+			td.variant_id AS transaction_detail_variant_id,
+			td.sku AS transaction_detail_sku,
 			td.quantity AS transaction_detail_quantity,
 			td.price AS transaction_detail_price,
+			td.discount AS transaction_detail_discount,
+			td.tax AS transaction_detail_tax,
 			td.created_at AS transaction_detail_created_at
 		FROM transactions AS t
 	JOIN
@@ -363,6 +560,10 @@ func (r *transactionRepository) FindManyWithDetailByProductID(ctx context.Contex
 		return nil, &web.PageMetadata{}, nil
 	}
 
+	for _, transaction := range transactions {
+		transaction.ApplyCurrency()
+	}
+
 	pageMetadata := helper.CalculatePagination(int64(transactions[0].Total), request.Page, request.Limit)
 
 	return transactions, pageMetadata, nil
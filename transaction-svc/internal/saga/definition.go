@@ -0,0 +1,111 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/transaction-svc/internal/entity"
+
+	"github.com/bytedance/sonic"
+)
+
+var (
+	// ErrAwaitingSignal is returned by a step action that can only be finished by an external event, the step
+	// stays WAITING until Signal is called for it.
+	ErrAwaitingSignal   = errors.New("saga step is awaiting an external signal")
+	ErrInstanceNotFound = errors.New("saga instance not found")
+	ErrUnknownSagaType  = errors.New("unknown saga type")
+)
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks a step error as not worth retrying and guarantees that the step left nothing behind,
+// the saga is compensated right away without compensating the rejected step itself.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+type StepFunc func(ctx context.Context, instance *Instance) error
+
+type Step struct {
+	Name string
+	// Action must be safe to run again, a step interrupted by a crash is re-run while it has attempts left.
+	Action StepFunc
+	// Compensate undoes the step, nil when the step has nothing to undo. It must be idempotent.
+	Compensate StepFunc
+	// MaxAttempts bounds how many times Action runs before the saga is compensated, defaults to 1.
+	MaxAttempts int
+}
+
+func (s *Step) maxAttempts() int {
+	if s.MaxAttempts <= 0 {
+		return 1
+	}
+	return s.MaxAttempts
+}
+
+type Definition struct {
+	Type  string
+	Steps []*Step
+}
+
+func (d *Definition) step(name string) *Step {
+	for _, step := range d.Steps {
+		if step.Name == name {
+			return step
+		}
+	}
+	return nil
+}
+
+// Instance is a saga execution together with its persisted step log, the payload carries the data
+// shared between steps.
+type Instance struct {
+	*entity.SagaInstance
+	Steps []*entity.SagaStep
+}
+
+func (i *Instance) Bind(v any) error {
+	return sonic.ConfigFastest.Unmarshal(i.Payload, v)
+}
+
+func (i *Instance) Store(v any) error {
+	payload, err := sonic.ConfigFastest.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	i.Payload = payload
+	return nil
+}
+
+func (i *Instance) Step(name string) *entity.SagaStep {
+	for _, step := range i.Steps {
+		if step.StepName == name {
+			return step
+		}
+	}
+	return nil
+}
+
+func (i *Instance) IsFinished() bool {
+	return i.Status == enum.SagaStatusCompleted || i.Status == enum.SagaStatusCompensated
+}
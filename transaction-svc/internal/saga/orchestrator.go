@@ -0,0 +1,530 @@
+package saga
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/utils"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/repository"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	maxStepRetryDelay      = 5 * time.Minute
+	compensationRetryDelay = 30 * time.Second
+)
+
+// Orchestrator runs registered saga definitions step by step and records every transition in saga_instances and
+// saga_steps, so a saga can be inspected at any time and resumed from its log after a restart.
+type Orchestrator interface {
+	Register(definition *Definition)
+	Start(ctx context.Context, sagaType string, aggregateID uuid.UUID, data any) (*Instance, error)
+	Signal(ctx context.Context, sagaType string, aggregateID uuid.UUID, stepName string, outcome error) error
+	Resume(ctx context.Context) error
+	Find(ctx context.Context, sagaType string, aggregateID uuid.UUID) (*Instance, error)
+}
+
+type orchestrator struct {
+	db              store.DatabaseStore
+	sagaRepository  repository.SagaRepository
+	definitions     map[string]*Definition
+	mu              sync.RWMutex
+	leaseDuration   time.Duration
+	resumeBatchSize int
+	logs            logs.Log
+}
+
+func NewOrchestrator(db store.DatabaseStore, sagaRepository repository.SagaRepository, logs logs.Log) Orchestrator {
+	leaseInt, err := strconv.Atoi(utils.GetEnv("SAGA_LEASE_IN_SECONDS"))
+	if err != nil || leaseInt <= 0 {
+		leaseInt = 60
+	}
+
+	resumeBatchSize, err := strconv.Atoi(utils.GetEnv("SAGA_RESUME_BATCH_SIZE"))
+	if err != nil || resumeBatchSize <= 0 {
+		resumeBatchSize = 50
+	}
+
+	return &orchestrator{
+		db:              db,
+		sagaRepository:  sagaRepository,
+		definitions:     make(map[string]*Definition),
+		leaseDuration:   time.Duration(leaseInt) * time.Second,
+		resumeBatchSize: resumeBatchSize,
+		logs:            logs,
+	}
+}
+
+func (o *orchestrator) Register(definition *Definition) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.definitions[definition.Type] = definition
+}
+
+func (o *orchestrator) definition(sagaType string) (*Definition, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	definition, ok := o.definitions[sagaType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSagaType, sagaType)
+	}
+	return definition, nil
+}
+
+// Start persists a new saga with all of its steps PENDING and runs it until it completes, waits for a signal,
+// schedules a retry or is compensated. The error of a step that failed for good is returned after compensation.
+func (o *orchestrator) Start(ctx context.Context, sagaType string, aggregateID uuid.UUID, data any) (*Instance, error) {
+	definition, err := o.definition(sagaType)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := sonic.ConfigFastest.Marshal(data)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(o.logs, "failed to marshal saga payload", err)
+	}
+
+	instance := &Instance{
+		SagaInstance: &entity.SagaInstance{
+			SagaType:    sagaType,
+			AggregateID: aggregateID,
+			Status:      enum.SagaStatusRunning,
+			CurrentStep: sql.NullString{String: definition.Steps[0].Name, Valid: true},
+			Payload:     payload,
+		},
+	}
+
+	if err := store.BeginTransaction(ctx, o.logs, o.db, func(tx store.Transaction) error {
+		if _, err := o.sagaRepository.InsertInstance(ctx, tx, instance.SagaInstance); err != nil {
+			return helper.WrapInternalServerError(o.logs, "failed to insert saga instance", err)
+		}
+
+		instance.Steps = make([]*entity.SagaStep, 0, len(definition.Steps))
+		for index, step := range definition.Steps {
+			sagaStep, err := o.sagaRepository.InsertStep(ctx, tx, &entity.SagaStep{
+				SagaInstanceID: instance.ID,
+				StepName:       step.Name,
+				StepOrder:      index + 1,
+				Status:         enum.SagaStepStatusPending,
+			})
+			if err != nil {
+				return helper.WrapInternalServerError(o.logs, "failed to insert saga step", err)
+			}
+			instance.Steps = append(instance.Steps, sagaStep)
+		}
+
+		// The creator owns the saga right away so the resumer never picks up a saga that is still being started
+		if _, err := o.sagaRepository.AcquireLease(ctx, tx, instance.ID, o.leaseDuration); err != nil {
+			return helper.WrapInternalServerError(o.logs, "failed to acquire saga lease", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	o.logs.Info("Saga started", zap.String("saga_type", sagaType), zap.String("saga_id", instance.ID.String()),
+		zap.String("aggregate_id", aggregateID.String()))
+
+	defer o.releaseLease(ctx, instance.ID)
+	return instance, o.execute(ctx, definition, instance)
+}
+
+// Signal records the outcome of a WAITING step and continues the saga, a failed outcome compensates it.
+// A failed outcome that arrives before the saga reached the step aborts the saga as well. The signal is only applied
+// by the process holding the lease, when another process holds it that process or the resumer picks the signal up.
+func (o *orchestrator) Signal(ctx context.Context, sagaType string, aggregateID uuid.UUID, stepName string, outcome error) error {
+	definition, err := o.definition(sagaType)
+	if err != nil {
+		return err
+	}
+
+	instance, err := o.Find(ctx, sagaType, aggregateID)
+	if err != nil {
+		if errors.Is(err, ErrInstanceNotFound) {
+			o.logs.Info("No saga found for signal, ignoring", zap.String("saga_type", sagaType),
+				zap.String("aggregate_id", aggregateID.String()), zap.String("step", stepName))
+			return nil
+		}
+		return err
+	}
+
+	if instance.Step(stepName) == nil {
+		return fmt.Errorf("saga %s has no step %s", sagaType, stepName)
+	}
+
+	signal := &entity.SagaSignal{SagaInstanceID: instance.ID, StepName: stepName}
+	if outcome != nil {
+		signal.Error = sql.NullString{String: outcome.Error(), Valid: true}
+	}
+
+	if err := o.sagaRepository.InsertSignal(ctx, o.db, signal); err != nil {
+		return helper.WrapInternalServerError(o.logs, "failed to insert saga signal", err)
+	}
+
+	return o.run(ctx, definition, instance.ID)
+}
+
+// Resume continues sagas that are due, either a step retry, an unfinished compensation, a saga with a signal to
+// apply or a saga whose owner stopped while running it.
+func (o *orchestrator) Resume(ctx context.Context) error {
+	instances, err := o.sagaRepository.FindManyResumable(ctx, o.db, o.resumeBatchSize)
+	if err != nil {
+		return helper.WrapInternalServerError(o.logs, "failed to find resumable sagas", err)
+	}
+
+	for _, sagaInstance := range instances {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		definition, err := o.definition(sagaInstance.SagaType)
+		if err != nil {
+			o.logs.Warn("Skipping saga without registered definition", zap.String("saga_id", sagaInstance.ID.String()), zap.Error(err))
+			continue
+		}
+
+		if err := o.run(ctx, definition, sagaInstance.ID); err != nil {
+			o.logs.Warn("Resumed saga did not complete", zap.String("saga_id", sagaInstance.ID.String()), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+func (o *orchestrator) Find(ctx context.Context, sagaType string, aggregateID uuid.UUID) (*Instance, error) {
+	sagaInstance, err := o.sagaRepository.FindInstanceByAggregateID(ctx, o.db, sagaType, aggregateID)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, ErrInstanceNotFound
+		}
+		return nil, helper.WrapInternalServerError(o.logs, "failed to find saga instance", err)
+	}
+
+	steps, err := o.sagaRepository.FindStepsByInstanceID(ctx, o.db, sagaInstance.ID)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(o.logs, "failed to find saga steps", err)
+	}
+
+	return &Instance{SagaInstance: sagaInstance, Steps: steps}, nil
+}
+
+// run continues the saga when its lease is free, it is loaded again once leased since the previous owner may
+// have moved it on.
+func (o *orchestrator) run(ctx context.Context, definition *Definition, id uuid.UUID) error {
+	acquired, err := o.sagaRepository.AcquireLease(ctx, o.db, id, o.leaseDuration)
+	if err != nil {
+		return helper.WrapInternalServerError(o.logs, "failed to acquire saga lease", err)
+	}
+
+	if !acquired {
+		o.logs.Info("Saga is leased by another process, skipping", zap.String("saga_id", id.String()))
+		return nil
+	}
+
+	defer o.releaseLease(ctx, id)
+
+	sagaInstance, err := o.sagaRepository.FindInstanceByID(ctx, o.db, id)
+	if err != nil {
+		return helper.WrapInternalServerError(o.logs, "failed to find saga instance", err)
+	}
+
+	steps, err := o.sagaRepository.FindStepsByInstanceID(ctx, o.db, id)
+	if err != nil {
+		return helper.WrapInternalServerError(o.logs, "failed to find saga steps", err)
+	}
+
+	return o.execute(ctx, definition, &Instance{SagaInstance: sagaInstance, Steps: steps})
+}
+
+func (o *orchestrator) releaseLease(ctx context.Context, id uuid.UUID) {
+	if err := o.sagaRepository.ReleaseLease(context.WithoutCancel(ctx), o.db, id); err != nil {
+		o.logs.Error("failed to release saga lease", zap.String("saga_id", id.String()), zap.Error(err))
+	}
+}
+
+func (o *orchestrator) execute(ctx context.Context, definition *Definition, instance *Instance) error {
+	for {
+		if err := o.receiveSignals(ctx, instance); err != nil {
+			return err
+		}
+
+		if instance.Status == enum.SagaStatusCompensating {
+			return o.compensate(ctx, definition, instance)
+		}
+
+		if instance.IsFinished() || instance.Status == enum.SagaStatusWaiting {
+			return nil
+		}
+
+		sagaStep := o.nextStep(instance)
+		if sagaStep == nil {
+			instance.Status = enum.SagaStatusCompleted
+			instance.CurrentStep = sql.NullString{}
+			instance.LastError = sql.NullString{}
+			instance.NextRunAt = sql.NullTime{}
+			if err := o.save(ctx, instance, nil); err != nil {
+				return err
+			}
+
+			o.logs.Info("Saga completed", zap.String("saga_id", instance.ID.String()), zap.String("saga_type", instance.SagaType))
+			return nil
+		}
+
+		step := definition.step(sagaStep.StepName)
+		if step == nil {
+			return fmt.Errorf("saga %s has no definition for step %s", definition.Type, sagaStep.StepName)
+		}
+
+		now := time.Now()
+		switch sagaStep.Status {
+		case enum.SagaStepStatusWaiting:
+			return nil
+		case enum.SagaStepStatusRetrying:
+			if sagaStep.NextRetryAt.Valid && sagaStep.NextRetryAt.Time.After(now) {
+				return nil
+			}
+		case enum.SagaStepStatusRunning:
+			// The previous owner stopped before recording an outcome, the step may or may not have taken effect
+			if sagaStep.Attempts >= step.maxAttempts() {
+				if err := o.fail(ctx, instance, sagaStep, errors.New("step was interrupted before it recorded an outcome")); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		sagaStep.Status = enum.SagaStepStatusRunning
+		sagaStep.Attempts++
+		sagaStep.StartedAt = nullable.ToSQLTime(now)
+		sagaStep.NextRetryAt = sql.NullTime{}
+		instance.CurrentStep = sql.NullString{String: step.Name, Valid: true}
+		instance.NextRunAt = sql.NullTime{}
+		if err := o.save(ctx, instance, sagaStep); err != nil {
+			return err
+		}
+
+		stepErr := step.Action(ctx, instance)
+		switch {
+		case stepErr == nil:
+			sagaStep.Status = enum.SagaStepStatusCompleted
+			sagaStep.CompletedAt = nullable.ToSQLTime(time.Now())
+			sagaStep.LastError = sql.NullString{}
+			instance.LastError = sql.NullString{}
+			if err := o.save(ctx, instance, sagaStep); err != nil {
+				return err
+			}
+
+		case errors.Is(stepErr, ErrAwaitingSignal):
+			sagaStep.Status = enum.SagaStepStatusWaiting
+			instance.Status = enum.SagaStatusWaiting
+			if err := o.save(ctx, instance, sagaStep); err != nil {
+				return err
+			}
+
+			o.logs.Info("Saga is waiting for a signal", zap.String("saga_id", instance.ID.String()), zap.String("step", step.Name))
+			return nil
+
+		case !IsPermanent(stepErr) && sagaStep.Attempts < step.maxAttempts():
+			nextRetryAt := time.Now().Add(helper.ExponentialBackoff(sagaStep.Attempts, time.Second, maxStepRetryDelay))
+			sagaStep.Status = enum.SagaStepStatusRetrying
+			sagaStep.NextRetryAt = nullable.ToSQLTime(nextRetryAt)
+			sagaStep.LastError = sql.NullString{String: stepErr.Error(), Valid: true}
+			instance.LastError = sagaStep.LastError
+			instance.NextRunAt = sagaStep.NextRetryAt
+			if err := o.save(ctx, instance, sagaStep); err != nil {
+				return err
+			}
+
+			o.logs.Warn("Saga step failed, retry scheduled", zap.String("saga_id", instance.ID.String()), zap.String("step", step.Name),
+				zap.Int("attempts", sagaStep.Attempts), zap.Time("next_retry_at", nextRetryAt), zap.Error(stepErr))
+			return nil
+
+		default:
+			if err := o.fail(ctx, instance, sagaStep, stepErr); err != nil {
+				return err
+			}
+
+			if err := o.compensate(ctx, definition, instance); err != nil {
+				o.logs.Error("failed to compensate saga", zap.String("saga_id", instance.ID.String()), zap.Error(err))
+			}
+
+			var permanent *permanentError
+			if errors.As(stepErr, &permanent) {
+				return permanent.err
+			}
+			return stepErr
+		}
+	}
+}
+
+// receiveSignals applies the signals recorded since the last check. Only the lease owner calls it, so a signal is
+// never overwritten by the owner saving the step it was running.
+func (o *orchestrator) receiveSignals(ctx context.Context, instance *Instance) error {
+	return store.BeginTransaction(ctx, o.logs, o.db, func(tx store.Transaction) error {
+		signals, err := o.sagaRepository.TakeSignals(ctx, tx, instance.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(o.logs, "failed to take saga signals", err)
+		}
+
+		for _, signal := range signals {
+			sagaStep, applied := o.applySignal(instance, signal)
+			if !applied {
+				continue
+			}
+
+			if err := o.update(ctx, tx, instance, sagaStep); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// applySignal moves a WAITING step on, or aborts a saga that has not reached the signaled step yet. It returns the
+// changed step, nil when only the instance changed, and false when the signal came too late to matter.
+func (o *orchestrator) applySignal(instance *Instance, signal *entity.SagaSignal) (*entity.SagaStep, bool) {
+	sagaStep := instance.Step(signal.StepName)
+	if sagaStep == nil {
+		o.logs.Warn("Saga has no step for signal, ignoring", zap.String("saga_id", instance.ID.String()),
+			zap.String("step", signal.StepName))
+		return nil, false
+	}
+
+	switch {
+	case sagaStep.Status == enum.SagaStepStatusWaiting && !signal.Error.Valid:
+		sagaStep.Status = enum.SagaStepStatusCompleted
+		sagaStep.CompletedAt = nullable.ToSQLTime(time.Now())
+		sagaStep.LastError = sql.NullString{}
+		instance.Status = enum.SagaStatusRunning
+
+	case sagaStep.Status == enum.SagaStepStatusWaiting:
+		sagaStep.Status = enum.SagaStepStatusRejected
+		sagaStep.LastError = signal.Error
+		instance.Status = enum.SagaStatusCompensating
+		instance.LastError = signal.Error
+
+	case signal.Error.Valid && !instance.IsFinished() && instance.Status != enum.SagaStatusCompensating:
+		o.logs.Warn("Aborting saga before it reached the signaled step", zap.String("saga_id", instance.ID.String()),
+			zap.String("step", signal.StepName), zap.String("step_status", string(sagaStep.Status)),
+			zap.String("error", signal.Error.String))
+		sagaStep = nil
+		instance.Status = enum.SagaStatusCompensating
+		instance.LastError = signal.Error
+
+	default:
+		o.logs.Info("Saga step is not waiting for a signal, ignoring", zap.String("saga_id", instance.ID.String()),
+			zap.String("step", signal.StepName), zap.String("step_status", string(sagaStep.Status)),
+			zap.String("saga_status", string(instance.Status)))
+		return nil, false
+	}
+
+	instance.NextRunAt = sql.NullTime{}
+	return sagaStep, true
+}
+
+// nextStep returns the first step that has not completed yet.
+func (o *orchestrator) nextStep(instance *Instance) *entity.SagaStep {
+	for _, sagaStep := range instance.Steps {
+		if sagaStep.Status != enum.SagaStepStatusCompleted {
+			return sagaStep
+		}
+	}
+	return nil
+}
+
+func (o *orchestrator) fail(ctx context.Context, instance *Instance, sagaStep *entity.SagaStep, stepErr error) error {
+	sagaStep.Status = enum.SagaStepStatusFailed
+	if IsPermanent(stepErr) {
+		sagaStep.Status = enum.SagaStepStatusRejected
+	}
+	sagaStep.LastError = sql.NullString{String: stepErr.Error(), Valid: true}
+	instance.Status = enum.SagaStatusCompensating
+	instance.LastError = sagaStep.LastError
+	instance.NextRunAt = sql.NullTime{}
+
+	o.logs.Warn("Saga step failed, compensating", zap.String("saga_id", instance.ID.String()), zap.String("step", sagaStep.StepName),
+		zap.Int("attempts", sagaStep.Attempts), zap.Error(stepErr))
+	return o.save(ctx, instance, sagaStep)
+}
+
+// compensate undoes completed steps, and failed steps whose outcome is unknown, in reverse order. A failing
+// compensation keeps the saga COMPENSATING so the resumer tries again later.
+func (o *orchestrator) compensate(ctx context.Context, definition *Definition, instance *Instance) error {
+	for i := len(instance.Steps) - 1; i >= 0; i-- {
+		sagaStep := instance.Steps[i]
+		if sagaStep.Status != enum.SagaStepStatusCompleted && sagaStep.Status != enum.SagaStepStatusFailed {
+			continue
+		}
+
+		step := definition.step(sagaStep.StepName)
+		if step == nil || step.Compensate == nil {
+			continue
+		}
+
+		instance.CurrentStep = sql.NullString{String: step.Name, Valid: true}
+		if err := step.Compensate(ctx, instance); err != nil {
+			nextRunAt := time.Now().Add(compensationRetryDelay)
+			instance.LastError = sql.NullString{String: fmt.Sprintf("compensate %s: %s", step.Name, err.Error()), Valid: true}
+			instance.NextRunAt = nullable.ToSQLTime(nextRunAt)
+			if saveErr := o.save(ctx, instance, nil); saveErr != nil {
+				return saveErr
+			}
+			return err
+		}
+
+		sagaStep.Status = enum.SagaStepStatusCompensated
+		sagaStep.CompensatedAt = nullable.ToSQLTime(time.Now())
+		if err := o.save(ctx, instance, sagaStep); err != nil {
+			return err
+		}
+	}
+
+	instance.Status = enum.SagaStatusCompensated
+	instance.CurrentStep = sql.NullString{}
+	instance.NextRunAt = sql.NullTime{}
+	if err := o.save(ctx, instance, nil); err != nil {
+		return err
+	}
+
+	o.logs.Info("Saga compensated", zap.String("saga_id", instance.ID.String()), zap.String("saga_type", instance.SagaType),
+		zap.String("last_error", instance.LastError.String))
+	return nil
+}
+
+func (o *orchestrator) save(ctx context.Context, instance *Instance, sagaStep *entity.SagaStep) error {
+	return store.BeginTransaction(ctx, o.logs, o.db, func(tx store.Transaction) error {
+		return o.update(ctx, tx, instance, sagaStep)
+	})
+}
+
+func (o *orchestrator) update(ctx context.Context, tx store.Transaction, instance *Instance, sagaStep *entity.SagaStep) error {
+	if err := o.sagaRepository.UpdateInstance(ctx, tx, instance.SagaInstance); err != nil {
+		return helper.WrapInternalServerError(o.logs, "failed to update saga instance", err)
+	}
+
+	if sagaStep == nil {
+		return nil
+	}
+
+	if err := o.sagaRepository.UpdateStep(ctx, tx, sagaStep); err != nil {
+		return helper.WrapInternalServerError(o.logs, "failed to update saga step", err)
+	}
+	return nil
+}
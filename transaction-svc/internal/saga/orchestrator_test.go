@@ -0,0 +1,637 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSagaType = "TEST"
+
+var errStepFailed = errors.New("step failed")
+
+// sagaRun records the actions and compensations a test saga ran, in the order they ran.
+type sagaRun struct {
+	actions       []string
+	compensations []string
+}
+
+// testStep describes a step of the test saga, outcomes are returned by the action one call after another and the last
+// outcome is repeated once they run out.
+type testStep struct {
+	outcomes      []error
+	maxAttempts   int
+	compensateErr error
+}
+
+func newTestDefinition(run *sagaRun, steps map[string]*testStep) *saga.Definition {
+	definition := &saga.Definition{Type: testSagaType}
+	for _, name := range []string{"FIRST", "SECOND", "THIRD"} {
+		behavior := steps[name]
+		if behavior == nil {
+			behavior = &testStep{}
+		}
+
+		calls := 0
+		step := &saga.Step{
+			Name:        name,
+			MaxAttempts: behavior.maxAttempts,
+			Action: func(context.Context, *saga.Instance) error {
+				run.actions = append(run.actions, name)
+				if len(behavior.outcomes) == 0 {
+					return nil
+				}
+
+				outcome := behavior.outcomes[min(calls, len(behavior.outcomes)-1)]
+				calls++
+				return outcome
+			},
+			Compensate: func(context.Context, *saga.Instance) error {
+				run.compensations = append(run.compensations, name)
+				return behavior.compensateErr
+			},
+		}
+		definition.Steps = append(definition.Steps, step)
+	}
+	return definition
+}
+
+func newTestOrchestrator(repo *fakeSagaRepository, definition *saga.Definition) saga.Orchestrator {
+	orchestrator := saga.NewOrchestrator(&fakeDatabaseStore{}, repo, zap.NewNop())
+	orchestrator.Register(definition)
+	return orchestrator
+}
+
+func TestOrchestrator_Start(t *testing.T) {
+	permanentErr := errors.New("out of stock")
+
+	tests := []struct {
+		name              string
+		steps             map[string]*testStep
+		wantErr           error
+		wantStatus        enum.SagaStatus
+		wantStepStatuses  []enum.SagaStepStatus
+		wantActions       []string
+		wantCompensations []string
+		wantRetryIn       time.Duration
+	}{
+		{
+			name:             "every step completes",
+			wantStatus:       enum.SagaStatusCompleted,
+			wantStepStatuses: []enum.SagaStepStatus{enum.SagaStepStatusCompleted, enum.SagaStepStatusCompleted, enum.SagaStepStatusCompleted},
+			wantActions:      []string{"FIRST", "SECOND", "THIRD"},
+		},
+		{
+			name:             "a failed step with attempts left is retried later",
+			steps:            map[string]*testStep{"SECOND": {outcomes: []error{errStepFailed}, maxAttempts: 3}},
+			wantStatus:       enum.SagaStatusRunning,
+			wantStepStatuses: []enum.SagaStepStatus{enum.SagaStepStatusCompleted, enum.SagaStepStatusRetrying, enum.SagaStepStatusPending},
+			wantActions:      []string{"FIRST", "SECOND"},
+			wantRetryIn:      2 * time.Second,
+		},
+		{
+			name:              "a failed step without attempts left is compensated with the steps before it, in reverse order",
+			steps:             map[string]*testStep{"THIRD": {outcomes: []error{errStepFailed}}},
+			wantErr:           errStepFailed,
+			wantStatus:        enum.SagaStatusCompensated,
+			wantStepStatuses:  []enum.SagaStepStatus{enum.SagaStepStatusCompensated, enum.SagaStepStatusCompensated, enum.SagaStepStatusCompensated},
+			wantActions:       []string{"FIRST", "SECOND", "THIRD"},
+			wantCompensations: []string{"THIRD", "SECOND", "FIRST"},
+		},
+		{
+			name:              "a permanent error is not retried and the rejected step is not compensated",
+			steps:             map[string]*testStep{"SECOND": {outcomes: []error{saga.Permanent(permanentErr)}, maxAttempts: 3}},
+			wantErr:           permanentErr,
+			wantStatus:        enum.SagaStatusCompensated,
+			wantStepStatuses:  []enum.SagaStepStatus{enum.SagaStepStatusCompensated, enum.SagaStepStatusRejected, enum.SagaStepStatusPending},
+			wantActions:       []string{"FIRST", "SECOND"},
+			wantCompensations: []string{"FIRST"},
+		},
+		{
+			name:             "a step awaiting a signal parks the saga",
+			steps:            map[string]*testStep{"SECOND": {outcomes: []error{saga.ErrAwaitingSignal}}},
+			wantStatus:       enum.SagaStatusWaiting,
+			wantStepStatuses: []enum.SagaStepStatus{enum.SagaStepStatusCompleted, enum.SagaStepStatusWaiting, enum.SagaStepStatusPending},
+			wantActions:      []string{"FIRST", "SECOND"},
+		},
+		{
+			name: "a failed compensation keeps the saga compensating",
+			steps: map[string]*testStep{
+				"FIRST":  {compensateErr: errors.New("stock service unavailable")},
+				"SECOND": {outcomes: []error{saga.Permanent(permanentErr)}},
+			},
+			wantErr:           permanentErr,
+			wantStatus:        enum.SagaStatusCompensating,
+			wantStepStatuses:  []enum.SagaStepStatus{enum.SagaStepStatusCompleted, enum.SagaStepStatusRejected, enum.SagaStepStatusPending},
+			wantActions:       []string{"FIRST", "SECOND"},
+			wantCompensations: []string{"FIRST"},
+			wantRetryIn:       30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := new(sagaRun)
+			repo := newFakeSagaRepository()
+			orchestrator := newTestOrchestrator(repo, newTestDefinition(run, tt.steps))
+
+			instance, err := orchestrator.Start(context.Background(), testSagaType, uuid.New(), map[string]string{})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, saga.IsPermanent(err))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			stored := repo.instance(instance.ID)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+			assert.Equal(t, tt.wantStepStatuses, repo.stepStatuses(instance.ID))
+			assert.Equal(t, tt.wantActions, run.actions)
+			assert.Equal(t, tt.wantCompensations, run.compensations)
+			assert.False(t, repo.isLeased(instance.ID), "the lease is released")
+
+			if tt.wantRetryIn > 0 {
+				require.True(t, stored.NextRunAt.Valid)
+				assert.WithinDuration(t, time.Now().Add(tt.wantRetryIn), stored.NextRunAt.Time, time.Second)
+			} else {
+				assert.False(t, stored.NextRunAt.Valid)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Signal(t *testing.T) {
+	canceledErr := errors.New("canceled by user")
+
+	tests := []struct {
+		name              string
+		steps             map[string]*testStep
+		signalStep        string
+		outcome           error
+		wantStatus        enum.SagaStatus
+		wantStepStatuses  []enum.SagaStepStatus
+		wantActions       []string
+		wantCompensations []string
+	}{
+		{
+			name:             "a successful outcome completes the waiting step and resumes the saga",
+			steps:            map[string]*testStep{"SECOND": {outcomes: []error{saga.ErrAwaitingSignal}}},
+			signalStep:       "SECOND",
+			wantStatus:       enum.SagaStatusCompleted,
+			wantStepStatuses: []enum.SagaStepStatus{enum.SagaStepStatusCompleted, enum.SagaStepStatusCompleted, enum.SagaStepStatusCompleted},
+			wantActions:      []string{"FIRST", "SECOND", "THIRD"},
+		},
+		{
+			name:              "a failed outcome rejects the waiting step and compensates the steps before it",
+			steps:             map[string]*testStep{"SECOND": {outcomes: []error{saga.ErrAwaitingSignal}}},
+			signalStep:        "SECOND",
+			outcome:           canceledErr,
+			wantStatus:        enum.SagaStatusCompensated,
+			wantStepStatuses:  []enum.SagaStepStatus{enum.SagaStepStatusCompensated, enum.SagaStepStatusRejected, enum.SagaStepStatusPending},
+			wantActions:       []string{"FIRST", "SECOND"},
+			wantCompensations: []string{"FIRST"},
+		},
+		{
+			name:              "a failed outcome before the saga reached the step aborts it",
+			steps:             map[string]*testStep{"SECOND": {outcomes: []error{errStepFailed}, maxAttempts: 3}},
+			signalStep:        "THIRD",
+			outcome:           canceledErr,
+			wantStatus:        enum.SagaStatusCompensated,
+			wantStepStatuses:  []enum.SagaStepStatus{enum.SagaStepStatusCompensated, enum.SagaStepStatusRetrying, enum.SagaStepStatusPending},
+			wantActions:       []string{"FIRST", "SECOND"},
+			wantCompensations: []string{"FIRST"},
+		},
+		{
+			name:             "a successful outcome for a step that is not waiting is ignored",
+			steps:            map[string]*testStep{"SECOND": {outcomes: []error{saga.ErrAwaitingSignal}}},
+			signalStep:       "THIRD",
+			wantStatus:       enum.SagaStatusWaiting,
+			wantStepStatuses: []enum.SagaStepStatus{enum.SagaStepStatusCompleted, enum.SagaStepStatusWaiting, enum.SagaStepStatusPending},
+			wantActions:      []string{"FIRST", "SECOND"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			run := new(sagaRun)
+			repo := newFakeSagaRepository()
+			orchestrator := newTestOrchestrator(repo, newTestDefinition(run, tt.steps))
+
+			aggregateID := uuid.New()
+			instance, err := orchestrator.Start(ctx, testSagaType, aggregateID, map[string]string{})
+			require.NoError(t, err)
+
+			require.NoError(t, orchestrator.Signal(ctx, testSagaType, aggregateID, tt.signalStep, tt.outcome))
+
+			assert.Equal(t, tt.wantStatus, repo.instance(instance.ID).Status)
+			assert.Equal(t, tt.wantStepStatuses, repo.stepStatuses(instance.ID))
+			assert.Equal(t, tt.wantActions, run.actions)
+			assert.Equal(t, tt.wantCompensations, run.compensations)
+			assert.Empty(t, repo.pendingSignals(instance.ID))
+		})
+	}
+}
+
+func TestOrchestrator_Signal_UnknownSaga(t *testing.T) {
+	orchestrator := newTestOrchestrator(newFakeSagaRepository(), newTestDefinition(new(sagaRun), nil))
+
+	assert.NoError(t, orchestrator.Signal(context.Background(), testSagaType, uuid.New(), "SECOND", nil))
+	assert.ErrorIs(t, orchestrator.Signal(context.Background(), "OTHER", uuid.New(), "SECOND", nil), saga.ErrUnknownSagaType)
+}
+
+// An abort signaled while another process runs a step must survive that process saving the step.
+func TestOrchestrator_Signal_WhileTheSagaIsLeased(t *testing.T) {
+	ctx := context.Background()
+	run := new(sagaRun)
+	repo := newFakeSagaRepository()
+	definition := newTestDefinition(run, nil)
+	orchestrator := newTestOrchestrator(repo, definition)
+
+	aggregateID := uuid.New()
+	definition.Steps[1].Action = func(ctx context.Context, instance *saga.Instance) error {
+		run.actions = append(run.actions, "SECOND")
+
+		assert.NoError(t, orchestrator.Signal(ctx, testSagaType, aggregateID, "THIRD", errors.New("canceled by user")))
+		assert.Equal(t, enum.SagaStatusRunning, repo.instance(instance.ID).Status, "the signal waits for the lease owner")
+		return nil
+	}
+
+	instance, err := orchestrator.Start(ctx, testSagaType, aggregateID, map[string]string{})
+	require.NoError(t, err)
+
+	assert.Equal(t, enum.SagaStatusCompensated, repo.instance(instance.ID).Status)
+	assert.Equal(t, []string{"FIRST", "SECOND"}, run.actions)
+	assert.Equal(t, []string{"SECOND", "FIRST"}, run.compensations)
+	assert.Empty(t, repo.pendingSignals(instance.ID))
+}
+
+func TestOrchestrator_Resume(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("a due retry is run again", func(t *testing.T) {
+		run := new(sagaRun)
+		repo := newFakeSagaRepository()
+		orchestrator := newTestOrchestrator(repo, newTestDefinition(run, map[string]*testStep{
+			"SECOND": {outcomes: []error{errStepFailed, nil}, maxAttempts: 3},
+		}))
+
+		instance, err := orchestrator.Start(ctx, testSagaType, uuid.New(), map[string]string{})
+		require.NoError(t, err)
+
+		require.NoError(t, orchestrator.Resume(ctx))
+		assert.Equal(t, []string{"FIRST", "SECOND"}, run.actions, "the retry is not due yet")
+
+		repo.makeDue(instance.ID)
+		require.NoError(t, orchestrator.Resume(ctx))
+
+		assert.Equal(t, enum.SagaStatusCompleted, repo.instance(instance.ID).Status)
+		assert.Equal(t, []string{"FIRST", "SECOND", "SECOND", "THIRD"}, run.actions)
+		assert.Equal(t, 2, repo.step(instance.ID, "SECOND").Attempts)
+	})
+
+	t.Run("a saga leased by another process is skipped", func(t *testing.T) {
+		run := new(sagaRun)
+		repo := newFakeSagaRepository()
+		orchestrator := newTestOrchestrator(repo, newTestDefinition(run, map[string]*testStep{
+			"SECOND": {outcomes: []error{errStepFailed, nil}, maxAttempts: 3},
+		}))
+
+		instance, err := orchestrator.Start(ctx, testSagaType, uuid.New(), map[string]string{})
+		require.NoError(t, err)
+
+		repo.makeDue(instance.ID)
+		repo.lease(instance.ID)
+		require.NoError(t, orchestrator.Resume(ctx))
+
+		assert.Equal(t, enum.SagaStatusRunning, repo.instance(instance.ID).Status)
+		assert.Equal(t, enum.SagaStepStatusRetrying, repo.step(instance.ID, "SECOND").Status)
+		assert.Equal(t, []string{"FIRST", "SECOND"}, run.actions)
+	})
+
+	t.Run("a signal left behind by a leased saga is applied", func(t *testing.T) {
+		run := new(sagaRun)
+		repo := newFakeSagaRepository()
+		orchestrator := newTestOrchestrator(repo, newTestDefinition(run, map[string]*testStep{
+			"SECOND": {outcomes: []error{saga.ErrAwaitingSignal}},
+		}))
+
+		aggregateID := uuid.New()
+		instance, err := orchestrator.Start(ctx, testSagaType, aggregateID, map[string]string{})
+		require.NoError(t, err)
+
+		repo.lease(instance.ID)
+		require.NoError(t, orchestrator.Signal(ctx, testSagaType, aggregateID, "SECOND", nil))
+		assert.Equal(t, enum.SagaStatusWaiting, repo.instance(instance.ID).Status)
+
+		repo.releaseLease(instance.ID)
+		require.NoError(t, orchestrator.Resume(ctx))
+
+		assert.Equal(t, enum.SagaStatusCompleted, repo.instance(instance.ID).Status)
+		assert.Equal(t, []string{"FIRST", "SECOND", "THIRD"}, run.actions)
+	})
+
+	t.Run("an interrupted step without attempts left is compensated", func(t *testing.T) {
+		run := new(sagaRun)
+		repo := newFakeSagaRepository()
+		orchestrator := newTestOrchestrator(repo, newTestDefinition(run, map[string]*testStep{
+			"SECOND": {outcomes: []error{saga.ErrAwaitingSignal}},
+		}))
+
+		instance, err := orchestrator.Start(ctx, testSagaType, uuid.New(), map[string]string{})
+		require.NoError(t, err)
+
+		// the owner crashed while running the step, before it recorded an outcome
+		repo.interrupt(instance.ID, "SECOND")
+		require.NoError(t, orchestrator.Resume(ctx))
+
+		assert.Equal(t, enum.SagaStatusCompensated, repo.instance(instance.ID).Status)
+		assert.Equal(t, []string{"SECOND", "FIRST"}, run.compensations)
+	})
+}
+
+type fakeSagaRepository struct {
+	mu        sync.Mutex
+	instances map[uuid.UUID]entity.SagaInstance
+	steps     map[uuid.UUID]entity.SagaStep
+	signals   []entity.SagaSignal
+	leases    map[uuid.UUID]bool
+}
+
+func newFakeSagaRepository() *fakeSagaRepository {
+	return &fakeSagaRepository{
+		instances: make(map[uuid.UUID]entity.SagaInstance),
+		steps:     make(map[uuid.UUID]entity.SagaStep),
+		leases:    make(map[uuid.UUID]bool),
+	}
+}
+
+func (r *fakeSagaRepository) InsertInstance(_ context.Context, _ store.Querier, instance *entity.SagaInstance) (*entity.SagaInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	instance.ID, instance.CreatedAt, instance.UpdatedAt = uuid.New(), &now, &now
+	r.instances[instance.ID] = *instance
+	return instance, nil
+}
+
+func (r *fakeSagaRepository) InsertStep(_ context.Context, _ store.Querier, step *entity.SagaStep) (*entity.SagaStep, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	step.ID = uuid.New()
+	r.steps[step.ID] = *step
+	return step, nil
+}
+
+func (r *fakeSagaRepository) FindInstanceByID(_ context.Context, _ store.Querier, id uuid.UUID) (*entity.SagaInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance, ok := r.instances[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &instance, nil
+}
+
+func (r *fakeSagaRepository) FindInstanceByAggregateID(_ context.Context, _ store.Querier, sagaType string, aggregateID uuid.UUID) (*entity.SagaInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, instance := range r.instances {
+		if instance.SagaType == sagaType && instance.AggregateID == aggregateID {
+			return &instance, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeSagaRepository) FindStepsByInstanceID(_ context.Context, _ store.Querier, instanceID uuid.UUID) ([]*entity.SagaStep, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	steps := make([]*entity.SagaStep, 0)
+	for _, step := range r.steps {
+		if step.SagaInstanceID == instanceID {
+			steps = append(steps, &step)
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].StepOrder < steps[j].StepOrder })
+	return steps, nil
+}
+
+// FindManyResumable ignores the leases, like a saga leased by another process between the query and AcquireLease.
+func (r *fakeSagaRepository) FindManyResumable(_ context.Context, _ store.Querier, limit int) ([]*entity.SagaInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instances := make([]*entity.SagaInstance, 0)
+	for _, instance := range r.instances {
+		finished := instance.Status == enum.SagaStatusCompleted || instance.Status == enum.SagaStatusCompensated
+		due := (instance.Status == enum.SagaStatusRunning || instance.Status == enum.SagaStatusCompensating) &&
+			(!instance.NextRunAt.Valid || !instance.NextRunAt.Time.After(time.Now()))
+		if (due || (!finished && r.hasSignals(instance.ID))) && len(instances) < limit {
+			instances = append(instances, &instance)
+		}
+	}
+	return instances, nil
+}
+
+func (r *fakeSagaRepository) AcquireLease(_ context.Context, _ store.Querier, id uuid.UUID, _ time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.leases[id] {
+		return false, nil
+	}
+	r.leases[id] = true
+	return true, nil
+}
+
+func (r *fakeSagaRepository) ReleaseLease(_ context.Context, _ store.Querier, id uuid.UUID) error {
+	r.releaseLease(id)
+	return nil
+}
+
+func (r *fakeSagaRepository) UpdateInstance(_ context.Context, _ store.Querier, instance *entity.SagaInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.instances[instance.ID]; !ok {
+		return errors.New("saga instance not found")
+	}
+	r.instances[instance.ID] = *instance
+	return nil
+}
+
+func (r *fakeSagaRepository) UpdateStep(_ context.Context, _ store.Querier, step *entity.SagaStep) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.steps[step.ID]; !ok {
+		return errors.New("saga step not found")
+	}
+	r.steps[step.ID] = *step
+	return nil
+}
+
+func (r *fakeSagaRepository) InsertSignal(_ context.Context, _ store.Querier, signal *entity.SagaSignal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	signal.ID = int64(len(r.signals) + 1)
+	r.signals = append(r.signals, *signal)
+	return nil
+}
+
+func (r *fakeSagaRepository) TakeSignals(_ context.Context, _ store.Querier, instanceID uuid.UUID) ([]*entity.SagaSignal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taken := make([]*entity.SagaSignal, 0)
+	kept := r.signals[:0]
+	for _, signal := range r.signals {
+		if signal.SagaInstanceID == instanceID {
+			taken = append(taken, &signal)
+			continue
+		}
+		kept = append(kept, signal)
+	}
+	r.signals = kept
+	return taken, nil
+}
+
+func (r *fakeSagaRepository) hasSignals(instanceID uuid.UUID) bool {
+	for _, signal := range r.signals {
+		if signal.SagaInstanceID == instanceID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeSagaRepository) pendingSignals(instanceID uuid.UUID) []entity.SagaSignal {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	signals := make([]entity.SagaSignal, 0)
+	for _, signal := range r.signals {
+		if signal.SagaInstanceID == instanceID {
+			signals = append(signals, signal)
+		}
+	}
+	return signals
+}
+
+func (r *fakeSagaRepository) instance(id uuid.UUID) entity.SagaInstance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.instances[id]
+}
+
+func (r *fakeSagaRepository) step(instanceID uuid.UUID, name string) entity.SagaStep {
+	steps, _ := r.FindStepsByInstanceID(context.Background(), nil, instanceID)
+	for _, step := range steps {
+		if step.StepName == name {
+			return *step
+		}
+	}
+	return entity.SagaStep{}
+}
+
+func (r *fakeSagaRepository) stepStatuses(instanceID uuid.UUID) []enum.SagaStepStatus {
+	steps, _ := r.FindStepsByInstanceID(context.Background(), nil, instanceID)
+	statuses := make([]enum.SagaStepStatus, 0, len(steps))
+	for _, step := range steps {
+		statuses = append(statuses, step.Status)
+	}
+	return statuses
+}
+
+// makeDue moves the scheduled retry of a saga to now.
+func (r *fakeSagaRepository) makeDue(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance := r.instances[id]
+	instance.NextRunAt.Time = time.Now().Add(-time.Second)
+	r.instances[id] = instance
+
+	for stepID, step := range r.steps {
+		if step.SagaInstanceID == id && step.NextRetryAt.Valid {
+			step.NextRetryAt.Time = instance.NextRunAt.Time
+			r.steps[stepID] = step
+		}
+	}
+}
+
+// interrupt leaves a step RUNNING like an owner that stopped before it recorded an outcome.
+func (r *fakeSagaRepository) interrupt(id uuid.UUID, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance := r.instances[id]
+	instance.Status = enum.SagaStatusRunning
+	r.instances[id] = instance
+
+	for stepID, step := range r.steps {
+		if step.SagaInstanceID == id && step.StepName == name {
+			step.Status = enum.SagaStepStatusRunning
+			r.steps[stepID] = step
+		}
+	}
+}
+
+func (r *fakeSagaRepository) lease(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.leases[id] = true
+}
+
+func (r *fakeSagaRepository) releaseLease(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.leases, id)
+}
+
+func (r *fakeSagaRepository) isLeased(id uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leases[id]
+}
+
+// fakeDatabaseStore hands out transactions that commit nothing, the fake repository applies every write at once.
+type fakeDatabaseStore struct {
+	store.DatabaseStore
+}
+
+func (s *fakeDatabaseStore) Begin(context.Context) (store.Transaction, error) {
+	return &fakeTransaction{}, nil
+}
+
+type fakeTransaction struct {
+	store.Transaction
+}
+
+func (t *fakeTransaction) Commit(context.Context) error {
+	return nil
+}
+
+func (t *fakeTransaction) Rollback(context.Context) error {
+	return nil
+}
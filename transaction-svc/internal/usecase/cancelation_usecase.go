@@ -5,64 +5,94 @@ import (
 	"database/sql"
 	"errors"
 	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
 	"go-saga-pattern/commoner/constant/message"
 	"go-saga-pattern/commoner/helper"
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/transaction-svc/internal/adapter"
-	"go-saga-pattern/transaction-svc/internal/model/event"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/gateway/task"
+	"go-saga-pattern/transaction-svc/internal/model"
 	"go-saga-pattern/transaction-svc/internal/repository"
 	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
 	"go-saga-pattern/transaction-svc/internal/usecase/contract"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
 type cancelationUseCase struct {
 	db               store.DatabaseStore
 	transactionRepo  repository.TransactionRepository
-	messagingAdapter adapter.MessagingAdapter
+	outboxRepo       repository.OutboxRepository
+	sagaOrchestrator saga.Orchestrator
+	paymentProviders adapter.PaymentProviderRegistry
+	statusAdapter    adapter.TransactionStatusAdapter
+	expireTask       task.TransactionTask
 	logs             logs.Log
 }
 
-func NewCancelationUseCase(db store.DatabaseStore, transactionRepo repository.TransactionRepository, messagingAdapter adapter.MessagingAdapter,
-	logs logs.Log) contract.CancelationUseCase {
+func NewCancelationUseCase(db store.DatabaseStore, transactionRepo repository.TransactionRepository, outboxRepo repository.OutboxRepository,
+	sagaOrchestrator saga.Orchestrator, paymentProviders adapter.PaymentProviderRegistry, statusAdapter adapter.TransactionStatusAdapter,
+	expireTask task.TransactionTask, logs logs.Log) contract.CancelationUseCase {
 	return &cancelationUseCase{
 		db:               db,
 		transactionRepo:  transactionRepo,
-		messagingAdapter: messagingAdapter,
+		outboxRepo:       outboxRepo,
+		sagaOrchestrator: sagaOrchestrator,
+		paymentProviders: paymentProviders,
+		statusAdapter:    statusAdapter,
+		expireTask:       expireTask,
 		logs:             logs,
 	}
 }
 
 func (uc *cancelationUseCase) ExpirePendingTransaction(ctx context.Context, transactionId string) error {
+	var transition *entity.TransactionTransition
 	if err := store.BeginTransaction(ctx, uc.logs, uc.db, func(tx store.Transaction) error {
-		if err := uc.updateTransactionStatusIfPending(ctx, tx, transactionId, enum.TrxInternalStatusExpired); err != nil {
-
+		var err error
+		if transition, err = uc.transitionTransaction(ctx, tx, transactionId, enum.TrxInternalStatusExpired); err != nil {
+			var invalidTransition *entity.InvalidTransitionError
+			if errors.As(err, &invalidTransition) {
+				return err
+			}
 			return helper.WrapInternalServerError(uc.logs, "expire failed", err)
 		}
 		return nil
 	}); err != nil {
 		uc.logs.Error("failed to expired pending transaction", zap.String("transactionId", transactionId), zap.Error(err))
+		return nil
 	}
 
 	uc.logs.Info("success expired pending transaction", zap.String("transactionId", transactionId))
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.logs, uuid.MustParse(transactionId), transition)
 
 	return nil
 }
 
 func (uc *cancelationUseCase) ExpireFinalTransaction(ctx context.Context, transactionId string) error {
+	var invalidTransition *entity.InvalidTransitionError
+	var transition *entity.TransactionTransition
 	if err := store.BeginTransaction(ctx, uc.logs, uc.db, func(tx store.Transaction) error {
-		if err := uc.updateTransactionStatusIfPending(ctx, tx, transactionId, enum.TrxInternalStatusExpiredCheckedInvalid); err != nil {
-			if strings.Contains(err.Error(), message.TransactionIsNotExpirable) {
+		var err error
+		transition, err = uc.transitionTransaction(ctx, tx, transactionId, enum.TrxInternalStatusExpiredCheckedInvalid)
+		if err != nil {
+			if errors.As(err, &invalidTransition) {
 				return err
 			}
 			return helper.WrapInternalServerError(uc.logs, "expire failed", err)
 		}
+
+		if err := insertTransactionOutbox(ctx, tx, uc.outboxRepo, transition.Event.Subject, uuid.MustParse(transactionId), transition.Event.Status); err != nil {
+			return helper.WrapInternalServerError(uc.logs, "failed to insert transaction expired outbox event", err)
+		}
 		return nil
 	}); err != nil {
-		if strings.Contains(err.Error(), message.TransactionIsNotExpirable) {
+		if errors.As(err, &invalidTransition) {
 			return nil
 		}
 		uc.logs.Error("failed to expired final transaction", zap.String("transactionId", transactionId), zap.Error(err))
@@ -70,76 +100,129 @@ func (uc *cancelationUseCase) ExpireFinalTransaction(ctx context.Context, transa
 	}
 
 	uc.logs.Info("success expired final transaction", zap.String("transactionId", transactionId))
-	event := &event.TransactionEvent{
-		TransactionID: transactionId,
-		Status:        enum.TransactionEventExpired,
-	}
-
-	if err := uc.messagingAdapter.Publish(ctx, "transaction.expired", event); err != nil {
-		return helper.WrapInternalServerError(uc.logs, "failed to publish transaction expired event", err)
-	}
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.logs, uuid.MustParse(transactionId), transition)
+	signalCheckoutPayment(ctx, uc.sagaOrchestrator, uc.logs, uuid.MustParse(transactionId), errors.New("payment expired"))
 
 	return nil
 }
 
 func (uc *cancelationUseCase) CancelPendingTransaction(ctx context.Context, transactionId string) error {
+	var invalidTransition *entity.InvalidTransitionError
+	var transition *entity.TransactionTransition
 	if err := store.BeginTransaction(ctx, uc.logs, uc.db, func(tx store.Transaction) error {
-		if err := uc.updateTransactionStatusIfPending(ctx, tx, transactionId, enum.TrxInternalStatusCancelledByUser); err != nil {
-			if strings.Contains(err.Error(), message.TransactionIsNotExpirable) {
+		var err error
+		transition, err = uc.transitionTransaction(ctx, tx, transactionId, enum.TrxInternalStatusCancelledByUser)
+		if err != nil {
+			if errors.As(err, &invalidTransition) {
 				return err
 			}
 			return helper.WrapInternalServerError(uc.logs, "cancel failed", err)
 		}
+
+		if err := insertTransactionOutbox(ctx, tx, uc.outboxRepo, transition.Event.Subject, uuid.MustParse(transactionId), transition.Event.Status); err != nil {
+			return helper.WrapInternalServerError(uc.logs, "failed to insert transaction canceled outbox event", err)
+		}
 		return nil
 	}); err != nil {
-		if strings.Contains(err.Error(), message.TransactionIsNotExpirable) {
+		if errors.As(err, &invalidTransition) {
 			return err
 		}
 		uc.logs.Error("failed to cancel pending transaction", zap.String("transactionId", transactionId), zap.Error(err))
 		return helper.WrapInternalServerError(uc.logs, "cancel pending transaction failed", err)
 	}
 	uc.logs.Info("success cancelled pending transaction", zap.String("transactionId", transactionId))
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.logs, uuid.MustParse(transactionId), transition)
+	signalCheckoutPayment(ctx, uc.sagaOrchestrator, uc.logs, uuid.MustParse(transactionId), errors.New("canceled by user"))
 	return nil
 }
 
-func (uc *cancelationUseCase) updateTransactionStatusIfPending(ctx context.Context, tx store.Querier, transactionId string, status enum.TrxInternalStatus) error {
-	transaction, err := uc.transactionRepo.FindByID(ctx, tx, transactionId, true)
+// UserCancelTransaction cancels a transaction of the requesting user that is still waiting for payment. The
+// cancellation is committed first, closing the payment at the gateway and dropping the expire tasks is best effort:
+// a payment that still gets through is recorded as a late settlement and refunded automatically.
+func (uc *cancelationUseCase) UserCancelTransaction(ctx context.Context, request *model.CancelTransactionRequest) error {
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.db, request.TransactionID.String(), false)
 	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+		}
+		return helper.WrapInternalServerError(uc.logs, "failed to find transaction by id", err)
+	}
+
+	if transaction.UserID != request.UserID {
+		return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+	}
+
+	if _, err := entity.NewTransactionTransition(transaction.InternalStatus, enum.TrxInternalStatusCancelledByUser); err != nil {
+		return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.TransactionIsNotCancelable)
+	}
+
+	if err := uc.CancelPendingTransaction(ctx, transaction.ID.String()); err != nil {
+		var invalidTransition *entity.InvalidTransitionError
+		if errors.As(err, &invalidTransition) {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.TransactionIsNotCancelable)
+		}
 		return err
 	}
 
-	//Make sure that only pending or token ready or expired transactions can be cancelled
-	if transaction.InternalStatus != enum.TrxInternalStatusPending ||
-		transaction.InternalStatus != enum.TrxInternalStatusTokenReady ||
-		transaction.InternalStatus != enum.TrxInternalStatusExpired {
-		uc.logs.Warn(message.TransactionIsNotExpirable, zap.String("transactionId", transactionId), zap.String("internalStatus", string(transaction.InternalStatus)))
-		return errors.New(message.TransactionIsNotExpirable)
+	uc.cancelPayment(ctx, transaction)
+
+	if err := uc.expireTask.DeleteTransactionExpireTasks(transaction.ID); err != nil {
+		uc.logs.Warn("failed to delete transaction expire tasks", zap.String("transactionId", transaction.ID.String()), zap.Error(err))
 	}
 
-	now := time.Now()
-	switch status {
-	case enum.TrxInternalStatusExpired:
-		transaction.TransactionStatus = enum.TransactionStatusExpired
-		transaction.InternalStatus = enum.TrxInternalStatusExpired
-	case enum.TrxInternalStatusExpiredCheckedInvalid:
-		transaction.TransactionStatus = enum.TransactionStatusExpired
-		transaction.InternalStatus = enum.TrxInternalStatusExpiredCheckedInvalid
-	default:
-		transaction.TransactionStatus = enum.TransactionStatusCancelled
-		transaction.InternalStatus = enum.TrxInternalStatusCancelledByUser
+	return nil
+}
+
+// cancelPayment closes the payment at the gateway, a transaction without a payment token has nothing to close.
+func (uc *cancelationUseCase) cancelPayment(ctx context.Context, transaction *entity.Transaction) {
+	if transaction.InternalStatus == enum.TrxInternalStatusPending {
+		return
+	}
+
+	paymentProvider, err := uc.paymentProviders.Get(transaction.PaymentProvider)
+	if err != nil {
+		uc.logs.Warn("failed to cancel payment, unknown payment provider", zap.String("transactionId", transaction.ID.String()), zap.Error(err))
+		return
 	}
+
+	if err := paymentProvider.Cancel(ctx, &model.CancelChargeRequest{
+		OrderID:   transaction.ID.String(),
+		Reference: transaction.PaymentReference.String,
+	}); err != nil {
+		uc.logs.Warn("failed to cancel payment at payment provider", zap.String("transactionId", transaction.ID.String()),
+			zap.String("payment_provider", string(transaction.PaymentProvider)), zap.Error(err))
+	}
+}
+
+// transitionTransaction moves a transaction to the given status through the transition table,
+// an *entity.InvalidTransitionError is returned when the current status does not allow it.
+func (uc *cancelationUseCase) transitionTransaction(ctx context.Context, tx store.Querier, transactionId string,
+	status enum.TrxInternalStatus) (*entity.TransactionTransition, error) {
+	transaction, err := uc.transactionRepo.FindByID(ctx, tx, transactionId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	transition, err := transaction.TransitionTo(status)
+	if err != nil {
+		uc.logs.Warn(message.TransactionIsNotExpirable, zap.String("transactionId", transactionId),
+			zap.String("internalStatus", string(transaction.InternalStatus)), zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
 	transaction.SnapToken = sql.NullString{Valid: true, String: ""}
 	transaction.UpdatedAt = &now
 
-	if err := uc.transactionRepo.UpdateStatus(ctx, tx, transaction); err != nil {
+	if err := uc.transactionRepo.UpdateStatus(ctx, tx, transaction, transition); err != nil {
 		if strings.Contains(err.Error(), message.InternalNoRowsAffected) {
 			uc.logs.Error("[LOGIC UPDATE FAILED] no rows affected when updating transaction status", zap.String("transactionId", transactionId), zap.Error(err))
-			return errors.New(message.InternalNoRowsAffected)
+			return nil, errors.New(message.InternalNoRowsAffected)
 		}
 		uc.logs.Error("failed to update transaction status", zap.String("transactionId", transactionId), zap.Error(err))
 
-		return err
+		return nil, err
 	}
 
-	return nil
+	return transition, nil
 }
@@ -0,0 +1,487 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/model/converter"
+	"go-saga-pattern/transaction-svc/internal/repository"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// cartItemsCacheTTL bounds how long the cached items of an idle cart stay in redis, every change drops them
+	cartItemsCacheTTL = time.Hour
+	// guestCartTTL is how long a guest cart is kept after its last change
+	guestCartTTL = 30 * 24 * time.Hour
+)
+
+type cartUseCase struct {
+	databaseStore      store.DatabaseStore
+	cartRepo           repository.CartRepository
+	productAdapter     adapter.ProductAdapter
+	cacheAdapter       adapter.CacheAdapter
+	transactionUseCase contract.TransactionUseCase
+	validator          helper.CustomValidator
+	log                logs.Log
+}
+
+func NewCartUseCase(databaseStore store.DatabaseStore, cartRepo repository.CartRepository, productAdapter adapter.ProductAdapter,
+	cacheAdapter adapter.CacheAdapter, transactionUseCase contract.TransactionUseCase, validator helper.CustomValidator,
+	log logs.Log) contract.CartUseCase {
+	return &cartUseCase{
+		databaseStore:      databaseStore,
+		cartRepo:           cartRepo,
+		productAdapter:     productAdapter,
+		cacheAdapter:       cacheAdapter,
+		transactionUseCase: transactionUseCase,
+		validator:          validator,
+		log:                log,
+	}
+}
+
+func (uc *cartUseCase) Get(ctx context.Context, request *model.GetCartRequest) (*model.CartResponse, error) {
+	cart, err := uc.resolveCart(ctx, uc.databaseStore, request.CartOwner, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.cartResponse(ctx, cart)
+}
+
+// AddItem adds the quantity to the product or variant already in the cart, a guest without a cart gets a new one.
+func (uc *cartUseCase) AddItem(ctx context.Context, request *model.AddCartItemRequest) (*model.CartResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	var cart *entity.Cart
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		var err error
+		cart, err = uc.resolveCart(ctx, tx, request.CartOwner, true)
+		if err != nil {
+			return err
+		}
+
+		items, err := uc.cartRepo.FindItemsByCartID(ctx, tx, cart.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find cart items", err)
+		}
+
+		quantity := request.Quantity
+		for _, item := range items {
+			if item.ProductID == request.ProductID && item.VariantID == request.VariantID {
+				quantity += item.Quantity
+			}
+		}
+
+		if err := uc.checkStock(ctx, request.ProductID, request.VariantID, quantity); err != nil {
+			return err
+		}
+
+		if err := uc.cartRepo.AddItems(ctx, tx, cart.ID, []*entity.CartItem{{
+			ProductID: request.ProductID,
+			VariantID: request.VariantID,
+			Quantity:  request.Quantity,
+		}}); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to add cart item", err)
+		}
+
+		return uc.touch(ctx, tx, cart)
+	}); err != nil {
+		return nil, err
+	}
+
+	uc.invalidate(ctx, cart.ID)
+	return uc.cartResponse(ctx, cart)
+}
+
+func (uc *cartUseCase) UpdateItem(ctx context.Context, request *model.UpdateCartItemRequest) (*model.CartResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	if request.Quantity == 0 {
+		return uc.RemoveItem(ctx, &model.RemoveCartItemRequest{
+			CartOwner: request.CartOwner,
+			ProductID: request.ProductID,
+			VariantID: request.VariantID,
+		})
+	}
+
+	var cart *entity.Cart
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		var err error
+		cart, err = uc.resolveCart(ctx, tx, request.CartOwner, false)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.checkStock(ctx, request.ProductID, request.VariantID, request.Quantity); err != nil {
+			return err
+		}
+
+		if err := uc.cartRepo.SetItemQuantity(ctx, tx, &entity.CartItem{
+			CartID:    cart.ID,
+			ProductID: request.ProductID,
+			VariantID: request.VariantID,
+			Quantity:  request.Quantity,
+		}); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update cart item", err)
+		}
+
+		return uc.touch(ctx, tx, cart)
+	}); err != nil {
+		return nil, err
+	}
+
+	uc.invalidate(ctx, cart.ID)
+	return uc.cartResponse(ctx, cart)
+}
+
+func (uc *cartUseCase) RemoveItem(ctx context.Context, request *model.RemoveCartItemRequest) (*model.CartResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	var cart *entity.Cart
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		var err error
+		cart, err = uc.resolveCart(ctx, tx, request.CartOwner, false)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := uc.cartRepo.DeleteItem(ctx, tx, cart.ID, request.ProductID, request.VariantID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to delete cart item", err)
+		}
+
+		if !deleted {
+			return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CartItemNotFound)
+		}
+
+		return uc.touch(ctx, tx, cart)
+	}); err != nil {
+		return nil, err
+	}
+
+	uc.invalidate(ctx, cart.ID)
+	return uc.cartResponse(ctx, cart)
+}
+
+// Merge moves the items of a guest cart into the user's cart after login, quantities of the same product and variant
+// are added up and the guest cart is deleted.
+func (uc *cartUseCase) Merge(ctx context.Context, request *model.MergeCartRequest) (*model.CartResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	var cart *entity.Cart
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		guestCart, err := uc.cartRepo.FindByID(ctx, tx, request.GuestCartID, true)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CartNotFound)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find guest cart", err)
+		}
+
+		if guestCart.UserID.Valid {
+			return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CartNotFound)
+		}
+
+		cart, err = uc.cartRepo.FindOrInsertByUserID(ctx, tx, request.UserID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find user cart", err)
+		}
+
+		items, err := uc.cartRepo.DeleteItems(ctx, tx, guestCart.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to empty guest cart", err)
+		}
+
+		if len(items) > 0 {
+			if err := uc.cartRepo.AddItems(ctx, tx, cart.ID, entity.MergeCartItems(items)); err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to merge cart items", err)
+			}
+		}
+
+		if err := uc.cartRepo.Delete(ctx, tx, guestCart.ID); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to delete guest cart", err)
+		}
+
+		return uc.touch(ctx, tx, cart)
+	}); err != nil {
+		return nil, err
+	}
+
+	uc.invalidate(ctx, request.GuestCartID, cart.ID)
+	uc.log.Info("Guest cart merged", zap.String("guest_cart_id", request.GuestCartID.String()), zap.String("cart_id", cart.ID.String()),
+		zap.String("user_id", request.UserID.String()))
+	return uc.cartResponse(ctx, cart)
+}
+
+// Checkout empties the cart into a new transaction priced by product-svc, the items are put back when the checkout
+// fails. Emptying the cart first keeps a double submit from checking out the same items twice.
+func (uc *cartUseCase) Checkout(ctx context.Context, request *model.CheckoutCartRequest) (*model.CreateTransactionResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	var cart *entity.Cart
+	var items []*entity.CartItem
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		var err error
+		cart, err = uc.cartRepo.FindByUserID(ctx, tx, request.UserID, true)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CartIsEmpty)
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find user cart", err)
+		}
+
+		items, err = uc.cartRepo.DeleteItems(ctx, tx, cart.ID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to empty cart", err)
+		}
+
+		if len(items) == 0 {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CartIsEmpty)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, cart.ID)
+
+	response, err := uc.checkout(ctx, request, items)
+	if err != nil {
+		uc.restoreItems(ctx, cart.ID, items)
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// checkout converts the claimed items into an order, product-svc prices it when the stock is reserved.
+func (uc *cartUseCase) checkout(ctx context.Context, request *model.CheckoutCartRequest, items []*entity.CartItem) (*model.CreateTransactionResponse, error) {
+	transactionProducts := make([]model.TransactionProduct, 0, len(items))
+	for _, item := range items {
+		transactionProducts = append(transactionProducts, model.TransactionProduct{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return uc.transactionUseCase.CreateTransaction(ctx, &model.CreateTransactionRequest{
+		UserID:          request.UserID,
+		Products:        transactionProducts,
+		PaymentProvider: request.PaymentProvider,
+		ExpectedTotal:   request.ExpectedTotal,
+		Currency:        request.Currency,
+		PromoCodes:      request.PromoCodes,
+	})
+}
+
+// restoreItems puts the items of a failed checkout back, on top of whatever was added to the cart meanwhile.
+func (uc *cartUseCase) restoreItems(ctx context.Context, cartID uuid.UUID, items []*entity.CartItem) {
+	if err := uc.cartRepo.AddItems(context.WithoutCancel(ctx), uc.databaseStore, cartID, entity.MergeCartItems(items)); err != nil {
+		uc.log.Error("failed to restore cart items after failed checkout", zap.String("cart_id", cartID.String()), zap.Error(err))
+		return
+	}
+	uc.invalidate(ctx, cartID)
+}
+
+func (uc *cartUseCase) DeleteStaleGuestCarts(ctx context.Context) error {
+	deleted, err := uc.cartRepo.DeleteGuestCartsUpdatedBefore(ctx, uc.databaseStore, time.Now().Add(-guestCartTTL))
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to delete stale guest carts", err)
+	}
+
+	if deleted > 0 {
+		uc.log.Info("Stale guest carts deleted", zap.Int64("deleted", deleted))
+	}
+	return nil
+}
+
+// resolveCart finds the cart of the owner. A user always has a cart, it is created on first use, a guest without a
+// cart id only gets one when createGuest is set. A guest can never reach a cart that belongs to a user.
+func (uc *cartUseCase) resolveCart(ctx context.Context, db store.Querier, owner model.CartOwner, createGuest bool) (*entity.Cart, error) {
+	if owner.UserID != uuid.Nil {
+		cart, err := uc.cartRepo.FindOrInsertByUserID(ctx, db, owner.UserID)
+		if err != nil {
+			return nil, helper.WrapInternalServerError(uc.log, "failed to find user cart", err)
+		}
+		return cart, nil
+	}
+
+	if owner.CartID == uuid.Nil {
+		if !createGuest {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CartNotFound)
+		}
+
+		cart, err := uc.cartRepo.Insert(ctx, db, &entity.Cart{ID: uuid.New()})
+		if err != nil {
+			return nil, helper.WrapInternalServerError(uc.log, "failed to insert guest cart", err)
+		}
+		return cart, nil
+	}
+
+	cart, err := uc.cartRepo.FindByID(ctx, db, owner.CartID, false)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CartNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find cart", err)
+	}
+
+	if cart.UserID.Valid {
+		return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CartNotFound)
+	}
+
+	return cart, nil
+}
+
+// checkStock checks the stock of the variant when the product has variants, and the stock of the product otherwise.
+func (uc *cartUseCase) checkStock(ctx context.Context, productID uuid.UUID, variantID uuid.NullUUID, quantity int) error {
+	products, err := uc.productAdapter.GetProducts(ctx, []uuid.UUID{productID})
+	if err != nil {
+		return err
+	}
+
+	if len(products) == 0 {
+		return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.CartProductNotFound)
+	}
+
+	available := products[0].Quantity
+	variant, ok := converter.CartItemVariant(&entity.CartItem{ProductID: productID, VariantID: variantID}, products[0])
+	if !ok {
+		if !variantID.Valid {
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.ProductVariantRequired)
+		}
+		return helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.ProductVariantNotFound)
+	}
+
+	if variant != nil {
+		available = variant.Quantity
+	}
+
+	if available < quantity {
+		return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CartQuantityExceedsStock)
+	}
+
+	return nil
+}
+
+func (uc *cartUseCase) touch(ctx context.Context, db store.Querier, cart *entity.Cart) error {
+	if err := uc.cartRepo.Touch(ctx, db, cart.ID); err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to update cart", err)
+	}
+
+	now := time.Now()
+	cart.UpdatedAt = &now
+	return nil
+}
+
+func (uc *cartUseCase) cartResponse(ctx context.Context, cart *entity.Cart) (*model.CartResponse, error) {
+	items, err := uc.findItems(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := uc.findProducts(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.CartToResponse(cart, items, products), nil
+}
+
+func (uc *cartUseCase) findProducts(ctx context.Context, items []*entity.CartItem) (map[string]*model.ProductResponse, error) {
+	products := make(map[string]*model.ProductResponse, len(items))
+	if len(items) == 0 {
+		return products, nil
+	}
+
+	// the variants of a product share it, so it is only looked up once
+	productIDs := make([]uuid.UUID, 0, len(items))
+	seen := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
+
+	productResponses, err := uc.productAdapter.GetProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, product := range productResponses {
+		products[product.ID] = product
+	}
+	return products, nil
+}
+
+// findItems reads the items through the redis cache, a redis failure falls back to postgres.
+func (uc *cartUseCase) findItems(ctx context.Context, cartID uuid.UUID) ([]*entity.CartItem, error) {
+	cacheKey := cartItemsCacheKey(cartID)
+	cached, err := uc.cacheAdapter.Get(ctx, cacheKey)
+	if err == nil {
+		var items []*entity.CartItem
+		if err := sonic.ConfigFastest.UnmarshalFromString(cached, &items); err == nil {
+			return items, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		uc.log.Warn("failed to get cached cart items", zap.String("cart_id", cartID.String()), zap.Error(err))
+	}
+
+	items, err := uc.cartRepo.FindItemsByCartID(ctx, uc.databaseStore, cartID)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find cart items", err)
+	}
+
+	payload, err := sonic.ConfigFastest.Marshal(items)
+	if err == nil {
+		err = uc.cacheAdapter.SetEx(ctx, cacheKey, payload, cartItemsCacheTTL)
+	}
+	if err != nil {
+		uc.log.Warn("failed to cache cart items", zap.String("cart_id", cartID.String()), zap.Error(err))
+	}
+
+	return items, nil
+}
+
+func (uc *cartUseCase) invalidate(ctx context.Context, cartIDs ...uuid.UUID) {
+	cacheKeys := make([]string, 0, len(cartIDs))
+	for _, cartID := range cartIDs {
+		cacheKeys = append(cacheKeys, cartItemsCacheKey(cartID))
+	}
+
+	if err := uc.cacheAdapter.Del(context.WithoutCancel(ctx), cacheKeys...); err != nil {
+		uc.log.Warn("failed to drop cached cart items", zap.Strings("cache_keys", cacheKeys), zap.Error(err))
+	}
+}
+
+func cartItemsCacheKey(cartID uuid.UUID) string {
+	return fmt.Sprintf("cart:items:%s", cartID)
+}
@@ -0,0 +1,859 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/gateway/task"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/pricing"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	checkoutSagaType = "CHECKOUT"
+
+	checkoutStepReserveStock          = "RESERVE_STOCK"
+	checkoutStepApplyPromotions       = "APPLY_PROMOTIONS"
+	checkoutStepPersistTransaction    = "PERSIST_TRANSACTION"
+	checkoutStepObtainPaymentToken    = "OBTAIN_PAYMENT_TOKEN"
+	checkoutStepAwaitPayment          = "AWAIT_PAYMENT"
+	checkoutStepSettle                = "SETTLE"
+	checkoutPaymentTokenMaxAttempts   = 3
+	checkoutSettleTransactionAttempts = 3
+)
+
+// checkoutSagaDefinition declares the checkout flow, reserving stock and persisting the transaction are only
+// tried once because the buyer is waiting for the answer, the payment token is retried in the background by the
+// payment token task.
+func (uc *transactionUseCase) checkoutSagaDefinition() *saga.Definition {
+	return &saga.Definition{
+		Type: checkoutSagaType,
+		Steps: []*saga.Step{
+			{Name: checkoutStepReserveStock, Action: uc.reserveStock, Compensate: uc.releaseStock},
+			{Name: checkoutStepApplyPromotions, Action: uc.applyPromotions, Compensate: uc.releasePromotions},
+			{Name: checkoutStepPersistTransaction, Action: uc.persistTransaction, Compensate: uc.cancelTransaction},
+			{Name: checkoutStepObtainPaymentToken, Action: uc.obtainPaymentToken, MaxAttempts: checkoutPaymentTokenMaxAttempts},
+			{Name: checkoutStepAwaitPayment, Action: uc.awaitPayment},
+			{Name: checkoutStepSettle, Action: uc.settleTransaction, MaxAttempts: checkoutSettleTransactionAttempts},
+		},
+	}
+}
+
+func (uc *transactionUseCase) reserveStock(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.CheckoutSagaData)
+	if err := instance.Bind(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	productReqs := make([]*model.CheckProductQuantity, 0, len(data.Products))
+	for _, productReq := range data.Products {
+		productReqs = append(productReqs, &model.CheckProductQuantity{
+			ProductID: productReq.ProductID,
+			VariantID: productReq.VariantID,
+			Quantity:  productReq.Quantity,
+		})
+	}
+
+	products, err := uc.productAdapter.CheckProductAndReserve(ctx, data.TransactionID, productReqs)
+	if err != nil {
+		uc.log.Warn("failed to check product and reserve", zap.Error(err), zap.String("transaction_id", data.TransactionID.String()))
+
+		// A business rejection from product-svc means nothing was reserved, anything else may have reserved the stock
+		var appErr *helper.AppError
+		if errors.As(err, &appErr) && appErr.Code != errorcode.ErrInternal {
+			return saga.Permanent(err)
+		}
+		return err
+	}
+
+	// The prices product-svc reserved the stock at replace whatever the client sent, persistTransaction checks them
+	// a product is reserved once per variant, the variant prices the line
+	reserved := make(map[string]*model.ProductResponse, len(products))
+	for _, product := range products {
+		reserved[reservedLineKey(product.ID, product.VariantID)] = product
+	}
+	for i := range data.Products {
+		data.Products[i].Price = money.Money{}
+		data.Products[i].OwnerID = uuid.Nil
+		data.Products[i].Category = ""
+		data.Products[i].SKU = ""
+		lineKey := reservedLineKey(data.Products[i].ProductID.String(), nullable.UUIDtoString(data.Products[i].VariantID))
+		if product, ok := reserved[lineKey]; ok {
+			data.Products[i].Price = product.Price
+			data.Products[i].OwnerID, _ = uuid.Parse(product.UserID)
+			data.Products[i].Category = product.Category
+			data.Products[i].SKU = product.SKU
+		}
+	}
+
+	return instance.Store(data)
+}
+
+// releaseStock asks product-svc to restore the reserved quantity when the transaction was never persisted,
+// otherwise cancelTransaction or the payment flow already published the event that releases it.
+func (uc *transactionUseCase) releaseStock(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.CheckoutSagaData)
+	if err := instance.Bind(data); err != nil {
+		return err
+	}
+
+	if _, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, data.TransactionID.String(), false); err == nil {
+		return nil
+	} else if !strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+		return err
+	}
+
+	return insertTransactionOutbox(ctx, uc.databaseStore, uc.outboxRepo, "transaction.canceled", data.TransactionID, enum.TransactionEventCancelled)
+}
+
+// applyPromotions takes the promo codes off the reserved products in the order they were sent, every promotion
+// discounts what the earlier ones left. A usage of each promotion is reserved against its usage limits until the
+// checkout settles or is compensated.
+func (uc *transactionUseCase) applyPromotions(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.CheckoutSagaData)
+	if err := instance.Bind(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	if len(data.PromoCodes) == 0 {
+		return nil
+	}
+
+	// The discounts are taken off the prices in the checkout currency
+	if err := uc.settleCheckoutCurrency(ctx, data); err != nil {
+		return err
+	}
+
+	lines := make([]*entity.PromotionLine, 0, len(data.Products))
+	for _, product := range data.Products {
+		linePrice, err := product.Price.Mul(int64(product.Quantity))
+		if err != nil {
+			return saga.Permanent(err)
+		}
+
+		lines = append(lines, &entity.PromotionLine{
+			ProductID: product.ProductID,
+			VariantID: product.VariantID,
+			OwnerID:   product.OwnerID,
+			Quantity:  product.Quantity,
+			Price:     linePrice,
+			Discount:  money.Zero(data.Currency),
+		})
+	}
+
+	var discounts []model.CheckoutDiscount
+	now := time.Now()
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		discounts = make([]model.CheckoutDiscount, 0, len(data.PromoCodes))
+		for _, code := range normalizePromoCodes(data.PromoCodes) {
+			promotion, err := uc.promotionRepo.FindByCode(ctx, tx, code, true)
+			if err != nil {
+				if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+					return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+						fmt.Sprintf("%s: %s", message.PromotionNotFound, code)))
+				}
+				return helper.WrapInternalServerError(uc.log, "failed to find promotion by code", err)
+			}
+
+			if !promotion.IsRedeemableAt(now) {
+				return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+					fmt.Sprintf("%s: %s", message.PromotionNotActive, code)))
+			}
+
+			lineDiscounts, err := promotion.Discounts(lines)
+			if err != nil {
+				if errors.Is(err, money.ErrCurrencyMismatch) {
+					return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+						fmt.Sprintf("%s: %s is in %s, checkout is in %s", message.PromotionCurrencyMismatch, code, promotion.Currency, data.Currency)))
+				}
+				return saga.Permanent(err)
+			}
+
+			applied := false
+			for i, lineDiscount := range lineDiscounts {
+				// every discount is rounded on its own so the discounts of a line add up to what the pipeline charges
+				left, err := lines[i].Price.Sub(lines[i].Discount)
+				if err != nil {
+					return saga.Permanent(err)
+				}
+
+				if lineDiscount, err = pricing.RoundDiscount(lineDiscount, left); err != nil {
+					return saga.Permanent(err)
+				}
+
+				if !lineDiscount.IsPositive() {
+					continue
+				}
+
+				if lines[i].Discount, err = lines[i].Discount.Add(lineDiscount); err != nil {
+					return saga.Permanent(err)
+				}
+
+				discounts = append(discounts, model.CheckoutDiscount{
+					PromotionID: promotion.ID,
+					Code:        promotion.Code,
+					ProductID:   lines[i].ProductID,
+					VariantID:   lines[i].VariantID,
+					Amount:      lineDiscount,
+				})
+				applied = true
+			}
+
+			if !applied {
+				return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+					fmt.Sprintf("%s: %s", message.PromotionNotApplicable, code)))
+			}
+
+			if err := uc.reservePromotionUsage(ctx, tx, promotion, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	data.Discounts = discounts
+	return instance.Store(data)
+}
+
+// reservePromotionUsage counts the checkout against the usage limits of the promotion, once per transaction so a
+// retried step does not count it twice.
+func (uc *transactionUseCase) reservePromotionUsage(ctx context.Context, tx store.Transaction, promotion *entity.Promotion, data *model.CheckoutSagaData) error {
+	reserved, err := uc.promotionRepo.InsertUsage(ctx, tx, &entity.PromotionUsage{
+		PromotionID:   promotion.ID,
+		TransactionID: data.TransactionID,
+		UserID:        data.UserID,
+		Status:        enum.PromotionUsageStatusReserved,
+	})
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to insert promotion usage", err)
+	}
+
+	if !reserved {
+		return nil
+	}
+
+	// The usage just inserted is part of the count
+	if promotion.UsageLimitPerUser.Valid {
+		count, err := uc.promotionRepo.CountUserUsages(ctx, tx, promotion.ID, data.UserID)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to count promotion usages", err)
+		}
+
+		if count > promotion.UsageLimitPerUser.Int64 {
+			return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+				fmt.Sprintf("%s: %s", message.PromotionUsageLimitReached, promotion.Code)))
+		}
+	}
+
+	incremented, err := uc.promotionRepo.IncrementUsageCount(ctx, tx, promotion.ID)
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to increment promotion usage count", err)
+	}
+
+	if !incremented {
+		return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+			fmt.Sprintf("%s: %s", message.PromotionUsageLimitReached, promotion.Code)))
+	}
+
+	return nil
+}
+
+// releasePromotions gives the reserved usages back to the usage limits when the checkout is canceled, expires or
+// fails. Usages of a settled checkout were redeemed and stay counted.
+func (uc *transactionUseCase) releasePromotions(ctx context.Context, instance *saga.Instance) error {
+	released, err := uc.promotionRepo.ReleaseUsages(ctx, uc.databaseStore, instance.AggregateID)
+	if err != nil {
+		return err
+	}
+
+	if released > 0 {
+		uc.log.Info("promotion usages released", zap.String("transaction_id", instance.AggregateID.String()), zap.Int64("released", released))
+	}
+
+	return nil
+}
+
+// priceCheckout runs the products of the checkout, with what the promo codes took off them, through the pricing
+// pipeline.
+func (uc *transactionUseCase) priceCheckout(ctx context.Context, data *model.CheckoutSagaData, paymentProvider enum.PaymentProvider) (*pricing.Breakdown, error) {
+	productDiscounts, err := checkoutDiscounts(data.Currency, data.Discounts)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]*pricing.Line, 0, len(data.Products))
+	for _, product := range data.Products {
+		linePrice, err := product.Price.Mul(int64(product.Quantity))
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, &pricing.Line{
+			ProductID: product.ProductID,
+			VariantID: product.VariantID,
+			Category:  product.Category,
+			Quantity:  product.Quantity,
+			Price:     linePrice,
+			Discount:  productDiscounts[checkoutLine{product.ProductID, product.VariantID}],
+		})
+	}
+
+	breakdown := pricing.NewBreakdown(data.Currency, paymentProvider, lines)
+	if err := uc.pricingPipeline.Price(ctx, breakdown); err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+func (uc *transactionUseCase) persistTransaction(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.CheckoutSagaData)
+	if err := instance.Bind(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	paymentProvider := data.PaymentProvider
+	if paymentProvider == "" {
+		paymentProvider = uc.paymentProviders.Default().Name()
+	}
+
+	for _, product := range data.Products {
+		if !product.Price.IsPositive() {
+			return saga.Permanent(fmt.Errorf("product %s was not priced by product-svc", product.ProductID))
+		}
+	}
+
+	// A rejected currency or total fails the step, the reserve stock compensation releases the stock again
+	if err := uc.settleCheckoutCurrency(ctx, data); err != nil {
+		return err
+	}
+
+	breakdown, err := uc.priceCheckout(ctx, data, paymentProvider)
+	if err != nil {
+		return saga.Permanent(err)
+	}
+
+	// The fees alone are not worth charging for an order the promo codes made free
+	if discounted, err := breakdown.Subtotal.Sub(breakdown.Discount); err != nil || !discounted.IsPositive() {
+		return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.DiscountedTotalNotPayable))
+	}
+
+	totalPrice := breakdown.GrandTotal
+
+	if !data.ExpectedTotal.IsZero() && !acceptsCheckoutTotal(uc.priceTolerance, data.ExpectedTotal, totalPrice) {
+		return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+			fmt.Sprintf("%s: expected total %s, current total %s", message.PriceChanged, data.ExpectedTotal, totalPrice)))
+	}
+
+	data.TotalPrice = totalPrice
+	data.Discount = breakdown.Discount
+	data.Breakdown = checkoutPriceBreakdown(breakdown)
+	if err := instance.Store(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	var exchangeRates *json.RawMessage
+	if len(data.ExchangeRates) > 0 {
+		snapshot, err := sonic.ConfigFastest.Marshal(data.ExchangeRates)
+		if err != nil {
+			return saga.Permanent(err)
+		}
+		exchangeRates = (*json.RawMessage)(&snapshot)
+	}
+
+	return store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		transaction, err := uc.transactionRepo.Insert(ctx, tx, &entity.Transaction{
+			ID:                data.TransactionID,
+			UserID:            data.UserID,
+			TotalPrice:        totalPrice,
+			Subtotal:          breakdown.Subtotal,
+			Discount:          breakdown.Discount,
+			Tax:               breakdown.Tax,
+			ServiceFee:        breakdown.ServiceFee,
+			PaymentFee:        breakdown.PaymentFee,
+			Currency:          totalPrice.Currency,
+			ExchangeRates:     exchangeRates,
+			TransactionStatus: enum.TransactionStatusPending,
+			InternalStatus:    enum.TrxInternalStatusPending,
+			PaymentProvider:   paymentProvider,
+		})
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert transaction", err)
+		}
+
+		skus := make(map[checkoutLine]string, len(data.Products))
+		for _, product := range data.Products {
+			skus[checkoutLine{product.ProductID, product.VariantID}] = product.SKU
+		}
+
+		transactionDetails := make([]*entity.TransactionDetail, 0, len(breakdown.Lines))
+		for _, line := range breakdown.Lines {
+			sku := skus[checkoutLine{line.ProductID, line.VariantID}]
+			transactionDetails = append(transactionDetails, &entity.TransactionDetail{
+				TransactionID: transaction.ID,
+				ProductID:     line.ProductID,
+				VariantID:     line.VariantID,
+				SKU:           sql.NullString{String: sku, Valid: sku != ""},
+				Quantity:      line.Quantity,
+				Price:         line.Price,
+				Discount:      line.Discount,
+				Tax:           line.Tax,
+			})
+		}
+
+		transactionDetails, err = uc.transactionDetailRepo.InsertMany(ctx, tx, transactionDetails)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert transaction details", err)
+		}
+
+		if err := uc.transactionDetailRepo.InsertDiscounts(ctx, tx, transactionDetailDiscounts(transactionDetails, data.Discounts)); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert transaction detail discounts", err)
+		}
+
+		if err := insertTransactionOutbox(ctx, tx, uc.outboxRepo, "transaction.committed", transaction.ID, enum.TransactionEventCommited); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert transaction committed outbox event", err)
+		}
+
+		return nil
+	})
+}
+
+// cancelTransaction cancels a transaction that is still waiting for payment and publishes the event that
+// releases its stock, a transaction that already reached a final status released it on its own.
+func (uc *transactionUseCase) cancelTransaction(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.CheckoutSagaData)
+	if err := instance.Bind(data); err != nil {
+		return err
+	}
+
+	var canceledTransition *entity.TransactionTransition
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		transaction, err := uc.transactionRepo.FindByID(ctx, tx, data.TransactionID.String(), true)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return nil
+			}
+			return err
+		}
+
+		transition, err := transaction.TransitionTo(enum.TrxInternalStatusCancelledBySystem)
+		if err != nil {
+			var invalidTransition *entity.InvalidTransitionError
+			if errors.As(err, &invalidTransition) {
+				return nil
+			}
+			return err
+		}
+
+		now := time.Now()
+		transaction.SnapToken.Valid, transaction.SnapToken.String = true, ""
+		transaction.UpdatedAt = &now
+
+		if err := uc.transactionRepo.UpdateStatus(ctx, tx, transaction, transition); err != nil {
+			return err
+		}
+
+		if err := insertTransactionOutbox(ctx, tx, uc.outboxRepo, transition.Event.Subject, transaction.ID, transition.Event.Status); err != nil {
+			return err
+		}
+		canceledTransition = transition
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.log, data.TransactionID, canceledTransition)
+	return nil
+}
+
+// obtainPaymentToken asks the payment provider for the token once while the buyer waits. A failure that may pass is
+// handed to the payment token task and the step waits for its outcome.
+func (uc *transactionUseCase) obtainPaymentToken(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.CheckoutSagaData)
+	if err := instance.Bind(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	charge, err := uc.requestPaymentToken(ctx, data.TransactionID)
+	if err != nil {
+		if saga.IsPermanent(err) {
+			return err
+		}
+
+		processIn := task.PaymentTokenRetryDelay(0, err)
+		uc.log.Warn("failed to obtain payment token, retrying in the payment token task", zap.Error(err),
+			zap.String("transaction_id", data.TransactionID.String()), zap.Duration("process_in", processIn))
+		if err := uc.expireTask.EnqueuePaymentToken(data.TransactionID, processIn); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to enqueue payment token task", err)
+		}
+		return saga.ErrAwaitingSignal
+	}
+
+	if charge == nil {
+		return nil
+	}
+
+	data.SnapToken = charge.Token
+	data.RedirectURL = charge.RedirectURL
+	return instance.Store(data)
+}
+
+// ObtainPaymentToken is a retry of the payment token step run by the payment token task. The outcome is signaled to the
+// checkout saga once it is final, a failed last attempt compensates the saga, which cancels the transaction.
+func (uc *transactionUseCase) ObtainPaymentToken(ctx context.Context, request *model.ObtainPaymentTokenRequest) error {
+	instance, err := uc.sagaOrchestrator.Find(ctx, checkoutSagaType, request.TransactionID)
+	if err != nil {
+		if errors.Is(err, saga.ErrInstanceNotFound) {
+			return saga.Permanent(err)
+		}
+		return err
+	}
+
+	sagaStep := instance.Step(checkoutStepObtainPaymentToken)
+	switch {
+	case sagaStep == nil:
+		return saga.Permanent(fmt.Errorf("saga %s has no step %s", checkoutSagaType, checkoutStepObtainPaymentToken))
+	case sagaStep.Status == enum.SagaStepStatusRunning:
+		// the inline attempt has not parked the step yet, a signal now would be ignored
+		return errors.New("payment token step is not waiting for the payment token task yet")
+	case sagaStep.Status != enum.SagaStepStatusWaiting:
+		return nil
+	}
+
+	_, err = uc.requestPaymentToken(ctx, request.TransactionID)
+	if err != nil && !saga.IsPermanent(err) && !request.LastAttempt {
+		return err
+	}
+
+	if err != nil {
+		uc.log.Warn("payment token could not be obtained, canceling checkout", zap.Error(err),
+			zap.String("transaction_id", request.TransactionID.String()))
+	}
+
+	if signalErr := uc.sagaOrchestrator.Signal(ctx, checkoutSagaType, request.TransactionID, checkoutStepObtainPaymentToken, err); signalErr != nil {
+		uc.log.Error("failed to signal payment token outcome", zap.Error(signalErr), zap.String("transaction_id", request.TransactionID.String()))
+		return signalErr
+	}
+
+	if err != nil {
+		return saga.Permanent(err)
+	}
+	return nil
+}
+
+// requestPaymentToken creates the charge and stores its token, a nil charge means a previous attempt already stored it.
+func (uc *transactionUseCase) requestPaymentToken(ctx context.Context, transactionID uuid.UUID) (*model.ChargeResponse, error) {
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, transactionID.String(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch transaction.InternalStatus {
+	case enum.TrxInternalStatusPending:
+	case enum.TrxInternalStatusTokenReady:
+		// A previous attempt stored the token before it could record its outcome
+		return nil, nil
+	default:
+		return nil, saga.Permanent(fmt.Errorf("transaction is %s, payment token is no longer needed", transaction.InternalStatus))
+	}
+
+	paymentProvider, err := uc.paymentProviders.Get(transaction.PaymentProvider)
+	if err != nil {
+		return nil, saga.Permanent(err)
+	}
+
+	transactionDetails, err := uc.transactionDetailRepo.FindManyByTransactionID(ctx, uc.databaseStore, transaction.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := chargeItems(transaction, transactionDetails)
+	if err != nil {
+		return nil, saga.Permanent(err)
+	}
+
+	charge, err := paymentProvider.CreateCharge(ctx, &model.CreateChargeRequest{
+		OrderID:     transaction.ID.String(),
+		GrossAmount: transaction.TotalPrice,
+		Email:       "",
+		Items:       items,
+	})
+	if err != nil {
+		// A total the gateway cannot take is refused rather than charged short or in another currency
+		if adapter.IsPaymentAmountUnsupported(err) {
+			return nil, saga.Permanent(err)
+		}
+		return nil, fmt.Errorf("%s create charge error: %w", strings.ToLower(string(paymentProvider.Name())), err)
+	}
+
+	if err := uc.updateTransactionToken(ctx, charge, transaction.ID); err != nil {
+		return nil, err
+	}
+
+	return charge, nil
+}
+
+// awaitPayment parks the saga until the payment outcome is signaled, unless the outcome was already recorded
+// before the saga reached this step.
+func (uc *transactionUseCase) awaitPayment(ctx context.Context, instance *saga.Instance) error {
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, instance.AggregateID.String(), false)
+	if err != nil {
+		return err
+	}
+
+	switch transaction.InternalStatus {
+	case enum.TrxInternalStatusSettled, enum.TrxInternalStatusExpiredCheckedValid:
+		return nil
+	case enum.TrxInternalStatusPending, enum.TrxInternalStatusTokenReady, enum.TrxInternalStatusExpired:
+		// EXPIRED is still checked against the payment gateway, the final outcome arrives as a signal
+		return saga.ErrAwaitingSignal
+	default:
+		return saga.Permanent(fmt.Errorf("payment %s", strings.ToLower(string(transaction.TransactionStatus))))
+	}
+}
+
+// settleTransaction confirms the settlement was recorded, the settled event itself is written by
+// CheckAndUpdateTransaction in the same database transaction as the status change.
+func (uc *transactionUseCase) settleTransaction(ctx context.Context, instance *saga.Instance) error {
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, instance.AggregateID.String(), false)
+	if err != nil {
+		return err
+	}
+
+	if transaction.TransactionStatus != enum.TransactionStatusSuccess {
+		return saga.Permanent(fmt.Errorf("transaction is %s, expected %s", transaction.TransactionStatus, enum.TransactionStatusSuccess))
+	}
+
+	// A usage left RESERVED still counts against the limits, failing to redeem it is not worth compensating for
+	if err := uc.promotionRepo.RedeemUsages(ctx, uc.databaseStore, transaction.ID); err != nil {
+		uc.log.Warn("failed to redeem promotion usages", zap.Error(err), zap.String("transaction_id", transaction.ID.String()))
+	}
+
+	return nil
+}
+
+// signalCheckoutPayment hands the payment outcome to the checkout saga, a nil outcome means the payment settled.
+func signalCheckoutPayment(ctx context.Context, sagaOrchestrator saga.Orchestrator, log logs.Log, transactionID uuid.UUID, outcome error) {
+	if err := sagaOrchestrator.Signal(ctx, checkoutSagaType, transactionID, checkoutStepAwaitPayment, outcome); err != nil {
+		log.Warn("failed to signal checkout saga", zap.String("transaction_id", transactionID.String()), zap.Error(err))
+	}
+}
+
+// settleCheckoutCurrency prices every product in the checkout currency, the requested one or else the currency of
+// the first product. Products in another currency are rejected, or converted at the current rate when the currency
+// policy allows it, the rates used are kept in the saga data to be stored with the transaction.
+func (uc *transactionUseCase) settleCheckoutCurrency(ctx context.Context, data *model.CheckoutSagaData) error {
+	if data.Currency == "" && len(data.Products) > 0 {
+		data.Currency = data.Products[0].Price.Currency
+	}
+	data.Currency = money.Zero(data.Currency).Currency
+
+	for i := range data.Products {
+		product := &data.Products[i]
+		if product.Price.Currency == data.Currency {
+			continue
+		}
+
+		if uc.currencyPolicy != enum.CurrencyPolicyConvert {
+			return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+				fmt.Sprintf("%s: %s and %s", message.CurrencyMismatch, product.Price.Currency, data.Currency)))
+		}
+
+		exchangeRate, err := uc.checkoutExchangeRate(ctx, data, product.Price.Currency)
+		if err != nil {
+			return err
+		}
+
+		rate, ok := new(big.Rat).SetString(exchangeRate.Rate)
+		if !ok {
+			return saga.Permanent(fmt.Errorf("invalid exchange rate %q from %s to %s", exchangeRate.Rate, exchangeRate.From, exchangeRate.To))
+		}
+
+		if product.Price, err = product.Price.Convert(data.Currency, rate); err != nil {
+			return saga.Permanent(err)
+		}
+	}
+	return nil
+}
+
+// checkoutExchangeRate quotes each currency once per checkout, so every product in it is converted at the same rate.
+func (uc *transactionUseCase) checkoutExchangeRate(ctx context.Context, data *model.CheckoutSagaData, from string) (*model.ExchangeRate, error) {
+	for i := range data.ExchangeRates {
+		if data.ExchangeRates[i].From == from && data.ExchangeRates[i].To == data.Currency {
+			return &data.ExchangeRates[i], nil
+		}
+	}
+
+	exchangeRate, err := uc.exchangeRates.Rate(ctx, from, data.Currency)
+	if err != nil {
+		if errors.Is(err, adapter.ErrExchangeRateNotFound) {
+			return nil, saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument,
+				fmt.Sprintf("%s: %s to %s", message.ExchangeRateUnavailable, from, data.Currency)))
+		}
+		return nil, err
+	}
+
+	data.ExchangeRates = append(data.ExchangeRates, *exchangeRate)
+	return exchangeRate, nil
+}
+
+// normalizePromoCodes upper-cases the promo codes and drops the repeated ones, keeping the order they were sent in.
+func normalizePromoCodes(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if _, ok := seen[code]; ok || code == "" {
+			continue
+		}
+		seen[code] = struct{}{}
+		normalized = append(normalized, code)
+	}
+	return normalized
+}
+
+// checkoutLine identifies a line of the checkout, VariantID is empty for a product without variants.
+type checkoutLine struct {
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+}
+
+// reservedLineKey matches a product reserved by product-svc with the line of the checkout it was reserved for.
+func reservedLineKey(productID, variantID string) string {
+	return productID + "/" + variantID
+}
+
+// checkoutDiscounts adds up the discounts of every line.
+func checkoutDiscounts(currency string, discounts []model.CheckoutDiscount) (map[checkoutLine]money.Money, error) {
+	productDiscounts := make(map[checkoutLine]money.Money, len(discounts))
+	for _, discount := range discounts {
+		line := checkoutLine{discount.ProductID, discount.VariantID}
+		productDiscount, ok := productDiscounts[line]
+		if !ok {
+			productDiscount = money.Zero(currency)
+		}
+
+		var err error
+		if productDiscounts[line], err = productDiscount.Add(discount.Amount); err != nil {
+			return nil, err
+		}
+	}
+	return productDiscounts, nil
+}
+
+// transactionDetailDiscounts ties the discounts of the checkout to the inserted transaction details of their lines.
+func transactionDetailDiscounts(transactionDetails []*entity.TransactionDetail, discounts []model.CheckoutDiscount) []*entity.TransactionDetailDiscount {
+	detailIDs := make(map[checkoutLine]uuid.UUID, len(transactionDetails))
+	for _, transactionDetail := range transactionDetails {
+		detailIDs[checkoutLine{transactionDetail.ProductID, transactionDetail.VariantID}] = transactionDetail.ID
+	}
+
+	detailDiscounts := make([]*entity.TransactionDetailDiscount, 0, len(discounts))
+	for _, discount := range discounts {
+		detailDiscounts = append(detailDiscounts, &entity.TransactionDetailDiscount{
+			TransactionDetailID: detailIDs[checkoutLine{discount.ProductID, discount.VariantID}],
+			PromotionID:         discount.PromotionID,
+			PromotionCode:       discount.Code,
+			Amount:              discount.Amount,
+		})
+	}
+	return detailDiscounts
+}
+
+// checkoutPriceBreakdown is the priced breakdown as the saga data and the responses carry it.
+func checkoutPriceBreakdown(breakdown *pricing.Breakdown) *model.PriceBreakdown {
+	return &model.PriceBreakdown{
+		Subtotal:   breakdown.Subtotal,
+		Discount:   breakdown.Discount,
+		Tax:        breakdown.Tax,
+		ServiceFee: breakdown.ServiceFee,
+		PaymentFee: breakdown.PaymentFee,
+	}
+}
+
+// chargeItems lists what the buyer pays for so the receipt of the gateway matches the transaction: every product at
+// its unit price, then the discount as a negative item, the tax and the fees. Transaction details keep no product
+// name, the product id names the item and the SKU names the item of a variant.
+func chargeItems(transaction *entity.Transaction, transactionDetails []*entity.TransactionDetail) ([]model.ChargeItem, error) {
+	items := make([]model.ChargeItem, 0, len(transactionDetails)+4)
+	for _, transactionDetail := range transactionDetails {
+		unitPrice, err := transactionDetail.Price.Prorate(1, int64(transactionDetail.Quantity))
+		if err != nil {
+			return nil, err
+		}
+
+		itemID := transactionDetail.ProductID.String()
+		if transactionDetail.SKU.Valid {
+			itemID = transactionDetail.SKU.String
+		}
+
+		items = append(items, model.ChargeItem{
+			ID:       itemID,
+			Name:     itemID,
+			Price:    unitPrice,
+			Quantity: transactionDetail.Quantity,
+		})
+	}
+
+	discount, err := money.Zero(transaction.Currency).Sub(transaction.Discount)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments := []model.ChargeItem{
+		{ID: "DISCOUNT", Name: "Discount", Price: discount, Quantity: 1},
+		{ID: "TAX", Name: "Tax", Price: transaction.Tax, Quantity: 1},
+		{ID: "SERVICE_FEE", Name: "Service fee", Price: transaction.ServiceFee, Quantity: 1},
+		{ID: "PAYMENT_FEE", Name: "Payment fee", Price: transaction.PaymentFee, Quantity: 1},
+	}
+	for _, adjustment := range adjustments {
+		if !adjustment.Price.IsZero() {
+			items = append(items, adjustment)
+		}
+	}
+
+	return items, nil
+}
+
+// acceptsCheckoutTotal tells whether the total stays within the tolerance above the expected total, an expected
+// total in another currency is never accepted.
+func acceptsCheckoutTotal(tolerance model.PriceTolerance, expectedTotal, totalPrice money.Money) bool {
+	allowed, err := expectedTotal.Prorate(tolerance.BasisPoints, 10000)
+	if err != nil {
+		return false
+	}
+
+	if cmp, err := tolerance.Amount.Cmp(allowed); err == nil && cmp > 0 {
+		allowed = tolerance.Amount
+	}
+
+	limit, err := expectedTotal.Add(allowed)
+	if err != nil {
+		return false
+	}
+
+	cmp, err := totalPrice.Cmp(limit)
+	return err == nil && cmp <= 0
+}
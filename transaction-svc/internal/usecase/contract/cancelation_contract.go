@@ -1,9 +1,13 @@
 package contract
 
-import "context"
+import (
+	"context"
+	"go-saga-pattern/transaction-svc/internal/model"
+)
 
 type CancelationUseCase interface {
 	ExpirePendingTransaction(ctx context.Context, transactionId string) error
 	ExpireFinalTransaction(ctx context.Context, transactionId string) error
 	CancelPendingTransaction(ctx context.Context, transactionId string) error
+	UserCancelTransaction(ctx context.Context, request *model.CancelTransactionRequest) error
 }
@@ -0,0 +1,16 @@
+package contract
+
+import (
+	"context"
+	"go-saga-pattern/transaction-svc/internal/model"
+)
+
+type CartUseCase interface {
+	Get(ctx context.Context, request *model.GetCartRequest) (*model.CartResponse, error)
+	AddItem(ctx context.Context, request *model.AddCartItemRequest) (*model.CartResponse, error)
+	UpdateItem(ctx context.Context, request *model.UpdateCartItemRequest) (*model.CartResponse, error)
+	RemoveItem(ctx context.Context, request *model.RemoveCartItemRequest) (*model.CartResponse, error)
+	Merge(ctx context.Context, request *model.MergeCartRequest) (*model.CartResponse, error)
+	Checkout(ctx context.Context, request *model.CheckoutCartRequest) (*model.CreateTransactionResponse, error)
+	DeleteStaleGuestCarts(ctx context.Context) error
+}
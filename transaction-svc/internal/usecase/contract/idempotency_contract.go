@@ -0,0 +1,18 @@
+package contract
+
+import (
+	"context"
+	"go-saga-pattern/transaction-svc/internal/model"
+)
+
+// IdempotencyUseCase makes a request with an Idempotency-Key run once per user and key. Postgres decides which
+// request runs and keeps the response, redis caches the stored responses for the replays.
+type IdempotencyUseCase interface {
+	// Begin returns the stored response of a completed key, or nil when the caller now owns the key and must
+	// Complete or Release it
+	Begin(ctx context.Context, request *model.IdempotencyRequest) (*model.IdempotentResponse, error)
+	Complete(ctx context.Context, request *model.IdempotencyRequest, response *model.IdempotentResponse) error
+	// Release gives up a key without a response, so the client can retry with it
+	Release(ctx context.Context, request *model.IdempotencyRequest) error
+	DeleteExpired(ctx context.Context) error
+}
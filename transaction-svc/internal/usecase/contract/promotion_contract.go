@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"context"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/transaction-svc/internal/model"
+)
+
+type PromotionUseCase interface {
+	Create(ctx context.Context, request *model.CreatePromotionRequest) (*model.PromotionResponse, error)
+	Get(ctx context.Context, request *model.GetPromotionRequest) (*model.PromotionResponse, error)
+	Search(ctx context.Context, request *model.SearchPromotionsRequest) ([]*model.PromotionResponse, *web.PageMetadata, error)
+	Deactivate(ctx context.Context, request *model.DeactivatePromotionRequest) (*model.PromotionResponse, error)
+}
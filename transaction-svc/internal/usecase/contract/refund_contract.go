@@ -0,0 +1,12 @@
+package contract
+
+import (
+	"context"
+	"go-saga-pattern/transaction-svc/internal/model"
+)
+
+type RefundUseCase interface {
+	RefundTransaction(ctx context.Context, request *model.RefundTransactionRequest) (*model.RefundResponse, error)
+	SearchRefunds(ctx context.Context, request *model.GetTransactionRequest) ([]*model.RefundResponse, error)
+	RefundLateSettlements(ctx context.Context) error
+}
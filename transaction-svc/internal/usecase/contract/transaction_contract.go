@@ -8,8 +8,12 @@ import (
 
 type TransactionUseCase interface {
 	CreateTransaction(ctx context.Context, request *model.CreateTransactionRequest) (*model.CreateTransactionResponse, error)
+	ObtainPaymentToken(ctx context.Context, request *model.ObtainPaymentTokenRequest) error
 	CheckAndUpdateTransaction(ctx context.Context, request *model.CheckAndUpdateTransactionRequest) error
+	HandlePaymentNotification(ctx context.Context, request *model.PaymentNotificationRequest) error
+	UserGet(ctx context.Context, request *model.GetTransactionRequest) (*model.TransactionResponse, error)
 	UserSearch(ctx context.Context, request *model.UserSearchTransactionRequest) ([]*model.TransactionResponse, *web.PageMetadata, error)
 	UserSearchWithDetail(ctx context.Context, request *model.UserSearchTransactionRequest) ([]*model.TransactionResponse, *web.PageMetadata, error)
 	OwnerSearchWithDetail(ctx context.Context, request *model.OwnerSearchTransactionRequest) ([]*model.TransactionResponse, *web.PageMetadata, error)
+	GetCheckoutSaga(ctx context.Context, request *model.GetTransactionRequest) (*model.SagaResponse, error)
 }
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/repository"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// idempotencyKeyTTL is how long the response of an Idempotency-Key is replayed
+	idempotencyKeyTTL = 24 * time.Hour
+	// idempotencyLockTimeout lets a duplicate take over a key whose first request died before storing a response
+	idempotencyLockTimeout  = time.Minute
+	idempotencyKeyMaxLength = 255
+)
+
+type idempotencyUseCase struct {
+	databaseStore   store.DatabaseStore
+	idempotencyRepo repository.IdempotencyRepository
+	cacheAdapter    adapter.CacheAdapter
+	log             logs.Log
+}
+
+func NewIdempotencyUseCase(databaseStore store.DatabaseStore, idempotencyRepo repository.IdempotencyRepository,
+	cacheAdapter adapter.CacheAdapter, log logs.Log) contract.IdempotencyUseCase {
+	return &idempotencyUseCase{
+		databaseStore:   databaseStore,
+		idempotencyRepo: idempotencyRepo,
+		cacheAdapter:    cacheAdapter,
+		log:             log,
+	}
+}
+
+func (uc *idempotencyUseCase) Begin(ctx context.Context, request *model.IdempotencyRequest) (*model.IdempotentResponse, error) {
+	if len(request.Key) > idempotencyKeyMaxLength {
+		return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.IdempotencyKeyTooLong)
+	}
+
+	if response := uc.getCachedResponse(ctx, request); response != nil {
+		if response.RequestHash != request.RequestHash {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.IdempotencyKeyReused)
+		}
+		return response, nil
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(idempotencyKeyTTL)
+	acquired, err := uc.idempotencyRepo.Acquire(ctx, uc.databaseStore, &entity.IdempotencyKey{
+		UserID:         request.UserID,
+		IdempotencyKey: request.Key,
+		RequestHash:    request.RequestHash,
+		LockedUntil:    sql.NullTime{Time: now.Add(idempotencyLockTimeout), Valid: true},
+		ExpiresAt:      &expiresAt,
+	}, now)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to acquire idempotency key", err)
+	}
+
+	if acquired {
+		return nil, nil
+	}
+
+	idempotencyKey, err := uc.idempotencyRepo.FindByKey(ctx, uc.databaseStore, request.UserID, request.Key)
+	if err != nil {
+		// the key was released between the acquire and the lookup, the client may retry right away
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.IdempotencyKeyInProgress)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find idempotency key", err)
+	}
+
+	if idempotencyKey.RequestHash != request.RequestHash {
+		return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.IdempotencyKeyReused)
+	}
+
+	if idempotencyKey.Status != enum.IdempotencyStatusCompleted {
+		return nil, helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.IdempotencyKeyInProgress)
+	}
+
+	response := &model.IdempotentResponse{
+		RequestHash: idempotencyKey.RequestHash,
+		StatusCode:  int(idempotencyKey.ResponseStatus.Int32),
+		Body:        idempotencyKey.ResponseBody,
+	}
+	uc.setCachedResponse(ctx, request, response, time.Until(*idempotencyKey.ExpiresAt))
+	return response, nil
+}
+
+func (uc *idempotencyUseCase) Complete(ctx context.Context, request *model.IdempotencyRequest, response *model.IdempotentResponse) error {
+	response.RequestHash = request.RequestHash
+	if err := uc.idempotencyRepo.Complete(ctx, uc.databaseStore, &entity.IdempotencyKey{
+		UserID:         request.UserID,
+		IdempotencyKey: request.Key,
+		ResponseStatus: sql.NullInt32{Int32: int32(response.StatusCode), Valid: true},
+		ResponseBody:   response.Body,
+		CompletedAt:    sql.NullTime{Time: time.Now(), Valid: true},
+	}); err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to complete idempotency key", err)
+	}
+
+	uc.setCachedResponse(ctx, request, response, idempotencyKeyTTL)
+	return nil
+}
+
+func (uc *idempotencyUseCase) Release(ctx context.Context, request *model.IdempotencyRequest) error {
+	if err := uc.idempotencyRepo.Delete(ctx, uc.databaseStore, request.UserID, request.Key); err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to release idempotency key", err)
+	}
+
+	return nil
+}
+
+func (uc *idempotencyUseCase) DeleteExpired(ctx context.Context) error {
+	deleted, err := uc.idempotencyRepo.DeleteExpired(ctx, uc.databaseStore, time.Now())
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to delete expired idempotency keys", err)
+	}
+
+	if deleted > 0 {
+		uc.log.Info("Expired idempotency keys deleted", zap.Int64("deleted", deleted))
+	}
+	return nil
+}
+
+// getCachedResponse is only a shortcut, a cache miss or a redis failure falls back to postgres.
+func (uc *idempotencyUseCase) getCachedResponse(ctx context.Context, request *model.IdempotencyRequest) *model.IdempotentResponse {
+	cached, err := uc.cacheAdapter.Get(ctx, idempotencyCacheKey(request.UserID, request.Key))
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			uc.log.Warn("failed to get cached idempotent response", zap.String("idempotency_key", request.Key), zap.Error(err))
+		}
+		return nil
+	}
+
+	response := new(model.IdempotentResponse)
+	if err := sonic.ConfigFastest.UnmarshalFromString(cached, response); err != nil {
+		uc.log.Warn("failed to unmarshal cached idempotent response", zap.String("idempotency_key", request.Key), zap.Error(err))
+		return nil
+	}
+
+	return response
+}
+
+func (uc *idempotencyUseCase) setCachedResponse(ctx context.Context, request *model.IdempotencyRequest,
+	response *model.IdempotentResponse, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	payload, err := sonic.ConfigFastest.Marshal(response)
+	if err != nil {
+		uc.log.Warn("failed to marshal idempotent response", zap.String("idempotency_key", request.Key), zap.Error(err))
+		return
+	}
+
+	if err := uc.cacheAdapter.SetEx(ctx, idempotencyCacheKey(request.UserID, request.Key), payload, ttl); err != nil {
+		uc.log.Warn("failed to cache idempotent response", zap.String("idempotency_key", request.Key), zap.Error(err))
+	}
+}
+
+func idempotencyCacheKey(userID uuid.UUID, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", userID, key)
+}
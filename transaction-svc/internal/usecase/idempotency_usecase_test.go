@@ -0,0 +1,265 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/repository"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	checkoutHash = "checkout-hash"
+	otherHash    = "other-hash"
+)
+
+var userID = uuid.MustParse("0b6a7e5c-3c8f-4a52-9d61-5f0f3e8a07ea")
+
+// fakeIdempotencyRepository keeps the keys in memory and takes a key over on the same conditions as the SQL of
+// idempotencyRepository.Acquire.
+type fakeIdempotencyRepository struct {
+	repository.IdempotencyRepository
+	keys      map[string]*entity.IdempotencyKey
+	findCalls int
+}
+
+func (r *fakeIdempotencyRepository) Acquire(_ context.Context, _ store.Querier, idempotencyKey *entity.IdempotencyKey, now time.Time) (bool, error) {
+	existing, ok := r.keys[idempotencyKey.IdempotencyKey]
+	if ok {
+		expired := existing.ExpiresAt.Before(now)
+		lockRanOut := existing.Status == enum.IdempotencyStatusProcessing && existing.LockedUntil.Time.Before(now) &&
+			existing.RequestHash == idempotencyKey.RequestHash
+		if !expired && !lockRanOut {
+			return false, nil
+		}
+	}
+
+	acquired := *idempotencyKey
+	acquired.Status = enum.IdempotencyStatusProcessing
+	r.keys[idempotencyKey.IdempotencyKey] = &acquired
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepository) FindByKey(_ context.Context, _ store.Querier, _ uuid.UUID, key string) (*entity.IdempotencyKey, error) {
+	r.findCalls++
+	idempotencyKey, ok := r.keys[key]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+
+	found := *idempotencyKey
+	return &found, nil
+}
+
+func (r *fakeIdempotencyRepository) Complete(_ context.Context, _ store.Querier, idempotencyKey *entity.IdempotencyKey) error {
+	existing := r.keys[idempotencyKey.IdempotencyKey]
+	existing.Status = enum.IdempotencyStatusCompleted
+	existing.ResponseStatus = idempotencyKey.ResponseStatus
+	existing.ResponseBody = idempotencyKey.ResponseBody
+	existing.CompletedAt = idempotencyKey.CompletedAt
+	return nil
+}
+
+type fakeCacheAdapter struct {
+	adapter.CacheAdapter
+	values map[string]string
+}
+
+func (a *fakeCacheAdapter) Get(_ context.Context, key string) (string, error) {
+	value, ok := a.values[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return value, nil
+}
+
+func (a *fakeCacheAdapter) SetEx(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	a.values[key] = string(value.([]byte))
+	return nil
+}
+
+func newIdempotencyKey(status enum.IdempotencyStatus, requestHash string, lockedUntil, expiresAt time.Time) *entity.IdempotencyKey {
+	idempotencyKey := &entity.IdempotencyKey{
+		UserID:         userID,
+		IdempotencyKey: "checkout-1",
+		RequestHash:    requestHash,
+		Status:         status,
+		LockedUntil:    sql.NullTime{Time: lockedUntil, Valid: true},
+		ExpiresAt:      &expiresAt,
+	}
+	if status == enum.IdempotencyStatusCompleted {
+		idempotencyKey.ResponseStatus = sql.NullInt32{Int32: 201, Valid: true}
+		idempotencyKey.ResponseBody = []byte(`{"id":"1"}`)
+	}
+	return idempotencyKey
+}
+
+func TestIdempotencyUseCase_Begin(t *testing.T) {
+	now := time.Now()
+	ago, later := now.Add(-time.Minute), now.Add(time.Minute)
+	tomorrow, yesterday := now.Add(24*time.Hour), now.Add(-24*time.Hour)
+
+	tests := []struct {
+		name         string
+		key          string
+		stored       *entity.IdempotencyKey
+		cached       *model.IdempotentResponse
+		want         *model.IdempotentResponse
+		wantCode     string
+		wantStatus   int
+		wantAcquired bool
+	}{
+		{
+			name:         "new key is acquired",
+			key:          "checkout-1",
+			wantAcquired: true,
+		},
+		{
+			name:   "completed key replays its response",
+			key:    "checkout-1",
+			stored: newIdempotencyKey(enum.IdempotencyStatusCompleted, checkoutHash, ago, tomorrow),
+			want:   &model.IdempotentResponse{RequestHash: checkoutHash, StatusCode: 201, Body: []byte(`{"id":"1"}`)},
+		},
+		{
+			name:   "cached response is replayed",
+			key:    "checkout-1",
+			cached: &model.IdempotentResponse{RequestHash: checkoutHash, StatusCode: 201, Body: []byte(`{"id":"1"}`)},
+			want:   &model.IdempotentResponse{RequestHash: checkoutHash, StatusCode: 201, Body: []byte(`{"id":"1"}`)},
+		},
+		{
+			name:       "key in flight is a conflict",
+			key:        "checkout-1",
+			stored:     newIdempotencyKey(enum.IdempotencyStatusProcessing, checkoutHash, later, tomorrow),
+			wantCode:   errorcode.ErrAlreadyExists,
+			wantStatus: 409,
+		},
+		{
+			name:       "completed key of a different request is rejected",
+			key:        "checkout-1",
+			stored:     newIdempotencyKey(enum.IdempotencyStatusCompleted, otherHash, ago, tomorrow),
+			wantCode:   errorcode.ErrInvalidArgument,
+			wantStatus: 422,
+		},
+		{
+			name:       "key in flight for a different request is rejected",
+			key:        "checkout-1",
+			stored:     newIdempotencyKey(enum.IdempotencyStatusProcessing, otherHash, later, tomorrow),
+			wantCode:   errorcode.ErrInvalidArgument,
+			wantStatus: 422,
+		},
+		{
+			name:       "cached response of a different request is rejected",
+			key:        "checkout-1",
+			cached:     &model.IdempotentResponse{RequestHash: otherHash, StatusCode: 201},
+			wantCode:   errorcode.ErrInvalidArgument,
+			wantStatus: 422,
+		},
+		{
+			name:         "expired lock of the same request is taken over",
+			key:          "checkout-1",
+			stored:       newIdempotencyKey(enum.IdempotencyStatusProcessing, checkoutHash, ago, tomorrow),
+			wantAcquired: true,
+		},
+		{
+			name:       "expired lock of a different request is not taken over",
+			key:        "checkout-1",
+			stored:     newIdempotencyKey(enum.IdempotencyStatusProcessing, otherHash, ago, tomorrow),
+			wantCode:   errorcode.ErrInvalidArgument,
+			wantStatus: 422,
+		},
+		{
+			name:         "expired key is reused by any request",
+			key:          "checkout-1",
+			stored:       newIdempotencyKey(enum.IdempotencyStatusCompleted, otherHash, yesterday, yesterday),
+			wantAcquired: true,
+		},
+		{
+			name:       "too long key is rejected",
+			key:        strings.Repeat("k", 256),
+			wantCode:   errorcode.ErrInvalidArgument,
+			wantStatus: 422,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeIdempotencyRepository{keys: map[string]*entity.IdempotencyKey{}}
+			if tt.stored != nil {
+				repo.keys[tt.stored.IdempotencyKey] = tt.stored
+			}
+
+			cache := &fakeCacheAdapter{values: map[string]string{}}
+			if tt.cached != nil {
+				payload, err := sonic.ConfigFastest.MarshalToString(tt.cached)
+				require.NoError(t, err)
+				cache.values["idempotency:"+userID.String()+":"+tt.key] = payload
+			}
+
+			uc := usecase.NewIdempotencyUseCase(nil, repo, cache, zap.NewNop())
+			request := &model.IdempotencyRequest{UserID: userID, Key: tt.key, RequestHash: checkoutHash}
+
+			response, err := uc.Begin(context.Background(), request)
+
+			if tt.wantCode != "" {
+				require.Error(t, err)
+				appErr, ok := err.(*helper.AppError)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, appErr.Code)
+				assert.Equal(t, tt.wantStatus, appErr.HTTPStatus())
+				assert.Nil(t, response)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, response)
+			if tt.wantAcquired {
+				acquired := repo.keys[tt.key]
+				require.NotNil(t, acquired)
+				assert.Equal(t, enum.IdempotencyStatusProcessing, acquired.Status)
+				assert.Equal(t, checkoutHash, acquired.RequestHash)
+				assert.True(t, acquired.LockedUntil.Time.After(now))
+			}
+		})
+	}
+}
+
+func TestIdempotencyUseCase_CompletedResponseIsReplayedFromCache(t *testing.T) {
+	repo := &fakeIdempotencyRepository{keys: map[string]*entity.IdempotencyKey{}}
+	cache := &fakeCacheAdapter{values: map[string]string{}}
+	uc := usecase.NewIdempotencyUseCase(nil, repo, cache, zap.NewNop())
+	request := &model.IdempotencyRequest{UserID: userID, Key: "checkout-1", RequestHash: checkoutHash}
+
+	response, err := uc.Begin(context.Background(), request)
+	require.NoError(t, err)
+	require.Nil(t, response)
+
+	_, err = uc.Begin(context.Background(), request)
+	require.Error(t, err)
+	assert.Equal(t, errorcode.ErrAlreadyExists, err.(*helper.AppError).Code)
+
+	require.NoError(t, uc.Complete(context.Background(), request, &model.IdempotentResponse{StatusCode: 201, Body: []byte(`{"id":"1"}`)}))
+	findCalls := repo.findCalls
+
+	response, err = uc.Begin(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, &model.IdempotentResponse{RequestHash: checkoutHash, StatusCode: 201, Body: []byte(`{"id":"1"}`)}, response)
+	assert.Equal(t, findCalls, repo.findCalls)
+	assert.Equal(t, enum.IdempotencyStatusCompleted, repo.keys["checkout-1"].Status)
+}
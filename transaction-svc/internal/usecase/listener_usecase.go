@@ -14,22 +14,24 @@ type ListenerUseCase interface {
 }
 type listenerUseCase struct {
 	messagingAdapter adapter.MessagingAdapter
+	validator        helper.CustomValidator
 	log              logs.Log
 }
 
-func NewListenerUseCase(messagingAdapter adapter.MessagingAdapter, log logs.Log) ListenerUseCase {
-	return &listenerUseCase{messagingAdapter: messagingAdapter, log: log}
+func NewListenerUseCase(messagingAdapter adapter.MessagingAdapter, validator helper.CustomValidator, log logs.Log) ListenerUseCase {
+	return &listenerUseCase{messagingAdapter: messagingAdapter, validator: validator, log: log}
 }
 
 func (uc *listenerUseCase) ConsumeAndProduceWebhook(ctx context.Context, request *model.WebhookNotifyRequest) error {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return validatonErrs
+	}
+
+	// the signature is verified by the worker with the provider's own scheme, the raw notification is forwarded as is
 	event := &event.WebhookNotifyEvent{
-		MidtransTransactionStatus: request.MidtransTransactionStatus,
-		StatusCode:                request.StatusCode,
-		SignatureKey:              request.SignatureKey,
-		SettlementTime:            request.SettlementTime,
-		OrderID:                   request.OrderID,
-		GrossAmount:               request.GrossAmount,
-		Body:                      request.Body,
+		Provider: request.Provider,
+		Headers:  request.Headers,
+		Body:     request.Body,
 	}
 
 	if err := uc.messagingAdapter.Publish(ctx, "webhook.notify", event); err != nil {
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/utils"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/model/event"
+	"go-saga-pattern/transaction-svc/internal/repository"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"strconv"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const maxOutboxRetryDelay = 5 * time.Minute
+
+type OutboxUseCase interface {
+	RelayPendingEvents(ctx context.Context) (*model.OutboxRelayResult, error)
+	GetLag(ctx context.Context) (*model.OutboxLagResponse, error)
+}
+
+type outboxUseCase struct {
+	db               store.DatabaseStore
+	outboxRepository repository.OutboxRepository
+	messagingAdapter adapter.MessagingAdapter
+	batchSize        int
+	maxAttempts      int
+	logs             logs.Log
+}
+
+func NewOutboxUseCase(db store.DatabaseStore, outboxRepository repository.OutboxRepository, messagingAdapter adapter.MessagingAdapter,
+	logs logs.Log) OutboxUseCase {
+	batchSize, err := strconv.Atoi(utils.GetEnv("OUTBOX_RELAY_BATCH_SIZE"))
+	if err != nil || batchSize <= 0 {
+		batchSize = 50
+	}
+
+	maxAttempts, err := strconv.Atoi(utils.GetEnv("OUTBOX_MAX_ATTEMPTS"))
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+
+	return &outboxUseCase{
+		db:               db,
+		outboxRepository: outboxRepository,
+		messagingAdapter: messagingAdapter,
+		batchSize:        batchSize,
+		maxAttempts:      maxAttempts,
+		logs:             logs,
+	}
+}
+
+// RelayPendingEvents publishes one batch of due outbox rows to JetStream. Rows stay locked until the batch
+// is committed, failed publishes are rescheduled with exponential backoff and parked as FAILED after maxAttempts.
+// Once an event of an aggregate is rescheduled, the rest of its events in the batch are left for a later batch so
+// consumers never see them out of order.
+func (uc *outboxUseCase) RelayPendingEvents(ctx context.Context) (*model.OutboxRelayResult, error) {
+	result := new(model.OutboxRelayResult)
+
+	if err := store.BeginTransaction(ctx, uc.logs, uc.db, func(tx store.Transaction) error {
+		events, err := uc.outboxRepository.FindManyPending(ctx, tx, uc.batchSize)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.logs, "failed to find pending outbox events", err)
+		}
+
+		blocked := make(map[uuid.UUID]bool)
+		for _, outboxEvent := range events {
+			if blocked[outboxEvent.AggregateID] {
+				result.Skipped++
+				continue
+			}
+
+			publishErr := uc.messagingAdapter.PublishRaw(ctx, outboxEvent.Subject, outboxEvent.ID.String(), outboxEvent.Payload)
+			if publishErr == nil {
+				if err := uc.outboxRepository.MarkSent(ctx, tx, outboxEvent.ID); err != nil {
+					return helper.WrapInternalServerError(uc.logs, "failed to mark outbox event as sent", err)
+				}
+				result.Sent++
+				continue
+			}
+
+			attempts := outboxEvent.Attempts + 1
+			uc.logs.Warn("failed to publish outbox event",
+				zap.String("outbox_event_id", outboxEvent.ID.String()),
+				zap.String("subject", outboxEvent.Subject),
+				zap.Int("attempts", attempts),
+				zap.Error(publishErr))
+
+			if attempts >= uc.maxAttempts {
+				if err := uc.outboxRepository.MarkFailed(ctx, tx, outboxEvent.ID, attempts, publishErr.Error()); err != nil {
+					return helper.WrapInternalServerError(uc.logs, "failed to mark outbox event as failed", err)
+				}
+				uc.logs.Error("outbox event exhausted its attempts", zap.String("outbox_event_id", outboxEvent.ID.String()),
+					zap.String("aggregate_id", outboxEvent.AggregateID.String()), zap.String("subject", outboxEvent.Subject))
+				result.Failed++
+				continue
+			}
+
+			nextAttemptAt := time.Now().Add(helper.ExponentialBackoff(attempts, time.Second, maxOutboxRetryDelay))
+			if err := uc.outboxRepository.MarkRetry(ctx, tx, outboxEvent.ID, attempts, nextAttemptAt, publishErr.Error()); err != nil {
+				return helper.WrapInternalServerError(uc.logs, "failed to reschedule outbox event", err)
+			}
+			blocked[outboxEvent.AggregateID] = true
+			result.Retried++
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (uc *outboxUseCase) GetLag(ctx context.Context) (*model.OutboxLagResponse, error) {
+	lag, err := uc.outboxRepository.FindLag(ctx, uc.db)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.logs, "failed to find outbox lag", err)
+	}
+
+	response := &model.OutboxLagResponse{
+		PendingCount: lag.PendingCount,
+		FailedCount:  lag.FailedCount,
+	}
+
+	if lag.OldestPendingAt.Valid {
+		response.OldestPendingAgeSeconds = time.Since(lag.OldestPendingAt.Time).Seconds()
+	}
+
+	return response, nil
+}
+
+// insertTransactionOutbox stores a saga event in the same database transaction as the status change it describes,
+// the outbox relay takes care of delivering it to TRANSACTION_STREAM.
+func insertTransactionOutbox(ctx context.Context, db store.Querier, outboxRepository repository.OutboxRepository,
+	subject string, transactionID uuid.UUID, status string) error {
+	return insertTransactionEventOutbox(ctx, db, outboxRepository, subject, transactionID, &event.TransactionEvent{
+		TransactionID: transactionID.String(),
+		Status:        status,
+	})
+}
+
+func insertTransactionEventOutbox(ctx context.Context, db store.Querier, outboxRepository repository.OutboxRepository,
+	subject string, transactionID uuid.UUID, transactionEvent *event.TransactionEvent) error {
+	payload, err := sonic.ConfigFastest.Marshal(transactionEvent)
+	if err != nil {
+		return err
+	}
+
+	_, err = outboxRepository.Insert(ctx, db, &entity.OutboxEvent{
+		AggregateID: transactionID,
+		Subject:     subject,
+		Payload:     payload,
+	})
+	return err
+}
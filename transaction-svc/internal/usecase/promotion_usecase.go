@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/commoner/web"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/model/converter"
+	"go-saga-pattern/transaction-svc/internal/repository"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type promotionUseCase struct {
+	databaseStore store.DatabaseStore
+	promotionRepo repository.PromotionRepository
+	validator     helper.CustomValidator
+	log           logs.Log
+}
+
+func NewPromotionUseCase(databaseStore store.DatabaseStore, promotionRepo repository.PromotionRepository,
+	validator helper.CustomValidator, log logs.Log) contract.PromotionUseCase {
+	return &promotionUseCase{
+		databaseStore: databaseStore,
+		promotionRepo: promotionRepo,
+		validator:     validator,
+		log:           log,
+	}
+}
+
+// Create stores a promo code, codes are upper-cased so buyers can send them in any case.
+func (uc *promotionUseCase) Create(ctx context.Context, request *model.CreatePromotionRequest) (*model.PromotionResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	promotion, err := newPromotion(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.promotionRepo.FindByCode(ctx, uc.databaseStore, promotion.Code, false); err == nil {
+		return nil, helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.PromotionCodeAlreadyExists)
+	} else if !strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find promotion by code", err)
+	}
+
+	promotion, err = uc.promotionRepo.Insert(ctx, uc.databaseStore, promotion)
+	if err != nil {
+		if strings.Contains(err.Error(), "idx_promotions_code") {
+			return nil, helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.PromotionCodeAlreadyExists)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to insert promotion", err)
+	}
+
+	uc.log.Info("promotion created", zap.String("promotion_id", promotion.ID.String()), zap.String("code", promotion.Code),
+		zap.String("type", string(promotion.Type)))
+	return converter.PromotionToResponse(promotion), nil
+}
+
+func (uc *promotionUseCase) Get(ctx context.Context, request *model.GetPromotionRequest) (*model.PromotionResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	promotion, err := uc.promotionRepo.FindByID(ctx, uc.databaseStore, request.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.PromotionNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find promotion by id", err)
+	}
+
+	return converter.PromotionToResponse(promotion), nil
+}
+
+func (uc *promotionUseCase) Search(ctx context.Context, request *model.SearchPromotionsRequest) ([]*model.PromotionResponse, *web.PageMetadata, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, nil, validatonErrs
+	}
+
+	promotions, pageMetadata, err := uc.promotionRepo.FindMany(ctx, uc.databaseStore, request)
+	if err != nil {
+		return nil, nil, helper.WrapInternalServerError(uc.log, "failed to find promotions", err)
+	}
+
+	return converter.PromotionsWithTotalToResponses(promotions), pageMetadata, nil
+}
+
+// Deactivate stops the promo code from being applied to new checkouts, checkouts that already reserved it keep
+// their discount.
+func (uc *promotionUseCase) Deactivate(ctx context.Context, request *model.DeactivatePromotionRequest) (*model.PromotionResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	promotion, err := uc.promotionRepo.Deactivate(ctx, uc.databaseStore, request.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.PromotionNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to deactivate promotion", err)
+	}
+
+	uc.log.Info("promotion deactivated", zap.String("promotion_id", promotion.ID.String()), zap.String("code", promotion.Code))
+	return converter.PromotionToResponse(promotion), nil
+}
+
+// newPromotion checks the fields the type and scope of the promotion need and builds the promotion to insert.
+func newPromotion(request *model.CreatePromotionRequest) (*entity.Promotion, error) {
+	promotion := &entity.Promotion{
+		Code:     strings.ToUpper(request.Code),
+		Name:     request.Name,
+		Type:     request.Type,
+		Scope:    request.Scope,
+		Amount:   money.Zero(request.Amount.CurrencyCode()),
+		Currency: request.Amount.CurrencyCode(),
+	}
+
+	if !money.Supported(promotion.Currency) {
+		return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.CurrencyNotSupported)
+	}
+
+	switch request.Type {
+	case enum.PromotionTypePercentage:
+		if request.Percentage <= 0 || request.Percentage > 100 {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PromotionPercentageRequired)
+		}
+		promotion.PercentageBasisPoints = int64(math.Round(request.Percentage * 100))
+	case enum.PromotionTypeFixed:
+		if !request.Amount.IsPositive() {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PromotionAmountRequired)
+		}
+		promotion.Amount = request.Amount
+	case enum.PromotionTypeBuyXGetY:
+		if request.BuyQuantity <= 0 || request.GetQuantity <= 0 {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PromotionBuyGetRequired)
+		}
+		promotion.BuyQuantity = request.BuyQuantity
+		promotion.GetQuantity = request.GetQuantity
+	}
+
+	switch request.Scope {
+	case enum.PromotionScopeProduct, enum.PromotionScopeOwner:
+		if request.ScopeID == uuid.Nil {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PromotionScopeIDRequired)
+		}
+		promotion.ScopeID = uuid.NullUUID{UUID: request.ScopeID, Valid: true}
+	default:
+		promotion.Scope = enum.PromotionScopeAll
+	}
+
+	if request.StartsAt != nil && request.EndsAt != nil && !request.EndsAt.After(*request.StartsAt) {
+		return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PromotionPeriodInvalid)
+	}
+	if request.StartsAt != nil {
+		promotion.StartsAt = sql.NullTime{Time: *request.StartsAt, Valid: true}
+	}
+	if request.EndsAt != nil {
+		promotion.EndsAt = sql.NullTime{Time: *request.EndsAt, Valid: true}
+	}
+
+	if request.UsageLimit > 0 {
+		promotion.UsageLimit = sql.NullInt64{Int64: int64(request.UsageLimit), Valid: true}
+	}
+	if request.UsageLimitPerUser > 0 {
+		promotion.UsageLimitPerUser = sql.NullInt64{Int64: int64(request.UsageLimitPerUser), Valid: true}
+	}
+
+	return promotion, nil
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/helper/nullable"
+	"go-saga-pattern/commoner/money"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/model/event"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	refundSagaType = "REFUND"
+
+	refundStepReserveRefund      = "RESERVE_REFUND"
+	refundStepRefundPayment      = "REFUND_PAYMENT"
+	refundStepCompleteRefund     = "COMPLETE_REFUND"
+	refundPaymentMaxAttempts     = 8
+	refundCompleteRefundAttempts = 8
+)
+
+// refundSagaDefinition declares the refund flow. Reserving the refund is only tried once because the requester is
+// waiting for the answer, the payment provider is retried in the background. Once the provider accepted the refund
+// it can no longer be compensated, so completing it is retried as long as the provider call was.
+func (uc *refundUseCase) refundSagaDefinition() *saga.Definition {
+	return &saga.Definition{
+		Type: refundSagaType,
+		Steps: []*saga.Step{
+			{Name: refundStepReserveRefund, Action: uc.reserveRefund, Compensate: uc.releaseRefund},
+			{Name: refundStepRefundPayment, Action: uc.refundPayment, MaxAttempts: refundPaymentMaxAttempts},
+			{Name: refundStepCompleteRefund, Action: uc.completeRefund, MaxAttempts: refundCompleteRefundAttempts},
+		},
+	}
+}
+
+// reserveRefund moves the transaction to REFUNDING and records the refunded quantities, so two refunds can never
+// claim the same items.
+func (uc *refundUseCase) reserveRefund(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.RefundSagaData)
+	if err := instance.Bind(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	var appliedTransition *entity.TransactionTransition
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		// a re-run after a crash finds the refund it already reserved
+		if _, err := uc.refundRepo.FindByID(ctx, tx, data.RefundID, false); err == nil {
+			return nil
+		} else if !strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return helper.WrapInternalServerError(uc.log, "failed to find refund by id", err)
+		}
+
+		transaction, err := uc.transactionRepo.FindByID(ctx, tx, data.TransactionID.String(), true)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return saga.Permanent(helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound))
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find transaction by id", err)
+		}
+
+		previousStatus := transaction.InternalStatus
+		transition, err := transaction.TransitionTo(enum.TrxInternalStatusRefunding)
+		if err != nil {
+			if isRefundInProgress(err, previousStatus) {
+				return saga.Permanent(helper.NewUseCaseError(errorcode.ErrAlreadyExists, message.RefundInProgress))
+			}
+			return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.TransactionIsNotRefundable))
+		}
+
+		transactionDetails, err := uc.transactionDetailRepo.FindManyByTransactionID(ctx, tx, transaction.ID, true)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find transaction details", err)
+		}
+
+		refundItems, err := newRefundItems(data.RefundID, transactionDetails, data.Items)
+		if err != nil {
+			return saga.Permanent(err)
+		}
+
+		amount, err := money.Sum(transaction.Currency, refundItemAmounts(refundItems)...)
+		if err != nil {
+			return saga.Permanent(err)
+		}
+
+		// items a promotion gave away for free have nothing to pay back
+		if !amount.IsPositive() {
+			return saga.Permanent(helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RefundAmountIsZero))
+		}
+
+		now := time.Now()
+		transaction.UpdatedAt = &now
+		if err := uc.transactionRepo.UpdateStatus(ctx, tx, transaction, transition); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update transaction status", err)
+		}
+		appliedTransition = transition
+
+		for _, refundItem := range refundItems {
+			if err := uc.transactionDetailRepo.AddRefundedQuantity(ctx, tx, refundItem.TransactionDetailID, refundItem.Quantity); err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to update refunded quantity", err)
+			}
+		}
+
+		if _, err := uc.refundRepo.Insert(ctx, tx, &entity.Refund{
+			ID:              data.RefundID,
+			TransactionID:   transaction.ID,
+			Initiator:       data.Initiator,
+			RequestedBy:     uuid.NullUUID{UUID: data.RequestedBy, Valid: data.RequestedBy != uuid.Nil},
+			Status:          enum.RefundStatusRequested,
+			PreviousStatus:  previousStatus,
+			Amount:          amount,
+			Currency:        amount.Currency,
+			Reason:          sql.NullString{String: data.Reason, Valid: data.Reason != ""},
+			PaymentProvider: transaction.PaymentProvider,
+		}); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert refund", err)
+		}
+
+		if err := uc.refundRepo.InsertItems(ctx, tx, refundItems); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert refund items", err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.log, data.TransactionID, appliedTransition)
+	return nil
+}
+
+// releaseRefund gives the reserved quantities back and returns the transaction to the status it had before the
+// refund. A refund the payment provider already accepted is kept, the saga stays COMPENSATING for an operator.
+func (uc *refundUseCase) releaseRefund(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.RefundSagaData)
+	if err := instance.Bind(data); err != nil {
+		return err
+	}
+
+	var appliedTransition *entity.TransactionTransition
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		refund, err := uc.refundRepo.FindByID(ctx, tx, data.RefundID, true)
+		if err != nil {
+			if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+				return nil
+			}
+			return helper.WrapInternalServerError(uc.log, "failed to find refund by id", err)
+		}
+
+		if refund.Status != enum.RefundStatusRequested {
+			return nil
+		}
+
+		if refund.ProviderRefundID.Valid {
+			return fmt.Errorf("refund %s was already accepted by %s as %s", refund.ID, refund.PaymentProvider, refund.ProviderRefundID.String)
+		}
+
+		transaction, err := uc.transactionRepo.FindByID(ctx, tx, data.TransactionID.String(), true)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find transaction by id", err)
+		}
+
+		transition, err := transaction.TransitionTo(refund.PreviousStatus)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transaction.UpdatedAt = &now
+		if err := uc.transactionRepo.UpdateStatus(ctx, tx, transaction, transition); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update transaction status", err)
+		}
+		appliedTransition = transition
+
+		refundItems, err := uc.refundRepo.FindItemsByRefundIDs(ctx, tx, []uuid.UUID{refund.ID})
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find refund items", err)
+		}
+
+		for _, refundItem := range refundItems {
+			if err := uc.transactionDetailRepo.AddRefundedQuantity(ctx, tx, refundItem.TransactionDetailID, -refundItem.Quantity); err != nil {
+				return helper.WrapInternalServerError(uc.log, "failed to update refunded quantity", err)
+			}
+		}
+
+		refund.Status = enum.RefundStatusFailed
+		refund.FailureReason = instance.LastError
+		refund.CompletedAt = nullable.ToSQLTime(now)
+		if err := uc.refundRepo.UpdateStatus(ctx, tx, refund, enum.RefundStatusRequested); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update refund status", err)
+		}
+
+		uc.log.Warn("Refund failed, transaction restored", zap.String("refund_id", refund.ID.String()),
+			zap.String("transaction_id", transaction.ID.String()), zap.String("internal_status", string(transaction.InternalStatus)),
+			zap.String("last_error", instance.LastError.String))
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.log, data.TransactionID, appliedTransition)
+	return nil
+}
+
+// refundPayment asks the payment provider for the refund, the refund id is sent as the idempotency key so a retry
+// never refunds twice.
+func (uc *refundUseCase) refundPayment(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.RefundSagaData)
+	if err := instance.Bind(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	refund, err := uc.refundRepo.FindByID(ctx, uc.databaseStore, data.RefundID, false)
+	if err != nil {
+		return err
+	}
+
+	if refund.ProviderRefundID.Valid {
+		return nil
+	}
+
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, data.TransactionID.String(), false)
+	if err != nil {
+		return err
+	}
+
+	paymentProvider, err := uc.paymentProviders.Get(refund.PaymentProvider)
+	if err != nil {
+		return saga.Permanent(err)
+	}
+
+	response, err := paymentProvider.Refund(ctx, &model.RefundChargeRequest{
+		OrderID:   transaction.ID.String(),
+		Reference: transaction.PaymentReference.String,
+		RefundKey: refund.ID.String(),
+		Amount:    refund.Amount,
+		Reason:    refund.Reason.String,
+	})
+	if err != nil {
+		if adapter.IsPaymentAmountUnsupported(err) {
+			return saga.Permanent(err)
+		}
+		return fmt.Errorf("%s refund error: %w", strings.ToLower(string(paymentProvider.Name())), err)
+	}
+
+	providerRefundID := response.RefundID
+	if providerRefundID == "" {
+		providerRefundID = refund.ID.String()
+	}
+
+	if err := uc.refundRepo.UpdateProviderRefundID(ctx, uc.databaseStore, refund.ID, providerRefundID); err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to update provider refund id", err)
+	}
+
+	uc.log.Info("Payment refunded", zap.String("refund_id", refund.ID.String()), zap.String("payment_provider", string(refund.PaymentProvider)),
+		zap.String("provider_refund_id", providerRefundID), zap.String("external_status", response.ExternalStatus))
+	return nil
+}
+
+// completeRefund settles the transaction as REFUNDED or PARTIALLY_REFUNDED and publishes transaction.refunded with
+// the refunded quantities, product-svc restocks them.
+func (uc *refundUseCase) completeRefund(ctx context.Context, instance *saga.Instance) error {
+	data := new(model.RefundSagaData)
+	if err := instance.Bind(data); err != nil {
+		return saga.Permanent(err)
+	}
+
+	var appliedTransition *entity.TransactionTransition
+	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
+		refund, err := uc.refundRepo.FindByID(ctx, tx, data.RefundID, true)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find refund by id", err)
+		}
+
+		if refund.Status == enum.RefundStatusSucceeded {
+			return nil
+		}
+
+		transaction, err := uc.transactionRepo.FindByID(ctx, tx, data.TransactionID.String(), true)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find transaction by id", err)
+		}
+
+		transactionDetails, err := uc.transactionDetailRepo.FindManyByTransactionID(ctx, tx, transaction.ID, false)
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find transaction details", err)
+		}
+
+		to := enum.TrxInternalStatusRefunded
+		for _, transactionDetail := range transactionDetails {
+			if transactionDetail.RefundableQuantity() > 0 {
+				to = enum.TrxInternalStatusPartiallyRefunded
+				break
+			}
+		}
+
+		transition, err := transaction.TransitionTo(to)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transaction.UpdatedAt = &now
+		if err := uc.transactionRepo.UpdateStatus(ctx, tx, transaction, transition); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update transaction status", err)
+		}
+		appliedTransition = transition
+
+		refund.Status = enum.RefundStatusSucceeded
+		refund.CompletedAt = nullable.ToSQLTime(now)
+		if err := uc.refundRepo.UpdateStatus(ctx, tx, refund, enum.RefundStatusRequested); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to update refund status", err)
+		}
+
+		refundItems, err := uc.refundRepo.FindItemsByRefundIDs(ctx, tx, []uuid.UUID{refund.ID})
+		if err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to find refund items", err)
+		}
+
+		eventItems := make([]*event.TransactionEventItem, 0, len(refundItems))
+		for _, refundItem := range refundItems {
+			eventItems = append(eventItems, &event.TransactionEventItem{
+				ProductID: refundItem.ProductID.String(),
+				VariantID: nullable.UUIDtoString(refundItem.VariantID),
+				Quantity:  refundItem.Quantity,
+			})
+		}
+
+		if err := insertTransactionEventOutbox(ctx, tx, uc.outboxRepo, transition.Event.Subject, transaction.ID, &event.TransactionEvent{
+			TransactionID: transaction.ID.String(),
+			Status:        transition.Event.Status,
+			RefundID:      refund.ID.String(),
+			Items:         eventItems,
+		}); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert transaction refunded outbox event", err)
+		}
+
+		uc.log.Info("Refund completed", zap.String("refund_id", refund.ID.String()), zap.String("transaction_id", transaction.ID.String()),
+			zap.String("internal_status", string(transaction.InternalStatus)))
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.log, data.TransactionID, appliedTransition)
+	return nil
+}
+
+func refundItemAmounts(refundItems []*entity.RefundItem) []money.Money {
+	amounts := make([]money.Money, 0, len(refundItems))
+	for _, refundItem := range refundItems {
+		amounts = append(amounts, refundItem.Amount)
+	}
+	return amounts
+}
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-saga-pattern/commoner/constant/enum"
+	errorcode "go-saga-pattern/commoner/constant/errcode"
+	"go-saga-pattern/commoner/constant/message"
+	"go-saga-pattern/commoner/helper"
+	"go-saga-pattern/commoner/logs"
+	"go-saga-pattern/transaction-svc/internal/adapter"
+	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
+	"go-saga-pattern/transaction-svc/internal/model/converter"
+	"go-saga-pattern/transaction-svc/internal/repository"
+	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
+	"go-saga-pattern/transaction-svc/internal/usecase/contract"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// lateSettlementRefundMaxFailures stops the automatic refund of a late settlement after it failed this many times
+	lateSettlementRefundMaxFailures = 3
+	lateSettlementRefundReason      = "Payment settled after the transaction had expired"
+)
+
+type refundUseCase struct {
+	transactionRepo       repository.TransactionRepository
+	transactionDetailRepo repository.TransactionDetailRepository
+	refundRepo            repository.RefundRepository
+	outboxRepo            repository.OutboxRepository
+	databaseStore         store.DatabaseStore
+	sagaOrchestrator      saga.Orchestrator
+	productAdapter        adapter.ProductAdapter
+	paymentProviders      adapter.PaymentProviderRegistry
+	statusAdapter         adapter.TransactionStatusAdapter
+	validator             helper.CustomValidator
+	log                   logs.Log
+}
+
+func NewRefundUseCase(transactionRepo repository.TransactionRepository, transactionDetailRepo repository.TransactionDetailRepository,
+	refundRepo repository.RefundRepository, outboxRepo repository.OutboxRepository, databaseStore store.DatabaseStore, sagaOrchestrator saga.Orchestrator,
+	productAdapter adapter.ProductAdapter, paymentProviders adapter.PaymentProviderRegistry, statusAdapter adapter.TransactionStatusAdapter,
+	validator helper.CustomValidator, log logs.Log) contract.RefundUseCase {
+	uc := &refundUseCase{
+		transactionRepo:       transactionRepo,
+		transactionDetailRepo: transactionDetailRepo,
+		refundRepo:            refundRepo,
+		outboxRepo:            outboxRepo,
+		databaseStore:         databaseStore,
+		sagaOrchestrator:      sagaOrchestrator,
+		productAdapter:        productAdapter,
+		paymentProviders:      paymentProviders,
+		statusAdapter:         statusAdapter,
+		validator:             validator,
+		log:                   log,
+	}
+
+	sagaOrchestrator.Register(uc.refundSagaDefinition())
+	return uc
+}
+
+// RefundTransaction runs the refund saga until the payment provider accepted the refund, a refund whose provider
+// call is being retried in the background is returned as REQUESTED.
+func (uc *refundUseCase) RefundTransaction(ctx context.Context, request *model.RefundTransactionRequest) (*model.RefundResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, request.TransactionID.String(), false)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find transaction by id", err)
+	}
+
+	switch request.Initiator {
+	case enum.RefundInitiatorUser:
+		if transaction.UserID != request.UserID {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+		}
+	case enum.RefundInitiatorOwner:
+		if len(request.Items) == 0 {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.OwnerRefundItemsRequired)
+		}
+	}
+
+	transactionDetails, err := uc.transactionDetailRepo.FindManyByTransactionID(ctx, uc.databaseStore, transaction.ID, false)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find transaction details", err)
+	}
+
+	// checked again under lock by the saga, this only rejects obviously invalid requests before a saga is started
+	refundItems, err := newRefundItems(uuid.Nil, transactionDetails, request.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Initiator == enum.RefundInitiatorOwner {
+		// an owner can only refund the lines of the products they own
+		checked := make(map[uuid.UUID]struct{}, len(refundItems))
+		for _, refundItem := range refundItems {
+			if _, ok := checked[refundItem.ProductID]; ok {
+				continue
+			}
+			if _, err := uc.productAdapter.OwnerGetProduct(ctx, request.UserID, refundItem.ProductID); err != nil {
+				return nil, err
+			}
+			checked[refundItem.ProductID] = struct{}{}
+		}
+	}
+
+	refundID := uuid.New()
+	data := &model.RefundSagaData{
+		RefundID:      refundID,
+		TransactionID: transaction.ID,
+		Initiator:     request.Initiator,
+		RequestedBy:   request.UserID,
+		Reason:        request.Reason,
+		Items:         request.Items,
+	}
+
+	uc.log.Info("Refunding transaction", zap.String("transaction_id", transaction.ID.String()), zap.String("refund_id", refundID.String()),
+		zap.String("initiator", string(request.Initiator)), zap.Any("items", request.Items))
+
+	instance, err := uc.sagaOrchestrator.Start(ctx, refundSagaType, refundID, data)
+	if err != nil {
+		uc.log.Warn("refund saga failed", zap.Error(err), zap.String("refund_id", refundID.String()))
+		return nil, err
+	}
+
+	uc.log.Info("refund saga started", zap.String("refund_id", refundID.String()),
+		zap.String("saga_status", string(instance.Status)), zap.String("saga_step", instance.CurrentStep.String))
+
+	refund, err := uc.refundRepo.FindByID(ctx, uc.databaseStore, refundID, false)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find refund by id", err)
+	}
+
+	responses, err := uc.findRefunds(ctx, uc.databaseStore, []*entity.Refund{refund})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses[0], nil
+}
+
+func (uc *refundUseCase) SearchRefunds(ctx context.Context, request *model.GetTransactionRequest) ([]*model.RefundResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, request.TransacitonID.String(), false)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find transaction by id", err)
+	}
+
+	if transaction.UserID != request.UserID {
+		return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+	}
+
+	refunds, err := uc.refundRepo.FindManyByTransactionID(ctx, uc.databaseStore, transaction.ID)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find refunds by transaction id", err)
+	}
+
+	return uc.findRefunds(ctx, uc.databaseStore, refunds)
+}
+
+// RefundLateSettlements refunds buyers who paid after their transaction expired, the stock of those transactions
+// was already given back when they were marked LATE_SETTLEMENT.
+func (uc *refundUseCase) RefundLateSettlements(ctx context.Context) error {
+	transactions, err := uc.transactionRepo.FindManyLateSettlement(ctx, uc.databaseStore, lateSettlementRefundMaxFailures)
+	if err != nil {
+		return helper.WrapInternalServerError(uc.log, "failed to find late settlement transactions", err)
+	}
+
+	if len(transactions) == 0 {
+		uc.log.Info("There are no late settlement transactions, ignoring refund process")
+		return nil
+	}
+
+	for _, transaction := range transactions {
+		if _, err := uc.RefundTransaction(ctx, &model.RefundTransactionRequest{
+			TransactionID: transaction.ID,
+			Initiator:     enum.RefundInitiatorSystem,
+			Reason:        lateSettlementRefundReason,
+		}); err != nil {
+			uc.log.Error("[RefundUseCase] RefundLateSettlements error:", zap.Error(err), zap.String("transactionId", transaction.ID.String()))
+		}
+	}
+
+	return nil
+}
+
+func (uc *refundUseCase) findRefunds(ctx context.Context, db store.Querier, refunds []*entity.Refund) ([]*model.RefundResponse, error) {
+	refundIDs := make([]uuid.UUID, 0, len(refunds))
+	for _, refund := range refunds {
+		refundIDs = append(refundIDs, refund.ID)
+	}
+
+	refundItems, err := uc.refundRepo.FindItemsByRefundIDs(ctx, db, refundIDs)
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find refund items", err)
+	}
+
+	return converter.RefundsToResponses(refunds, refundItems), nil
+}
+
+// newRefundItems prices the requested lines from the transaction details, an empty request refunds the remaining
+// quantity of every line.
+func newRefundItems(refundID uuid.UUID, transactionDetails []*entity.TransactionDetail, requestItems []model.RefundItemRequest) ([]*entity.RefundItem, error) {
+	refundItems := make([]*entity.RefundItem, 0, len(transactionDetails))
+	if len(requestItems) == 0 {
+		for _, transactionDetail := range transactionDetails {
+			if transactionDetail.RefundableQuantity() == 0 {
+				continue
+			}
+			refundItem, err := newRefundItem(refundID, transactionDetail, transactionDetail.RefundableQuantity())
+			if err != nil {
+				return nil, err
+			}
+			refundItems = append(refundItems, refundItem)
+		}
+
+		if len(refundItems) == 0 {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.TransactionIsNotRefundable)
+		}
+		return refundItems, nil
+	}
+
+	transactionDetailMap := make(map[uuid.UUID]*entity.TransactionDetail, len(transactionDetails))
+	for _, transactionDetail := range transactionDetails {
+		transactionDetailMap[transactionDetail.ID] = transactionDetail
+	}
+
+	requested := make(map[uuid.UUID]struct{}, len(requestItems))
+	for _, requestItem := range requestItems {
+		transactionDetail, ok := transactionDetailMap[requestItem.TransactionDetailID]
+		if !ok {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RefundItemNotFound)
+		}
+
+		if _, ok := requested[requestItem.TransactionDetailID]; ok {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RefundItemDuplicated)
+		}
+		requested[requestItem.TransactionDetailID] = struct{}{}
+
+		if requestItem.Quantity > transactionDetail.RefundableQuantity() {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.RefundQuantityExceeded)
+		}
+
+		refundItem, err := newRefundItem(refundID, transactionDetail, requestItem.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		refundItems = append(refundItems, refundItem)
+	}
+
+	return refundItems, nil
+}
+
+// newRefundItem refunds the prorated price of everything refunded so far less what was already refunded, so the
+// refunds of a line always add up to exactly its price and the last items get whatever is left.
+func newRefundItem(refundID uuid.UUID, transactionDetail *entity.TransactionDetail, quantity int) (*entity.RefundItem, error) {
+	// the buyer gets back what was paid for the line, after the promotions
+	netPrice, err := transactionDetail.NetPrice()
+	if err != nil {
+		return nil, err
+	}
+
+	refunded, err := netPrice.Prorate(int64(transactionDetail.RefundedQuantity), int64(transactionDetail.Quantity))
+	if err != nil {
+		return nil, err
+	}
+
+	refundedAfter, err := netPrice.Prorate(int64(transactionDetail.RefundedQuantity+quantity), int64(transactionDetail.Quantity))
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := refundedAfter.Sub(refunded)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.RefundItem{
+		RefundID:            refundID,
+		TransactionDetailID: transactionDetail.ID,
+		ProductID:           transactionDetail.ProductID,
+		VariantID:           transactionDetail.VariantID,
+		Quantity:            quantity,
+		Amount:              amount,
+	}, nil
+}
+
+func isRefundInProgress(err error, previousStatus enum.TrxInternalStatus) bool {
+	var invalidTransition *entity.InvalidTransitionError
+	return errors.As(err, &invalidTransition) && previousStatus == enum.TrxInternalStatusRefunding
+}
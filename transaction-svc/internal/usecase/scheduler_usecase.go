@@ -6,27 +6,35 @@ import (
 	"go-saga-pattern/commoner/logs"
 	"go-saga-pattern/transaction-svc/internal/adapter"
 	"go-saga-pattern/transaction-svc/internal/entity"
+	"go-saga-pattern/transaction-svc/internal/model"
 	"go-saga-pattern/transaction-svc/internal/model/converter"
 	"go-saga-pattern/transaction-svc/internal/repository"
 	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
 	"go-saga-pattern/transaction-svc/internal/usecase/contract"
 	"sync"
 
-	"github.com/bytedance/sonic"
-	"github.com/midtrans/midtrans-go/coreapi"
 	"go.uber.org/zap"
 )
 
 type SchedulerUseCase interface {
 	CheckTransactionStatus(ctx context.Context) error
+	ResumeSagas(ctx context.Context) error
+	RefundLateSettlements(ctx context.Context) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) error
+	DeleteStaleGuestCarts(ctx context.Context) error
 }
 
 type schedulerUseCase struct {
 	db                    store.DatabaseStore
 	transactionRepository repository.TransactionRepository
-	paymentAdapter        adapter.PaymentAdapter
+	paymentProviders      adapter.PaymentProviderRegistry
 	transactionUseCase    contract.TransactionUseCase
 	cancelationUseCase    contract.CancelationUseCase
+	refundUseCase         contract.RefundUseCase
+	idempotencyUseCase    contract.IdempotencyUseCase
+	cartUseCase           contract.CartUseCase
+	sagaOrchestrator      saga.Orchestrator
 	logs                  logs.Log
 }
 
@@ -35,19 +43,27 @@ func NewSchedulerUseCase(
 	transactionRepository repository.TransactionRepository,
 	transactionUseCase contract.TransactionUseCase,
 	cancelationUseCase contract.CancelationUseCase,
-	paymentAdapter adapter.PaymentAdapter,
+	refundUseCase contract.RefundUseCase,
+	idempotencyUseCase contract.IdempotencyUseCase,
+	cartUseCase contract.CartUseCase,
+	paymentProviders adapter.PaymentProviderRegistry,
+	sagaOrchestrator saga.Orchestrator,
 	logs logs.Log,
 ) SchedulerUseCase {
 	return &schedulerUseCase{db: db,
 		transactionRepository: transactionRepository,
 		transactionUseCase:    transactionUseCase,
 		cancelationUseCase:    cancelationUseCase,
-		paymentAdapter:        paymentAdapter,
+		refundUseCase:         refundUseCase,
+		idempotencyUseCase:    idempotencyUseCase,
+		cartUseCase:           cartUseCase,
+		paymentProviders:      paymentProviders,
+		sagaOrchestrator:      sagaOrchestrator,
 		logs:                  logs}
 }
 
 type Job struct {
-	response    *coreapi.TransactionStatusResponse
+	response    *model.PaymentStatusResponse
 	transaction *entity.Transaction
 }
 
@@ -82,7 +98,16 @@ func (uc *schedulerUseCase) CheckTransactionStatus(ctx context.Context) error {
 				// 		continue // skip pengecekan ke payment gateway
 				// 	}
 				// }
-				resp, err := uc.paymentAdapter.CheckTransactionStatus(context.Background(), tx.ID.String())
+				paymentProvider, err := uc.paymentProviders.Get(tx.PaymentProvider)
+				if err != nil {
+					uc.logs.Error("[SchedulerUseCase] CheckTransactionStatus error:", zap.Error(err), zap.String("transactionId", tx.ID.String()))
+					continue
+				}
+
+				resp, err := paymentProvider.GetStatus(context.Background(), &model.PaymentStatusRequest{
+					OrderID:   tx.ID.String(),
+					Reference: tx.PaymentReference.String,
+				})
 				if err != nil {
 					uc.logs.Error("[SchedulerUseCase] CheckTransactionStatus error:", zap.Error(err), zap.String("transactionId", tx.ID.String()))
 					continue
@@ -102,13 +127,7 @@ func (uc *schedulerUseCase) CheckTransactionStatus(ctx context.Context) error {
 		go func() {
 			defer wgUpdate.Done()
 			for job := range updateJobs {
-				jsonValue, err := sonic.ConfigFastest.Marshal(job.response)
-				if err != nil {
-					uc.logs.Error("[SchedulerUseCase] Failed to marshal response", zap.Error(err), zap.String("transactionId", job.transaction.ID.String()))
-					continue
-				}
-
-				request := converter.SchedulerReqToCheckAndUpdate(job.response, jsonValue)
+				request := converter.PaymentStatusToCheckAndUpdate(job.response)
 				_ = uc.transactionUseCase.CheckAndUpdateTransaction(
 					ctx, request,
 				)
@@ -133,3 +152,23 @@ func (uc *schedulerUseCase) CheckTransactionStatus(ctx context.Context) error {
 	wgUpdate.Wait()
 	return nil
 }
+
+// ResumeSagas continues sagas with a due retry, an unfinished compensation or a lost owner.
+func (uc *schedulerUseCase) ResumeSagas(ctx context.Context) error {
+	return uc.sagaOrchestrator.Resume(ctx)
+}
+
+// RefundLateSettlements starts the automatic refund of transactions that were paid after they expired.
+func (uc *schedulerUseCase) RefundLateSettlements(ctx context.Context) error {
+	return uc.refundUseCase.RefundLateSettlements(ctx)
+}
+
+// DeleteExpiredIdempotencyKeys removes the idempotency keys whose response is no longer replayed.
+func (uc *schedulerUseCase) DeleteExpiredIdempotencyKeys(ctx context.Context) error {
+	return uc.idempotencyUseCase.DeleteExpired(ctx)
+}
+
+// DeleteStaleGuestCarts removes the guest carts nobody touched for a while, user carts are kept.
+func (uc *schedulerUseCase) DeleteStaleGuestCarts(ctx context.Context) error {
+	return uc.cartUseCase.DeleteStaleGuestCarts(ctx)
+}
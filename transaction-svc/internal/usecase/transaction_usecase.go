@@ -2,9 +2,7 @@ package usecase
 
 import (
 	"context"
-	"crypto/sha512"
 	"database/sql"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,176 +19,153 @@ import (
 	"go-saga-pattern/transaction-svc/internal/model"
 	"go-saga-pattern/transaction-svc/internal/model/converter"
 	"go-saga-pattern/transaction-svc/internal/model/event"
+	"go-saga-pattern/transaction-svc/internal/pricing"
 	"go-saga-pattern/transaction-svc/internal/repository"
 	"go-saga-pattern/transaction-svc/internal/repository/store"
+	"go-saga-pattern/transaction-svc/internal/saga"
 	"go-saga-pattern/transaction-svc/internal/usecase/contract"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/redis/go-redis/v9"
-	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
 )
 
 type transactionUseCase struct {
 	transactionRepo       repository.TransactionRepository
 	transactionDetailRepo repository.TransactionDetailRepository
+	promotionRepo         repository.PromotionRepository
 	databaseStore         store.DatabaseStore
+	outboxRepo            repository.OutboxRepository
+	sagaOrchestrator      saga.Orchestrator
 	productAdapter        adapter.ProductAdapter
-	messagingAdapter      adapter.MessagingAdapter
-	paymentAdapter        adapter.PaymentAdapter
+	paymentProviders      adapter.PaymentProviderRegistry
 	cacheAdapter          adapter.CacheAdapter
+	statusAdapter         adapter.TransactionStatusAdapter
 	expireTask            task.TransactionTask
 	timeParserHelper      helper.TimeParserHelper
+	priceTolerance        model.PriceTolerance
+	exchangeRates         adapter.ExchangeRateProvider
+	currencyPolicy        enum.CurrencyPolicy
+	pricingPipeline       pricing.Pipeline
 	validator             helper.CustomValidator
 	log                   logs.Log
 }
 
 func NewTransactionUseCase(transactionRepo repository.TransactionRepository, transactionDetailRepo repository.TransactionDetailRepository,
-	databaseStore store.DatabaseStore, productAdapter adapter.ProductAdapter, messagingAdapter adapter.MessagingAdapter, paymentAdapter adapter.PaymentAdapter,
-	cacheAdapter adapter.CacheAdapter, expireTask task.TransactionTask, timeParserHelper helper.TimeParserHelper, validator helper.CustomValidator,
-	log logs.Log) contract.TransactionUseCase {
-	return &transactionUseCase{
+	promotionRepo repository.PromotionRepository, outboxRepo repository.OutboxRepository, databaseStore store.DatabaseStore, sagaOrchestrator saga.Orchestrator, productAdapter adapter.ProductAdapter,
+	paymentProviders adapter.PaymentProviderRegistry, cacheAdapter adapter.CacheAdapter, statusAdapter adapter.TransactionStatusAdapter,
+	expireTask task.TransactionTask, timeParserHelper helper.TimeParserHelper, priceTolerance model.PriceTolerance,
+	exchangeRates adapter.ExchangeRateProvider, currencyPolicy enum.CurrencyPolicy, pricingPipeline pricing.Pipeline,
+	validator helper.CustomValidator, log logs.Log) contract.TransactionUseCase {
+	uc := &transactionUseCase{
 		transactionRepo:       transactionRepo,
 		transactionDetailRepo: transactionDetailRepo,
+		promotionRepo:         promotionRepo,
+		outboxRepo:            outboxRepo,
 		databaseStore:         databaseStore,
+		sagaOrchestrator:      sagaOrchestrator,
 		productAdapter:        productAdapter,
-		messagingAdapter:      messagingAdapter,
-		paymentAdapter:        paymentAdapter,
+		paymentProviders:      paymentProviders,
 		cacheAdapter:          cacheAdapter,
+		statusAdapter:         statusAdapter,
 		expireTask:            expireTask,
 		timeParserHelper:      timeParserHelper,
+		priceTolerance:        priceTolerance,
+		exchangeRates:         exchangeRates,
+		currencyPolicy:        currencyPolicy,
+		pricingPipeline:       pricingPipeline,
 		validator:             validator,
 		log:                   log,
 	}
+
+	sagaOrchestrator.Register(uc.checkoutSagaDefinition())
+	return uc
 }
 
+// CreateTransaction runs the checkout saga until it waits for the payment, a missing snap token means the
+// payment token is being retried by the payment token task in the background.
 func (uc *transactionUseCase) CreateTransaction(ctx context.Context, request *model.CreateTransactionRequest) (*model.CreateTransactionResponse, error) {
 	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
 		return nil, validatonErrs
 	}
 
-	var transaction *entity.Transaction
-	var transactionDetails []*entity.TransactionDetail
-	productReqs := make([]*model.CheckProductQuantity, 0, len(request.Products))
-	for _, productReq := range request.Products {
-		productReqs = append(productReqs, &model.CheckProductQuantity{
-			ProductID: productReq.ProductID,
-			Quantity:  productReq.Quantity,
-			Price:     productReq.Price,
-		})
+	paymentProvider := uc.paymentProviders.Default().Name()
+	if request.PaymentProvider != "" {
+		if _, err := uc.paymentProviders.Get(request.PaymentProvider); err != nil {
+			return nil, helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PaymentProviderNotSupported)
+		}
+		paymentProvider = request.PaymentProvider
 	}
 
-	uc.log.Info("Creating transaction", zap.String("user_id", request.UserID.String()), zap.Any("products", productReqs))
 	transactionID := uuid.New()
-
-	products, err := uc.productAdapter.CheckProductAndReserve(ctx, transactionID, productReqs)
-	if err != nil {
-		uc.log.Warn("failed to check product and reserve", zap.Error(err), zap.String("transaction_id", transactionID.String()))
-		return nil, err
+	data := &model.CheckoutSagaData{
+		TransactionID:   transactionID,
+		UserID:          request.UserID,
+		Products:        request.Products,
+		PaymentProvider: paymentProvider,
+		Currency:        request.Currency,
+		ExpectedTotal:   request.ExpectedTotal,
+		PromoCodes:      request.PromoCodes,
 	}
 
-	if err := store.BeginTransaction(ctx, uc.log, uc.databaseStore, func(tx store.Transaction) error {
-		//test purpose
-		// return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PriceChanged)
-
-		var totalPrice float64
-		for _, product := range productReqs {
-			totalPrice += product.Price * float64(product.Quantity)
-		}
-
-		transaction = &entity.Transaction{
-			ID:                transactionID,
-			UserID:            request.UserID,
-			TotalPrice:        totalPrice,
-			TransactionStatus: enum.TransactionStatusPending,
-			InternalStatus:    enum.TrxInternalStatusPending,
-		}
-
-		transaction, err = uc.transactionRepo.Insert(ctx, uc.databaseStore, transaction)
-		if err != nil {
-			return helper.WrapInternalServerError(uc.log, "failed to insert transaction", err)
-		}
-
-		transactionDetails = make([]*entity.TransactionDetail, 0, len(products))
-		for _, product := range productReqs {
-			transactionDetails = append(transactionDetails, &entity.TransactionDetail{
-				TransactionID: transaction.ID,
-				ProductID:     product.ProductID,
-				Quantity:      product.Quantity,
-				Price:         product.Price * float64(product.Quantity),
-			})
-		}
+	uc.log.Info("Creating transaction", zap.String("user_id", request.UserID.String()), zap.String("transaction_id", transactionID.String()),
+		zap.String("payment_provider", string(paymentProvider)), zap.Any("products", request.Products))
 
-		_, err = uc.transactionDetailRepo.InsertMany(ctx, uc.databaseStore, transactionDetails)
-		if err != nil {
-			return helper.WrapInternalServerError(uc.log, "failed to insert transaction details", err)
-		}
-
-		return nil
-	}); err != nil {
-		event := &event.TransactionEvent{
-			TransactionID: transactionID.String(),
-			Status:        enum.TransactionEventCancelled,
-		}
-
-		if err := uc.messagingAdapter.Publish(ctx, "transaction.canceled", event); err != nil {
-			uc.log.Error("failed to create transaction", zap.Error(err), zap.String("transaction_id", transactionID.String()))
-			return nil, helper.WrapInternalServerError(uc.log, "failed to publish transaction canceled event", err)
-		}
-
-		return nil, err
-	}
-
-	event := &event.TransactionEvent{
-		TransactionID: transaction.ID.String(),
-		Status:        enum.TransactionEventCommited,
-	}
-
-	if err := uc.messagingAdapter.Publish(ctx, "transaction.committed", event); err != nil {
-		uc.log.Error("failed to publish transaction committed event", zap.Error(err), zap.String("transaction_id", transactionID.String()))
-		return nil, helper.WrapInternalServerError(uc.log, "failed to publish transaction committed event", err)
-	}
-
-	token, redirectUrl, err := uc.getPaymentToken(ctx, transaction)
+	instance, err := uc.sagaOrchestrator.Start(ctx, checkoutSagaType, transactionID, data)
 	if err != nil {
-		return converter.TransactionToCreateResponse(transaction, ""), nil
+		uc.log.Warn("checkout saga failed", zap.Error(err), zap.String("transaction_id", transactionID.String()))
+		return nil, err
 	}
 
-	if err := uc.updateTransactionToken(ctx, token, transaction.ID); err != nil {
-		uc.log.Error("failed to update transaction token", zap.Error(err), zap.String("transaction_id", transaction.ID.String()))
-		return nil, helper.WrapInternalServerError(uc.log, "failed to update transaction token", err)
+	if err := instance.Bind(data); err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to read checkout saga payload", err)
 	}
 
-	transaction.SnapToken = nullable.ToSQLString(&token)
-
-	uc.log.Info("transaction created successfully", zap.Any("transaction", transaction))
-	return converter.TransactionToCreateResponse(transaction, redirectUrl), nil
+	uc.log.Info("transaction created successfully", zap.String("transaction_id", transactionID.String()),
+		zap.String("saga_status", string(instance.Status)), zap.String("saga_step", instance.CurrentStep.String))
+	return &model.CreateTransactionResponse{
+		TransactionId:   transactionID.String(),
+		PaymentProvider: data.PaymentProvider,
+		SnapToken:       data.SnapToken,
+		RedirectURL:     data.RedirectURL,
+		Discount:        data.Discount,
+		Breakdown:       data.Breakdown,
+		TotalPrice:      data.TotalPrice,
+	}, nil
 }
 
-func (uc *transactionUseCase) updateTransactionToken(ctx context.Context, token string, transactionID uuid.UUID) error {
+func (uc *transactionUseCase) updateTransactionToken(ctx context.Context, charge *model.ChargeResponse, transactionID uuid.UUID) error {
 	uc.log.Info("Updating transaction token",
 		zap.Any("transaction_id", transactionID),
-		zap.String("token", token))
+		zap.String("payment_provider", string(charge.Provider)),
+		zap.String("token", charge.Token))
 
 	now := time.Now()
 	transaction := &entity.Transaction{
-		ID:             transactionID,
-		InternalStatus: enum.TrxInternalStatusTokenReady,
-		SnapToken:      sql.NullString{String: token, Valid: true},
-		UpdatedAt:      &now,
+		ID:                 transactionID,
+		InternalStatus:     enum.TrxInternalStatusPending,
+		SnapToken:          sql.NullString{String: charge.Token, Valid: true},
+		PaymentReference:   sql.NullString{String: charge.Reference, Valid: charge.Reference != ""},
+		PaymentRedirectURL: sql.NullString{String: charge.RedirectURL, Valid: charge.RedirectURL != ""},
+		UpdatedAt:          &now,
+	}
+
+	transition, err := transaction.TransitionTo(enum.TrxInternalStatusTokenReady)
+	if err != nil {
+		return err
 	}
 
 	uc.log.Info("Updating transaction token in database",
 		zap.Any("transaction_id", transaction.ID),
 		zap.String("token", transaction.SnapToken.String))
 
-	err := uc.transactionRepo.UpdateToken(ctx, uc.databaseStore, transaction)
+	err = uc.transactionRepo.UpdateToken(ctx, uc.databaseStore, transaction, transition)
 	if err != nil {
 		return helper.WrapInternalServerError(uc.log, "failed to update snapshot token in database", err)
 	}
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.log, transactionID, transition)
 
 	if err = uc.expireTask.EnqueueTransactionExpire(transactionID); err != nil {
 		uc.log.Error("failed to enqueue transaction expire task", zap.Error(err), zap.String("transaction_id", transaction.ID.String()))
@@ -205,103 +180,32 @@ func (uc *transactionUseCase) updateTransactionToken(ctx context.Context, token
 	return nil
 }
 
-func (uc *transactionUseCase) getPaymentToken(ctx context.Context, transaction *entity.Transaction) (string, string, error) {
-	snapRequest := &model.PaymentSnapshotRequest{
-		OrderID:     transaction.ID.String(),
-		GrossAmount: int64(transaction.TotalPrice),
-		Email:       "",
+// HandlePaymentNotification verifies a webhook with the provider it was sent to before applying its payment status.
+func (uc *transactionUseCase) HandlePaymentNotification(ctx context.Context, request *model.PaymentNotificationRequest) error {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return validatonErrs
 	}
 
-	snapResponse, err := uc.paymentAdapter.CreateSnapshot(ctx, snapRequest)
-	if err == nil {
-		return snapResponse.Token, snapResponse.RedirectURL, nil
+	paymentProvider, err := uc.paymentProviders.Get(request.Provider)
+	if err != nil {
+		return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.PaymentProviderNotSupported)
 	}
 
-	// Jalankan retry async jika terjadi error
-	go func() {
-		const maxRetry = 5
-		retry := 0
-
-		for retry < maxRetry && !errors.Is(err, gobreaker.ErrOpenState) {
-			time.Sleep(time.Second * time.Duration(2<<retry)) // exponential backoff: 2s, 4s, 8s, ...
-
-			snapResponse, retryErr := uc.paymentAdapter.CreateSnapshot(context.TODO(), snapRequest)
-			if retryErr == nil {
-				uc.log.Info("[RetrySuccess] Transaction berhasil update snap token", zap.String("transaction_id", transaction.ID.String()), zap.String("snap_token", snapResponse.Token))
-				if err := uc.updateTransactionToken(ctx, snapResponse.Token, transaction.ID); err != nil {
-					uc.log.Warn("[UpdateFailed] Gagal update token transaksi setelah retry", zap.Error(err), zap.String("transaction_id", transaction.ID.String()), zap.String("snap_token", snapResponse.Token))
-				} else {
-					uc.log.Info("[UpdateSuccess] Token transaksi berhasil diupdate setelah retry", zap.String("transaction_id", transaction.ID.String()), zap.String("snap_token", snapResponse.Token))
-				}
-				return
-			}
-
-			if errors.Is(retryErr, gobreaker.ErrOpenState) {
-
-				break
-			}
-
-			err = retryErr
-			retry++
-		}
-
-		// Jika tetap gagal karena breaker open, listen redis stream
-		uc.log.Warn("[Recovery] Gagal membuat snapshot, akan mencoba recovery", zap.Error(err), zap.String("transaction_id", transaction.ID.String()))
-		uc.subscribeMidtransRecoveryAndRetry(uc.paymentAdapter, snapRequest, transaction.ID)
-	}()
-
-	return "", "", fmt.Errorf("midtrans create snapshot error: %w", err)
-}
-
-func (uc *transactionUseCase) subscribeMidtransRecoveryAndRetry(adapter adapter.PaymentAdapter, req *model.PaymentSnapshotRequest, transactionID uuid.UUID) {
-	for {
-		streams, err := uc.cacheAdapter.XRead(context.Background(), &redis.XReadArgs{
-			Streams: []string{"midtrans:recovery", "0"},
-			Block:   0, // block selamanya sampai ada signal
-			Count:   1,
-		})
-
-		if err != nil {
-			uc.log.Error("[RecoveryFailed] Gagal membaca stream Redis", zap.Error(err), zap.String("transaction_id", transactionID.String()))
-			time.Sleep(time.Second * 5)
-			continue
-		}
-
-		for _, msg := range streams[0].Messages {
-			uc.log.Info("[RecoverySignal] Received recovery signal from Redis stream",
-				zap.String("transaction_id", transactionID.String()),
-				zap.String("message_id", msg.ID),
-				zap.String("message_data", msg.Values["data"].(string)))
-
-			ctx := context.Background()
-			resp, err := adapter.CreateSnapshot(ctx, req)
-			if err != nil {
-				uc.log.Error(fmt.Sprintf("[RecoveryFailed] Retry gagal: tx %s dengan error: %v", transactionID, err))
-				continue
-			}
-
-			uc.log.Info("[RecoverySuccess] Retry berhasil untuk transaksi", zap.String("transaction_id", transactionID.String()))
-			if err := uc.updateTransactionToken(ctx, resp.Token, transactionID); err != nil {
-				uc.log.Error(fmt.Sprintf("[UpdateFailed] Update transaction token failed with reason : %v", err))
-			}
-
-			return
+	paymentStatus, err := paymentProvider.VerifyNotification(ctx, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, adapter.ErrInvalidPaymentSignature):
+			uc.log.Warn("Invalid payment notification signature", zap.String("payment_provider", string(request.Provider)))
+			return helper.NewUseCaseError(errorcode.ErrForbidden, "Invalid signature key")
+		case errors.Is(err, adapter.ErrInvalidPaymentNotification):
+			uc.log.Warn("Invalid payment notification", zap.String("payment_provider", string(request.Provider)), zap.Error(err))
+			return helper.NewUseCaseError(errorcode.ErrInvalidArgument, message.InvalidPaymentNotification)
+		default:
+			return helper.WrapInternalServerError(uc.log, "failed to verify payment notification", err)
 		}
 	}
-}
 
-func (uc *transactionUseCase) CheckPaymentSignature(signatureKey, transcationId, statusCode, grossAmount string) (bool, string) {
-	uc.log.Info("Checking payment signature",
-		zap.String("transaction_id", transcationId),
-		zap.String("status_code", statusCode),
-		zap.String("gross_amount", grossAmount),
-		zap.String("payment_server_key", uc.paymentAdapter.GetPaymentServerKey()))
-
-	signatureToCompare := transcationId + statusCode + grossAmount + uc.paymentAdapter.GetPaymentServerKey()
-	hash := sha512.New()
-	hash.Write([]byte(signatureToCompare))
-	hashedSignature := hex.EncodeToString(hash.Sum(nil))
-	return hashedSignature == signatureKey, hashedSignature
+	return uc.CheckAndUpdateTransaction(ctx, converter.PaymentStatusToCheckAndUpdate(paymentStatus))
 }
 
 func (uc *transactionUseCase) CheckAndUpdateTransaction(ctx context.Context, request *model.CheckAndUpdateTransactionRequest) error {
@@ -310,7 +214,7 @@ func (uc *transactionUseCase) CheckAndUpdateTransaction(ctx context.Context, req
 	}
 
 	var transaction *entity.Transaction
-	var transactionStatus enum.TransactionStatus
+	var appliedTransition *entity.TransactionTransition
 	var transactionInternalStatus enum.TrxInternalStatus
 	var settlementTimePtr *time.Time
 	var err error
@@ -324,147 +228,183 @@ func (uc *transactionUseCase) CheckAndUpdateTransaction(ctx context.Context, req
 			return helper.WrapInternalServerError(uc.log, "failed to update transaction callback in database", err)
 		}
 
-		requestIsValid, hashedSignature := uc.CheckPaymentSignature(request.SignatureKey, transaction.ID.String(), request.StatusCode, request.GrossAmount)
-		if !requestIsValid {
-			uc.log.Warn("Invalid signature key", zap.String("transaction_id", transaction.ID.String()), zap.String("hashed_signature", hashedSignature))
-			return helper.NewUseCaseError(errorcode.ErrForbidden, "Invalid signature key")
+		if transaction.PaymentProvider != request.Provider {
+			uc.log.Warn("Ignoring payment status from another payment provider", zap.String("transaction_id", transaction.ID.String()),
+				zap.String("payment_provider", string(transaction.PaymentProvider)), zap.String("request_provider", string(request.Provider)))
+			return helper.NewUseCaseError(errorcode.ErrForbidden, message.PaymentProviderMismatch)
 		}
 
-		//Only check if internal transaction status is "PENDING", "TOKEN_READY", "EXPIRED"
-		if transaction.InternalStatus != enum.TrxInternalStatusPending &&
-			transaction.InternalStatus != enum.TrxInternalStatusTokenReady &&
-			transaction.InternalStatus != enum.TrxInternalStatusExpired {
-			uc.log.Warn("Transaction internal status already final", zap.String("transaction_id", transaction.ID.String()), zap.String("internal_status", string(transaction.InternalStatus)))
+		switch transaction.InternalStatus {
+		case enum.TrxInternalStatusRefunding, enum.TrxInternalStatusPartiallyRefunded, enum.TrxInternalStatusRefunded:
+			// refunds are driven by the refund saga, a repeated settlement callback must not move the transaction back
+			uc.log.Info("Ignoring payment status for refunded transaction", zap.String("transaction_id", transaction.ID.String()),
+				zap.String("payment_status", string(request.PaymentStatus)), zap.String("internal_status", string(transaction.InternalStatus)))
 			return nil
 		}
 
 		now := time.Now()
+		settlementTime := now
+		if request.SettlementTime != nil {
+			settlementTime = *request.SettlementTime
+		}
 
 		if transaction.InternalStatus == enum.TrxInternalStatusExpired {
-			// IF External Or Midtrans Payment Settled Check the Settlement Time
-			if request.MidtransTransactionStatus == string(enum.PaymentStatusSettlement) {
-				settlementTime, err := uc.timeParserHelper.TimeParseInDefaultLocation(request.SettlementTime)
-				if err != nil {
-					uc.log.Error("failed to parse settlement time from external webhook request", zap.String("transaction_id", request.OrderID), zap.Error(err))
-					return err
-				}
-
+			// IF External Payment Settled Check the Settlement Time
+			if request.PaymentStatus == enum.PaymentStatusPaid {
 				settlementTimePtr = &settlementTime
 				graceDeadline := transaction.UpdatedAt.Add(5 * time.Minute)
 
-				//Checking settlement time from external midtrans with internal expired time
+				//Checking settlement time from external payment provider with internal expired time
 				if settlementTime.After(graceDeadline) {
-					transactionStatus = enum.TransactionStatusExpired //User transaction is valid or settled
 					transactionInternalStatus = enum.TrxInternalStatusLateSettlement
 				} else {
 					//User transaction is valid or settled even the user come late but from system not the settled time
-					transactionStatus = enum.TransactionStatusSuccess
 					transactionInternalStatus = enum.TrxInternalStatusExpiredCheckedValid
 					transaction.PaymentAt = nullable.ToSQLTime(now)
 				}
-				transaction.ExternalSettlementAt = nullable.ToSQLTime(now)
+				transaction.ExternalSettlementAt = nullable.ToSQLTime(settlementTime)
 				transaction.SnapToken = sql.NullString{String: "", Valid: true}
 			} else {
 				transactionInternalStatus = enum.TrxInternalStatusExpiredCheckedInvalid //User doesnt settled even internal status has expired
-				transactionStatus = enum.TransactionStatusExpired
 			}
 
 		} else {
-			switch request.MidtransTransactionStatus {
-			case string(enum.PaymentStatusCapture), string(enum.PaymentStatusSettlement):
-				settlementTime, err := uc.timeParserHelper.TimeParseInDefaultLocation(request.SettlementTime)
-				if err != nil {
-					uc.log.Error("failed to parse settlement time from external webhook request", zap.String("transaction_id", request.OrderID), zap.Error(err))
-					return err
-				}
-
+			switch request.PaymentStatus {
+			case enum.PaymentStatusPaid:
 				settlementTimePtr = &settlementTime
-				transactionStatus = enum.TransactionStatusSuccess
 				transactionInternalStatus = enum.TrxInternalStatusSettled
 				transaction.PaymentAt = nullable.ToSQLTime(now)
 				transaction.SnapToken = sql.NullString{String: "", Valid: true}
 				transaction.ExternalSettlementAt = nullable.ToSQLTime(*settlementTimePtr)
 
-			case string(enum.PaymentStatusPending):
-				transactionStatus = enum.TransactionStatusPending
-				transactionInternalStatus = enum.TrxInternalStatusPending
+			case enum.PaymentStatusPending:
+				// Still waiting for the buyer, only the callback is recorded
+				transactionInternalStatus = transaction.InternalStatus
 
-			case string(enum.PaymentStatusExpire):
-				transactionStatus = enum.TransactionStatusExpired
+			case enum.PaymentStatusExpired:
 				transactionInternalStatus = enum.TrxInternalStatusExpiredCheckedInvalid
 				transaction.SnapToken = sql.NullString{String: "", Valid: true}
 
-			case string(enum.PaymentStatusFailure), string(enum.PaymentStatusDeny):
-				transactionStatus = enum.TransactionStatusFailed
+			case enum.PaymentStatusFailed:
 				transactionInternalStatus = enum.TrxInternalStatusFailed
 				transaction.SnapToken = sql.NullString{String: "", Valid: true}
 
-			case string(enum.PaymentStatusCancel):
-				transactionStatus = enum.TransactionStatusCancelled
+			case enum.PaymentStatusCanceled:
 				transactionInternalStatus = enum.TrxInternalStatusCancelledBySystem
 				transaction.SnapToken = sql.NullString{String: "", Valid: true}
+
+			default:
+				uc.log.Info("Ignoring payment status without internal transition", zap.String("transaction_id", transaction.ID.String()),
+					zap.String("payment_status", string(request.PaymentStatus)), zap.String("external_status", request.ExternalStatus))
+				return nil
+			}
+		}
+
+		transition, err := transaction.TransitionTo(transactionInternalStatus)
+		if err != nil {
+			var invalidTransition *entity.InvalidTransitionError
+			if errors.As(err, &invalidTransition) {
+				uc.log.Warn("Ignoring payment callback for transaction", zap.String("transaction_id", transaction.ID.String()),
+					zap.String("payment_status", string(request.PaymentStatus)), zap.Error(err))
+				return nil
 			}
+			return err
 		}
 
-		transaction.TransactionStatus = transactionStatus
-		transaction.InternalStatus = transactionInternalStatus
 		transaction.ExternalStatus = sql.NullString{
-			String: request.MidtransTransactionStatus,
+			String: request.ExternalStatus,
 			Valid:  true,
 		}
 
 		externalCallbackResponse := json.RawMessage(request.Body)
 		transaction.ExternalCallbackResponse = &externalCallbackResponse
 
-		if err := uc.transactionRepo.UpdateCallback(ctx, tx, transaction); err != nil {
+		if err := uc.transactionRepo.UpdateCallback(ctx, tx, transaction, transition); err != nil {
 			return helper.WrapInternalServerError(uc.log, "failed to update transaction callback in database", err)
 		}
+		appliedTransition = transition
+
+		if transition.Event == nil {
+			return nil
+		}
+
+		if err := insertTransactionOutbox(ctx, tx, uc.outboxRepo, transition.Event.Subject, transaction.ID, transition.Event.Status); err != nil {
+			return helper.WrapInternalServerError(uc.log, "failed to insert transaction outbox event", err)
+		}
 
 		return nil
 	}); err != nil {
 		return helper.WrapInternalServerError(uc.log, "failed to update transaction callback in database", err)
 	}
 
-	switch transactionStatus {
-	case enum.TransactionStatusCancelled:
-		event := &event.TransactionEvent{
-			TransactionID: transaction.ID.String(),
-			Status:        enum.TransactionEventCancelled,
-		}
+	publishTransactionStatus(ctx, uc.statusAdapter, uc.log, transaction.ID, appliedTransition)
 
-		if err := uc.messagingAdapter.Publish(ctx, "transaction.canceled", event); err != nil {
-			return helper.WrapInternalServerError(uc.log, "failed to publish transaction canceled event", err)
-		}
-	case enum.TransactionStatusExpired, enum.TransactionStatusFailed:
-		event := &event.TransactionEvent{
-			TransactionID: transaction.ID.String(),
-			Status:        enum.TransactionEventExpired,
-		}
-
-		if err := uc.messagingAdapter.Publish(ctx, "transaction.expired", event); err != nil {
-			return helper.WrapInternalServerError(uc.log, "failed to publish transaction expired event", err)
-		}
+	switch transaction.TransactionStatus {
+	case enum.TransactionStatusSuccess:
+		signalCheckoutPayment(ctx, uc.sagaOrchestrator, uc.log, transaction.ID, nil)
+	case enum.TransactionStatusExpired, enum.TransactionStatusFailed, enum.TransactionStatusCancelled:
+		signalCheckoutPayment(ctx, uc.sagaOrchestrator, uc.log, transaction.ID,
+			fmt.Errorf("payment %s", strings.ToLower(string(transaction.TransactionStatus))))
 	}
 
-	if transactionStatus != enum.TransactionStatusSuccess {
+	if transaction.TransactionStatus != enum.TransactionStatusSuccess {
 		uc.log.Info("Transaction is not successful, no further processing required",
 			zap.String("transaction_id", transaction.ID.String()),
 			zap.String("transaction_status", string(transaction.TransactionStatus)),
 			zap.String("internal_status", string(transaction.InternalStatus)))
+	}
 
-		return nil
+	return nil
+}
+
+func (uc *transactionUseCase) GetCheckoutSaga(ctx context.Context, request *model.GetTransactionRequest) (*model.SagaResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
 	}
 
-	event := &event.TransactionEvent{
-		TransactionID: transaction.ID.String(),
-		Status:        enum.TransactionEventSettled,
+	transaction, err := uc.transactionRepo.FindByID(ctx, uc.databaseStore, request.TransacitonID.String(), false)
+	if err != nil {
+		if strings.Contains(err.Error(), pgx.ErrNoRows.Error()) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+		}
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find transaction by id", err)
 	}
 
-	if err := uc.messagingAdapter.Publish(ctx, "transaction.settled", event); err != nil {
-		return helper.WrapInternalServerError(uc.log, "failed to publish transaction settled event", err)
+	if transaction.UserID != request.UserID {
+		return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
 	}
 
-	return nil
+	instance, err := uc.sagaOrchestrator.Find(ctx, checkoutSagaType, transaction.ID)
+	if err != nil {
+		if errors.Is(err, saga.ErrInstanceNotFound) {
+			return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.SagaNotFound)
+		}
+		return nil, err
+	}
+
+	return converter.SagaToResponse(instance.SagaInstance, instance.Steps), nil
+}
+
+func (uc *transactionUseCase) UserGet(ctx context.Context, request *model.GetTransactionRequest) (*model.TransactionResponse, error) {
+	if validatonErrs := uc.validator.ValidateUseCase(request); validatonErrs != nil {
+		return nil, validatonErrs
+	}
+
+	transactionWithDetails, err := uc.transactionRepo.FindDetailByID(ctx, uc.databaseStore, request.TransacitonID.String())
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find transaction with details by id", err)
+	}
+
+	if len(transactionWithDetails) == 0 || transactionWithDetails[0].TransactionUserID != request.UserID {
+		return nil, helper.NewUseCaseError(errorcode.ErrResourceNotFound, message.TransactionNotFound)
+	}
+
+	histories, err := uc.transactionRepo.FindStatusHistoryByID(ctx, uc.databaseStore, request.TransacitonID.String())
+	if err != nil {
+		return nil, helper.WrapInternalServerError(uc.log, "failed to find transaction status history by id", err)
+	}
+
+	return converter.TransactionWithDetailToResponse(transactionWithDetails, histories), nil
 }
 
 func (uc *transactionUseCase) UserSearch(ctx context.Context, request *model.UserSearchTransactionRequest) ([]*model.TransactionResponse, *web.PageMetadata, error) {
@@ -509,3 +449,22 @@ func (uc *transactionUseCase) OwnerSearchWithDetail(ctx context.Context, request
 
 	return converter.TransactionWithDetailAndTotalToResponse(transactions, true), metadata, nil
 }
+
+// publishTransactionStatus tells the status watchers about a committed status change. Watchers reload the transaction
+// when they connect, so a change that could not be published is only logged.
+func publishTransactionStatus(ctx context.Context, statusAdapter adapter.TransactionStatusAdapter, log logs.Log,
+	transactionID uuid.UUID, transition *entity.TransactionTransition) {
+	if transition == nil || transition.From == transition.To {
+		return
+	}
+
+	if err := statusAdapter.Publish(ctx, &event.TransactionStatusEvent{
+		TransactionID:     transactionID.String(),
+		Status:            transition.To,
+		TransactionStatus: transition.TransactionStatus,
+		UpdatedAt:         time.Now().Format(time.RFC1123),
+	}); err != nil {
+		log.Warn("failed to publish transaction status", zap.String("transaction_id", transactionID.String()),
+			zap.String("status", string(transition.To)), zap.Error(err))
+	}
+}